@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxActions bounds the actions kept in memory for the action log view; the file, when
+// there is one, keeps them all.
+const maxActions = 200
+
+// actionLogQueue bounds the entries waiting for the file writer, room for a tree kill of
+// a large process tree. A full queue drops entries instead of blocking the UI.
+const actionLogQueue = 1024
+
+// actionOK is the result of an action that succeeded.
+const actionOK = "ok"
+
+// actionEntry is one action taken through the TUI: a signal, renice, I/O priority or
+// affinity change, with its outcome.
+type actionEntry struct {
+	at     time.Time
+	pid    int32
+	name   string
+	action string
+	// result is actionOK or why the action failed.
+	result string
+}
+
+// line formats e for the action log file, one logfmt line per action so it greps and
+// parses easily after the fact.
+func (e actionEntry) line() string {
+	return fmt.Sprintf("%s pid=%d name=%q action=%q result=%q\n", e.at.Format(time.RFC3339), e.pid, e.name, e.action, e.result)
+}
+
+// actionLog records the actions taken through the TUI, for reconstructing what was done
+// during an incident. The latest maxActions are kept in memory for the L view; with
+// -action-log every entry is also appended to a file by a background goroutine, so a slow
+// disk never stalls a key press. Only the Update goroutine records.
+type actionLog struct {
+	entries []actionEntry
+
+	// path is the file appended to, "" for none; queue feeds its writer, which closes
+	// done once the queue is drained.
+	path  string
+	queue chan actionEntry
+	done  chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func newActionLog() *actionLog {
+	return &actionLog{}
+}
+
+// openFile starts appending every recorded action to path. The writer reports panics to
+// crash, which may be nil.
+func (l *actionLog) openFile(path string, crash *crashReporter) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open action log: %w", err)
+	}
+	l.path = path
+	l.queue = make(chan actionEntry, actionLogQueue)
+	l.done = make(chan struct{})
+	go func() {
+		defer crash.guard()
+		l.run(f)
+	}()
+	return nil
+}
+
+// run is the writer goroutine: it appends each queued entry and closes the file once the
+// queue is closed.
+func (l *actionLog) run(f *os.File) {
+	defer close(l.done)
+	for e := range l.queue {
+		if _, err := f.WriteString(e.line()); err != nil {
+			l.setErr(fmt.Errorf("could not write %s: %w", l.path, err))
+		}
+	}
+	if err := f.Close(); err != nil {
+		l.setErr(fmt.Errorf("could not close %s: %w", l.path, err))
+	}
+}
+
+// signaled reports whether a signal was sent to pid through the TUI this session, as far
+// as the entries kept in memory go.
+func (l *actionLog) signaled(pid int32) bool {
+	return slices.ContainsFunc(l.entries, func(e actionEntry) bool {
+		return e.pid == pid && e.result == actionOK && strings.HasPrefix(e.action, "SIG")
+	})
+}
+
+// record logs an action on a process; err is nil when it succeeded.
+func (l *actionLog) record(pid int32, name, action string, err error) {
+	e := actionEntry{at: time.Now(), pid: pid, name: name, action: action, result: actionOK}
+	if err != nil {
+		e.result = err.Error()
+	}
+	l.entries = append(l.entries, e)
+	if len(l.entries) > maxActions {
+		l.entries = l.entries[len(l.entries)-maxActions:]
+	}
+	if l.queue == nil {
+		return
+	}
+	select {
+	case l.queue <- e:
+	default:
+		l.setErr(errors.New("action log writer is falling behind, dropped an entry"))
+	}
+}
+
+func (l *actionLog) setErr(err error) {
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+}
+
+// writeErr returns the latest write error, nil when every entry reached the file.
+func (l *actionLog) writeErr() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// close writes the queued entries and closes the file.
+func (l *actionLog) close() error {
+	if l.queue == nil {
+		return nil
+	}
+	close(l.queue)
+	<-l.done
+	return l.writeErr()
+}
+
+// viewActionLog lists the actions of the session, newest first.
+func (m model) viewActionLog() string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	red := m.baseStyle.Foreground(Color.Red).Render
+
+	lines := []string{title("Actions taken this session"), ""}
+	if m.actionLog.path != "" {
+		lines = append(lines, hint("appending to "+m.actionLog.path))
+	}
+	if err := m.actionLog.writeErr(); err != nil {
+		lines = append(lines, red(err.Error()))
+	}
+	if len(lines) > 2 {
+		lines = append(lines, "")
+	}
+	entries := m.actionLog.entries
+	if len(entries) == 0 {
+		lines = append(lines, hint("no signals, renices, I/O priority or affinity changes yet"))
+		return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+	// Newest first, so the region cuts the oldest entries when the list is longer than the screen.
+	lines = append(lines, hint(fmt.Sprintf("%-19s %8s %-16s %-22s %s", "time", "pid", "name", "action", "result")))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		line := fmt.Sprintf("%-19s %8d %s %s ", e.at.Format(time.DateTime), e.pid, fit(e.name, 16), fit(e.action, 22))
+		if e.result == actionOK {
+			line += m.baseStyle.Foreground(Color.Green).Render(e.result)
+		} else {
+			line += red(e.result)
+		}
+		lines = append(lines, line)
+	}
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestViewActionLogAlignment(t *testing.T) {
+	withPlainProfile(t)
+	m := newModel(defaultConfig())
+	for i, name := range widthCorpus {
+		m.actionLog.record(int32(100+i), name, "SIGTERM · 终止进程 👩‍💻 to the whole group", errors.New("operation not permitted"))
+	}
+	var column int
+	for _, line := range strings.Split(ansi.Strip(m.viewActionLog()), "\n") {
+		before, _, ok := strings.Cut(line, "operation not permitted")
+		if !ok {
+			continue
+		}
+		if w := ansi.StringWidth(before); column == 0 {
+			column = w
+		} else if w != column {
+			t.Errorf("result at column %d, want %d: %q", w, column, line)
+		}
+	}
+	if column == 0 {
+		t.Fatal("no entries shown")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Nice values accepted by setpriority(2).
+const (
+	minNice = -20
+	maxNice = 19
+)
+
+// actionMenu lists what can be done with one process, so the actions don't all need a key
+// of their own. It is opened with a on the process table.
+type actionMenu struct {
+	*menu
+	proc ProcessInfo
+}
+
+// renicePicker holds the nice value being edited for a process before it is applied.
+type renicePicker struct {
+	proc ProcessInfo
+	nice int
+}
+
+// processActions lists the actions offered for p. Actions the platform lacks, or that need
+// root for another user's process, are disabled with the reason.
+func (m model) processActions(p ProcessInfo) []menuItem {
+	needsRoot := ""
+	if !ownProcess(p) {
+		needsRoot = "needs root"
+	}
+	unsupported := func(supported bool, reason string) string {
+		if !supported {
+			return "not on this platform"
+		}
+		return reason
+	}
+	pin := "Pin to the top of the table"
+	if m.isPinned(p) {
+		pin = "Unpin"
+	}
+	items := []menuItem{
+		{id: "detail", label: "Show details"},
+		{id: "kill", label: "Send a signal…", disabled: needsRoot},
+		{id: "killtree", label: "Send a signal to the whole tree…", disabled: needsRoot},
+		{id: "renice", label: fmt.Sprintf("Change the nice value (%d)…", p.Nice), disabled: unsupported(reniceSupported, needsRoot)},
+		{id: "ionice", label: "Change the I/O priority…", disabled: unsupported(ioniceSupported, needsRoot)},
+		{id: "pin", label: pin},
+		{id: "copypid", label: "Copy the PID (yp)"},
+		{id: "copycmd", label: "Copy the command line (yc)"},
+		{id: "user", label: "Show only " + p.Username + "'s processes"},
+		{id: "name", label: fmt.Sprintf("Filter by the name %q", p.Name)},
+	}
+	if p.Cmdline == "" {
+		items[7].disabled = "command line not readable"
+	}
+	if p.Username == "" {
+		items[8].label, items[8].disabled = "Show only this user's processes", "owner unknown"
+	}
+	return items
+}
+
+// openActionMenu opens the actions menu of the selected process.
+func (m *model) openActionMenu() {
+	pid, ok := m.selectedPID()
+	if !ok {
+		return
+	}
+	p, ok := m.findProcess(pid)
+	if !ok {
+		return
+	}
+	m.actions = &actionMenu{menu: newMenu(fmt.Sprintf("PID %d (%s)", p.PID, p.Name), m.processActions(p)), proc: p}
+}
+
+// updateActionMenu handles key presses while the actions menu is open.
+func (m model) updateActionMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.actions.move(-1)
+	case "down", "j":
+		m.actions.move(1)
+	// Runs the action under the cursor and closes the menu.
+	case "enter":
+		item, ok := m.actions.selected()
+		p := m.actions.proc
+		m.actions = nil
+		if ok {
+			return m, m.runAction(item.id, p)
+		}
+	case "esc", "q", "a":
+		m.actions = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// runAction carries out one action of the actions menu on p.
+func (m *model) runAction(id string, p ProcessInfo) tea.Cmd {
+	switch id {
+	case "detail":
+		m.showDetail(p.PID)
+	case "kill", "killtree":
+		m.openKillDialog(p.PID, id == "killtree")
+	case "renice":
+		m.renice = &renicePicker{proc: p, nice: int(p.Nice)}
+	case "ionice":
+		m.showDetail(p.PID)
+		m.openIONicePicker()
+	case "pin":
+		m.togglePin(p)
+	case "copypid":
+		return m.copyCmd(strconv.Itoa(int(p.PID)), fmt.Sprintf("PID %d", p.PID))
+	case "copycmd":
+		return m.copyCmd(p.Cmdline, fmt.Sprintf("the command line of PID %d", p.PID))
+	case "user":
+		m.procFilter, m.unitFilter, m.userFilter = "", "", p.Username
+		m.processTable.GotoTop()
+		m.refreshRows()
+	case "name":
+		m.procFilter, m.unitFilter, m.userFilter = p.Name, "", ""
+		m.processTable.GotoTop()
+		m.refreshRows()
+	}
+	return nil
+}
+
+// isPinned reports whether p is the pinned process.
+func (m model) isPinned(p ProcessInfo) bool {
+	return m.pinned != nil && *m.pinned == (procKey{p.PID, p.CreateTime})
+}
+
+// togglePin pins p to the top of the process table, or unpins it. The process tree keeps
+// its shape, so the pin only applies to the flat list.
+func (m *model) togglePin(p ProcessInfo) {
+	if m.isPinned(p) {
+		m.pinned = nil
+	} else {
+		m.pinned = &procKey{p.PID, p.CreateTime}
+	}
+	m.refreshRows()
+}
+
+// withPinned moves the pinned process to the front of the sorted and filtered procs,
+// adding it when the filter left it out, so it stays in view whatever the table shows.
+// A pin outlives its process only until the process is gone from all.
+func (m *model) withPinned(procs, all []ProcessInfo) []ProcessInfo {
+	if m.pinned == nil {
+		return procs
+	}
+	for _, p := range all {
+		if m.isPinned(p) {
+			out := []ProcessInfo{p}
+			for _, q := range procs {
+				if !m.isPinned(q) {
+					out = append(out, q)
+				}
+			}
+			return out
+		}
+	}
+	// The command of the run subcommand is pinned before the first pass lists it.
+	if !m.child.running() || *m.pinned != m.child.key {
+		m.pinned = nil
+	}
+	return procs
+}
+
+// updateRenicePicker handles key presses while the nice value is edited.
+func (m model) updateRenicePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h", "down", "j":
+		m.renice.nice = max(m.renice.nice-1, minNice)
+	case "right", "l", "up", "k":
+		m.renice.nice = min(m.renice.nice+1, maxNice)
+	// Applies the nice value; lowering it below the current one needs root, and the error
+	// says so in the banner.
+	case "enter":
+		p := m.renice.proc
+		err := setNice(p.PID, m.renice.nice)
+		m.actionLog.record(p.PID, p.Name, fmt.Sprintf("renice %d", m.renice.nice), err)
+		if err != nil {
+			m.reportError(fmt.Errorf("could not renice PID %d: %w", p.PID, err))
+		} else {
+			m.reportInfo(fmt.Sprintf("set the nice value of PID %d (%s) to %d", p.PID, p.Name, m.renice.nice))
+		}
+		m.renice = nil
+	case "esc", "q":
+		m.renice = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// renicePrompt renders the nice value picker as a footer prompt.
+func (m model) renicePrompt() string {
+	r := m.renice
+	return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render(fmt.Sprintf("Nice value of PID %d (%s): < %d >", r.proc.PID, r.proc.Name, r.nice)) +
+		m.baseStyle.Foreground(Color.Secondary).Render(fmt.Sprintf(" · ←/→: %d to %d, higher is nicer · enter: apply · esc: cancel", minNice, maxNice))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatCoreList renders the cores set in an affinity mask as ranges, e.g. "0–3,8–11".
+func formatCoreList(cores []bool) string {
+	var parts []string
+	for i := 0; i < len(cores); i++ {
+		if !cores[i] {
+			continue
+		}
+		j := i
+		for j+1 < len(cores) && cores[j+1] {
+			j++
+		}
+		if j == i {
+			parts = append(parts, fmt.Sprintf("%d", i))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d–%d", i, j))
+		}
+		i = j
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ",")
+}
+
+// affinityPicker holds the affinity mask being edited for a process before it is applied.
+type affinityPicker struct {
+	pid    int32
+	cores  []bool
+	cursor int
+}
+
+// affinityRowLen is how many cores the picker shows per line.
+const affinityRowLen = 16
+
+// move shifts the cursor by delta cores, clamped to the mask.
+func (p *affinityPicker) move(delta int) {
+	p.cursor = min(max(p.cursor+delta, 0), len(p.cores)-1)
+}
+
+// toggle flips the core under the cursor.
+func (p *affinityPicker) toggle() {
+	p.cores[p.cursor] = !p.cores[p.cursor]
+}
+
+// toggleAll selects every core, or clears them all when every core is already selected.
+func (p *affinityPicker) toggleAll() {
+	all := true
+	for _, set := range p.cores {
+		all = all && set
+	}
+	for i := range p.cores {
+		p.cores[i] = !all
+	}
+}
+
+// empty reports whether no core is selected, which the kernel rejects.
+func (p *affinityPicker) empty() bool {
+	for _, set := range p.cores {
+		if set {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package main
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// affinitySupported reports whether CPU affinity can be read and changed on this platform.
+const affinitySupported = true
+
+// maxCores is the number of cores a unix.CPUSet can describe.
+const maxCores = 1024
+
+// getAffinity reads the CPU affinity mask of a process via sched_getaffinity(2). The mask
+// covers every online core, and any higher core the process is allowed on.
+func getAffinity(pid int32) ([]bool, error) {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(int(pid), &set); err != nil {
+		return nil, err
+	}
+	n := runtime.NumCPU()
+	for i := n; i < maxCores; i++ {
+		if set.IsSet(i) {
+			n = i + 1
+		}
+	}
+	cores := make([]bool, n)
+	for i := range cores {
+		cores[i] = set.IsSet(i)
+	}
+	return cores, nil
+}
+
+// setAffinity changes the CPU affinity mask of a process via sched_setaffinity(2).
+func setAffinity(pid int32, cores []bool) error {
+	var set unix.CPUSet
+	for i, on := range cores {
+		if on {
+			set.Set(i)
+		}
+	}
+	return unix.SchedSetaffinity(int(pid), &set)
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// affinitySupported reports whether CPU affinity can be read and changed on this platform.
+const affinitySupported = false
+
+var errAffinityUnsupported = errors.New("CPU affinity is not supported on this platform")
+
+func getAffinity(pid int32) ([]bool, error) {
+	return nil, errAffinityUnsupported
+}
+
+func setAffinity(pid int32, cores []bool) error {
+	return errAffinityUnsupported
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AlertRule is one [[alerts]] entry of the config file. It fires when its metric rises
+// above the threshold and re-arms once the metric drops back below it.
+type AlertRule struct {
+	Name   string  `toml:"name"`
+	Metric string  `toml:"metric"`
+	Above  float64 `toml:"above"`
+	// PerCore multiplies the threshold by the number of logical CPUs, e.g. load1 above 2 per core.
+	PerCore bool `toml:"per_core"`
+	// Bell rings the terminal bell and Flash inverts the header for flashTicks refreshes.
+	Bell  bool `toml:"bell"`
+	Flash bool `toml:"flash"`
+	// Cooldown is the minimum time between two notifications of the rule.
+	Cooldown time.Duration `toml:"cooldown"`
+}
+
+// defaultAlertCooldown is used by rules that don't set a cooldown.
+const defaultAlertCooldown = time.Minute
+
+// flashTicks is how many refreshes the header stays inverted after a flash alert.
+const flashTicks = 2
+
+// alertMetrics are the values alert rules can watch. ok is false while the value is not
+// available, e.g. before the first collection.
+var alertMetrics = map[string]func(s Snapshot) (value float64, ok bool){
+	"cpu":    func(s Snapshot) (float64, bool) { return 100 - s.CPU.Idle, s.CPU.CPU != "" },
+	"mem":    func(s Snapshot) (float64, bool) { return s.Mem.UsedPercent, s.Mem.Total > 0 },
+	"load1":  func(s Snapshot) (float64, bool) { return s.Load.Load1, s.Load != nil },
+	"load5":  func(s Snapshot) (float64, bool) { return s.Load.Load5, s.Load != nil },
+	"load15": func(s Snapshot) (float64, bool) { return s.Load.Load15, s.Load != nil },
+	"time_wait": func(s Snapshot) (float64, bool) {
+		return float64(s.Sockets.States["TIME_WAIT"]), s.Sockets.States != nil
+	},
+	"close_wait": func(s Snapshot) (float64, bool) {
+		return float64(s.Sockets.States["CLOSE_WAIT"]), s.Sockets.States != nil
+	},
+	// on_battery is 1 while the host runs on battery, so a rule above 0 fires on unplugging.
+	"on_battery": func(s Snapshot) (float64, bool) {
+		if s.Battery == nil {
+			return 0, false
+		}
+		if s.Battery.OnBattery {
+			return 1, true
+		}
+		return 0, true
+	},
+}
+
+// alertMetricNames returns the metric names in a stable order for error messages.
+func alertMetricNames() string {
+	names := make([]string, 0, len(alertMetrics))
+	for name := range alertMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// threshold returns the value the rule fires above.
+func (r AlertRule) threshold() float64 {
+	if r.PerCore {
+		return r.Above * float64(runtime.NumCPU())
+	}
+	return r.Above
+}
+
+// alertState tracks one rule between refreshes. announced is set while the rule fires
+// and its firing was notified, so only a notified alert is reported as resolved.
+type alertState struct {
+	firing    bool
+	announced bool
+	notified  time.Time
+}
+
+// evaluateAlerts checks every rule against the latest snapshot. A rule that starts firing
+// shows a notice and, outside its cooldown, is sent to the webhooks and, unless --quiet
+// is set, rings the bell and flashes the header. A notified rule that stops firing is
+// sent to the webhooks as resolved.
+func (m *model) evaluateAlerts(now time.Time) tea.Cmd {
+	if m.flash > 0 {
+		m.flash--
+	}
+	// The CPU and throughput of a pass the clock jumped in are no measure to alert on.
+	if m.data.ClockJump != 0 {
+		return nil
+	}
+	if len(m.alertStates) != len(m.cfg.Alerts) {
+		m.alertStates = make([]alertState, len(m.cfg.Alerts))
+	}
+
+	var cmds []tea.Cmd
+	bell := false
+	for i, rule := range m.cfg.Alerts {
+		value, ok := alertMetrics[rule.Metric](m.data)
+		if !ok {
+			continue
+		}
+		state := &m.alertStates[i]
+		if value <= rule.threshold() {
+			if state.announced {
+				cmds = append(cmds, m.webhookCmd(rule, value, now, alertResolved))
+			}
+			state.firing, state.announced = false, false
+			continue
+		}
+		if state.firing {
+			continue
+		}
+		state.firing = true
+
+		cooldown := rule.Cooldown
+		if cooldown == 0 {
+			cooldown = defaultAlertCooldown
+		}
+		if !state.notified.IsZero() && now.Sub(state.notified) < cooldown {
+			continue
+		}
+		state.notified = now
+		state.announced = true
+		m.reportInfo(fmt.Sprintf("alert %s: %s %s above %s", rule.Name, rule.Metric, formatFloat(value, 2), formatFloat(rule.threshold(), 2)))
+		cmds = append(cmds, m.webhookCmd(rule, value, now, alertFiring))
+		if m.quiet {
+			continue
+		}
+		bell = bell || rule.Bell
+		if rule.Flash {
+			m.flash = flashTicks
+		}
+	}
+	if bell {
+		cmds = append(cmds, ringBell)
+	}
+	return tea.Batch(cmds...)
+}
+
+// ringBell writes the terminal bell character. It moves no cursor, so it is safe to write
+// next to the renderer.
+func ringBell() tea.Msg {
+	os.Stdout.WriteString("\a")
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Anomaly flags, shown in the ! column.
+const (
+	flagLeak = 'L'
+	flagSpin = 'S'
+	flagFork = 'F'
+)
+
+// AnomaliesConfig is the [anomalies] section of the config file: the heuristics behind the
+// flags of the ! column. The defaults are conservative, so a flag is worth a look when it
+// appears; a threshold of 0 turns its flag off.
+type AnomaliesConfig struct {
+	// LeakWindow and LeakGrowthPercent flag a leak suspect, L: a process whose RSS has
+	// not shrunk once for LeakWindow and grew by at least LeakGrowthPercent meanwhile.
+	LeakWindow        time.Duration `toml:"leak_window"`
+	LeakGrowthPercent float64       `toml:"leak_growth_percent"`
+	// SpinWindow and SpinPercent flag a spinning process, S: one whose CPU% stayed at or
+	// above SpinPercent for SpinWindow.
+	SpinWindow  time.Duration `toml:"spin_window"`
+	SpinPercent float64       `toml:"spin_percent"`
+	// ForkWindow and ForkRate flag a fork storm, F: a process that started more than
+	// ForkRate children per second over the last ForkWindow.
+	ForkWindow time.Duration `toml:"fork_window"`
+	ForkRate   float64       `toml:"fork_rate"`
+}
+
+// checkAnomalies validates the [anomalies] section.
+func checkAnomalies(c AnomaliesConfig) error {
+	for _, w := range []struct {
+		key   string
+		value time.Duration
+	}{{"leak_window", c.LeakWindow}, {"spin_window", c.SpinWindow}, {"fork_window", c.ForkWindow}} {
+		if w.value < minInterval {
+			return fmt.Errorf("%s must be at least %s, got %s", w.key, minInterval, w.value)
+		}
+	}
+	for _, t := range []struct {
+		key   string
+		value float64
+	}{{"leak_growth_percent", c.LeakGrowthPercent}, {"spin_percent", c.SpinPercent}, {"fork_rate", c.ForkRate}} {
+		if t.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %v", t.key, t.value)
+		}
+	}
+	return nil
+}
+
+// procTrend is what anomalyWatch remembers of one process between passes.
+type procTrend struct {
+	// rss is the latest RSS; growingSince and growthBase are when the current run of
+	// samples without a shrinking RSS started and the RSS at its start.
+	rss          uint64
+	growingSince time.Time
+	growthBase   uint64
+	// pegged is when the CPU% last rose to the spin threshold, zero while below it.
+	pegged time.Time
+	// forks are the start times of the children seen within the fork window.
+	forks []time.Time
+}
+
+// anomalyWatch follows every process across passes for the anomaly flags. It only sees
+// what each pass lists, so a child that starts and exits between two passes isn't counted
+// and the fork rate is a lower bound; a process missing from a pass, e.g. in low-overhead
+// mode, starts its trends afresh.
+type anomalyWatch struct {
+	trends map[procKey]*procTrend
+	// started is when the first pass was observed; children older than it were not forked
+	// during the session.
+	started time.Time
+}
+
+func newAnomalyWatch() *anomalyWatch {
+	return &anomalyWatch{trends: map[procKey]*procTrend{}}
+}
+
+// observe updates the trends with a pass and sets the Flags of procs.
+func (w *anomalyWatch) observe(procs []ProcessInfo, cfg AnomaliesConfig, now time.Time) {
+	if w.started.IsZero() {
+		w.started = now
+	}
+	byPID := make(map[int32]procKey, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = procKey{p.PID, p.CreateTime}
+	}
+
+	trends := make(map[procKey]*procTrend, len(procs))
+	for _, p := range procs {
+		key := procKey{p.PID, p.CreateTime}
+		t, ok := w.trends[key]
+		if !ok {
+			t = &procTrend{rss: p.Memory, growingSince: now, growthBase: p.Memory}
+		}
+		if p.Memory < t.rss {
+			t.growingSince, t.growthBase = now, p.Memory
+		}
+		t.rss = p.Memory
+		switch {
+		case p.CPUPercent < cfg.SpinPercent:
+			t.pegged = time.Time{}
+		case t.pegged.IsZero():
+			t.pegged = now
+		}
+		trends[key] = t
+	}
+	// A child not seen before that started during the session was forked since the
+	// previous pass.
+	for _, p := range procs {
+		key := procKey{p.PID, p.CreateTime}
+		if _, seen := w.trends[key]; seen || time.UnixMilli(p.CreateTime).Before(w.started) {
+			continue
+		}
+		if parent, ok := byPID[p.PPID]; ok && p.PPID != p.PID {
+			pt := trends[parent]
+			pt.forks = append(pt.forks, time.UnixMilli(p.CreateTime))
+		}
+	}
+	w.trends = trends
+
+	for i := range procs {
+		t := trends[procKey{procs[i].PID, procs[i].CreateTime}]
+		procs[i].Flags = t.flags(cfg, now)
+	}
+}
+
+// flags returns the anomaly flags of a process, "" for none, dropping the forks that left
+// the window.
+func (t *procTrend) flags(cfg AnomaliesConfig, now time.Time) string {
+	var flags []rune
+	if cfg.LeakGrowthPercent > 0 && t.growthBase > 0 && now.Sub(t.growingSince) >= cfg.LeakWindow &&
+		float64(t.rss-t.growthBase)/float64(t.growthBase)*100 >= cfg.LeakGrowthPercent {
+		flags = append(flags, flagLeak)
+	}
+	if cfg.SpinPercent > 0 && !t.pegged.IsZero() && now.Sub(t.pegged) >= cfg.SpinWindow {
+		flags = append(flags, flagSpin)
+	}
+	from := now.Add(-cfg.ForkWindow)
+	for len(t.forks) > 0 && t.forks[0].Before(from) {
+		t.forks = t.forks[1:]
+	}
+	if cfg.ForkRate > 0 && float64(len(t.forks))/cfg.ForkWindow.Seconds() > cfg.ForkRate {
+		flags = append(flags, flagFork)
+	}
+	return string(flags)
+}
+
+// flagsCell renders the ! column.
+func flagsCell(p ProcessInfo) string {
+	if p.Flags == "" {
+		return ""
+	}
+	return tagCell(p.Flags, tagRed)
+}
+
+// flagNames explains each anomaly flag in the detail view.
+var flagNames = map[rune]string{
+	flagLeak: "L memory keeps growing (leak suspect)",
+	flagSpin: "S CPU pegged",
+	flagFork: "F forking children fast",
+}
+
+// describeFlags lists the meanings of a process's flags.
+func describeFlags(flags string) string {
+	names := make([]string, 0, len(flags))
+	for _, f := range flags {
+		names = append(names, flagNames[f])
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// auditSeverity ranks how suspicious an audit finding is.
+type auditSeverity int
+
+const (
+	auditLow auditSeverity = iota
+	auditMedium
+	auditHigh
+)
+
+func (s auditSeverity) String() string {
+	switch s {
+	case auditHigh:
+		return "high"
+	case auditMedium:
+		return "med"
+	}
+	return "low"
+}
+
+// AuditFinding is a process whose executable looks unusual.
+type AuditFinding struct {
+	PID      int32
+	Name     string
+	Exe      string
+	Severity auditSeverity
+	Reason   string
+}
+
+// tempDirs are the world-writable directories programs are not normally run from.
+var tempDirs = []string{"/tmp/", "/var/tmp/", "/dev/shm/"}
+
+// deletedSuffix is what the kernel appends to the executable of a process whose binary
+// was removed or replaced since it started.
+const deletedSuffix = " (deleted)"
+
+// classifyExe returns the finding for an executable path, or ok false when nothing about
+// it is unusual. A binary deleted outside the temporary directories is only low: that is
+// what a package upgrade leaves behind until the service restarts.
+func classifyExe(exe string) (severity auditSeverity, reason string, ok bool) {
+	if strings.HasPrefix(exe, "/memfd:") {
+		return auditHigh, "runs from memory (memfd)", true
+	}
+	path, deleted := strings.CutSuffix(exe, deletedSuffix)
+	temp := hasAnyPrefix(path, tempDirs)
+	switch {
+	case temp && deleted:
+		return auditHigh, "deleted binary in a temp directory", true
+	case temp:
+		return auditMedium, "runs from a temp directory", true
+	case deleted:
+		return auditLow, "binary deleted or replaced", true
+	}
+	return 0, "", false
+}
+
+// auditProcesses reads the executable of every process and returns the unusual ones,
+// most severe first. Kernel threads and zombies have no executable; user processes without
+// one are reported, while those whose executable may not be read are skipped.
+func auditProcesses(procs []ProcessInfo) []AuditFinding {
+	var findings []AuditFinding
+	for _, p := range procs {
+		if isKernelThread(p) || p.Zombie {
+			continue
+		}
+		exe, err := readExe(p.PID)
+		if errors.Is(err, errNoBinary) {
+			findings = append(findings, AuditFinding{PID: p.PID, Name: p.Name, Severity: auditMedium, Reason: "no executable"})
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if severity, reason, ok := classifyExe(exe); ok {
+			findings = append(findings, AuditFinding{PID: p.PID, Name: p.Name, Exe: exe, Severity: severity, Reason: reason})
+		}
+	}
+	slices.SortFunc(findings, func(a, b AuditFinding) int {
+		if c := cmp.Compare(b.Severity, a.Severity); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PID, b.PID)
+	})
+	return findings
+}
+
+// isKernelThread reports whether p is a kernel thread, which has no executable: kthreadd
+// and its children.
+func isKernelThread(p ProcessInfo) bool {
+	return p.PID == 2 || p.PPID == 2 || p.PID == 0
+}
+
+// auditKey identifies what hiding a finding hides: every process running the same
+// executable, or, without one, the process name.
+func (f AuditFinding) auditKey() string {
+	if f.Exe != "" {
+		return f.Exe
+	}
+	return "name:" + f.Name
+}
+
+// visibleFindings returns the findings not hidden this session.
+func (m model) visibleFindings() []AuditFinding {
+	var visible []AuditFinding
+	for _, f := range m.data.Audit {
+		if !m.auditHidden[f.auditKey()] {
+			visible = append(visible, f)
+		}
+	}
+	return visible
+}
+
+// moveAuditCursor moves the selection of the focused audit panel by step findings.
+func (m *model) moveAuditCursor(step int) {
+	n := len(m.visibleFindings())
+	m.auditCursor = min(max(m.auditCursor+step, 0), max(n-1, 0))
+}
+
+// hideAuditFinding hides the selected finding, and every other process running the same
+// executable, for the rest of the session. It is for the known false positives, such as a
+// service still running the binary an upgrade replaced.
+func (m *model) hideAuditFinding() {
+	visible := m.visibleFindings()
+	if m.auditCursor >= len(visible) {
+		return
+	}
+	f := visible[m.auditCursor]
+	m.auditHidden[f.auditKey()] = true
+	m.auditCursor = max(min(m.auditCursor, len(visible)-2), 0)
+	m.reportInfo(fmt.Sprintf("hid %s (%s) from the audit panel for this session", f.Name, f.Reason))
+}
+
+// maxAuditLines bounds how many findings the audit panel lists.
+const maxAuditLines = 10
+
+// auditPanel lists the processes whose executable was deleted, runs from a temp directory
+// or memory, or is missing. It only reports; nothing is enforced. While the panel is
+// focused, x hides the selected finding for the session.
+func (m model) auditPanel() panel {
+	p := panel{collector: collectorAudit, title: "Audit"}
+	if !m.cfg.Audit.Panel {
+		return p
+	}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	visible := m.visibleFindings()
+	if hidden := len(m.data.Audit) - len(visible); hidden > 0 {
+		p.title += fmt.Sprintf(" · %s hidden", formatInt(hidden))
+	}
+	if len(visible) == 0 {
+		p.lines = append(p.lines, hint("nothing unusual"))
+		return p
+	}
+	focused := m.focus == collectorAudit && m.panelFocused()
+	for i, f := range visible {
+		if i == maxAuditLines {
+			p.lines = append(p.lines, hint(fmt.Sprintf("… %d more", len(visible)-maxAuditLines)))
+			break
+		}
+		severity := fmt.Sprintf("%-4s", f.Severity)
+		switch f.Severity {
+		case auditHigh:
+			severity = m.baseStyle.Foreground(Color.Red).Render(severity)
+		case auditMedium:
+			severity = m.baseStyle.Foreground(Color.Yellow).Render(severity)
+		default:
+			severity = hint(severity)
+		}
+		detail := f.Reason
+		if f.Exe != "" {
+			detail += ": " + f.Exe
+		}
+		cursor := "  "
+		if focused && i == m.auditCursor {
+			cursor = m.baseStyle.Foreground(Color.Highlight).Render("> ")
+		}
+		p.lines = append(p.lines, fmt.Sprintf("%s%s %7d %s %s", cursor, severity, f.PID, fit(f.Name, 16), truncate(detail, 48)))
+	}
+	return p
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// auditSupported reports whether the audit panel can read executables on this platform.
+const auditSupported = true
+
+// errNoBinary is returned by readExe for a live process without an executable.
+var errNoBinary = errors.New("no executable")
+
+// readExe returns the executable of the process, with the " (deleted)" suffix the kernel
+// adds once the file is gone.
+func readExe(pid int32) (string, error) {
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if errors.Is(err, fs.ErrNotExist) {
+		// The link is missing both for an exited process and for one without an
+		// executable; only the latter still has its /proc directory.
+		if _, statErr := os.Stat(fmt.Sprintf("/proc/%d", pid)); statErr == nil {
+			return "", errNoBinary
+		}
+	}
+	return exe, err
+}
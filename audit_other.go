@@ -0,0 +1,17 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// auditSupported reports whether the audit panel can read executables on this platform;
+// only Linux marks deleted executables.
+const auditSupported = false
+
+// errNoBinary is returned by readExe for a live process without an executable.
+var errNoBinary = errors.New("no executable")
+
+// readExe exists so the audit builds everywhere; it is never used here.
+func readExe(pid int32) (string, error) {
+	return "", errors.New("executables are only audited on Linux")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// Row tags tint the background of a whole process row by its usage band. Like the cell
+// tags they are zero-width control characters, placed at the start of the row's first
+// cell and turned into escape sequences by colorCells.
+const (
+	tagBandWarning  = "\x06"
+	tagBandCritical = "\x0e"
+)
+
+// RowsConfig is the [rows] section of the config file: whole-row tinting of the process
+// table by usage band, for spotting the hot processes while scrolling fast.
+type RowsConfig struct {
+	// Tint enables the tinting; it is off by default.
+	Tint bool `toml:"tint"`
+	// A row is in the warning band once its CPU% or its share of RAM reaches the warning
+	// threshold, and in the critical band once either reaches the critical one. CPU% is
+	// per core, so on a multi-core host it can exceed 100.
+	CPUWarning  float64 `toml:"cpu_warning"`
+	CPUCritical float64 `toml:"cpu_critical"`
+	MemWarning  float64 `toml:"mem_warning"`
+	MemCritical float64 `toml:"mem_critical"`
+}
+
+// band returns the row tag for p, or "" when it is below both warning thresholds.
+// memTotal is the RAM the memory share is computed against.
+func (c RowsConfig) band(p ProcessInfo, memTotal uint64) string {
+	mem := 0.0
+	if memTotal > 0 {
+		mem = float64(p.Memory) / float64(memTotal) * 100
+	}
+	switch {
+	case p.CPUPercent >= c.CPUCritical || mem >= c.MemCritical:
+		return tagBandCritical
+	case p.CPUPercent >= c.CPUWarning || mem >= c.MemWarning:
+		return tagBandWarning
+	}
+	return ""
+}
+
+// rowBands returns the band tag of each process above a warning threshold, by PID as the
+// rows show it, or nil while tinting is off.
+func (c RowsConfig) rowBands(procs []ProcessInfo, memTotal uint64) map[string]string {
+	if !c.Tint {
+		return nil
+	}
+	bands := map[string]string{}
+	for _, p := range procs {
+		if tag := c.band(p, memTotal); tag != "" {
+			bands[fmt.Sprintf("%d", p.PID)] = tag
+		}
+	}
+	return bands
+}
+
+// markBand tints row with the band tag. Watched rows keep their own color instead, and the
+// selected row's highlight replaces the tint when it is rendered.
+func markBand(row table.Row, tag string) {
+	if len(row) > 0 {
+		row[0] = tag + row[0]
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// BatteryConfig is the [battery] section of the config file.
+type BatteryConfig struct {
+	// Interval is the refresh interval while the host runs on battery, when longer than
+	// interval, so the monitor itself costs less of the charge; "0s" keeps interval.
+	Interval time.Duration `toml:"interval"`
+	// UPower gets the battery from UPower over D-Bus on Linux, which tells as soon as AC is
+	// plugged or unplugged. Without it, or without UPower running, the battery is read from
+	// sysfs on every refresh.
+	UPower bool `toml:"upower"`
+}
+
+// checkBattery validates the [battery] section.
+func checkBattery(c BatteryConfig) error {
+	if c.Interval != 0 && c.Interval < minInterval {
+		return fmt.Errorf("interval must be 0s or at least %s, got %s", minInterval, c.Interval)
+	}
+	return nil
+}
+
+// errNoBattery fails the battery collector at the startup probe on hosts without one.
+var errNoBattery = fmt.Errorf("no battery found: %w", fs.ErrNotExist)
+
+// BatteryStatus is the charge of the host's batteries, combined into one as UPower's
+// display device does.
+type BatteryStatus struct {
+	// Percent is the charge left.
+	Percent float64
+	// OnBattery is set while no AC adapter powers the host.
+	OnBattery bool
+	Charging  bool
+	// TimeToEmpty and TimeToFull are the estimates while discharging and charging, 0 while
+	// unknown.
+	TimeToEmpty, TimeToFull time.Duration
+}
+
+// batteryMsg carries a battery change pushed by UPower, between two refreshes.
+type batteryMsg struct {
+	status *BatteryStatus
+}
+
+// readBattery returns the battery status from the UPower watcher when it is connected,
+// and from sysfs otherwise.
+func readBattery(upower *upowerWatcher) (*BatteryStatus, error) {
+	if upower != nil {
+		return upower.latest()
+	}
+	return readSysfsBattery()
+}
+
+// onBattery reports whether the host was running on battery at the last reading.
+func (m model) onBattery() bool {
+	return m.data.Battery != nil && m.data.Battery.OnBattery
+}
+
+// applyBattery follows a change of the power source: the refresh interval switches to
+// battery.interval and back. It reports whether the source changed.
+func (m *model) applyBattery(wasOnBattery bool) bool {
+	if m.onBattery() == wasOnBattery {
+		return false
+	}
+	m.interval = m.refreshInterval(m.cfg)
+	return true
+}
+
+// viewBattery renders the battery for the status line, e.g. "battery 82% · 3h10m left",
+// or "" on hosts without one.
+func (m model) viewBattery() string {
+	b := m.data.Battery
+	if b == nil {
+		return ""
+	}
+	source := "AC"
+	if b.OnBattery {
+		source = "battery"
+	}
+	line := source + " " + formatPercent(b.Percent, 0)
+	switch {
+	case b.OnBattery && b.TimeToEmpty > 0:
+		line += " · " + formatAge(b.TimeToEmpty) + " left"
+	case b.Charging && b.TimeToFull > 0:
+		line += " · full in " + formatAge(b.TimeToFull)
+	case b.Charging:
+		line += " · charging"
+	}
+	if b.OnBattery && b.Percent <= lowBatteryPercent {
+		return m.baseStyle.Foreground(Color.Red).Render(line)
+	}
+	return line
+}
+
+// lowBatteryPercent is the charge below which the battery shows in red.
+const lowBatteryPercent = 10
@@ -0,0 +1,238 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/godbus/dbus/v5"
+)
+
+// batterySupported reports whether the battery is read on this platform.
+const batterySupported = true
+
+// powerSupplyDir lists the kernel's power supplies.
+const powerSupplyDir = "/sys/class/power_supply"
+
+// readSupplyFile returns a sysfs attribute of a power supply, "" when it is missing.
+func readSupplyFile(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSupplyInt returns a numeric sysfs attribute of a power supply.
+func readSupplyInt(dir, name string) (float64, bool) {
+	v, err := strconv.ParseFloat(readSupplyFile(dir, name), 64)
+	return v, err == nil
+}
+
+// readSysfsBattery combines the system batteries of /sys/class/power_supply into one
+// status. Batteries of peripherals, such as a wireless mouse, have a "Device" scope and are
+// left out. Hosts whose AC adapter isn't listed count as on battery while one discharges.
+func readSysfsBattery() (*BatteryStatus, error) {
+	dirs, err := filepath.Glob(filepath.Join(powerSupplyDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		found, mainsListed, mainsOnline, discharging, charging bool
+		energyNow, energyFull, power, capacity                 float64
+		batteries                                              int
+	)
+	for _, dir := range dirs {
+		switch readSupplyFile(dir, "type") {
+		case "Mains", "USB":
+			mainsListed = true
+			mainsOnline = mainsOnline || readSupplyFile(dir, "online") == "1"
+		case "Battery":
+			if readSupplyFile(dir, "scope") == "Device" || readSupplyFile(dir, "present") == "0" {
+				continue
+			}
+			found = true
+			batteries++
+			switch readSupplyFile(dir, "status") {
+			case "Discharging":
+				discharging = true
+			case "Charging":
+				charging = true
+			}
+			if c, ok := readSupplyInt(dir, "capacity"); ok {
+				capacity += c
+			}
+			// Batteries report energy in µWh and power in µW, or charge in µAh and current
+			// in µA; the ratios come out the same either way.
+			now, okNow := readSupplyInt(dir, "energy_now")
+			full, okFull := readSupplyInt(dir, "energy_full")
+			rate, okRate := readSupplyInt(dir, "power_now")
+			if !okNow || !okFull {
+				now, okNow = readSupplyInt(dir, "charge_now")
+				full, okFull = readSupplyInt(dir, "charge_full")
+				rate, okRate = readSupplyInt(dir, "current_now")
+			}
+			if okNow && okFull {
+				energyNow += now
+				energyFull += full
+			}
+			if okRate {
+				power += max(rate, -rate)
+			}
+		}
+	}
+	if !found {
+		return nil, errNoBattery
+	}
+	b := &BatteryStatus{
+		Percent:   capacity / float64(batteries),
+		OnBattery: mainsListed && !mainsOnline || !mainsListed && discharging,
+		Charging:  charging,
+	}
+	if energyFull > 0 {
+		b.Percent = energyNow / energyFull * 100
+	}
+	if power > 0 {
+		hours := func(energy float64) time.Duration { return time.Duration(energy / power * float64(time.Hour)) }
+		switch {
+		case discharging:
+			b.TimeToEmpty = hours(energyNow)
+		case charging:
+			b.TimeToFull = hours(energyFull - energyNow)
+		}
+	}
+	return b, nil
+}
+
+// UPower's names on the system bus.
+const (
+	upowerService   = "org.freedesktop.UPower"
+	upowerPath      = "/org/freedesktop/UPower"
+	upowerDisplay   = "/org/freedesktop/UPower/devices/DisplayDevice"
+	upowerDeviceIfc = "org.freedesktop.UPower.Device"
+)
+
+// upowerCharging is the State of a charging UPower device.
+const upowerCharging = 1
+
+// upowerWatcher follows UPower's display device, the combination of the system batteries,
+// and its OnBattery property. UPower signals every change, so the battery collector reads
+// the last status instead of sysfs, and run pushes a change the moment AC is plugged or
+// unplugged.
+type upowerWatcher struct {
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+
+	mu     sync.Mutex
+	status *BatteryStatus
+	err    error
+}
+
+// watchUPower connects to UPower on the system bus, failing where there is no system bus
+// or UPower isn't running.
+func watchUPower() (*upowerWatcher, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	w := &upowerWatcher{conn: conn, signals: make(chan *dbus.Signal, 16)}
+	// The first read also fails when UPower isn't running, so the caller falls back to
+	// sysfs rather than finding out on the first refresh.
+	if w.status, err = w.read(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for _, path := range []dbus.ObjectPath{upowerPath, upowerDisplay} {
+		err := conn.AddMatchSignal(
+			dbus.WithMatchSender(upowerService),
+			dbus.WithMatchObjectPath(path),
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged"))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	conn.Signal(w.signals)
+	return w, nil
+}
+
+// read queries UPower for the current status; nil without a battery.
+func (w *upowerWatcher) read() (*BatteryStatus, error) {
+	var onBattery bool
+	if err := w.conn.Object(upowerService, upowerPath).StoreProperty(upowerService+".OnBattery", &onBattery); err != nil {
+		return nil, err
+	}
+	device := w.conn.Object(upowerService, upowerDisplay)
+	var present bool
+	if err := device.StoreProperty(upowerDeviceIfc+".IsPresent", &present); err != nil {
+		return nil, err
+	}
+	if !present {
+		return nil, nil
+	}
+	var (
+		percent     float64
+		state       uint32
+		empty, full int64
+	)
+	for _, p := range []struct {
+		name string
+		dst  any
+	}{{"Percentage", &percent}, {"State", &state}, {"TimeToEmpty", &empty}, {"TimeToFull", &full}} {
+		if err := device.StoreProperty(upowerDeviceIfc+"."+p.name, p.dst); err != nil {
+			return nil, err
+		}
+	}
+	return &BatteryStatus{
+		Percent:     percent,
+		OnBattery:   onBattery,
+		Charging:    state == upowerCharging,
+		TimeToEmpty: time.Duration(empty) * time.Second,
+		TimeToFull:  time.Duration(full) * time.Second,
+	}, nil
+}
+
+// update re-reads the status, keeping the error for the collector.
+func (w *upowerWatcher) update() *BatteryStatus {
+	status, err := w.read()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		w.status = status
+	}
+	w.err = err
+	return w.status
+}
+
+// latest returns the status as of UPower's last signal.
+func (w *upowerWatcher) latest() (*BatteryStatus, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return nil, w.err
+	}
+	if w.status == nil {
+		return nil, errNoBattery
+	}
+	return w.status, nil
+}
+
+// run sends a batteryMsg through send for every change UPower signals, until close. A
+// change to several properties comes as one signal, so it is a single message.
+func (w *upowerWatcher) run(send func(tea.Msg)) {
+	for range w.signals {
+		send(batteryMsg{status: w.update()})
+	}
+}
+
+// close disconnects from the system bus, which closes the signal channel and so ends run.
+func (w *upowerWatcher) close() {
+	w.conn.Close()
+}
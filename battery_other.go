@@ -0,0 +1,28 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batterySupported reports whether the battery is read on this platform.
+const batterySupported = false
+
+// readSysfsBattery is a stub; batteries are only read on Linux.
+func readSysfsBattery() (*BatteryStatus, error) {
+	return nil, errNoBattery
+}
+
+// upowerWatcher is a stub; UPower is only used on Linux.
+type upowerWatcher struct{}
+
+func watchUPower() (*upowerWatcher, error) {
+	return nil, errors.New("UPower is only used on Linux")
+}
+
+func (w *upowerWatcher) latest() (*BatteryStatus, error) { return nil, errNoBattery }
+func (w *upowerWatcher) run(func(tea.Msg))               {}
+func (w *upowerWatcher) close()                          {}
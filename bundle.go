@@ -0,0 +1,201 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// bundleColumns are the optional columns switched on for a bundle, so the process list
+// carries every field this host can collect whatever the config shows.
+var bundleColumns = []string{columnFDs, columnRunDelay, columnIODelay, columnMemLimit, columnCtxSwitch}
+
+// bundleVersion is version.json: the build of the program that wrote the bundle.
+type bundleVersion struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// buildVersion reads the version from the build info the go tool embeds.
+func buildVersion() bundleVersion {
+	v := bundleVersion{Version: "unknown", Go: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	v.Version = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.time":
+			v.BuildTime = s.Value
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		}
+	}
+	return v
+}
+
+// bundleProcess is one entry of processes.json. Environ is only read with -sensitive.
+type bundleProcess struct {
+	ProcessInfo
+	Environ []string `json:"Environ,omitempty"`
+}
+
+// bundleProcesses returns the processes of procs for processes.json. Unless sensitive is
+// set, the command lines are cut to the program, and with them the friendly names that
+// are made from their arguments, as arguments often carry tokens and passwords.
+func bundleProcesses(procs []ProcessInfo, sensitive bool) []bundleProcess {
+	out := make([]bundleProcess, 0, len(procs))
+	for _, p := range procs {
+		bp := bundleProcess{ProcessInfo: p}
+		if sensitive {
+			if proc, err := process.NewProcess(p.PID); err == nil {
+				bp.Environ, _ = proc.Environ()
+			}
+		} else {
+			bp.Cmdline, _, _ = strings.Cut(p.Cmdline, " ")
+			bp.DisplayName = ""
+		}
+		out = append(out, bp)
+	}
+	return out
+}
+
+// bundleWriter adds files to the tar.gz of a bundle.
+type bundleWriter struct {
+	gz  *gzip.Writer
+	tar *tar.Writer
+	dir string
+	at  time.Time
+	err error
+}
+
+func newBundleWriter(f *os.File, dir string, at time.Time) *bundleWriter {
+	gz := gzip.NewWriter(f)
+	return &bundleWriter{gz: gz, tar: tar.NewWriter(gz), dir: dir, at: at}
+}
+
+// add writes a file of data under the bundle's directory. The first error is kept for
+// close, so the files can be added without checking each.
+func (w *bundleWriter) add(name string, data []byte) {
+	if w.err != nil {
+		return
+	}
+	hdr := &tar.Header{Name: w.dir + "/" + name, Mode: 0o644, Size: int64(len(data)), ModTime: w.at}
+	if w.err = w.tar.WriteHeader(hdr); w.err == nil {
+		_, w.err = w.tar.Write(data)
+	}
+}
+
+// addJSON writes v as an indented JSON file.
+func (w *bundleWriter) addJSON(name string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		w.add(name+".error", []byte(err.Error()+"\n"))
+		return
+	}
+	w.add(name, append(data, '\n'))
+}
+
+func (w *bundleWriter) close() error {
+	if err := w.tar.Close(); w.err == nil {
+		w.err = err
+	}
+	if err := w.gz.Close(); w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// runBundle writes a tar.gz for bug reports: the build and capability report, host info,
+// -duration of snapshots in the json subcommand's format, and the last pass in full,
+// including the process list with every field collected and the connections.
+func runBundle(args []string) int {
+	fs := newFlagSet("bundle")
+	configSrc := configFlag(fs)
+	output := fs.String("o", "", "file to write (default: "+appName+"-bundle-<time>.tar.gz in the current directory)")
+	duration := fs.Duration("duration", 5*time.Second, "how long to sample the metrics for")
+	interval := fs.Duration("interval", time.Second, "time between samples")
+	sensitive := fs.Bool("sensitive", false, "include full command lines and the environment of each process, which may hold secrets")
+	fs.Parse(args)
+
+	cfg := loadConfigOrExit(*configSrc)
+	Color = cfg.Theme.theme()
+	cfg.Columns.Visible = append(cfg.Columns.Visible, bundleColumns...)
+	cfg.Columns.Net = true
+
+	started := time.Now()
+	path := *output
+	if path == "" {
+		path = fmt.Sprintf("%s-bundle-%s.tar.gz", appName, started.Format("20060102-150405"))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	w := newBundleWriter(f, strings.TrimSuffix(filepath.Base(path), ".tar.gz"), started)
+
+	// The probe drops the collectors that fail here, as in the TUI, and its report tells why.
+	m := newModel(cfg)
+	m.procOpts.threads = true
+	m.procOpts.ctxTargets = nil
+	m.collectors = defaultCollectors(m.procOpts)
+	m.probe()
+	w.addJSON("version.json", buildVersion())
+	w.add("capabilities.txt", []byte(m.caps.String()+"\n"))
+	if info, err := host.Info(); err == nil {
+		// The host ID identifies the machine and tells nothing about the numbers.
+		info.HostID = ""
+		w.addJSON("host.json", info)
+	} else {
+		w.add("host.json.error", []byte(err.Error()+"\n"))
+	}
+
+	fmt.Fprintf(os.Stderr, "Sampling for %s…\n", *duration)
+	snap := m.data
+	step := max(*interval, minInterval)
+	var samples strings.Builder
+	enc := json.NewEncoder(&samples)
+	for at := time.Now(); time.Since(at) < *duration; {
+		time.Sleep(step)
+		runCollectors(m.collectors, &snap, nil, step)
+		enc.Encode(toJSON(snap, time.Now()))
+	}
+	w.add("samples.jsonl", []byte(samples.String()))
+
+	var metrics strings.Builder
+	series, dropped := cfg.Metrics.processSeries(snap.Procs)
+	writeMetrics(&metrics, snap, cfg.Metrics, series, uint64(dropped))
+	w.add("metrics.prom", []byte(metrics.String()))
+	w.addJSON("processes.json", bundleProcesses(snap.Procs, *sensitive))
+	// The processes are in processes.json, where their command lines are cut.
+	snap.Procs = nil
+	w.addJSON("snapshot.json", snap)
+
+	if err := w.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Remove(path)
+		return 1
+	}
+	fmt.Println(path)
+	return 0
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// capStatus is how well a feature works on this host.
+type capStatus int
+
+const (
+	capAvailable capStatus = iota
+	capPartial
+	capUnavailable
+)
+
+func (s capStatus) String() string {
+	switch s {
+	case capAvailable:
+		return "available"
+	case capPartial:
+		return "partial"
+	}
+	return "unavailable"
+}
+
+// capability is the probe result of one feature; reason explains a partial or missing one.
+type capability struct {
+	name   string
+	status capStatus
+	reason string
+}
+
+func (c capability) String() string {
+	if c.reason == "" {
+		return fmt.Sprintf("%s: %s", c.name, c.status)
+	}
+	return fmt.Sprintf("%s: %s (%s)", c.name, c.status, c.reason)
+}
+
+// capabilities is the startup probe report, in probe order.
+type capabilities []capability
+
+// String renders the report one feature per line, ready to paste into a bug report.
+func (c capabilities) String() string {
+	lines := make([]string, len(c))
+	for i, cap := range c {
+		lines[i] = cap.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collectorLabels names the collectors in the report.
+var collectorLabels = map[string]string{
+	collectorCPU:       "CPU usage",
+	collectorMem:       "memory",
+	collectorLoad:      "load average",
+	collectorProcesses: "processes",
+	collectorTmpfs:     "shared memory (tmpfs)",
+	collectorDisk:      "disk I/O",
+	collectorSockets:   "TCP sockets",
+	collectorFS:        "filesystem usage",
+	collectorNet:       "network traffic",
+	collectorLimits:    "kernel limits",
+	collectorNFS:       "NFS statistics",
+	collectorIRQ:       "interrupts",
+	collectorAudit:     "executable audit",
+	collectorProto:     "protocol counters",
+	collectorThrottle:  "CPU throttling",
+	collectorSlab:      "slab caches",
+	collectorSoC:       "SoC temperature and power",
+	collectorIfaces:    "network interfaces",
+	collectorSensors:   "fan and power sensors",
+	collectorBattery:   "battery",
+	collectorFirewall:  "firewall counters",
+}
+
+// probeReason turns a probe error into the short reason shown in the report.
+func probeReason(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return "permission denied"
+	case errors.Is(err, fs.ErrNotExist):
+		return "not available on this system"
+	}
+	return err.Error()
+}
+
+// probe runs every collector once before the first frame and keeps only the ones that
+// work, so unsupported panels are hidden instead of zero-filled or logging an error on
+// every tick. The probe pass also seeds the first frame. Features that aren't collectors
+// of their own, such as the optional process columns, are checked alongside.
+func (m *model) probe() {
+	var caps capabilities
+
+	// The NET column needs ss; without it every pass would fail to attribute traffic.
+	netCap := capability{name: "per-process NET"}
+	switch {
+	case !procNetSupported:
+		netCap.status, netCap.reason = capUnavailable, "not supported on this platform"
+	default:
+		if _, err := exec.LookPath("ss"); err != nil {
+			netCap.status, netCap.reason = capUnavailable, "ss not found"
+		} else if unprivileged() {
+			netCap.status, netCap.reason = capPartial, "own processes only without root"
+		}
+	}
+	// GPU-MEM is only collected where nvidia-smi lists a GPU, so GPU-less hosts never run it
+	// again after this check.
+	gpuCap := capability{name: "per-process GPU memory"}
+	switch {
+	case m.cfg.Columns.GPU == "off":
+		gpuCap.status, gpuCap.reason = capUnavailable, "columns.gpu is off"
+	case !m.procOpts.gpu:
+		gpuCap.status, gpuCap.reason = capUnavailable, "nvidia-smi not found"
+	}
+	if m.procOpts.gpu {
+		if _, err := listGPUs(); err != nil {
+			gpuCap.status, gpuCap.reason = capUnavailable, probeReason(err)
+		}
+	}
+	if (netCap.status == capUnavailable && m.procOpts.net) || (gpuCap.status == capUnavailable && m.procOpts.gpu) {
+		m.procOpts.net = m.procOpts.net && netCap.status != capUnavailable
+		m.procOpts.gpu = m.procOpts.gpu && gpuCap.status != capUnavailable
+		m.collectors = defaultCollectors(m.procOpts)
+		m.setColumns(selectColumns(m.cfg.Columns.columnIDs(m.procOpts)))
+	}
+
+	snap := m.data
+	var ok []string
+	var working []Collector
+	for _, c := range m.collectors {
+		cap := capability{name: collectorLabels[c.Name()]}
+		if cap.name == "" {
+			cap.name = c.Name()
+		}
+		if err := c.Collect(&snap); err != nil {
+			cap.status, cap.reason = capUnavailable, probeReason(err)
+			caps = append(caps, cap)
+			continue
+		}
+		ok = append(ok, c.Name())
+		working = append(working, c)
+		caps = append(caps, cap)
+	}
+	m.collectors = working
+
+	// Socket owners are read from other processes' file descriptors, which needs root.
+	if slices.Contains(ok, collectorSockets) {
+		pids := capability{name: "connections with PID"}
+		if unprivileged() {
+			pids.status, pids.reason = capPartial, "own processes only without root"
+		}
+		caps = append(caps, pids)
+	}
+	ioCap := capability{name: "per-process disk I/O"}
+	switch {
+	case !procIOSupported:
+		ioCap.status, ioCap.reason = capUnavailable, "not supported on this platform"
+	case unprivileged():
+		ioCap.status, ioCap.reason = capPartial, "own processes only without root"
+	}
+	delayCap := capability{name: "scheduler delays"}
+	switch {
+	case !delaySupported:
+		delayCap.status, delayCap.reason = capUnavailable, "not supported on this platform"
+	case !delayAccountingEnabled():
+		delayCap.status, delayCap.reason = capPartial, "no I/O delay while kernel.task_delayacct is 0"
+	}
+	caps = append(caps, ioCap, delayCap, netCap, gpuCap)
+
+	containers := capability{name: "containers"}
+	if !containerRuntimeDetected() {
+		containers.status, containers.reason = capUnavailable, "no container runtime found"
+	}
+	units := capability{name: "systemd units"}
+	if !m.procOpts.units {
+		units.status, units.reason = capUnavailable, "not booted with systemd"
+	}
+	ionice := capability{name: "I/O priority"}
+	if !ioniceSupported {
+		ionice.status, ionice.reason = capUnavailable, "not supported on this platform"
+	}
+	m.caps = append(caps, containers, units, ionice)
+	// Battery changes come from UPower as they happen where it runs, and with the
+	// refreshes otherwise.
+	if slices.Contains(ok, collectorBattery) && m.procOpts.upower == nil {
+		reason := "UPower not running, battery read on each refresh"
+		if !m.cfg.Battery.UPower {
+			reason = "battery.upower is off"
+		}
+		m.caps = append(m.caps, capability{name: "battery events", status: capPartial, reason: reason})
+	}
+	// Without a core dump feed, only the exits of the watched and pinned processes are seen.
+	if m.cfg.Crashes.Watch {
+		crashes := capability{name: "crash feed"}
+		if src, err := findCrashSource(); err != nil {
+			crashes.status, crashes.reason = capPartial, probeReason(err)+", exits of watched and pinned processes only"
+		} else {
+			crashes.reason = src.String()
+		}
+		m.caps = append(m.caps, crashes)
+	}
+
+	m.applySnapshot(collectedMsg{snap: snap, at: time.Now(), ok: ok})
+}
+
+// hasCollector reports whether the named collector passed the startup probe.
+func (m model) hasCollector(name string) bool {
+	return slices.ContainsFunc(m.collectors, func(c Collector) bool { return c.Name() == name })
+}
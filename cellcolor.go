@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The bubbles table measures cells with runewidth, which counts the bytes of ANSI escape
+// sequences, so a styled cell would be truncated and knock the columns out of line.
+// Colored cells are instead tagged with control characters, which both runewidth and
+// lipgloss treat as zero-width, and colorCells turns the tags into escape sequences once
+// the table is rendered.
+const (
+	tagRed = "\x01"
+	tagDim = "\x02"
+	tagEnd = "\x03"
+	// tagHighlight marks search matches.
+	tagHighlight = "\x04"
+	// tagWatched marks the cells of watched processes.
+	tagWatched = "\x05"
+)
+
+// tagCell marks s to be rendered with the given tag.
+func tagCell(s, tag string) string {
+	return tag + s + tagEnd
+}
+
+// stripTags removes the color tags from s, for output that isn't a rendered table.
+func stripTags(s string) string {
+	return strings.NewReplacer(tagRed, "", tagDim, "", tagHighlight, "", tagWatched, "", tagBandWarning, "", tagBandCritical, "", tagEnd, "").Replace(s)
+}
+
+// colorCells replaces the color tags in a rendered table. Tags only set and reset the
+// foreground color and the text attributes, so the background of the selected row
+// survives. selected is the escape sequence the table starts its selected row with; as its
+// background is the highlight color, matches on that row are only bold and underlined. A
+// band tag tints the background of its whole line, except on the selected row, whose
+// highlight takes precedence.
+func colorCells(view, selected string) string {
+	const tags = tagRed + tagDim + tagHighlight + tagWatched + tagBandWarning + tagBandCritical
+	if !strings.ContainsAny(view, tags) {
+		return view
+	}
+	red, highlight, watched := foreground(Color.Red), foreground(Color.Highlight), foreground(Color.Watched)
+	dim, match, end := "\x1b[2m", "\x1b[1;4m", "\x1b[39;22;24m"
+	if red == "" {
+		// Without color support the tags are dropped rather than half-applied.
+		dim, match, end = "", "", ""
+	}
+	bands := map[string]string{tagBandWarning: background(Color.WarningRow), tagBandCritical: background(Color.CriticalRow)}
+	plain := strings.NewReplacer(tagRed, red, tagDim, dim, tagHighlight, highlight+match, tagWatched, watched, tagBandWarning, "", tagBandCritical, "", tagEnd, end)
+	onSelected := strings.NewReplacer(tagRed, red, tagDim, dim, tagHighlight, match, tagWatched, watched, tagBandWarning, "", tagBandCritical, "", tagEnd, end)
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if !strings.ContainsAny(line, tags) {
+			continue
+		}
+		if selected != "" && strings.HasPrefix(line, selected) {
+			lines[i] = onSelected.Replace(line)
+		} else {
+			tint := ""
+			for tag, bg := range bands {
+				if strings.Contains(line, tag) && bg != "" {
+					tint = bg
+				}
+			}
+			lines[i] = plain.Replace(line)
+			if tint != "" {
+				lines[i] = tint + lines[i] + "\x1b[49m"
+			}
+		}
+		// A cell cut short by the table loses its end tag; never let it color the next line.
+		lines[i] += end
+	}
+	return strings.Join(lines, "\n")
+}
+
+// background returns the escape sequence that sets c as the background color, or "" when
+// the terminal has no colors.
+func background(c lipgloss.AdaptiveColor) string {
+	hex := c.Light
+	if lipgloss.HasDarkBackground() {
+		hex = c.Dark
+	}
+	seq := lipgloss.ColorProfile().Color(hex).Sequence(true)
+	if seq == "" {
+		return ""
+	}
+	return "\x1b[" + seq + "m"
+}
+
+// foreground returns the escape sequence that sets c as the foreground color, or "" when
+// the terminal has no colors.
+func foreground(c lipgloss.AdaptiveColor) string {
+	hex := c.Light
+	if lipgloss.HasDarkBackground() {
+		hex = c.Dark
+	}
+	seq := lipgloss.ColorProfile().Color(hex).Sequence(false)
+	if seq == "" {
+		return ""
+	}
+	return "\x1b[" + seq + "m"
+}
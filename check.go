@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runCheck evaluates the configured alert rules against one snapshot, for cron jobs and
+// monitoring plugins. It exits 0 when no rule fires, 1 when one does and 2 when the config
+// is invalid or a rule's metric cannot be read.
+func runCheck(args []string) int {
+	fs := newFlagSet("check")
+	configSrc := configFlag(fs)
+	fs.Parse(args)
+
+	cfg, err := LoadConfig(*configSrc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(cfg.Alerts) == 0 {
+		fmt.Println("no alert rules configured")
+		return 0
+	}
+
+	snap := newHeadless(cfg, cfg.Interval).collect()
+	code := 0
+	for _, r := range cfg.Alerts {
+		value, ok := alertMetrics[r.Metric](snap)
+		if !ok {
+			fmt.Printf("UNKNOWN %s: %s not available\n", r.Name, r.Metric)
+			code = 2
+			continue
+		}
+		status := "OK"
+		if value > r.threshold() {
+			status = "ALERT"
+			code = max(code, 1)
+		}
+		fmt.Printf("%s %s: %s %s (threshold %s)\n", status, r.Name, r.Metric, formatFloat(value, 2), formatFloat(r.threshold(), 2))
+	}
+	return code
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// childRun is the command started by the run subcommand. The monitor pins it with its
+// descendants to the top of the process table and, with -summary, records what the tree
+// uses for the report printed on exit, a /usr/bin/time -v with live feedback.
+type childRun struct {
+	cmd  *exec.Cmd
+	argv []string
+	key  procKey
+	// record is set by -summary; peakRSS and peakCPU are the highest memory and CPU% of the
+	// whole tree seen in a pass, samples the number of passes that saw it.
+	record          bool
+	peakRSS         uint64
+	peakCPU, sumCPU float64
+	samples         int
+	started, exited time.Time
+	state           *os.ProcessState
+	waitErr         error
+	done            bool
+}
+
+// childExitedMsg reports that the monitored command has exited.
+type childExitedMsg struct {
+	state *os.ProcessState
+	err   error
+	at    time.Time
+}
+
+// startChild starts argv in its own process group with its output written to out, so it
+// neither draws over the TUI nor gets the terminal's signals directly; ctrl+c in the
+// monitor forwards them instead.
+func startChild(argv []string, out io.Writer, record bool) (*childRun, error) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.SysProcAttr = childSysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := &childRun{cmd: cmd, argv: argv, record: record, started: time.Now()}
+	c.key.pid = int32(cmd.Process.Pid)
+	if p, err := process.NewProcess(c.key.pid); err == nil {
+		c.key.createTime, _ = p.CreateTime()
+	}
+	return c, nil
+}
+
+// waitCmd waits for the command in the background and reports its exit.
+func (c *childRun) waitCmd() tea.Cmd {
+	return func() tea.Msg {
+		err := c.cmd.Wait()
+		return childExitedMsg{state: c.cmd.ProcessState, err: err, at: time.Now()}
+	}
+}
+
+// running reports whether the command has not exited yet.
+func (c *childRun) running() bool {
+	return c != nil && !c.done
+}
+
+// name is the command line, shortened for prompts and the banner.
+func (c *childRun) name() string {
+	return truncate(strings.Join(c.argv, " "), 40)
+}
+
+// observe records the usage of the command and its descendants in a pass.
+func (c *childRun) observe(procs []ProcessInfo) {
+	if !c.record || c.done {
+		return
+	}
+	var rss uint64
+	var cpu float64
+	found := false
+	for _, p := range procs {
+		if p.PID == c.key.pid {
+			rss, cpu, found = p.Memory, p.CPUPercent, true
+		}
+	}
+	if !found {
+		return
+	}
+	for _, d := range descendants(procs, c.key.pid) {
+		rss += d.Memory
+		cpu += d.CPUPercent
+	}
+	c.peakRSS = max(c.peakRSS, rss)
+	c.peakCPU = max(c.peakCPU, cpu)
+	c.sumCPU += cpu
+	c.samples++
+}
+
+// exit records the end of the command.
+func (c *childRun) exit(msg childExitedMsg) {
+	c.done, c.state, c.waitErr, c.exited = true, msg.state, msg.err, msg.at
+}
+
+// status describes how the command ended, e.g. "exit status 0" or "signal: interrupt".
+func (c *childRun) status() string {
+	switch {
+	case c.state != nil:
+		return c.state.String()
+	case c.waitErr != nil:
+		return c.waitErr.Error()
+	}
+	return "unknown"
+}
+
+// signaled reports whether the command was killed by a signal.
+func (c *childRun) signaled() bool {
+	return c.done && c.state != nil && c.state.ExitCode() == -1
+}
+
+// crashEvent is the command's exit as a crash, for the crashes panel.
+func (c *childRun) crashEvent() crashEvent {
+	return crashEvent{name: filepath.Base(c.argv[0]), pid: c.key.pid, cause: c.status(), at: c.exited}
+}
+
+// exitCode is the exit code of the run subcommand: the command's own, 0 while it still
+// runs and 1 when it died on a signal.
+func (c *childRun) exitCode() int {
+	if !c.done || c.state == nil {
+		return 0
+	}
+	if code := c.state.ExitCode(); code >= 0 {
+		return code
+	}
+	return 1
+}
+
+// summary is the report printed when the monitor quits.
+func (c *childRun) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Command: %s\n", strings.Join(c.argv, " "))
+	if !c.done {
+		fmt.Fprintf(&b, "Still running as PID %d after %s\n", c.key.pid, formatAge(time.Since(c.started)))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Exit status: %s\n", c.status())
+	fmt.Fprintf(&b, "Elapsed time: %s\n", c.exited.Sub(c.started).Round(time.Millisecond))
+	if c.state != nil {
+		// The rusage of a reaped process includes its own reaped children.
+		fmt.Fprintf(&b, "CPU time: %s user, %s system\n",
+			c.state.UserTime().Round(time.Millisecond), c.state.SystemTime().Round(time.Millisecond))
+	}
+	if c.record && c.samples > 0 {
+		fmt.Fprintf(&b, "Peak RSS: %s\n", formatBytes(c.peakRSS))
+		fmt.Fprintf(&b, "CPU: %s average, %s peak over %d samples\n",
+			formatPercent(c.sumCPU/float64(c.samples), 1), formatPercent(c.peakCPU, 1), c.samples)
+	}
+	return b.String()
+}
+
+// withChildTree moves the descendants of the pinned monitored command right below it, so
+// the whole tree it started stays in view.
+func (m model) withChildTree(procs, all []ProcessInfo) []ProcessInfo {
+	if m.child == nil || m.pinned == nil || *m.pinned != m.child.key || len(procs) == 0 || !m.isPinned(procs[0]) {
+		return procs
+	}
+	below := map[int32]bool{}
+	for _, d := range descendants(all, m.child.key.pid) {
+		below[d.PID] = true
+	}
+	tree := []ProcessInfo{procs[0]}
+	var rest []ProcessInfo
+	for _, p := range procs[1:] {
+		if below[p.PID] {
+			tree = append(tree, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	return append(tree, rest...)
+}
+
+// updateChildQuitPrompt handles the answer to whether to quit after ctrl+c was forwarded
+// to the command.
+func (m model) updateChildQuitPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.childQuitPrompt = false
+	switch msg.String() {
+	case "y", "q", "enter", "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// interrupt handles ctrl+c wherever it is pressed: while the command of the run
+// subcommand runs it gets the interrupt, then the question whether to quit too; otherwise
+// the program quits.
+func (m model) interrupt() (tea.Model, tea.Cmd) {
+	if m.child.running() {
+		m.interruptChild()
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// interruptChild forwards ctrl+c to the monitored command and asks whether to quit too.
+func (m *model) interruptChild() {
+	err := interruptProcessGroup(m.child.key.pid)
+	m.actionLog.record(m.child.key.pid, m.child.name(), "SIGINT to the group", err)
+	if err != nil {
+		m.reportError(fmt.Errorf("could not interrupt %s: %w", m.child.name(), err))
+		return
+	}
+	m.childQuitPrompt = true
+}
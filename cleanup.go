@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cleanupItem is one problem listed by the cleanup assistant: a parent leaving zombie
+// children unreaped, or a process holding sockets in CLOSE_WAIT.
+type cleanupItem struct {
+	// proc is the parent of the zombies, or the owner of the sockets.
+	proc ProcessInfo
+	// zombies are the unreaped children; empty for a CLOSE_WAIT item.
+	zombies []ProcessInfo
+	// closeWait is the number of CLOSE_WAIT sockets owned by proc.
+	closeWait int
+}
+
+// maxListedZombies bounds how many zombie children an item lists by PID.
+const maxListedZombies = 5
+
+// cleanupItems correlates the latest process and socket data into the problems the cleanup
+// assistant offers actions for, the largest first. CLOSE_WAIT owners are only listed while
+// the total is above the sockets panel's warning threshold.
+func (m model) cleanupItems() []cleanupItem {
+	var items []cleanupItem
+
+	byParent := map[int32][]ProcessInfo{}
+	for _, p := range m.data.Procs {
+		if p.Zombie {
+			byParent[p.PPID] = append(byParent[p.PPID], p)
+		}
+	}
+	var zombieItems []cleanupItem
+	for ppid, zombies := range byParent {
+		parent, ok := m.findProcess(ppid)
+		if !ok {
+			parent = ProcessInfo{PID: ppid, Name: "Unknown"}
+		}
+		zombieItems = append(zombieItems, cleanupItem{proc: parent, zombies: zombies})
+	}
+	sort.Slice(zombieItems, func(i, j int) bool {
+		a, b := zombieItems[i], zombieItems[j]
+		if len(a.zombies) != len(b.zombies) {
+			return len(a.zombies) > len(b.zombies)
+		}
+		return a.proc.PID < b.proc.PID
+	})
+	items = append(items, zombieItems...)
+
+	if warn := m.cfg.Sockets.CloseWaitWarn; warn > 0 && m.data.Sockets.States["CLOSE_WAIT"] > warn {
+		byOwner := map[int32]int{}
+		for _, c := range m.data.Sockets.Conns {
+			if c.Status == "CLOSE_WAIT" {
+				byOwner[c.Pid]++
+			}
+		}
+		var sockItems []cleanupItem
+		for pid, n := range byOwner {
+			owner, ok := m.findProcess(pid)
+			if !ok || pid == 0 {
+				// Owners of other users' sockets are only visible with privileges.
+				owner = ProcessInfo{PID: pid, Name: "unknown owner"}
+			}
+			sockItems = append(sockItems, cleanupItem{proc: owner, closeWait: n})
+		}
+		sort.Slice(sockItems, func(i, j int) bool {
+			a, b := sockItems[i], sockItems[j]
+			if a.closeWait != b.closeWait {
+				return a.closeWait > b.closeWait
+			}
+			return a.proc.PID < b.proc.PID
+		})
+		items = append(items, sockItems...)
+	}
+	return items
+}
+
+// cleanupNotice summarises what the cleanup assistant found, or returns "" when nothing is wrong.
+func (m model) cleanupNotice() string {
+	zombies, closeWait := 0, 0
+	for _, item := range m.cleanupItems() {
+		zombies += len(item.zombies)
+		closeWait += item.closeWait
+	}
+	var found []string
+	if zombies > 0 {
+		found = append(found, fmt.Sprintf("%s zombie %s", formatInt(zombies), plural(zombies, "process", "processes")))
+	}
+	if closeWait > 0 {
+		found = append(found, fmt.Sprintf("%s CLOSE_WAIT sockets", formatInt(closeWait)))
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	return strings.Join(found, ", ") + " · Z: clean up"
+}
+
+// plural picks the singular or plural form for n.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// updateCleanup handles key presses in the cleanup assistant. Nothing is done without a
+// key press, and signals additionally ask for confirmation.
+func (m model) updateCleanup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.reapTarget != nil {
+		return m.updateReapConfirm(msg)
+	}
+	items := m.cleanupItems()
+	m.cleanupCursor = min(m.cleanupCursor, max(len(items)-1, 0))
+	switch msg.String() {
+	case "up", "k":
+		m.cleanupCursor = max(m.cleanupCursor-1, 0)
+	case "down", "j":
+		m.cleanupCursor = min(m.cleanupCursor+1, max(len(items)-1, 0))
+	// Inspects the parent of the zombies, or jumps to the socket owner in the process table.
+	case "enter":
+		if len(items) == 0 {
+			return m, nil
+		}
+		item := items[m.cleanupCursor]
+		if len(item.zombies) > 0 {
+			m.showDetail(item.proc.PID)
+			return m, nil
+		}
+		if !m.selectPID(item.proc.PID) {
+			m.reportInfo(fmt.Sprintf("PID %d is not in the process table", item.proc.PID))
+		}
+	// Asks to send SIGCHLD to the parent of the zombies.
+	case "s":
+		if len(items) > 0 && len(items[m.cleanupCursor].zombies) > 0 && reapSignal != nil {
+			target := items[m.cleanupCursor].proc
+			m.reapTarget = &target
+		}
+	case "esc", "Z", "q":
+		m.view = viewProcesses
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// updateReapConfirm handles the answer to sending SIGCHLD to a zombie parent.
+func (m model) updateReapConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		t := *m.reapTarget
+		if !sameProcess(t) {
+			m.reportInfo(fmt.Sprintf("PID %d has exited", t.PID))
+		} else if err := sendSignal(t.PID, reapSignal.sig); err != nil {
+			m.actionLog.record(t.PID, t.Name, reapSignal.name, err)
+			m.reportError(fmt.Errorf("could not send %s to PID %d (%s): %w", reapSignal.name, t.PID, t.Name, err))
+		} else {
+			m.actionLog.record(t.PID, t.Name, reapSignal.name, nil)
+			m.reportInfo(fmt.Sprintf("sent %s to PID %d (%s)", reapSignal.name, t.PID, t.Name))
+		}
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	m.reapTarget = nil
+	return m, nil
+}
+
+// viewCleanup lists the zombie parents and CLOSE_WAIT owners with the selected one marked.
+func (m model) viewCleanup() string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	items := m.cleanupItems()
+
+	if m.reapTarget != nil {
+		t := m.reapTarget
+		sig := m.baseStyle.Foreground(Color.Red).Bold(true).Render(reapSignal.name)
+		return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+			title("Send ")+sig+title(fmt.Sprintf(" to PID %d (%s)?", t.PID, t.Name)),
+			"",
+			hint("A parent that handles SIGCHLD reaps its exited children; one that ignores it needs a fix or a restart."),
+			"",
+			hint("y: send · any other key: cancel"),
+		))
+	}
+
+	lines := []string{title("Cleanup"), ""}
+	if len(items) == 0 {
+		lines = append(lines, hint("no zombies or CLOSE_WAIT pileups"))
+	}
+	cursor := min(m.cleanupCursor, len(items)-1)
+	for i, item := range items {
+		var line string
+		if len(item.zombies) > 0 {
+			pids := make([]string, 0, maxListedZombies)
+			for j, z := range item.zombies {
+				if j == maxListedZombies {
+					pids = append(pids, "…")
+					break
+				}
+				pids = append(pids, fmt.Sprintf("%d", z.PID))
+			}
+			line = fmt.Sprintf("PID %d (%s) is not reaping %d zombie %s: %s", item.proc.PID, item.proc.Name,
+				len(item.zombies), plural(len(item.zombies), "child", "children"), strings.Join(pids, ", "))
+		} else {
+			line = fmt.Sprintf("PID %d (%s) holds %d sockets in CLOSE_WAIT", item.proc.PID, item.proc.Name, item.closeWait)
+		}
+		if i == cursor {
+			line = m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	keys := []string{"↑/↓: select", "enter: inspect parent / jump to owner"}
+	if reapSignal != nil {
+		keys = append(keys, "s: send SIGCHLD to parent")
+	}
+	lines = append(lines, "", hint(strings.Join(append(keys, "esc: back"), " · ")))
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
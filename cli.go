@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// subcommand is one mode of the program. Each has its own flag set; they all share the
+// config file and the collectors.
+type subcommand struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// subcommands lists the modes in the order the usage shows them; the first is the default.
+var subcommands []subcommand
+
+func init() {
+	// Assigned in init because the help command's usage refers back to the list.
+	subcommands = []subcommand{
+		{"tui", "run the interactive monitor (default)", runTUI},
+		{"run", "start a command and follow it in the monitor, then summarise its usage", runRun},
+		{"batch", "print plain-text snapshots, like top -b", runBatch},
+		{"json", "print a JSON snapshot", runJSON},
+		{"check", "evaluate the alert rules once; exit 1 if any fires", runCheck},
+		{"serve", "serve JSON snapshots and Prometheus metrics over HTTP", runServe},
+		{"bundle", "write a tar.gz of snapshots and the capability report for a bug report", runBundle},
+		{"help", "show this help, or the flags of a command", runHelp},
+	}
+}
+
+// programName is the name the program was invoked as, used in usage messages.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// runCLI dispatches to the subcommand named by the first argument and returns the exit
+// code. A bare invocation, or one starting with a flag, runs the TUI so existing command
+// lines such as "-config x.toml" keep working.
+func runCLI(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runTUI(args)
+	}
+	for _, c := range subcommands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", programName(), args[0])
+	usage()
+	return 2
+}
+
+// usage prints the list of subcommands, one per line as "name<spaces>summary" so shell
+// completion scripts can parse it.
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", programName())
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "  %-8s%s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", programName())
+}
+
+// runHelp prints the usage, or the flags of the named command.
+func runHelp(args []string) int {
+	if len(args) > 0 {
+		for _, c := range subcommands {
+			if c.name == args[0] && c.name != "help" {
+				return c.run([]string{"-h"})
+			}
+		}
+	}
+	usage()
+	return 0
+}
+
+// newFlagSet returns the flag set of the named subcommand. Its usage lists one flag per
+// line with its default, the format shell completion generators expect.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: %s %s [flags]\n\n", programName(), name)
+		for _, c := range subcommands {
+			if c.name == name {
+				fmt.Fprintf(w, "%s%s.\n\n", strings.ToUpper(c.summary[:1]), c.summary[1:])
+			}
+		}
+		fmt.Fprintln(w, "Flags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// configFlag adds the -config and -profile flags every subcommand shares.
+func configFlag(fs *flag.FlagSet) *configSource {
+	src := &configSource{}
+	fs.StringVar(&src.path, "config", defaultConfigPath(), "path to the TOML config file")
+	fs.StringVar(&src.profile, "profile", "", "config profile to apply, e.g. server (default: the first profile whose match rule fits this host)")
+	return src
+}
+
+// loadConfigOrExit loads the config for a headless subcommand. Invalid keys only cost
+// their value, so they are reported on stderr and the defaults are used for them; an
+// unknown profile exits, since running with the wrong settings would go unnoticed.
+func loadConfigOrExit(src configSource) Config {
+	cfg, err := LoadConfig(src)
+	if errors.Is(err, errUnknownProfile) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	Units = cfg.Units.unitPrefs()
+	Names = cfg.Names.prefs()
+	cfg.Columns.setCustomColumns()
+	return cfg
+}
+
+// loadMonitorConfig loads the config for the TUI like loadConfigOrExit, returning the
+// keys that took their defaults to be told in the error banner, as the screen hides
+// stderr. An unknown profile or a file that can't be parsed exits, since the user would
+// not notice the whole file being ignored.
+func loadMonitorConfig(src configSource) (Config, error) {
+	cfg, err := LoadConfig(src)
+	if err != nil && !errors.Is(err, errInvalidKeys) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	Units = cfg.Units.unitPrefs()
+	Names = cfg.Names.prefs()
+	cfg.Columns.setCustomColumns()
+	return cfg, err
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// ClipboardConfig is the [clipboard] section of the config file.
+type ClipboardConfig struct {
+	// Method is how copied text reaches the clipboard: "osc52" asks the terminal with the
+	// OSC 52 escape sequence, which works over SSH; "command" runs the first of pbcopy,
+	// wl-copy, xclip, xsel or clip that is installed; "auto" uses OSC 52 over SSH and the
+	// command otherwise, falling back to OSC 52 without one. A terminal that ignores OSC
+	// 52 can't be told from one that honours it, so set "command" for those.
+	Method string `toml:"method"`
+}
+
+// clipboardTimeout bounds a clipboard command, which only hands the text to a daemon.
+const clipboardTimeout = 2 * time.Second
+
+// clipboardCommands are the clipboard commands tried in order, each with the environment
+// variable it needs set, if any.
+var clipboardCommands = []struct {
+	env  string
+	args []string
+}{
+	{"", []string{"pbcopy"}},
+	{"WAYLAND_DISPLAY", []string{"wl-copy"}},
+	{"DISPLAY", []string{"xclip", "-selection", "clipboard"}},
+	{"DISPLAY", []string{"xsel", "--clipboard", "--input"}},
+	{"", []string{"clip"}},
+}
+
+// clipboardCommand returns the first usable clipboard command, nil when there is none.
+func clipboardCommand() []string {
+	for _, c := range clipboardCommands {
+		if c.env != "" && os.Getenv(c.env) == "" {
+			continue
+		}
+		if c.args[0] == "clip" && runtime.GOOS != "windows" {
+			continue
+		}
+		if _, err := exec.LookPath(c.args[0]); err == nil {
+			return c.args
+		}
+	}
+	return nil
+}
+
+// overSSH reports whether the monitor runs in an SSH session, where a local clipboard
+// command would copy to the remote host's clipboard.
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// clipboardMsg reports the outcome of a copy: what was copied and how.
+type clipboardMsg struct {
+	what, via string
+	err       error
+}
+
+// copyCmd copies s to the clipboard the configured way; what names s in the notice.
+// OSC 52 is written right away, a clipboard command runs in the background.
+func (m model) copyCmd(s, what string) tea.Cmd {
+	args := clipboardCommand()
+	switch m.cfg.Clipboard.Method {
+	case "osc52":
+		args = nil
+	case "auto":
+		if overSSH() {
+			args = nil
+		}
+	case "command":
+		if args == nil {
+			return func() tea.Msg {
+				return clipboardMsg{what: what, err: errors.New("no clipboard command found; install wl-copy, xclip or xsel, or set clipboard.method = \"osc52\"")}
+			}
+		}
+	}
+	if args == nil {
+		termenv.NewOutput(os.Stdout).Copy(s)
+		return func() tea.Msg { return clipboardMsg{what: what, via: "OSC 52"} }
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(s)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%w: %s", err, msg)
+			}
+			return clipboardMsg{what: what, via: args[0], err: err}
+		}
+		return clipboardMsg{what: what, via: args[0]}
+	}
+}
+
+// reportCopy shows the outcome of a copy in the banner.
+func (m *model) reportCopy(msg clipboardMsg) {
+	if msg.err != nil {
+		m.reportError(fmt.Errorf("could not copy %s: %w", msg.what, msg.err))
+		return
+	}
+	m.reportInfo(fmt.Sprintf("copied %s to the clipboard (%s)", msg.what, msg.via))
+}
+
+// yankTargets are the keys that may follow y, in the order the footer lists them.
+var yankTargets = []struct{ key, what string }{
+	{"p", "PID"},
+	{"c", "command line"},
+	{"n", "name"},
+}
+
+// updateYank handles the key after y: p, c or n copy the PID, command line or name of the
+// process actions apply to, anything else cancels.
+func (m model) updateYank(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.yanking = false
+	pid, ok := m.actionPID()
+	if !ok {
+		return m, nil
+	}
+	p, ok := m.findProcess(pid)
+	if !ok {
+		return m, nil
+	}
+	switch msg.String() {
+	case "p":
+		return m, m.copyCmd(strconv.Itoa(int(p.PID)), fmt.Sprintf("PID %d", p.PID))
+	case "c":
+		if p.Cmdline == "" {
+			m.reportInfo(fmt.Sprintf("the command line of PID %d can't be read", p.PID))
+			return m, nil
+		}
+		return m, m.copyCmd(p.Cmdline, fmt.Sprintf("the command line of PID %d", p.PID))
+	case "n":
+		return m, m.copyCmd(p.Name, fmt.Sprintf("the name of PID %d", p.PID))
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// yankPrompt lists the keys that may follow y.
+func (m model) yankPrompt() string {
+	keys := make([]string, 0, len(yankTargets)+1)
+	for _, t := range yankTargets {
+		keys = append(keys, t.key+": "+t.what)
+	}
+	return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("copy") +
+		m.baseStyle.Foreground(Color.Secondary).Render(" · "+strings.Join(append(keys, "esc: cancel"), " · "))
+}
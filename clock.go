@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// clockJumpMin is how far the wall clock must move against the monotonic clock between
+// two passes to count as a jump; NTP slews smaller differences away.
+const clockJumpMin = 2 * time.Second
+
+// clockJump returns how far the wall clock jumped between the passes collected at prev
+// and at, or 0 for none. Go's monotonic clock doesn't follow clock steps and stops while
+// the machine is suspended, so the difference between the wall and the monotonic time
+// elapsed is the jump, whatever the refresh interval and however long a pass took.
+func clockJump(prev, at time.Time) time.Duration {
+	if prev.IsZero() {
+		return 0
+	}
+	jump := at.Round(0).Sub(prev.Round(0)) - at.Sub(prev)
+	if jump.Abs() < clockJumpMin {
+		return 0
+	}
+	return jump
+}
+
+// describeClockJump renders a jump for the banner, e.g. "clock jumped 42s — suspended?".
+func describeClockJump(jump time.Duration) string {
+	if jump < 0 {
+		return fmt.Sprintf("clock jumped back %s", (-jump).Round(time.Second))
+	}
+	return fmt.Sprintf("clock jumped %s — suspended?", jump.Round(time.Second))
+}
+
+// rateColumns are the process columns showing a rate over the last interval.
+var rateColumns = []string{columnCPU, columnNet, columnCtxSwitch}
+
+// discardedRate is shown instead of a rate computed over an interval the clock jumped in.
+const discardedRate = "--"
+
+// rateCell returns s, a rate over the last interval, or discardedRate when the clock
+// jumped during it.
+func (m model) rateCell(s string) string {
+	if m.data.ClockJump != 0 {
+		return discardedRate
+	}
+	return s
+}
+
+// discardRates blanks the rate columns of rows built from data when the clock jumped
+// during its interval.
+func (m model) discardRates(rows []table.Row, data Snapshot) {
+	if data.ClockJump == 0 {
+		return
+	}
+	for i, c := range m.columns {
+		if !slices.Contains(rateColumns, c.id) {
+			continue
+		}
+		for _, row := range rows {
+			row[i] = discardedRate
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// Snapshot holds the data gathered by one collection pass.
+type Snapshot struct {
+	CPU     cpu.TimesStat
+	Mem     MemStats
+	Procs   []ProcessInfo
+	Shm     SharedMemStats
+	Sockets SocketStats
+	Disks   []DiskIO
+	// NFS is the activity of the NFS mounts, shown with the disks.
+	NFS []NFSMount
+	// Interrupts is nil until there are two samples to diff.
+	Interrupts *InterruptStats
+	// Audit lists the processes with unusual executables, when the audit panel is on.
+	Audit []AuditFinding
+	// Slabs are the slab caches, largest first, where /proc/slabinfo can be read.
+	Slabs []SlabCache
+	// Throttle is nil where CPU throttling is not detected.
+	Throttle *ThrottleStats
+	// SoC is nil except on ARM boards such as the Raspberry Pi.
+	SoC *SoCStatus
+	// Sensors is nil where no fan or power sensor is found.
+	Sensors *SensorStats
+	// Protocols is nil until there are two samples to diff, and where they are not read.
+	Protocols *NetProtocols
+	// Limits is nil where the kernel limits are not read.
+	Limits *KernelLimits
+	// Interfaces is the traffic of each network interface, physical ones first; Namespaces
+	// lists the other network namespaces with network.namespaces.
+	Interfaces []NetInterface
+	Namespaces []NetNamespace
+	// Filesystems and Net feed the session statistics.
+	Filesystems []Filesystem
+	Net         NetTotals
+	// Load is nil until the load average has been read.
+	Load *load.AvgStat
+	// Battery is nil on hosts without one.
+	Battery *BatteryStatus
+	// Firewall are the counters of network.firewall_counters, in config order.
+	Firewall []FirewallCounter
+	// ClockJump is how far the wall clock jumped during the pass, 0 for none; the rates of
+	// such a pass are shown as discarded. See clockJump.
+	ClockJump time.Duration
+}
+
+// Collector gathers one kind of statistic into a Snapshot. Name identifies the
+// collector and the panels fed by it. Collect must leave the snapshot untouched
+// when it returns an error, so the previous values stay on screen.
+type Collector interface {
+	Name() string
+	Collect(s *Snapshot) error
+}
+
+// Names of the built-in collectors, used to look up per-panel freshness.
+const (
+	collectorCPU       = "cpu"
+	collectorMem       = "mem"
+	collectorProcesses = "processes"
+	collectorTmpfs     = "tmpfs"
+	collectorSockets   = "sockets"
+	collectorLoad      = "load"
+	collectorDisk      = "disk"
+	collectorFS        = "filesystems"
+	collectorNet       = "network"
+	collectorLimits    = "limits"
+	collectorNFS       = "nfs"
+	collectorIRQ       = "interrupts"
+	collectorAudit     = "audit"
+	collectorProto     = "protocols"
+	collectorThrottle  = "throttle"
+	collectorSlab      = "slab"
+	collectorSoC       = "soc"
+	collectorIfaces    = "interfaces"
+	collectorSensors   = "sensors"
+	collectorBattery   = "battery"
+	collectorFirewall  = "firewall"
+)
+
+// overrunWarnAfter is how many passes in a row must overrun the refresh interval before
+// the footer suggests a longer one.
+const overrunWarnAfter = 3
+
+// staleFactor is how many refresh intervals may pass without a successful
+// collection before a panel is marked stale.
+const staleFactor = 3
+
+// collectorFunc adapts a plain function into a Collector.
+type collectorFunc struct {
+	name string
+	fn   func(s *Snapshot) error
+}
+
+func (c collectorFunc) Name() string              { return c.name }
+func (c collectorFunc) Collect(s *Snapshot) error { return c.fn(s) }
+
+// defaultCollectors returns the collectors used by the TUI. opts enables the optional
+// per-process data backing the Container and NET columns.
+func defaultCollectors(opts processOptions) []Collector {
+	processes := NewProcessCollector(opts)
+	disks := NewDiskIOCollector()
+	dirty := &dirtyTracker{}
+	collectors := []Collector{
+		collectorFunc{collectorCPU, func(s *Snapshot) error {
+			stats, err := GetCPUStats()
+			if err != nil {
+				return err
+			}
+			s.CPU = stats
+			return nil
+		}},
+		collectorFunc{collectorMem, func(s *Snapshot) error {
+			stats, err := GetMEMStats()
+			if err != nil {
+				return err
+			}
+			dirty.observe(&stats, time.Now())
+			s.Mem = stats
+			return nil
+		}},
+		collectorFunc{collectorLoad, func(s *Snapshot) error {
+			avg, err := load.Avg()
+			if err != nil {
+				return err
+			}
+			s.Load = avg
+			return nil
+		}},
+		collectorFunc{collectorProcesses, func(s *Snapshot) error {
+			procs, err := processes.GetProcesses(0, opts.ctxTargets.get())
+			if err != nil {
+				return err
+			}
+			s.Procs = procs
+			return nil
+		}},
+		collectorFunc{collectorTmpfs, func(s *Snapshot) error {
+			stats, err := GetSharedMemStats()
+			if err != nil {
+				return err
+			}
+			s.Shm = stats
+			return nil
+		}},
+		collectorFunc{collectorDisk, func(s *Snapshot) error {
+			devices, err := disks.GetDiskIO()
+			if err != nil {
+				return err
+			}
+			s.Disks = devices
+			return nil
+		}},
+		collectorFunc{collectorFS, func(s *Snapshot) error {
+			filesystems, err := GetFilesystems()
+			if err != nil {
+				return err
+			}
+			s.Filesystems = filesystems
+			return nil
+		}},
+		collectorFunc{collectorNet, func(s *Snapshot) error {
+			totals, err := GetNetTotals()
+			if err != nil {
+				return err
+			}
+			s.Net = totals
+			return nil
+		}},
+		collectorFunc{collectorSockets, func(s *Snapshot) error {
+			stats, err := GetSocketStats()
+			if err != nil {
+				return err
+			}
+			s.Sockets = stats
+			return nil
+		}},
+	}
+	if nfsSupported {
+		nfs := NewNFSCollector()
+		collectors = append(collectors, collectorFunc{collectorNFS, func(s *Snapshot) error {
+			mounts, err := nfs.GetNFSMounts()
+			if err != nil {
+				return err
+			}
+			s.NFS = mounts
+			return nil
+		}})
+	}
+	if interruptsSupported {
+		irqs := NewInterruptCollector()
+		collectors = append(collectors, collectorFunc{collectorIRQ, func(s *Snapshot) error {
+			stats, err := irqs.GetInterrupts()
+			if err != nil {
+				return err
+			}
+			s.Interrupts = stats
+			return nil
+		}})
+	}
+	if slabSupported {
+		collectors = append(collectors, collectorFunc{collectorSlab, func(s *Snapshot) error {
+			caches, err := GetSlabCaches()
+			if err != nil {
+				return err
+			}
+			s.Slabs = caches
+			return nil
+		}})
+	}
+	if throttleSupported {
+		throttle := newThrottleCollector()
+		collectors = append(collectors, collectorFunc{collectorThrottle, func(s *Snapshot) error {
+			t, err := throttle.GetThrottle()
+			if err != nil {
+				return err
+			}
+			s.Throttle = t
+			return nil
+		}})
+	}
+	if socSupported {
+		soc := newSoCCollector()
+		collectors = append(collectors, collectorFunc{collectorSoC, func(s *Snapshot) error {
+			status, err := soc.GetSoC()
+			if err != nil {
+				return err
+			}
+			s.SoC = status
+			return nil
+		}})
+	}
+	sensors := newSensorCollector(opts.powerCommand)
+	collectors = append(collectors, collectorFunc{collectorSensors, func(s *Snapshot) error {
+		stats, err := sensors.GetSensors()
+		if err != nil {
+			return err
+		}
+		s.Sensors = stats
+		return nil
+	}})
+	if batterySupported {
+		collectors = append(collectors, collectorFunc{collectorBattery, func(s *Snapshot) error {
+			b, err := readBattery(opts.upower)
+			if err != nil {
+				return err
+			}
+			s.Battery = b
+			return nil
+		}})
+	}
+	if protocolsSupported {
+		protocols := newProtocolCollector()
+		collectors = append(collectors, collectorFunc{collectorProto, func(s *Snapshot) error {
+			p, err := protocols.GetProtocols()
+			if err != nil {
+				return err
+			}
+			s.Protocols = p
+			return nil
+		}})
+	}
+	if firewallSupported && len(opts.firewallCounters) > 0 {
+		firewall := newFirewallCollector(opts.firewallCounters)
+		collectors = append(collectors, collectorFunc{collectorFirewall, func(s *Snapshot) error {
+			counters, err := firewall.GetCounters()
+			if err != nil {
+				return err
+			}
+			s.Firewall = counters
+			return nil
+		}})
+	}
+	// Runs after the process collector, whose containers label the veth pairs.
+	ifaces := newIfaceCollector(opts.netns)
+	collectors = append(collectors, collectorFunc{collectorIfaces, func(s *Snapshot) error {
+		list, namespaces, err := ifaces.GetInterfaces(s.Procs)
+		if err != nil {
+			return err
+		}
+		s.Interfaces, s.Namespaces = list, namespaces
+		return nil
+	}})
+	if opts.audit && auditSupported {
+		// Runs after the process collector, so it audits the processes just listed.
+		collectors = append(collectors, collectorFunc{collectorAudit, func(s *Snapshot) error {
+			s.Audit = auditProcesses(s.Procs)
+			return nil
+		}})
+	}
+	if limitsSupported {
+		limits := newLimitsCollector()
+		collectors = append(collectors, collectorFunc{collectorLimits, func(s *Snapshot) error {
+			l, err := limits.GetKernelLimits()
+			if err != nil {
+				return err
+			}
+			s.Limits = l
+			return nil
+		}})
+	}
+	return collectors
+}
+
+// collectedMsg carries the result of a collection pass run in the background.
+type collectedMsg struct {
+	snap Snapshot
+	at   time.Time
+	// ok lists the names of the collectors that succeeded.
+	ok []string
+	// took is how long the whole pass ran.
+	took time.Duration
+}
+
+// collectCmd returns a command that runs every collector against a snapshot seeded with
+// the current data, so a failing collector leaves its panel showing the previous values.
+func (m model) collectCmd() tea.Cmd {
+	snap := m.data
+	collectors := m.collectors
+	metrics, interval, crash, statsd := m.metrics, m.interval, m.crash, m.statsd
+	return func() tea.Msg {
+		defer crash.capture()
+		start := time.Now()
+		ok := runCollectors(collectors, &snap, metrics, interval)
+		statsd.push(snap)
+		return collectedMsg{snap: snap, at: time.Now(), ok: ok, took: time.Since(start)}
+	}
+}
+
+// runCollectors runs one collection pass against snap and returns the names of the
+// collectors that succeeded. Failures are logged and leave their part of snap untouched.
+func runCollectors(collectors []Collector, snap *Snapshot, metrics *selfMetrics, interval time.Duration) []string {
+	var ok []string
+	start := time.Now()
+	for _, c := range collectors {
+		began := time.Now()
+		err := c.Collect(snap)
+		metrics.observeCollector(c.Name(), time.Since(began))
+		if err != nil {
+			slog.Error("Could not collect data", "collector", c.Name(), "error", err)
+			continue
+		}
+		ok = append(ok, c.Name())
+	}
+	metrics.observePass(time.Since(start), interval)
+	return ok
+}
+
+// observePass tracks passes that overran the refresh interval. Ticks arriving during such a
+// pass start no second one; they are counted as dropped instead.
+func (m *model) observePass(took time.Duration) {
+	m.lastPass = took
+	if took > m.interval {
+		m.overruns++
+	} else {
+		m.overruns = 0
+	}
+}
+
+// overrunWarning suggests a longer interval after overrunWarnAfter overruns in a row, or
+// returns "".
+func (m model) overrunWarning() string {
+	if m.overruns < overrunWarnAfter {
+		return ""
+	}
+	return fmt.Sprintf("collection is slower than interval (%s > %s), consider increasing -interval",
+		m.lastPass.Round(100*time.Millisecond), m.interval)
+}
+
+// staleAge reports how old the named collector's data is once it exceeds
+// staleFactor refresh intervals, or zero while the data is still fresh.
+// A collector that never succeeded is measured from the program start.
+func (m model) staleAge(name string, now time.Time) time.Duration {
+	last, ok := m.lastSuccess[name]
+	if !ok {
+		last = m.started
+	}
+	age := now.Sub(last)
+	if age <= staleFactor*m.interval {
+		return 0
+	}
+	return age.Truncate(time.Second)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+func TestFailingCollector(t *testing.T) {
+	failing := collectorFunc{collectorLoad, func(s *Snapshot) error { return errors.New("no load average") }}
+	working := collectorFunc{collectorCPU, func(s *Snapshot) error {
+		s.CPU.Idle = 75
+		return nil
+	}}
+
+	prev := &load.AvgStat{Load1: 1.5}
+	snap := Snapshot{Load: prev}
+	snap.CPU.Idle = 10
+	ok := runCollectors([]Collector{failing, working}, &snap, nil, time.Second)
+	if !slices.Equal(ok, []string{collectorCPU}) {
+		t.Fatalf("ok = %v, want only %s", ok, collectorCPU)
+	}
+	if snap.Load != prev || snap.Load.Load1 != 1.5 {
+		t.Errorf("Load = %v after a failed collection, want the previous %v", snap.Load, prev)
+	}
+	if snap.CPU.Idle != 75 {
+		t.Errorf("CPU.Idle = %v, want the working collector's 75", snap.CPU.Idle)
+	}
+
+	m := newModel(defaultConfig())
+	m.interval = time.Second
+	start := time.Now()
+	m.started = start
+	m.applySnapshot(collectedMsg{snap: snap, at: start, ok: ok})
+
+	fresh := start.Add(staleFactor * m.interval)
+	stale := fresh.Add(time.Second)
+	if age := m.staleAge(collectorLoad, fresh); age != 0 {
+		t.Errorf("failing collector stale after %v, want fresh until %d intervals", age, staleFactor)
+	}
+	if age := m.staleAge(collectorLoad, stale); age == 0 {
+		t.Error("failing collector still fresh after staleFactor intervals")
+	}
+	if m.staleBadge(collectorLoad) != "" {
+		t.Error("badge shown before staleFactor intervals")
+	}
+	// The working collector succeeded at start, so it goes stale on the same schedule
+	// until its next success.
+	m.applySnapshot(collectedMsg{snap: snap, at: stale, ok: ok})
+	if age := m.staleAge(collectorCPU, stale); age != 0 {
+		t.Errorf("working collector stale %v after a success", age)
+	}
+	if age := m.staleAge(collectorLoad, stale); age == 0 {
+		t.Error("failing collector fresh again without a success")
+	}
+}
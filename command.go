@@ -0,0 +1,206 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	gnet "github.com/shirou/gopsutil/v4/net"
+)
+
+// commandUsage lists the commands of the : prompt for the error shown on a typo.
+const commandUsage = ":1234 or :pid 1234 jumps to a process, :port 8080 to the one listening on a port"
+
+// updateCommand handles the keys typed at the : prompt; enter runs the command.
+func (m model) updateCommand(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m.interrupt()
+	}
+	m.command, m.commanding = editSearch(m.command, msg)
+	if msg.Type != tea.KeyEnter {
+		return m, nil
+	}
+	command := strings.TrimSpace(m.command)
+	m.command = ""
+	return m, m.runCommand(command)
+}
+
+// runCommand runs one command of the : prompt.
+func (m *model) runCommand(command string) tea.Cmd {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	name, arg := "pid", fields[0]
+	if len(fields) == 2 {
+		name, arg = fields[0], fields[1]
+	} else if len(fields) > 2 {
+		m.reportError(fmt.Errorf("unknown command %q; %s", command, commandUsage))
+		return nil
+	}
+	switch name {
+	case "pid":
+		pid, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			break
+		}
+		if !m.selectPID(int32(pid)) {
+			m.reportInfo(fmt.Sprintf("PID %d is not in the process table", pid))
+		}
+		return nil
+	case "port":
+		port, err := strconv.ParseUint(arg, 10, 16)
+		if err != nil || port == 0 {
+			m.reportError(fmt.Errorf("invalid port %q", arg))
+			return nil
+		}
+		return portLookupCmd(uint32(port))
+	}
+	m.reportError(fmt.Errorf("unknown command %q; %s", command, commandUsage))
+	return nil
+}
+
+// portListener is one socket bound to the port looked up by :port.
+type portListener struct {
+	proto string
+	addr  string
+	pid   int32
+}
+
+// portListenersMsg carries the sockets bound to a port, found in the background.
+type portListenersMsg struct {
+	port      uint32
+	listeners []portListener
+	err       error
+}
+
+// portLookupCmd finds the TCP sockets listening on port and the UDP sockets bound to it,
+// over IPv4 and IPv6. Connected UDP sockets are left out; like outgoing TCP connections
+// they only use the port as their local end.
+func portLookupCmd(port uint32) tea.Cmd {
+	return func() tea.Msg {
+		conns, err := gnet.Connections("inet")
+		if err != nil {
+			return portListenersMsg{port: port, err: err}
+		}
+		var listeners []portListener
+		for _, c := range conns {
+			if c.Laddr.Port != port {
+				continue
+			}
+			var proto string
+			switch {
+			case c.Type == syscall.SOCK_STREAM && c.Status == "LISTEN":
+				proto = "tcp"
+			case c.Type == syscall.SOCK_DGRAM && c.Raddr.Port == 0:
+				proto = "udp"
+			default:
+				continue
+			}
+			if c.Family == syscall.AF_INET6 {
+				proto += "6"
+			}
+			addr := net.JoinHostPort(c.Laddr.IP, strconv.FormatUint(uint64(c.Laddr.Port), 10))
+			listeners = append(listeners, portListener{proto: proto, addr: addr, pid: c.Pid})
+		}
+		slices.SortFunc(listeners, func(a, b portListener) int {
+			if c := cmp.Compare(a.pid, b.pid); c != 0 {
+				return c
+			}
+			return strings.Compare(a.proto+a.addr, b.proto+b.addr)
+		})
+		return portListenersMsg{port: port, listeners: listeners}
+	}
+}
+
+// showListeners jumps to the process listening on the port, or opens the picker when
+// several processes share it, e.g. with SO_REUSEPORT.
+func (m *model) showListeners(msg portListenersMsg) {
+	if msg.err != nil {
+		m.reportError(fmt.Errorf("could not list sockets: %w", msg.err))
+		return
+	}
+	if len(msg.listeners) == 0 {
+		m.reportInfo(fmt.Sprintf("nothing listening on %d", msg.port))
+		return
+	}
+	pids := map[int32]bool{}
+	for _, l := range msg.listeners {
+		pids[l.pid] = true
+	}
+	if len(pids) > 1 {
+		m.portPicker = &portPicker{port: msg.port, listeners: msg.listeners}
+		return
+	}
+	m.jumpToListener(msg.port, msg.listeners[0])
+}
+
+// jumpToListener selects the owner of a listening socket in the process table.
+func (m *model) jumpToListener(port uint32, l portListener) {
+	if l.pid == 0 {
+		// Owners of other users' sockets are only visible with privileges.
+		m.reportInfo(fmt.Sprintf("%s %s is bound by a process we may not inspect; run as root to see it", l.proto, l.addr))
+		return
+	}
+	if !m.selectPID(l.pid) {
+		m.reportInfo(fmt.Sprintf("PID %d listening on %d is not in the process table", l.pid, port))
+	}
+}
+
+// portPicker lists the sockets bound to a port shared by several processes.
+type portPicker struct {
+	port      uint32
+	listeners []portListener
+	cursor    int
+}
+
+// updatePortPicker handles the keys of the port picker: enter jumps to the selected
+// socket's owner.
+func (m model) updatePortPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.portPicker
+	switch msg.String() {
+	case "up", "k":
+		p.cursor = max(p.cursor-1, 0)
+	case "down", "j":
+		p.cursor = min(p.cursor+1, len(p.listeners)-1)
+	case "enter":
+		m.portPicker = nil
+		m.jumpToListener(p.port, p.listeners[p.cursor])
+	case "esc", "q":
+		m.portPicker = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// viewPortPicker renders the sockets bound to the port with their owners.
+func (m model) viewPortPicker() string {
+	p := m.portPicker
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	lines := []string{title(fmt.Sprintf("Processes listening on %d", p.port)), ""}
+	for i, l := range p.listeners {
+		owner := "unknown owner"
+		if proc, ok := m.findProcess(l.pid); ok && l.pid != 0 {
+			owner = fmt.Sprintf("PID %d (%s)", proc.PID, proc.Name)
+		} else if l.pid != 0 {
+			owner = fmt.Sprintf("PID %d", l.pid)
+		}
+		line := fmt.Sprintf("%-5s %-40s %s", l.proto, l.addr, owner)
+		if i == p.cursor {
+			line = m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", hint("↑/↓: select · enter: jump to process · esc: cancel"))
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// compactHeight is the terminal height below which the full layout no longer fits and
+// the compact view takes over.
+const compactHeight = 8
+
+// compact reports whether the compact view is shown: when forced with --compact, or while
+// the terminal is too small for the full layout. It follows resizes in both directions.
+func (m model) compact() bool {
+	return m.compactFlag || (m.height > 0 && m.height < compactHeight)
+}
+
+// updateCompact handles keys in the compact view, where only quitting is available.
+func (m model) updateCompact(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		if m.confirmQuit {
+			m.quitPrompt = true
+			return m, nil
+		}
+		return m, tea.Quit
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// viewCompact renders the CPU and memory bars, the load and the busiest process without
+// borders or tables, on two lines, or one when the terminal is a single line high.
+func (m model) viewCompact() string {
+	label := m.baseStyle.Bold(true).Render
+	width := min(max((m.width-40)/2, 12), 29)
+	meter := barMeter{m.baseStyle, m.cfg.Meters.ASCII}
+
+	stats := []string{
+		label("CPU ") + meter.render(meterInput{value: 100 - m.data.CPU.Idle, fill: Color.Green}, width),
+		label("MEM ") + meter.render(meterInput{value: m.data.Mem.UsedPercent, fill: memBarColor(m.data.Mem)}, width),
+	}
+	if l := m.data.Load; l != nil && m.hasCollector(collectorLoad) {
+		stats = append(stats, label("Load ")+fmt.Sprintf("%s %s %s", formatFloat(l.Load1, 2), formatFloat(l.Load5, 2), formatFloat(l.Load15, 2)))
+	}
+
+	top := m.baseStyle.Foreground(Color.Secondary).Render("no processes")
+	if p, ok := m.topProcess(); ok {
+		top = label("Top ") + fmt.Sprintf("%s (%d) %s", p.Name, p.PID, formatPercent(p.CPUPercent, 1))
+	}
+	if m.quitPrompt {
+		top = m.footerHints()
+	}
+
+	line := lipgloss.NewStyle().MaxWidth(m.width).Render
+	if m.height == 1 {
+		return line(strings.Join(append(stats, top), "  "))
+	}
+	return line(strings.Join(stats, "  ")) + "\n" + line(top)
+}
+
+// topProcess returns the process using the most CPU.
+func (m model) topProcess() (ProcessInfo, bool) {
+	var top ProcessInfo
+	found := false
+	for _, p := range m.data.Procs {
+		if !found || p.CPUPercent > top.CPUPercent {
+			top, found = p, true
+		}
+	}
+	return top, found
+}
@@ -0,0 +1,885 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// appName is used for the config and state directory names.
+const appName = "system-monitor-tui"
+
+// Config is the user configuration read from the TOML config file.
+type Config struct {
+	// ConfirmQuit asks "are you sure" before q quits the program.
+	ConfirmQuit bool `toml:"confirm_quit"`
+	// QuitSummary prints a few lines of session statistics to the terminal after quitting.
+	QuitSummary bool `toml:"quit_summary"`
+	// Interval is the time between collection passes, e.g. "2s".
+	Interval time.Duration `toml:"interval"`
+	// SortHold keeps the process table's order for this long after moving the cursor, so a
+	// refresh doesn't put another process under it; "0s" re-sorts on every refresh.
+	SortHold time.Duration `toml:"sort_hold"`
+
+	Units   UnitsConfig   `toml:"units"`
+	Tmpfs   TmpfsConfig   `toml:"tmpfs"`
+	Columns ColumnsConfig `toml:"columns"`
+	Theme   ThemeConfig   `toml:"theme"`
+	Sockets SocketsConfig `toml:"sockets"`
+	Network NetworkConfig `toml:"network"`
+	Disk    DiskConfig    `toml:"disk"`
+	Memory  MemoryConfig  `toml:"memory"`
+	Limits  LimitsConfig  `toml:"limits"`
+	// Highlight lists the watched processes.
+	Highlight HighlightConfig `toml:"highlight"`
+	Rows      RowsConfig      `toml:"rows"`
+	Audit     AuditConfig     `toml:"audit"`
+	Anomalies AnomaliesConfig `toml:"anomalies"`
+	History   HistoryConfig   `toml:"history"`
+	Statsd    StatsdConfig    `toml:"statsd"`
+	Meters    MetersConfig    `toml:"meters"`
+	Clipboard ClipboardConfig `toml:"clipboard"`
+	Kill      KillConfig      `toml:"kill"`
+	Metrics   MetricsConfig   `toml:"metrics"`
+	Battery   BatteryConfig   `toml:"battery"`
+	Title     TitleConfig     `toml:"title"`
+	Crashes   CrashesConfig   `toml:"crashes"`
+	Names     NamesConfig     `toml:"names"`
+	Sensors   SensorsConfig   `toml:"sensors"`
+	Alerts    []AlertRule     `toml:"alerts"`
+	// Views are saved views shared through the config file, recalled with V.
+	Views []SavedView `toml:"views"`
+	// Webhooks receive the alerts as they fire and resolve.
+	Webhooks []Webhook `toml:"webhooks"`
+	// ExecPanels are panels showing the output of commands.
+	ExecPanels []ExecPanel `toml:"exec_panels"`
+
+	// profile is the [profile.<name>] table applied on top of the file, "" for none.
+	profile string
+}
+
+// MetersConfig is the [meters] section of the config file. Each header meter is drawn
+// as "bar", "graph" or "numeric".
+type MetersConfig struct {
+	CPU string `toml:"cpu"`
+	Mem string `toml:"mem"`
+	// ASCII fills the bars with whole "|" cells instead of Unicode partial blocks, for
+	// terminals and fonts without them; -ascii sets it for a session.
+	ASCII bool `toml:"ascii"`
+}
+
+// styles returns the meter styles in header order. Invalid names, which validation
+// has already replaced, fall back to the bar.
+func (c MetersConfig) styles() [meterCount]meterStyle {
+	var styles [meterCount]meterStyle
+	styles[meterCPU], _ = parseMeterStyle(c.CPU)
+	styles[meterMem], _ = parseMeterStyle(c.Mem)
+	return styles
+}
+
+// HistoryConfig is the [history] section of the config file, used with -history.
+type HistoryConfig struct {
+	// Retention is how long stored samples are kept, e.g. "168h" for a week.
+	Retention time.Duration `toml:"retention"`
+	// FlushInterval is how often queued samples are written in one transaction.
+	FlushInterval time.Duration `toml:"flush_interval"`
+	// TopProcesses stores the N most CPU-intensive processes with every sample; 0 stores none.
+	TopProcesses int `toml:"top_processes"`
+}
+
+// DiskConfig is the [disk] section of the config file.
+type DiskConfig struct {
+	// AwaitWarn highlights an average read or write latency above this value; 0 disables it.
+	AwaitWarn time.Duration `toml:"await_warn"`
+}
+
+// AuditConfig is the [audit] section of the config file.
+type AuditConfig struct {
+	// Panel shows the processes running deleted binaries or from temp directories (Linux
+	// only). It reads every process's executable on each refresh, so it is off by default.
+	Panel bool `toml:"panel"`
+}
+
+// MemoryConfig is the [memory] section of the config file.
+type MemoryConfig struct {
+	// DirtyWarnPercent highlights the dirty pages once they reach this percentage of RAM;
+	// 0 disables it.
+	DirtyWarnPercent float64 `toml:"dirty_warn_percent"`
+	// ExhaustionHorizon warns when available memory, shrinking at its recent rate, would
+	// run out within this time, e.g. "1h"; 0 disables the estimate.
+	ExhaustionHorizon time.Duration `toml:"exhaustion_horizon"`
+}
+
+// SocketsConfig is the [sockets] section of the config file.
+type SocketsConfig struct {
+	// CloseWaitWarn highlights the CLOSE_WAIT count once it exceeds this value; 0 disables it.
+	CloseWaitWarn int `toml:"close_wait_warn"`
+}
+
+// NetworkConfig is the [network] section of the config file.
+type NetworkConfig struct {
+	// RetransWarnPercent highlights the TCP retransmissions once they exceed this share of
+	// the sent segments; 0 disables it.
+	RetransWarnPercent float64 `toml:"retrans_warn_percent"`
+	// Namespaces lists the interfaces of the other network namespaces, those of containers
+	// and of ip netns, in the interfaces panel (Linux only). It stats every process's
+	// namespace on each refresh, so it is off by default.
+	Namespaces bool `toml:"namespaces"`
+	// FirewallCounters lists firewall counters to show in the network panel with their
+	// rates (Linux, as root): nftables counter objects by name or as "table/name", else
+	// iptables rules commented with the name or the policy of the chain of that name. A
+	// drop counter that moves tells whether the firewall eats the packets of a client that
+	// "can't connect sometimes".
+	FirewallCounters []string `toml:"firewall_counters"`
+}
+
+// LimitsConfig is the [limits] section of the config file.
+type LimitsConfig struct {
+	// WarnPercent highlights a kernel limit once its usage reaches this percentage; 0 disables it.
+	WarnPercent float64 `toml:"warn_percent"`
+}
+
+// HighlightConfig is the [highlight] section of the config file.
+type HighlightConfig struct {
+	// Names are the process names to watch. Their rows are drawn in the theme's watched
+	// color wherever they sort.
+	Names []string `toml:"names"`
+	// Panel shows a panel with the CPU and memory of each watched name, and the names
+	// with no process running.
+	Panel bool `toml:"panel"`
+}
+
+// ColumnsConfig is the [columns] section of the config file.
+type ColumnsConfig struct {
+	// Visible lists the IDs of the process table columns to show.
+	Visible []string `toml:"visible"`
+	// Container shows the Container column: "on", "off", or "auto" to show it only
+	// when a container runtime socket is found.
+	Container string `toml:"container"`
+	// Net shows the approximate per-process network traffic column (Linux only). Reading
+	// every process's file descriptors each tick is expensive, so it is off by default.
+	Net bool `toml:"net"`
+	// GPU shows the GPU-MEM column of the GPU memory each process holds: "on", "off", or
+	// "auto" to show it only when nvidia-smi is installed and finds a GPU.
+	GPU string `toml:"gpu"`
+	// Custom defines computed columns, shown before Command and sortable like the built-in
+	// ones, e.g. mem_per_thread = "rss / threads". Expressions hold numbers, + - * /,
+	// parentheses and the fields pid, ppid, cpu_percent, rss, mem_percent, cpu_time, age
+	// (both in seconds), threads, fds, nice, net_rx, net_tx, disk_read, disk_write,
+	// gpu_mem, run_delay and io_delay, with the units of the columns they come from. A
+	// row shows "-" when a field is unknown for it or the expression divides by zero.
+	// Outside Linux, threads is only read if a column used it at startup.
+	Custom map[string]string `toml:"custom"`
+}
+
+// ThemeConfig is the [theme] section of the config file. Each color is a hex value such
+// as "#8b2def" or an ANSI color number; empty keeps the built-in color.
+type ThemeConfig struct {
+	Primary   string `toml:"primary"`
+	Secondary string `toml:"secondary"`
+	Highlight string `toml:"highlight"`
+	Border    string `toml:"border"`
+	Green     string `toml:"green"`
+	Yellow    string `toml:"yellow"`
+	Red       string `toml:"red"`
+	Watched   string `toml:"watched"`
+	// WarningRow and CriticalRow tint the rows of the [rows] usage bands.
+	WarningRow  string `toml:"warning_row"`
+	CriticalRow string `toml:"critical_row"`
+}
+
+// TmpfsConfig is the [tmpfs] section of the config file.
+type TmpfsConfig struct {
+	// WarnFraction highlights a tmpfs mount whose files exceed this fraction of total RAM.
+	WarnFraction float64 `toml:"warn_fraction"`
+}
+
+// UnitsConfig is the [units] section of the config file.
+type UnitsConfig struct {
+	// System is "iec" (KiB, MiB) or "si" (kB, MB).
+	System string `toml:"system"`
+	// NetworkBits renders network rates in bits per second.
+	NetworkBits bool `toml:"network_bits"`
+	// DecimalSeparator is the character placed before the fractional digits.
+	DecimalSeparator string `toml:"decimal_separator"`
+	// ThousandsSeparator groups the digits of counts: "comma", "period", "space" or
+	// "none". Unset, it is a comma, or a period where the decimal separator is a comma.
+	ThousandsSeparator string `toml:"thousands_separator"`
+}
+
+// thousandsSeparators maps the thousands_separator names to the separator they stand for.
+var thousandsSeparators = map[string]string{"comma": ",", "period": ".", "space": " ", "none": ""}
+
+// thousandsSeparator returns the separator thousands_separator selects.
+func (c UnitsConfig) thousandsSeparator() string {
+	if c.ThousandsSeparator == "" {
+		if c.DecimalSeparator == "," {
+			return "."
+		}
+		return ","
+	}
+	return thousandsSeparators[c.ThousandsSeparator]
+}
+
+// minInterval keeps a typo such as "1ms" from turning the monitor into a CPU hog.
+const minInterval = 100 * time.Millisecond
+
+// defaultConfig returns the configuration used when no config file exists.
+func defaultConfig() Config {
+	return Config{
+		Interval: time.Second,
+		SortHold: 2500 * time.Millisecond,
+		Units: UnitsConfig{
+			System:           "iec",
+			DecimalSeparator: ".",
+		},
+		Tmpfs: TmpfsConfig{
+			WarnFraction: 0.10,
+		},
+		Clipboard: ClipboardConfig{
+			Method: "auto",
+		},
+		Meters: MetersConfig{
+			CPU: "bar",
+			Mem: "bar",
+		},
+		History: HistoryConfig{
+			Retention:     7 * 24 * time.Hour,
+			FlushInterval: 10 * time.Second,
+		},
+		Statsd: StatsdConfig{
+			Prefix: "smtui",
+		},
+		Sockets: SocketsConfig{
+			CloseWaitWarn: 100,
+		},
+		Network: NetworkConfig{
+			RetransWarnPercent: 2,
+		},
+		Kill: KillConfig{
+			Protected: []string{"sshd"},
+		},
+		Metrics: MetricsConfig{
+			MaxSeries: 100,
+		},
+		Battery: BatteryConfig{
+			UPower: true,
+		},
+		Crashes: CrashesConfig{
+			Watch: true,
+		},
+		Names: NamesConfig{
+			Friendly: true,
+		},
+		Title: TitleConfig{
+			Mode:     "off",
+			Interval: 5 * time.Second,
+		},
+		Disk: DiskConfig{
+			AwaitWarn: 50 * time.Millisecond,
+		},
+		Memory: MemoryConfig{
+			DirtyWarnPercent:  10,
+			ExhaustionHorizon: time.Hour,
+		},
+		Rows: RowsConfig{
+			CPUWarning:  50,
+			CPUCritical: 90,
+			MemWarning:  10,
+			MemCritical: 25,
+		},
+		Limits: LimitsConfig{
+			WarnPercent: 90,
+		},
+		Anomalies: AnomaliesConfig{
+			LeakWindow:        30 * time.Minute,
+			LeakGrowthPercent: 20,
+			SpinWindow:        10 * time.Minute,
+			SpinPercent:       95,
+			ForkWindow:        time.Minute,
+			ForkRate:          10,
+		},
+		Columns: ColumnsConfig{
+			Visible:   append([]string{}, defaultColumnIDs...),
+			Container: "auto",
+			GPU:       "auto",
+		},
+	}
+}
+
+// defaultConfigPath returns the config file location under the user's config directory.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, appName, "config.toml")
+}
+
+// LoadConfig reads the config file and the selected profile on top of the defaults.
+// A missing file is not an error; the defaults are returned as-is.
+func LoadConfig(src configSource) (Config, error) {
+	return loadConfig(src, defaultConfig())
+}
+
+// loadConfig reads the config file and the selected profile on top of the defaults. Keys
+// that fail validation take their value from fallback and are listed in the returned
+// error; a file that cannot be parsed at all, or lacks the profile asked for, returns
+// fallback unchanged.
+func loadConfig(src configSource, fallback Config) (Config, error) {
+	file := configFile{Config: defaultConfig()}
+	if src.path == "" {
+		if src.profile != "" {
+			return fallback, fmt.Errorf("%w %q: no config file", errUnknownProfile, src.profile)
+		}
+		return file.Config, nil
+	}
+
+	md, err := toml.DecodeFile(src.path, &file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if src.profile != "" {
+				return fallback, fmt.Errorf("%w %q: %s does not exist", errUnknownProfile, src.profile, src.path)
+			}
+			return file.Config, nil
+		}
+		return fallback, fmt.Errorf("could not read config %s: %w", src.path, err)
+	}
+	cfg := file.Config
+	profile, err := file.applyProfile(md, &cfg, src.profile)
+	if err != nil {
+		return fallback, fmt.Errorf("could not read config %s: %w", src.path, err)
+	}
+	cfg.profile = profile
+
+	if err := cfg.sanitize(fallback); err != nil {
+		return cfg, fmt.Errorf("%w %s: %w", errInvalidKeys, src.path, err)
+	}
+	return cfg, nil
+}
+
+// errInvalidKeys is returned when keys of the config file failed validation and took
+// their fallback values; the rest of the file applies.
+var errInvalidKeys = errors.New("invalid config")
+
+// configCheck validates a single config key. restore copies the key from another
+// config, so an invalid value can be replaced without touching the rest of the file.
+type configCheck struct {
+	key     string
+	check   func(c Config) error
+	restore func(dst *Config, src Config)
+}
+
+// configChecks cover the values that cannot be expressed by the TOML types alone.
+var configChecks = []configCheck{
+	{
+		key: "interval",
+		check: func(c Config) error {
+			if c.Interval < minInterval {
+				return fmt.Errorf("must be at least %s, got %s", minInterval, c.Interval)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Interval = src.Interval },
+	},
+	{
+		key:     "sort_hold",
+		check:   func(c Config) error { return checkSortHold(c.SortHold) },
+		restore: func(dst *Config, src Config) { dst.SortHold = src.SortHold },
+	},
+	{
+		key: "units.system",
+		check: func(c Config) error {
+			switch c.Units.System {
+			case "iec", "si":
+				return nil
+			}
+			return fmt.Errorf("must be \"iec\" or \"si\", got %q", c.Units.System)
+		},
+		restore: func(dst *Config, src Config) { dst.Units.System = src.Units.System },
+	},
+	{
+		key: "units.decimal_separator",
+		check: func(c Config) error {
+			if c.Units.DecimalSeparator == "" {
+				return errors.New("must not be empty")
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Units.DecimalSeparator = src.Units.DecimalSeparator },
+	},
+	{
+		key: "units.thousands_separator",
+		check: func(c Config) error {
+			if _, ok := thousandsSeparators[c.Units.ThousandsSeparator]; !ok && c.Units.ThousandsSeparator != "" {
+				return fmt.Errorf("must be \"comma\", \"period\", \"space\" or \"none\", got %q", c.Units.ThousandsSeparator)
+			}
+			if sep := c.Units.thousandsSeparator(); sep != "" && sep == c.Units.DecimalSeparator {
+				return fmt.Errorf("must differ from the decimal separator %q", c.Units.DecimalSeparator)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Units.ThousandsSeparator = src.Units.ThousandsSeparator },
+	},
+	{
+		key: "tmpfs.warn_fraction",
+		check: func(c Config) error {
+			if c.Tmpfs.WarnFraction < 0 || c.Tmpfs.WarnFraction > 1 {
+				return fmt.Errorf("must be between 0 and 1, got %v", c.Tmpfs.WarnFraction)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Tmpfs.WarnFraction = src.Tmpfs.WarnFraction },
+	},
+	{
+		key: "sockets.close_wait_warn",
+		check: func(c Config) error {
+			if c.Sockets.CloseWaitWarn < 0 {
+				return fmt.Errorf("must not be negative, got %d", c.Sockets.CloseWaitWarn)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Sockets.CloseWaitWarn = src.Sockets.CloseWaitWarn },
+	},
+	{
+		key: "memory.dirty_warn_percent",
+		check: func(c Config) error {
+			if c.Memory.DirtyWarnPercent < 0 || c.Memory.DirtyWarnPercent > 100 {
+				return fmt.Errorf("must be between 0 and 100, got %v", c.Memory.DirtyWarnPercent)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Memory.DirtyWarnPercent = src.Memory.DirtyWarnPercent },
+	},
+	{
+		key: "memory.exhaustion_horizon",
+		check: func(c Config) error {
+			if h := c.Memory.ExhaustionHorizon; h != 0 && (h < memTrendMinSpan || h > maxExhaustionHorizon) {
+				return fmt.Errorf("must be 0 or between %s and %s, got %s", memTrendMinSpan, maxExhaustionHorizon, h)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Memory.ExhaustionHorizon = src.Memory.ExhaustionHorizon },
+	},
+	{
+		key: "rows",
+		check: func(c Config) error {
+			r := c.Rows
+			if r.CPUWarning < 0 || r.MemWarning < 0 {
+				return errors.New("thresholds must not be negative")
+			}
+			if r.CPUWarning > r.CPUCritical || r.MemWarning > r.MemCritical {
+				return fmt.Errorf("warning thresholds must not exceed the critical ones, got cpu %v/%v and mem %v/%v", r.CPUWarning, r.CPUCritical, r.MemWarning, r.MemCritical)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) {
+			tint := dst.Rows.Tint
+			dst.Rows = src.Rows
+			dst.Rows.Tint = tint
+		},
+	},
+	{
+		key: "network.retrans_warn_percent",
+		check: func(c Config) error {
+			if c.Network.RetransWarnPercent < 0 || c.Network.RetransWarnPercent > 100 {
+				return fmt.Errorf("must be between 0 and 100, got %v", c.Network.RetransWarnPercent)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Network.RetransWarnPercent = src.Network.RetransWarnPercent },
+	},
+	{
+		key: "sensors.power_command",
+		check: func(c Config) error {
+			if len(c.Sensors.PowerCommand) > 0 && c.Sensors.PowerCommand[0] == "" {
+				return errors.New("the program to run must not be empty")
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Sensors.PowerCommand = src.Sensors.PowerCommand },
+	},
+	{
+		key:     "kill.protected",
+		check:   func(c Config) error { return checkKill(c.Kill) },
+		restore: func(dst *Config, src Config) { dst.Kill = src.Kill },
+	},
+	{
+		key:     "metrics",
+		check:   func(c Config) error { return checkMetrics(c.Metrics) },
+		restore: func(dst *Config, src Config) { dst.Metrics = src.Metrics },
+	},
+	{
+		key:     "battery",
+		check:   func(c Config) error { return checkBattery(c.Battery) },
+		restore: func(dst *Config, src Config) { dst.Battery = src.Battery },
+	},
+	{
+		key:     "title",
+		check:   func(c Config) error { return checkTitle(c.Title) },
+		restore: func(dst *Config, src Config) { dst.Title = src.Title },
+	},
+	{
+		key: "limits.warn_percent",
+		check: func(c Config) error {
+			if c.Limits.WarnPercent < 0 || c.Limits.WarnPercent > 100 {
+				return fmt.Errorf("must be between 0 and 100, got %v", c.Limits.WarnPercent)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Limits.WarnPercent = src.Limits.WarnPercent },
+	},
+	{
+		key: "disk.await_warn",
+		check: func(c Config) error {
+			if c.Disk.AwaitWarn < 0 {
+				return fmt.Errorf("must not be negative, got %s", c.Disk.AwaitWarn)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Disk.AwaitWarn = src.Disk.AwaitWarn },
+	},
+	{
+		key: "history.retention",
+		check: func(c Config) error {
+			if c.History.Retention < time.Hour {
+				return fmt.Errorf("must be at least 1h, got %s", c.History.Retention)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.History.Retention = src.History.Retention },
+	},
+	{
+		key: "history.flush_interval",
+		check: func(c Config) error {
+			if c.History.FlushInterval < time.Second {
+				return fmt.Errorf("must be at least 1s, got %s", c.History.FlushInterval)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.History.FlushInterval = src.History.FlushInterval },
+	},
+	{
+		key: "history.top_processes",
+		check: func(c Config) error {
+			if c.History.TopProcesses < 0 {
+				return fmt.Errorf("must not be negative, got %d", c.History.TopProcesses)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.History.TopProcesses = src.History.TopProcesses },
+	},
+	{
+		key: "columns.visible",
+		check: func(c Config) error {
+			for _, id := range c.Columns.Visible {
+				if _, ok := columnByID(id); !ok {
+					return fmt.Errorf("unknown column %q", id)
+				}
+			}
+			// The actions, the selection and the highlighting find a row's process by
+			// its PID, which is always the first column.
+			if !slices.Contains(c.Columns.Visible, columnPID) {
+				return errors.New("must include pid")
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Columns.Visible = src.Columns.Visible },
+	},
+	{
+		key: "columns.custom",
+		check: func(c Config) error {
+			_, _, err := c.Columns.compileCustomColumns()
+			return err
+		},
+		restore: func(dst *Config, src Config) { dst.Columns.Custom = src.Columns.Custom },
+	},
+	{
+		key: "columns.container",
+		check: func(c Config) error {
+			switch c.Columns.Container {
+			case "auto", "on", "off":
+				return nil
+			}
+			return fmt.Errorf("must be \"auto\", \"on\" or \"off\", got %q", c.Columns.Container)
+		},
+		restore: func(dst *Config, src Config) { dst.Columns.Container = src.Columns.Container },
+	},
+	{
+		key: "columns.gpu",
+		check: func(c Config) error {
+			switch c.Columns.GPU {
+			case "auto", "on", "off":
+				return nil
+			}
+			return fmt.Errorf("must be \"auto\", \"on\" or \"off\", got %q", c.Columns.GPU)
+		},
+		restore: func(dst *Config, src Config) { dst.Columns.GPU = src.Columns.GPU },
+	},
+	{
+		key: "meters.cpu",
+		check: func(c Config) error {
+			_, err := parseMeterStyle(c.Meters.CPU)
+			return err
+		},
+		restore: func(dst *Config, src Config) { dst.Meters.CPU = src.Meters.CPU },
+	},
+	{
+		key: "meters.mem",
+		check: func(c Config) error {
+			_, err := parseMeterStyle(c.Meters.Mem)
+			return err
+		},
+		restore: func(dst *Config, src Config) { dst.Meters.Mem = src.Meters.Mem },
+	},
+	{
+		key: "clipboard.method",
+		check: func(c Config) error {
+			switch c.Clipboard.Method {
+			case "auto", "osc52", "command":
+				return nil
+			}
+			return fmt.Errorf("must be \"auto\", \"osc52\" or \"command\", got %q", c.Clipboard.Method)
+		},
+		restore: func(dst *Config, src Config) { dst.Clipboard.Method = src.Clipboard.Method },
+	},
+	{
+		key: "alerts",
+		check: func(c Config) error {
+			for i, r := range c.Alerts {
+				if _, ok := alertMetrics[r.Metric]; !ok {
+					return fmt.Errorf("rule %d: unknown metric %q, want one of %s", i+1, r.Metric, alertMetricNames())
+				}
+				if r.Cooldown < 0 {
+					return fmt.Errorf("rule %d: cooldown must not be negative, got %s", i+1, r.Cooldown)
+				}
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Alerts = src.Alerts },
+	},
+	{
+		key:     "views",
+		check:   func(c Config) error { return checkViews(c.Views) },
+		restore: func(dst *Config, src Config) { dst.Views = src.Views },
+	},
+	{
+		key: "webhooks",
+		check: func(c Config) error {
+			for i, w := range c.Webhooks {
+				if err := checkWebhook(w); err != nil {
+					return fmt.Errorf("webhook %d: %w", i+1, err)
+				}
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.Webhooks = src.Webhooks },
+	},
+	{
+		key: "exec_panels",
+		check: func(c Config) error {
+			for i, p := range c.ExecPanels {
+				if err := checkExecPanel(p); err != nil {
+					return fmt.Errorf("panel %d: %w", i+1, err)
+				}
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { dst.ExecPanels = src.ExecPanels },
+	},
+	{
+		key:     "anomalies",
+		check:   func(c Config) error { return checkAnomalies(c.Anomalies) },
+		restore: func(dst *Config, src Config) { dst.Anomalies = src.Anomalies },
+	},
+	{
+		key:     "statsd",
+		check:   func(c Config) error { return checkStatsd(c.Statsd) },
+		restore: func(dst *Config, src Config) { dst.Statsd = src.Statsd },
+	},
+	themeCheck("primary", func(t *ThemeConfig) *string { return &t.Primary }),
+	themeCheck("secondary", func(t *ThemeConfig) *string { return &t.Secondary }),
+	themeCheck("highlight", func(t *ThemeConfig) *string { return &t.Highlight }),
+	themeCheck("border", func(t *ThemeConfig) *string { return &t.Border }),
+	themeCheck("green", func(t *ThemeConfig) *string { return &t.Green }),
+	themeCheck("yellow", func(t *ThemeConfig) *string { return &t.Yellow }),
+	themeCheck("red", func(t *ThemeConfig) *string { return &t.Red }),
+	themeCheck("watched", func(t *ThemeConfig) *string { return &t.Watched }),
+	themeCheck("warning_row", func(t *ThemeConfig) *string { return &t.WarningRow }),
+	themeCheck("critical_row", func(t *ThemeConfig) *string { return &t.CriticalRow }),
+}
+
+// themeCheck validates the [theme] color selected by field.
+func themeCheck(name string, field func(t *ThemeConfig) *string) configCheck {
+	return configCheck{
+		key: "theme." + name,
+		check: func(c Config) error {
+			if v := *field(&c.Theme); v != "" && !validColor(v) {
+				return fmt.Errorf("must be a hex color or ANSI color number, got %q", v)
+			}
+			return nil
+		},
+		restore: func(dst *Config, src Config) { *field(&dst.Theme) = *field(&src.Theme) },
+	}
+}
+
+// sanitize replaces every invalid key with its value from fallback and returns the
+// validation errors, or nil when all keys are valid.
+func (c *Config) sanitize(fallback Config) error {
+	var errs []error
+	for _, check := range configChecks {
+		if err := check.check(*c); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", check.key, err))
+			check.restore(c, fallback)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validColor reports whether s is a color lipgloss understands: "#rgb", "#rrggbb" or an
+// ANSI color number from 0 to 255.
+func validColor(s string) bool {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// theme applies the configured colors on top of the built-in theme.
+func (t ThemeConfig) theme() Theme {
+	theme := defaultTheme
+	for _, c := range []struct {
+		value string
+		color *lipgloss.AdaptiveColor
+	}{
+		{t.Primary, &theme.Primary},
+		{t.Secondary, &theme.Secondary},
+		{t.Highlight, &theme.Highlight},
+		{t.Border, &theme.Border},
+		{t.Green, &theme.Green},
+		{t.Yellow, &theme.Yellow},
+		{t.Red, &theme.Red},
+		{t.Watched, &theme.Watched},
+		{t.WarningRow, &theme.WarningRow},
+		{t.CriticalRow, &theme.CriticalRow},
+	} {
+		if c.value != "" {
+			*c.color = lipgloss.AdaptiveColor{Light: c.value, Dark: c.value}
+		}
+	}
+	return theme
+}
+
+// showContainers reports whether the Container column is enabled, probing for a
+// container runtime when set to "auto".
+func (c ColumnsConfig) showContainers() bool {
+	switch c.Container {
+	case "on":
+		return true
+	case "auto":
+		return containerRuntimeDetected()
+	default:
+		return false
+	}
+}
+
+// showGPU reports whether the GPU-MEM column is collected, following columns.gpu.
+func (c ColumnsConfig) showGPU() bool {
+	switch c.GPU {
+	case "on":
+		return true
+	case "auto":
+		return gpuDetected()
+	default:
+		return false
+	}
+}
+
+// processOptions returns the optional per-process data to collect. All of it is expensive,
+// so it is only gathered when its column is switched on.
+func (c ColumnsConfig) processOptions() processOptions {
+	return processOptions{
+		containers: c.showContainers(),
+		net:        c.Net && procNetSupported,
+		gpu:        c.showGPU(),
+		units:      systemdDetected(),
+		delays:     delaySupported && (slices.Contains(c.Visible, columnRunDelay) || slices.Contains(c.Visible, columnIODelay)),
+		memLimits:  memLimitSupported && slices.Contains(c.Visible, columnMemLimit),
+		ctxSwitch:  ctxSwitchSupported && slices.Contains(c.Visible, columnCtxSwitch),
+		threads:    c.customUses("threads"),
+	}
+}
+
+// customUses reports whether a custom column uses field.
+func (c ColumnsConfig) customUses(field string) bool {
+	_, uses, _ := c.compileCustomColumns()
+	return uses[field]
+}
+
+// columnIDs returns the visible column IDs, with the optional columns added when their
+// data is collected.
+func (c ColumnsConfig) columnIDs(opts processOptions) []string {
+	ids := slices.DeleteFunc(slices.Clone(c.Visible), func(id string) bool {
+		return id == columnContainer || id == columnNet || id == columnGPUMem || !opts.collects(id)
+	})
+	if opts.net {
+		ids = append(ids, columnNet)
+	}
+	if opts.gpu {
+		ids = append(ids, columnGPUMem)
+	}
+	if opts.containers {
+		ids = append(ids, columnContainer)
+	}
+	for name := range c.Custom {
+		ids = append(ids, name)
+	}
+	return ids
+}
+
+// collects reports whether the data of the column id is collected with opts.
+func (opts processOptions) collects(id string) bool {
+	switch id {
+	case columnContainer:
+		return opts.containers
+	case columnNet:
+		return opts.net
+	case columnGPUMem:
+		return opts.gpu
+	case columnRunDelay, columnIODelay:
+		return opts.delays
+	case columnMemLimit:
+		return opts.memLimits
+	case columnCtxSwitch:
+		return opts.ctxSwitch && !opts.lite
+	case columnFDs:
+		return !opts.lite
+	case columnUnit:
+		return opts.units
+	}
+	return true
+}
+
+// unitPrefs converts the [units] section into the formatting preferences.
+func (c UnitsConfig) unitPrefs() UnitPrefs {
+	return UnitPrefs{
+		SI:                 c.System == "si",
+		Bits:               c.NetworkBits,
+		DecimalSeparator:   c.DecimalSeparator,
+		ThousandsSeparator: c.thousandsSeparator(),
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file of content and returns where.
+func writeConfig(t *testing.T, content string) configSource {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return configSource{path: path}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, "interval = \"1ms\"\nconfirm_quit = true\n"))
+	if !errors.Is(err, errInvalidKeys) || !strings.Contains(err.Error(), "interval") {
+		t.Errorf("invalid interval: err = %v, want errInvalidKeys naming it", err)
+	}
+	if cfg.Interval != defaultConfig().Interval || !cfg.ConfirmQuit {
+		t.Errorf("invalid interval: got interval %s, confirm_quit %v; want the default and the rest of the file", cfg.Interval, cfg.ConfirmQuit)
+	}
+
+	cfg, err = LoadConfig(writeConfig(t, "[columns]\nvisible = [\"name\", \"cpu\"]\n"))
+	if !errors.Is(err, errInvalidKeys) || !strings.Contains(err.Error(), "columns.visible must include pid") {
+		t.Errorf("columns without pid: err = %v", err)
+	}
+	if !slices.Equal(cfg.Columns.Visible, defaultConfig().Columns.Visible) {
+		t.Errorf("columns without pid: got %v, want the default columns", cfg.Columns.Visible)
+	}
+
+	if _, err := LoadConfig(writeConfig(t, "interval = \n")); err == nil || errors.Is(err, errInvalidKeys) {
+		t.Errorf("unparseable file: err = %v, want a read error", err)
+	}
+	src := writeConfig(t, "interval = \"2s\"\n")
+	src.profile = "server"
+	if _, err := LoadConfig(src); !errors.Is(err, errUnknownProfile) {
+		t.Errorf("unknown profile: err = %v, want errUnknownProfile", err)
+	}
+}
+
+func TestStartupConfigBanner(t *testing.T) {
+	withUnits(t, Units)
+	withNames(t, Names)
+	cfg, err := loadMonitorConfig(writeConfig(t, "interval = \"1ms\"\n"))
+	m := newModel(cfg)
+	m.reportConfigError(err, "used the defaults")
+	if !m.bannerIsErr || !strings.HasPrefix(m.banner, "invalid config ") || !strings.Contains(m.banner, "interval must be at least") ||
+		!strings.HasSuffix(m.banner, "; used the defaults") || strings.Contains(m.banner, "\n") {
+		t.Errorf("banner = %q", m.banner)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	gnet "github.com/shirou/gopsutil/v4/net"
+)
+
+// connStateFilters are the state filters cycled with s; "" shows every connection.
+var connStateFilters = append([]string{""}, socketStates...)
+
+func newConnTable(styles table.Styles) table.Model {
+	return table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Local", Width: 28},
+			{Title: "Remote", Width: 40},
+			{Title: "State", Width: 12},
+			{Title: "PID", Width: 8},
+			{Title: "Process", Width: 20},
+		}),
+		table.WithRows([]table.Row{}),
+		table.WithFocused(true),
+		table.WithHeight(20),
+		table.WithStyles(styles),
+	)
+}
+
+// formatAddr renders an address as host:port, with IPv6 hosts in brackets.
+func formatAddr(host string, port uint32) string {
+	if host == "" {
+		return "*"
+	}
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
+
+// remoteHost returns the remote host of c as shown in the table: its resolved name once
+// the lookup returned and DNS is on, the address otherwise.
+func (m model) remoteHost(c gnet.ConnectionStat) string {
+	if m.resolveDNS && c.Raddr.IP != "" {
+		if name := m.dns.name(c.Raddr.IP); name != "" {
+			return name
+		}
+	}
+	return c.Raddr.IP
+}
+
+// refreshConnections rebuilds the connections table from the latest socket list, applying
+// the state filter and the search across addresses, names and process names.
+func (m *model) refreshConnections() {
+	names := make(map[int32]string, len(m.data.Procs))
+	for _, p := range m.data.Procs {
+		names[p.PID] = p.Name
+	}
+
+	search := strings.ToLower(m.connSearch)
+	rows := make([]table.Row, 0, len(m.data.Sockets.Conns))
+	for _, c := range m.data.Sockets.Conns {
+		if m.connState != "" && c.Status != m.connState {
+			continue
+		}
+		process := "-"
+		if name, ok := names[c.Pid]; ok {
+			process = name
+		}
+		pid := "-"
+		if c.Pid > 0 {
+			pid = strconv.Itoa(int(c.Pid))
+		}
+		row := table.Row{
+			formatAddr(c.Laddr.IP, c.Laddr.Port),
+			formatAddr(m.remoteHost(c), c.Raddr.Port),
+			c.Status,
+			pid,
+			process,
+		}
+		if search != "" && !strings.Contains(strings.ToLower(strings.Join(row, " ")+" "+c.Raddr.IP), search) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	m.connTable.SetRows(rows)
+}
+
+// dnsLookupsCmd starts reverse lookups for the remote addresses in view while DNS is on.
+func (m model) dnsLookupsCmd() tea.Cmd {
+	if !m.resolveDNS || m.view != viewConnections {
+		return nil
+	}
+	var ips []string
+	for _, c := range m.data.Sockets.Conns {
+		if c.Raddr.IP != "" && !net.ParseIP(c.Raddr.IP).IsUnspecified() {
+			ips = append(ips, c.Raddr.IP)
+		}
+	}
+	return m.dns.lookups(ips, time.Now())
+}
+
+// updateConnSearch handles typing into the connections search: the table filters as you
+// type, enter keeps the search and esc clears it.
+func (m model) updateConnSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m.interrupt()
+	}
+	m.connSearch, m.connSearching = editSearch(m.connSearch, msg)
+	m.connTable.GotoTop()
+	m.refreshConnections()
+	return m, nil
+}
+
+// viewConnectionsFooter describes the active filters of the connections view.
+func (m model) viewConnectionsFooter() string {
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	if m.connSearching {
+		return m.baseStyle.Foreground(Color.Highlight).Render("/"+m.connSearch+"▏") + hint(" · enter: keep · esc: clear")
+	}
+	state := m.connState
+	if state == "" {
+		state = "all"
+	}
+	dns := "off"
+	if m.resolveDNS {
+		dns = "on"
+	}
+	status := fmt.Sprintf("%s connections · state: %s", formatInt(len(m.connTable.Rows())), state)
+	if m.connSearch != "" {
+		status += fmt.Sprintf(" · search: %q", m.connSearch)
+	}
+	return hint(fmt.Sprintf("%s · /: search · s: state · d: DNS (%s) · esc: back", status, dns))
+}
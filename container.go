@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// containerIDPattern matches the 64 hex digit container IDs that Docker, containerd, CRI-O
+// and Podman embed in cgroup paths, e.g. /docker/<id> or /kubepods.slice/.../cri-containerd-<id>.scope.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// containerSockets are the runtime API sockets whose presence means containers may be running.
+var containerSockets = []string{
+	"/var/run/docker.sock",
+	"/run/containerd/containerd.sock",
+	"/run/crio/crio.sock",
+	"/run/podman/podman.sock",
+}
+
+// dockerSocket is queried to turn container IDs into names.
+const dockerSocket = "/var/run/docker.sock"
+
+// containerRuntimeDetected reports whether a container runtime socket exists on this host.
+func containerRuntimeDetected() bool {
+	for _, path := range containerSockets {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// containerIDFromCgroup extracts the container ID from the contents of /proc/<pid>/cgroup,
+// or returns "" for processes running on the host.
+func containerIDFromCgroup(cgroup string) string {
+	scanner := bufio.NewScanner(strings.NewReader(cgroup))
+	for scanner.Scan() {
+		// Lines look like "hierarchy-ID:controllers:path"; only the path carries the ID.
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if id := containerIDPattern.FindString(parts[2]); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// containerEntry is the cached container of one process.
+type containerEntry struct {
+	createTime int64
+	label      string
+}
+
+// containerResolver maps processes to the container they run in. Results are cached per
+// PID, so /proc/<pid>/cgroup is read once per process rather than on every tick, and
+// container names are cached per ID. Like ProcessCollector it is not safe for concurrent use.
+type containerResolver struct {
+	byPID  map[int32]containerEntry
+	names  map[string]string
+	client *http.Client
+}
+
+func newContainerResolver() *containerResolver {
+	return &containerResolver{
+		byPID: map[int32]containerEntry{},
+		names: map[string]string{},
+		client: &http.Client{
+			Timeout: 500 * time.Millisecond,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", dockerSocket)
+				},
+			},
+		},
+	}
+}
+
+// lookup returns the container name (or short ID) of a process, or "" on the host.
+func (r *containerResolver) lookup(pid int32, createTime int64) string {
+	if e, ok := r.byPID[pid]; ok && e.createTime == createTime {
+		return e.label
+	}
+
+	label := ""
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup"); err == nil {
+		if id := containerIDFromCgroup(string(data)); id != "" {
+			label = r.name(id)
+		}
+	}
+	r.byPID[pid] = containerEntry{createTime: createTime, label: label}
+	return label
+}
+
+// name resolves a container ID to its name via the Docker API, falling back to the short
+// ID when Docker is not reachable or doesn't know the container.
+func (r *containerResolver) name(id string) string {
+	if name, ok := r.names[id]; ok {
+		return name
+	}
+	name := id[:12]
+	if resp, err := r.client.Get("http://docker/containers/" + id + "/json"); err == nil {
+		var info struct {
+			Name string `json:"Name"`
+		}
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&info) == nil && info.Name != "" {
+			name = strings.TrimPrefix(info.Name, "/")
+		}
+		resp.Body.Close()
+	}
+	r.names[id] = name
+	return name
+}
+
+// prune forgets processes that no longer exist.
+func (r *containerResolver) prune(alive map[int32]cpuSample) {
+	for pid := range r.byPID {
+		if _, ok := alive[pid]; !ok {
+			delete(r.byPID, pid)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// crashReporter keeps the stack of a panic in a crash file. Bubble Tea restores the terminal
+// when Update, View or a command panics, but the trace it prints goes to the alternate
+// screen and is lost, so without the file a crash leaves nothing to report.
+type crashReporter struct {
+	dir string
+
+	mu sync.Mutex
+	// program is killed when a goroutine outside Bubble Tea panics; set once it exists.
+	program *tea.Program
+	value   any
+	path    string
+	err     error
+}
+
+// newCrashReporter writes crash files next to the state file.
+func newCrashReporter(statePath string) *crashReporter {
+	return &crashReporter{dir: filepath.Dir(statePath)}
+}
+
+func (c *crashReporter) setProgram(p *tea.Program) {
+	c.mu.Lock()
+	c.program = p
+	c.mu.Unlock()
+}
+
+// capture is deferred by code run by Bubble Tea: Update, View and commands. It records the
+// panic and panics again, so Bubble Tea still shuts down and restores the terminal.
+func (c *crashReporter) capture() {
+	if c == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		c.record(r, debug.Stack())
+		panic(r)
+	}
+}
+
+// guard is deferred by goroutines Bubble Tea doesn't know about, whose panic would end the
+// process with the terminal still in the alternate screen. It records the panic and kills
+// the program instead, which restores the terminal; rethrow then panics from main.
+func (c *crashReporter) guard() {
+	if c == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		c.record(r, debug.Stack())
+		c.mu.Lock()
+		p := c.program
+		c.mu.Unlock()
+		if p == nil {
+			panic(r)
+		}
+		p.Kill()
+		// The process ends with rethrow in main; this goroutine must not end it first.
+		select {}
+	}
+}
+
+// record writes the first panic to a crash file. Later panics are usually consequences of
+// the first one and are ignored.
+func (c *crashReporter) record(r any, stack []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.value != nil {
+		return
+	}
+	c.value = r
+	now := time.Now()
+	c.path = filepath.Join(c.dir, "crash-"+now.Format("20060102-150405")+".txt")
+	report := fmt.Sprintf("%s crashed at %s\n%s %s/%s\n\npanic: %v\n\n%s",
+		appName, now.Format(time.RFC3339), runtime.Version(), runtime.GOOS, runtime.GOARCH, r, stack)
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		c.err = err
+		return
+	}
+	c.err = os.WriteFile(c.path, []byte(report), 0o644)
+}
+
+// rethrow is called after the program returned and the terminal is restored. When a panic
+// was recorded it tells the user where the crash file is and panics again with the
+// original value, so the process still ends like an unrecovered panic.
+func (c *crashReporter) rethrow() {
+	c.mu.Lock()
+	value, path, err := c.value, c.path, c.err
+	c.mu.Unlock()
+	if value == nil {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s crashed; could not write the crash report: %v\n", appName, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s crashed; please attach %s to a bug report\n", appName, path)
+	}
+	panic(value)
+}
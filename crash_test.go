@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// panicCollector panics in the middle of a collection pass.
+func panicCollector(s *Snapshot) error {
+	panic("collector exploded")
+}
+
+func TestCollectCmdPanic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	m := newModel(defaultConfig())
+	m.crash = newCrashReporter(filepath.Join(dir, "state.json"))
+	m.collectors = []Collector{collectorFunc{"boom", panicCollector}}
+
+	func() {
+		defer func() {
+			// capture panics again so Bubble Tea still shuts down.
+			if r := recover(); r != "collector exploded" {
+				t.Errorf("recovered %v, want the collector's panic", r)
+			}
+		}()
+		m.collectCmd()()
+		t.Error("collectCmd returned")
+	}()
+
+	files, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("crash files = %v, %v, want one", files, err)
+	}
+	if m.crash.path != files[0] || m.crash.err != nil {
+		t.Errorf("reporter has %q, %v, want %q", m.crash.path, m.crash.err, files[0])
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{
+		appName + " crashed at ",
+		"panic: collector exploded",
+		// The stack leads to the collector that panicked, through the pass that ran it.
+		".panicCollector(",
+		".runCollectors(",
+		"crash_test.go:",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("crash report lacks %q:\n%s", want, report)
+		}
+	}
+
+	// Only the first panic is kept.
+	m.crash.record("later", nil)
+	if m.crash.value != "collector exploded" {
+		t.Errorf("value = %v after a second panic", m.crash.value)
+	}
+}
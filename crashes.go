@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// CrashesConfig is the [crashes] section of the config file.
+type CrashesConfig struct {
+	// Watch reports the processes that crash during the session in the banner and the
+	// crashes panel. On Linux the core dumps come from systemd-coredump's journal entries,
+	// or from the directory kernel.core_pattern writes core files to; elsewhere, and where
+	// neither can be read, only the watched and pinned processes that exit are reported.
+	Watch bool `toml:"watch"`
+}
+
+// crashEvent is one crash seen during the session.
+type crashEvent struct {
+	name string
+	pid  int32
+	// cause is how the process ended, e.g. "SIGSEGV, core dumped".
+	cause string
+	at    time.Time
+}
+
+// line renders the event, e.g. "14:03:12 worker (PID 4121) SIGSEGV, core dumped".
+func (e crashEvent) line() string {
+	return fmt.Sprintf("%s %s (PID %d) %s", e.at.Format(time.TimeOnly), e.name, e.pid, e.cause)
+}
+
+// crashMsg carries a crash found by the crash watcher between refreshes.
+type crashMsg struct {
+	event crashEvent
+}
+
+// maxCrashes bounds the crashes kept for the panel; crashPanelLines are shown.
+const (
+	maxCrashes      = 50
+	crashPanelLines = 5
+)
+
+// crashDedupWindow is how close two reports of the same PID must be to count as one
+// crash, as when the run subcommand's command dumps core and the journal tells too.
+const crashDedupWindow = 10 * time.Second
+
+// recordCrash adds a crash to the session's list and shows it in the banner.
+func (m *model) recordCrash(e crashEvent) {
+	if m.addCrash(e) {
+		m.reportError(errors.New("crash: " + e.line()))
+	}
+}
+
+// addCrash adds a crash to the session's list, reporting false when it was already there.
+func (m *model) addCrash(e crashEvent) bool {
+	if slices.ContainsFunc(m.crashes, func(c crashEvent) bool {
+		return c.pid == e.pid && e.at.Sub(c.at).Abs() < crashDedupWindow
+	}) {
+		return false
+	}
+	m.crashes = append(m.crashes, e)
+	if len(m.crashes) > maxCrashes {
+		m.crashes = slices.Delete(m.crashes, 0, len(m.crashes)-maxCrashes)
+	}
+	return true
+}
+
+// crashesPanel lists the latest crashes of the session, newest first.
+func (m model) crashesPanel() panel {
+	p := panel{collector: "crashes", title: "Crashes", external: true}
+	for i := len(m.crashes) - 1; i >= 0 && len(p.lines) < crashPanelLines; i-- {
+		p.lines = append(p.lines, m.crashes[i].line())
+	}
+	if n := len(m.crashes) - crashPanelLines; n > 0 {
+		p.lines = append(p.lines, m.baseStyle.Foreground(Color.Secondary).Render(fmt.Sprintf("%s earlier", formatInt(n))))
+	}
+	return p
+}
+
+// exitWatch stands in for a core dump feed where there is none: it reports the watched
+// and pinned processes that exit. Their exit status can't be read, as they aren't
+// children of the monitor, so every exit is reported.
+type exitWatch struct {
+	live map[procKey]ProcessInfo
+}
+
+func newExitWatch() *exitWatch {
+	return &exitWatch{live: map[procKey]ProcessInfo{}}
+}
+
+// observe compares the watched and pinned processes among procs with the previous pass
+// and returns the ones that exited. skip is a process whose exit is reported elsewhere,
+// such as the command of the run subcommand.
+func (w *exitWatch) observe(procs []ProcessInfo, names []string, pinned *procKey, skip procKey, now time.Time) []crashEvent {
+	cur := map[procKey]ProcessInfo{}
+	for _, p := range procs {
+		key := procKey{p.PID, p.CreateTime}
+		if _, ok := watchedName(p, names); ok || pinned != nil && *pinned == key {
+			cur[key] = p
+		}
+	}
+	var exited []crashEvent
+	for key, p := range w.live {
+		if _, ok := cur[key]; ok || key == skip {
+			continue
+		}
+		// A process missing from the list may still run, e.g. in low-overhead mode, which
+		// only lists the busiest processes.
+		if running, err := process.PidExists(key.pid); err == nil && running {
+			cur[key] = p
+			continue
+		}
+		exited = append(exited, crashEvent{name: p.Name, pid: p.PID, cause: "exited, status unknown", at: now})
+	}
+	w.live = cur
+	return exited
+}
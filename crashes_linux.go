@@ -0,0 +1,270 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/sys/unix"
+)
+
+// crashWatchSupported reports whether core dumps are followed on this platform.
+const crashWatchSupported = true
+
+// corePatternFile tells where the kernel sends core dumps.
+const corePatternFile = "/proc/sys/kernel/core_pattern"
+
+// coredumpMessageID is the MESSAGE_ID of systemd-coredump's journal entries.
+const coredumpMessageID = "fc2e22bc6ee647b6b90729ab34a250b1"
+
+// coreDirPoll is the time between two listings of the core file directory.
+const coreDirPoll = 2 * time.Second
+
+// crashSource is where the crash watcher finds core dumps: systemd-coredump's journal
+// entries, or the core files the kernel writes to dir.
+type crashSource struct {
+	journal bool
+	dir     string
+	// files matches the names of core files, capturing what core_pattern puts in them.
+	files *regexp.Regexp
+}
+
+func (s crashSource) String() string {
+	if s.journal {
+		return "systemd-coredump journal"
+	}
+	return "core files in " + s.dir
+}
+
+// findCrashSource reads kernel.core_pattern for where core dumps go. Dumps piped to
+// another handler than systemd-coredump, such as apport, and core files written to the
+// crashing process's working directory can't be followed.
+func findCrashSource() (crashSource, error) {
+	data, err := os.ReadFile(corePatternFile)
+	if err != nil {
+		return crashSource{}, err
+	}
+	pattern := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(pattern, "|") && strings.Contains(pattern, "systemd-coredump"):
+		if _, err := exec.LookPath("journalctl"); err != nil {
+			return crashSource{}, errors.New("journalctl not found")
+		}
+		return crashSource{journal: true}, nil
+	case strings.HasPrefix(pattern, "|"):
+		return crashSource{}, fmt.Errorf("core dumps go to %s", strings.Fields(pattern[1:])[0])
+	case !strings.HasPrefix(pattern, "/"):
+		return crashSource{}, errors.New("core files are written to each process's working directory")
+	}
+	dir := filepath.Dir(pattern)
+	if strings.Contains(dir, "%") {
+		return crashSource{}, errors.New("core files are written to a directory per process")
+	}
+	if _, err := os.ReadDir(dir); err != nil {
+		return crashSource{}, err
+	}
+	return crashSource{dir: dir, files: coreFileRegexp(filepath.Base(pattern))}, nil
+}
+
+// coreFileRegexp turns the file name part of core_pattern into a regexp capturing the
+// PID (%p), command name (%e) and signal number (%s). Without %p the kernel appends
+// ".<pid>" when kernel.core_uses_pid is set.
+func coreFileRegexp(base string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	named := map[string]bool{}
+	group := func(name, expr string) {
+		if named[name] {
+			b.WriteString(expr)
+			return
+		}
+		named[name] = true
+		fmt.Fprintf(&b, "(?P<%s>%s)", name, expr)
+	}
+	for i := 0; i < len(base); i++ {
+		if base[i] != '%' || i+1 == len(base) {
+			b.WriteString(regexp.QuoteMeta(base[i : i+1]))
+			continue
+		}
+		i++
+		switch base[i] {
+		case 'p', 'P':
+			group("pid", `\d+`)
+		case 'e':
+			group("comm", `.+?`)
+		case 's':
+			group("sig", `\d+`)
+		case '%':
+			b.WriteString("%")
+		default:
+			b.WriteString(".*?")
+		}
+	}
+	if !named["pid"] {
+		b.WriteString(`(?:\.(?P<pid>\d+))?`)
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// crashWatcher follows the crash source for the session: a journalctl process for
+// systemd-coredump, or a poll of the core file directory.
+type crashWatcher struct {
+	src     crashSource
+	started time.Time
+
+	journal *exec.Cmd
+	out     io.ReadCloser
+
+	stop chan struct{}
+}
+
+// watchCrashes starts following src. Only crashes from now on are reported.
+func watchCrashes(src crashSource) (*crashWatcher, error) {
+	w := &crashWatcher{src: src, started: time.Now(), stop: make(chan struct{})}
+	if !src.journal {
+		return w, nil
+	}
+	// Only the fields shown are asked for: with Storage=journal the core dump itself is
+	// a field of the entry.
+	w.journal = exec.Command("journalctl", "--follow", "--lines=0", "--output=json",
+		"--output-fields=COREDUMP_COMM,COREDUMP_PID,COREDUMP_SIGNAL,COREDUMP_SIGNAL_NAME,COREDUMP_TIMESTAMP",
+		"MESSAGE_ID="+coredumpMessageID)
+	out, err := w.journal.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.journal.Start(); err != nil {
+		return nil, err
+	}
+	w.out = out
+	return w, nil
+}
+
+// run sends a crashMsg through send for every crash found, until close.
+func (w *crashWatcher) run(send func(tea.Msg)) {
+	if w.src.journal {
+		w.followJournal(send)
+		return
+	}
+	w.pollDir(send)
+}
+
+// followJournal reads journalctl's entries, one JSON object per line.
+func (w *crashWatcher) followJournal(send func(tea.Msg)) {
+	scanner := bufio.NewScanner(w.out)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		if e, ok := parseCoredumpEntry(scanner.Bytes()); ok {
+			send(crashMsg{event: e})
+		}
+	}
+}
+
+// parseCoredumpEntry reads a crash from a systemd-coredump journal entry. Fields holding
+// binary data come as arrays of bytes and are left out.
+func parseCoredumpEntry(line []byte) (crashEvent, bool) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return crashEvent{}, false
+	}
+	field := func(name string) string {
+		var s string
+		json.Unmarshal(raw[name], &s)
+		return s
+	}
+	pid, err := strconv.ParseInt(field("COREDUMP_PID"), 10, 32)
+	if err != nil {
+		return crashEvent{}, false
+	}
+	e := crashEvent{name: field("COREDUMP_COMM"), pid: int32(pid), at: time.Now()}
+	if us, err := strconv.ParseInt(field("COREDUMP_TIMESTAMP"), 10, 64); err == nil {
+		e.at = time.UnixMicro(us)
+	}
+	e.cause = field("COREDUMP_SIGNAL_NAME")
+	if e.cause == "" {
+		e.cause = signalName(field("COREDUMP_SIGNAL"))
+	}
+	e.cause += ", core dumped"
+	return e, true
+}
+
+// signalName names a signal number the way systemd-coredump does, e.g. "SIGSEGV" for "11".
+func signalName(num string) string {
+	n, err := strconv.Atoi(num)
+	if err != nil || unix.SignalName(syscall.Signal(n)) == "" {
+		return "signal " + num
+	}
+	return unix.SignalName(syscall.Signal(n))
+}
+
+// pollDir lists the core file directory every coreDirPoll and reports the files that
+// appeared since the watcher started.
+func (w *crashWatcher) pollDir(send func(tea.Msg)) {
+	seen := map[string]bool{}
+	ticker := time.NewTicker(coreDirPoll)
+	defer ticker.Stop()
+	for {
+		entries, _ := os.ReadDir(w.src.dir)
+		for _, entry := range entries {
+			name := entry.Name()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(w.started) {
+				continue
+			}
+			if e, ok := w.coreFileEvent(name, info.ModTime()); ok {
+				send(crashMsg{event: e})
+			}
+		}
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// coreFileEvent reads a crash from the name of a core file.
+func (w *crashWatcher) coreFileEvent(name string, at time.Time) (crashEvent, bool) {
+	m := w.src.files.FindStringSubmatch(name)
+	if m == nil {
+		return crashEvent{}, false
+	}
+	e := crashEvent{name: name, cause: "core dumped", at: at}
+	if i := w.src.files.SubexpIndex("comm"); i >= 0 && m[i] != "" {
+		e.name = m[i]
+	}
+	if pid, err := strconv.ParseInt(m[w.src.files.SubexpIndex("pid")], 10, 32); err == nil {
+		e.pid = int32(pid)
+	}
+	if i := w.src.files.SubexpIndex("sig"); i >= 0 && m[i] != "" {
+		e.cause = signalName(m[i]) + ", core dumped"
+	}
+	return e, true
+}
+
+// close stops following: journalctl is killed, which ends its output and so run.
+func (w *crashWatcher) close() {
+	close(w.stop)
+	if w.journal != nil {
+		w.journal.Process.Kill()
+		w.journal.Wait()
+	}
+}
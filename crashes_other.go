@@ -0,0 +1,31 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// crashWatchSupported reports whether core dumps are followed on this platform.
+const crashWatchSupported = false
+
+// crashSource is a stub; core dumps are only followed on Linux.
+type crashSource struct{}
+
+func (crashSource) String() string { return "" }
+
+func findCrashSource() (crashSource, error) {
+	return crashSource{}, errors.New("no core dump feed on this platform")
+}
+
+// crashWatcher is a stub; core dumps are only followed on Linux.
+type crashWatcher struct{}
+
+func watchCrashes(crashSource) (*crashWatcher, error) {
+	return nil, errors.New("core dumps are only followed on Linux")
+}
+
+func (w *crashWatcher) run(func(tea.Msg)) {}
+func (w *crashWatcher) close()            {}
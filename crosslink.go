@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gnet "github.com/shirou/gopsutil/v4/net"
+)
+
+// linkFilter narrows the process table to the processes linked to a device picked in the
+// disk or interfaces panel. The PIDs are found once, when the device is picked; processes
+// started afterwards don't show until it is picked again.
+type linkFilter struct {
+	// label names the device in the footer, e.g. "disk sda".
+	label string
+	pids  map[int32]bool
+}
+
+// linkFilterMsg carries the processes found for a device picked in a panel.
+type linkFilterMsg struct {
+	label string
+	pids  map[int32]bool
+	err   error
+}
+
+// diskLinkCmd finds the processes with files open on the filesystems of disk.
+func diskLinkCmd(disk string) tea.Cmd {
+	return func() tea.Msg {
+		return linkFilterMsg{label: "disk " + disk, pids: processesOnDisk(disk)}
+	}
+}
+
+// ifaceLinkCmd finds the processes with TCP or UDP sockets bound to one of the addresses
+// of an interface. Sockets bound to the wildcard address accept on every interface, so
+// they are left out; otherwise every server would match every interface.
+func ifaceLinkCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		label := "interface " + name
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			return linkFilterMsg{label: label, err: err}
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return linkFilterMsg{label: label, err: err}
+		}
+		var ips []net.IP
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok {
+				ips = append(ips, ipnet.IP)
+			}
+		}
+		conns, err := gnet.Connections("inet")
+		if err != nil {
+			return linkFilterMsg{label: label, err: err}
+		}
+		pids := map[int32]bool{}
+		for _, c := range conns {
+			// Link-local addresses come with their zone, e.g. "fe80::1%eth0".
+			host, _, _ := strings.Cut(c.Laddr.IP, "%")
+			local := net.ParseIP(host)
+			if c.Pid == 0 || local == nil {
+				continue
+			}
+			for _, ip := range ips {
+				if ip.Equal(local) {
+					pids[c.Pid] = true
+					break
+				}
+			}
+		}
+		return linkFilterMsg{label: label, pids: pids}
+	}
+}
+
+// applyLinkFilter filters the process table to the processes found for a device, or says
+// why there are none.
+func (m *model) applyLinkFilter(msg linkFilterMsg) {
+	if msg.err != nil {
+		m.reportError(fmt.Errorf("could not find the processes of %s: %w", msg.label, msg.err))
+		return
+	}
+	if len(msg.pids) == 0 {
+		m.reportInfo(fmt.Sprintf("no process we may inspect uses %s", msg.label))
+		return
+	}
+	m.linkFilter = &linkFilter{label: msg.label, pids: msg.pids}
+	m.focus = areaProcesses
+	m.view = viewProcesses
+	m.processTable.GotoTop()
+	m.refreshRows()
+}
+
+// moveLinkCursor moves the selected device of the focused disk or interfaces panel.
+func (m *model) moveLinkCursor(step int) {
+	switch m.focus {
+	case collectorDisk:
+		n := min(len(m.data.Disks), maxDiskLines)
+		m.diskCursor = min(max(m.diskCursor+step, 0), max(n-1, 0))
+	case collectorIfaces:
+		n := len(m.shownInterfaces())
+		m.ifaceCursor = min(max(m.ifaceCursor+step, 0), max(n-1, 0))
+	}
+}
+
+// linkSelected starts finding the processes of the device selected in the focused panel.
+func (m *model) linkSelected() tea.Cmd {
+	switch m.focus {
+	case collectorDisk:
+		if !diskLinkSupported {
+			m.reportInfo("mapping processes to disks is only supported on Linux")
+			return nil
+		}
+		if m.diskCursor < min(len(m.data.Disks), maxDiskLines) {
+			return diskLinkCmd(m.data.Disks[m.diskCursor].Name)
+		}
+	case collectorIfaces:
+		if shown := m.shownInterfaces(); m.ifaceCursor < len(shown) {
+			return ifaceLinkCmd(shown[m.ifaceCursor].Name)
+		}
+	}
+	return nil
+}
+
+// shownInterfaces returns the host interfaces the interfaces panel lists, in its order.
+func (m model) shownInterfaces() []NetInterface {
+	var shown []NetInterface
+	virtual := 0
+	for _, iface := range m.data.Interfaces {
+		if iface.Virtual {
+			if virtual++; virtual > maxVirtualLines {
+				continue
+			}
+		}
+		shown = append(shown, iface)
+	}
+	return shown
+}
+
+// linkCursor returns the marker in front of row i of a panel with a device selection:
+// "> " on the selected device while the panel is focused, blank otherwise.
+func (m model) linkCursor(collector string, i, cursor int) string {
+	if m.focus == collector && m.panelFocused() && i == cursor {
+		return m.baseStyle.Foreground(Color.Highlight).Render("> ")
+	}
+	return "  "
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// diskLinkSupported reports whether the disk panel can filter the process table.
+const diskLinkSupported = true
+
+// diskDevices returns the device numbers of a block device, its partitions and the
+// device-mapper or md devices stacked on them, which is what st_dev holds for the files
+// on their filesystems.
+func diskDevices(disk string) map[uint64]bool {
+	devs := map[uint64]bool{}
+	var add func(name string)
+	add = func(name string) {
+		dir := filepath.Join("/sys/class/block", name)
+		data, err := os.ReadFile(filepath.Join(dir, "dev"))
+		if err != nil {
+			return
+		}
+		major, minor, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+		if !ok {
+			return
+		}
+		maj, err1 := strconv.ParseUint(major, 10, 32)
+		mnr, err2 := strconv.ParseUint(minor, 10, 32)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		dev := unix.Mkdev(uint32(maj), uint32(mnr))
+		if devs[dev] {
+			return
+		}
+		devs[dev] = true
+		holders, _ := os.ReadDir(filepath.Join(dir, "holders"))
+		for _, h := range holders {
+			add(h.Name())
+		}
+	}
+	add(disk)
+	parts, _ := filepath.Glob(filepath.Join("/sys/class/block", disk, disk+"*", "dev"))
+	for _, p := range parts {
+		add(filepath.Base(filepath.Dir(p)))
+	}
+	return devs
+}
+
+// processesOnDisk returns the processes holding a file open on one of the filesystems of
+// disk, found by the st_dev of what their file descriptors point to. Without root only the
+// caller's own processes can be read, and filesystems with their own anonymous device
+// numbers, such as btrfs subvolumes, don't match.
+func processesOnDisk(disk string) map[int32]bool {
+	devs := diskDevices(disk)
+	pids := map[int32]bool{}
+	if len(devs) == 0 {
+		return pids
+	}
+	dirs, _ := filepath.Glob("/proc/[0-9]*/fd")
+	for _, dir := range dirs {
+		pid, err := strconv.ParseInt(filepath.Base(filepath.Dir(dir)), 10, 32)
+		if err != nil {
+			continue
+		}
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			var st syscall.Stat_t
+			if syscall.Stat(filepath.Join(dir, fd.Name()), &st) != nil {
+				continue
+			}
+			if st.Mode&syscall.S_IFMT == syscall.S_IFREG && devs[uint64(st.Dev)] {
+				pids[int32(pid)] = true
+				break
+			}
+		}
+	}
+	return pids
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+// diskLinkSupported reports whether the disk panel can filter the process table.
+const diskLinkSupported = false
+
+// processesOnDisk is a stub; open files are only mapped to disks on Linux.
+func processesOnDisk(string) map[int32]bool {
+	return nil
+}
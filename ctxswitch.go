@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ctxSwitchSupported reports whether gopsutil reads context switch counts on this platform.
+const ctxSwitchSupported = runtime.GOOS == "linux"
+
+// ctxSample is the cumulative context switches of a process at the moment they were read.
+// Voluntary switches happen when the process waits, on I/O or a lock; involuntary ones
+// when the scheduler preempts it for another task, so many of them mean it is fighting for
+// a CPU.
+type ctxSample struct {
+	createTime int64
+	vol, invol int64
+	at         time.Time
+}
+
+// readCtxSwitches samples the context switches of p. On Linux they come from
+// /proc/<pid>/status, one more read per process.
+func readCtxSwitches(p *process.Process, createTime int64, now time.Time) (ctxSample, error) {
+	n, err := p.NumCtxSwitches()
+	if err != nil {
+		return ctxSample{}, err
+	}
+	return ctxSample{createTime: createTime, vol: n.Voluntary, invol: n.Involuntary, at: now}, nil
+}
+
+// ctxTargets are the processes the process collector reads context switches of: the rows
+// of the process table on screen and the detail process. Reading /proc/<pid>/status of
+// every process costs more than the rest of a pass on busy hosts. It is shared by pointer
+// between the model, which sets it, and the collector, which reads it on its own goroutine.
+type ctxTargets struct {
+	mu   sync.Mutex
+	pids map[int32]bool
+}
+
+// set replaces the target processes with pids, nil for every process.
+func (t *ctxTargets) set(pids map[int32]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pids = pids
+}
+
+// get returns the target processes, or nil for every process: before the table was first
+// filled, and when t is nil, as in headless mode and bundles, which have no screen.
+func (t *ctxTargets) get() map[int32]bool {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pids
+}
+
+// targetCtxSwitches sets the processes whose context switches the next pass reads to the
+// rows the process table renders, which are those on screen and at most a screen either
+// side of the cursor, and the detail process. Sorting by CSW/s needs every process's.
+func (m model) targetCtxSwitches() {
+	if m.procOpts.ctxTargets == nil {
+		return
+	}
+	if m.sortColumn == columnCtxSwitch {
+		m.procOpts.ctxTargets.set(nil)
+		return
+	}
+	rows := m.processTable.Rows()
+	if len(rows) == 0 && m.view != viewDetail {
+		// Nothing was collected yet, or the table only emptied for now.
+		return
+	}
+	cursor, height := m.processTable.Cursor(), m.processTable.Height()
+	pids := map[int32]bool{}
+	for _, row := range rows[max(cursor-height, 0):min(cursor+height, len(rows))] {
+		if pid, err := strconv.ParseInt(stripTags(row[0]), 10, 32); err == nil {
+			pids[int32(pid)] = true
+		}
+	}
+	if m.view == viewDetail {
+		pids[m.detailPID] = true
+	}
+	m.procOpts.ctxTargets.set(pids)
+}
+
+// ctxRates returns the voluntary and involuntary switches per second between prev and
+// cur. ok is false when the samples don't belong to the same process or no time has passed.
+func ctxRates(prev, cur ctxSample) (vol, invol float64, ok bool) {
+	if prev.at.IsZero() || prev.createTime != cur.createTime || !cur.at.After(prev.at) {
+		return 0, 0, false
+	}
+	secs := cur.at.Sub(prev.at).Seconds()
+	return float64(max(cur.vol-prev.vol, 0)) / secs, float64(max(cur.invol-prev.invol, 0)) / secs, true
+}
+
+// ctxReading is the latest context switch measurement of the detail process.
+type ctxReading struct {
+	sample     ctxSample
+	vol, invol float64
+	// ok is false until two samples were taken.
+	ok  bool
+	err error
+}
+
+// next takes a new sample of pid and measures the switch rates since the previous one.
+func (r ctxReading) next(pid int32, createTime int64) ctxReading {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ctxReading{err: err}
+	}
+	cur, err := readCtxSwitches(p, createTime, time.Now())
+	if err != nil {
+		return ctxReading{err: err}
+	}
+	vol, invol, ok := ctxRates(r.sample, cur)
+	return ctxReading{sample: cur, vol: vol, invol: invol, ok: ok}
+}
+
+// ctxSwitchCell renders the CSW/s column as voluntary/involuntary switches per second.
+func ctxSwitchCell(p ProcessInfo) string {
+	if !p.CtxKnown {
+		return "-"
+	}
+	return formatCtxRate(p.VolCtxRate) + "/" + formatCtxRate(p.InvolCtxRate)
+}
+
+// formatCtxRate renders a context switch rate as a whole count.
+func formatCtxRate(v float64) string {
+	return formatInt(int64(math.Round(v)))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCtxTargets(t *testing.T) {
+	if !ctxSwitchSupported {
+		t.Skip("no context switch counts on this platform")
+	}
+	cfg := defaultConfig()
+	cfg.Columns.Visible = append(cfg.Columns.Visible, columnCtxSwitch)
+	m := newModel(cfg)
+	m.sortColumn = columnCPU
+	if m.procOpts.ctxTargets.get() != nil {
+		t.Fatal("the first pass doesn't read every process")
+	}
+
+	m = send(m, tea.WindowSizeMsg{Width: 160, Height: 40}, tick(time.Now(), busyProcs(500)))
+	height := m.processTable.Height()
+	down := make([]string, 100)
+	for i := range down {
+		down[i] = "down"
+	}
+	m = send(m, keys(down...)...)
+	cursor := m.processTable.Cursor()
+	pids := m.procOpts.ctxTargets.get()
+	selected, _ := m.selectedPID()
+	if !pids[selected] {
+		t.Errorf("the selected PID %d isn't read", selected)
+	}
+	if len(pids) > 2*height {
+		t.Errorf("%d processes are read for a table %d rows high", len(pids), height)
+	}
+	// PIDs are the row numbers plus one, as busyProcs are in CPU order.
+	if far := int32(cursor + 2*height); pids[far] || pids[1] {
+		t.Errorf("rows off screen are read: %v", pids)
+	}
+
+	m.view, m.detailPID = viewDetail, 1
+	m = send(m, tick(time.Now(), busyProcs(500)))
+	if pids := m.procOpts.ctxTargets.get(); !pids[1] || !pids[selected] {
+		t.Errorf("the detail process isn't read with the rows: %v", pids)
+	}
+
+	m.view = viewProcesses
+	m.sortColumn = columnCtxSwitch
+	m = send(m, tick(time.Now(), busyProcs(500)))
+	if pids := m.procOpts.ctxTargets.get(); pids != nil {
+		t.Errorf("sorting by CSW/s reads only %d processes", len(pids))
+	}
+}
+
+func TestGetProcessesCtxPIDs(t *testing.T) {
+	if !ctxSwitchSupported {
+		t.Skip("no context switch counts on this platform")
+	}
+	self := int32(os.Getpid())
+	c := NewProcessCollector(processOptions{ctxSwitch: true})
+	only := map[int32]bool{self: true}
+	if _, err := c.GetProcesses(0, only); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	procs, err := c.GetProcesses(0, only)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range procs {
+		if p.CtxKnown != (p.PID == self) {
+			t.Errorf("PID %d has CtxKnown %v", p.PID, p.CtxKnown)
+		}
+	}
+	if len(c.prevCtx) != 1 {
+		t.Errorf("%d samples kept, want the one of PID %d", len(c.prevCtx), self)
+	}
+}
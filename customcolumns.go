@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// exprValue evaluates a custom column expression, or part of one, for a process. ok is
+// false when a field it uses is unknown for the process or it divides by zero.
+type exprValue func(p ProcessInfo) (v float64, ok bool)
+
+// hostMemTotal is the RAM of the host, read once for mem_percent; 0 when it can't be read.
+var hostMemTotal = sync.OnceValue(func() uint64 {
+	if vm, err := mem.VirtualMemory(); err == nil {
+		return vm.Total
+	}
+	return 0
+})
+
+// exprFields are the per-process values custom column expressions may use, by name.
+var exprFields = map[string]exprValue{
+	"pid":         func(p ProcessInfo) (float64, bool) { return float64(p.PID), true },
+	"ppid":        func(p ProcessInfo) (float64, bool) { return float64(p.PPID), true },
+	"cpu_percent": func(p ProcessInfo) (float64, bool) { return p.CPUPercent, true },
+	"rss":         func(p ProcessInfo) (float64, bool) { return float64(p.Memory), true },
+	"mem_percent": func(p ProcessInfo) (float64, bool) {
+		total := hostMemTotal()
+		return float64(p.Memory) / float64(total) * 100, total > 0
+	},
+	// cpu_time and age are in seconds.
+	"cpu_time": func(p ProcessInfo) (float64, bool) { return p.CPUTime.Seconds(), true },
+	"age": func(p ProcessInfo) (float64, bool) {
+		return time.Since(time.UnixMilli(p.CreateTime)).Seconds(), p.CreateTime > 0
+	},
+	"threads":    func(p ProcessInfo) (float64, bool) { return float64(p.Threads), p.Threads > 0 },
+	"fds":        func(p ProcessInfo) (float64, bool) { return float64(p.NumFDs), p.FDsKnown },
+	"nice":       func(p ProcessInfo) (float64, bool) { return float64(p.Nice), true },
+	"net_rx":     func(p ProcessInfo) (float64, bool) { return p.NetRx, p.NetKnown },
+	"net_tx":     func(p ProcessInfo) (float64, bool) { return p.NetTx, p.NetKnown },
+	"disk_read":  func(p ProcessInfo) (float64, bool) { return p.DiskRead, p.DiskKnown },
+	"disk_write": func(p ProcessInfo) (float64, bool) { return p.DiskWrite, p.DiskKnown },
+	"gpu_mem":    func(p ProcessInfo) (float64, bool) { return float64(p.GPUMem), true },
+	"run_delay":  func(p ProcessInfo) (float64, bool) { return p.RunDelay, p.RunDelayKnown },
+	"io_delay":   func(p ProcessInfo) (float64, bool) { return p.IODelay, p.IODelayKnown },
+}
+
+// exprParser compiles a custom column expression: numbers, field names, + - * /, unary
+// minus and parentheses, with the usual precedence. It compiles straight into closures,
+// so evaluating a row walks no syntax tree and allocates nothing.
+type exprParser struct {
+	src string
+	pos int
+	// fields collects the field names the expression uses.
+	fields []string
+}
+
+// errorf returns a syntax error at pos, reported 1-based so it matches the column a
+// reader counts to in the config file's string.
+func (ps *exprParser) errorf(pos int, format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+// compileExpr compiles src and returns it with the names of the fields it uses.
+func compileExpr(src string) (exprValue, []string, error) {
+	ps := &exprParser{src: src}
+	v, err := ps.sum()
+	if err != nil {
+		return nil, nil, err
+	}
+	if ps.skipSpace(); ps.pos < len(ps.src) {
+		return nil, nil, ps.errorf(ps.pos, "unexpected %q", ps.src[ps.pos])
+	}
+	return v, ps.fields, nil
+}
+
+func (ps *exprParser) skipSpace() {
+	for ps.pos < len(ps.src) && (ps.src[ps.pos] == ' ' || ps.src[ps.pos] == '\t') {
+		ps.pos++
+	}
+}
+
+// peek returns the next non-blank byte, 0 at the end.
+func (ps *exprParser) peek() byte {
+	if ps.skipSpace(); ps.pos < len(ps.src) {
+		return ps.src[ps.pos]
+	}
+	return 0
+}
+
+// sum parses terms joined by + and -.
+func (ps *exprParser) sum() (exprValue, error) {
+	left, err := ps.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ps.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		ps.pos++
+		right, err := ps.product()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(op, left, right)
+	}
+}
+
+// product parses factors joined by * and /.
+func (ps *exprParser) product() (exprValue, error) {
+	left, err := ps.factor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ps.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		ps.pos++
+		right, err := ps.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(op, left, right)
+	}
+}
+
+// factor parses a number, a field, a negated factor or a parenthesized sum.
+func (ps *exprParser) factor() (exprValue, error) {
+	c := ps.peek()
+	start := ps.pos
+	switch {
+	case c == 0:
+		return nil, ps.errorf(start, "unexpected end of expression")
+	case c == '-':
+		ps.pos++
+		inner, err := ps.factor()
+		if err != nil {
+			return nil, err
+		}
+		return func(p ProcessInfo) (float64, bool) {
+			v, ok := inner(p)
+			return -v, ok
+		}, nil
+	case c == '(':
+		ps.pos++
+		inner, err := ps.sum()
+		if err != nil {
+			return nil, err
+		}
+		if ps.peek() != ')' {
+			return nil, ps.errorf(ps.pos, "missing )")
+		}
+		ps.pos++
+		return inner, nil
+	case c >= '0' && c <= '9' || c == '.':
+		for ps.pos < len(ps.src) && (ps.src[ps.pos] >= '0' && ps.src[ps.pos] <= '9' || ps.src[ps.pos] == '.') {
+			ps.pos++
+		}
+		n, err := strconv.ParseFloat(ps.src[start:ps.pos], 64)
+		if err != nil {
+			return nil, ps.errorf(start, "bad number %q", ps.src[start:ps.pos])
+		}
+		return func(ProcessInfo) (float64, bool) { return n, true }, nil
+	case c >= 'a' && c <= 'z' || c == '_':
+		for ps.pos < len(ps.src) && (ps.src[ps.pos] >= 'a' && ps.src[ps.pos] <= 'z' || ps.src[ps.pos] == '_' || ps.src[ps.pos] >= '0' && ps.src[ps.pos] <= '9') {
+			ps.pos++
+		}
+		name := ps.src[start:ps.pos]
+		field, ok := exprFields[name]
+		if !ok {
+			return nil, ps.errorf(start, "unknown field %q", name)
+		}
+		ps.fields = append(ps.fields, name)
+		return field, nil
+	}
+	return nil, ps.errorf(start, "unexpected %q", c)
+}
+
+// binaryExpr combines two operands. Division by zero leaves the value unknown rather than
+// putting an infinity in the table.
+func binaryExpr(op byte, left, right exprValue) exprValue {
+	return func(p ProcessInfo) (float64, bool) {
+		a, okA := left(p)
+		b, okB := right(p)
+		if !okA || !okB {
+			return 0, false
+		}
+		switch op {
+		case '+':
+			return a + b, true
+		case '-':
+			return a - b, true
+		case '*':
+			return a * b, true
+		}
+		if b == 0 {
+			return 0, false
+		}
+		return a / b, true
+	}
+}
+
+// customColumnName is what a custom column may be called: its name is also its ID in
+// saved views and the sort state.
+var customColumnName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// customColumn compiles the columns.custom entry name = src into a process column. Custom
+// columns sort highest first, with rows whose value is unknown last.
+func customColumn(name, src string) (processColumn, []string, error) {
+	if !customColumnName.MatchString(name) {
+		return processColumn{}, nil, fmt.Errorf("%s: names may only hold lowercase letters, digits and _", name)
+	}
+	if slices.ContainsFunc(processColumns, func(c processColumn) bool { return c.id == name }) {
+		return processColumn{}, nil, fmt.Errorf("%s: a built-in column is called that", name)
+	}
+	eval, fields, err := compileExpr(src)
+	if err != nil {
+		return processColumn{}, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	col := processColumn{
+		id: name, title: name, minWidth: 6, maxWidth: max(16, len(name)+2),
+		cell: func(p ProcessInfo) string {
+			v, ok := eval(p)
+			if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+				return "-"
+			}
+			// Large values read better as grouped integers; the decimals only matter
+			// for small ones, such as ratios.
+			if math.Abs(v) >= 1000 {
+				return formatInt(int64(math.Round(v)))
+			}
+			return formatFloat(v, 2)
+		},
+		less: func(a, b ProcessInfo) bool {
+			va, okA := eval(a)
+			vb, okB := eval(b)
+			if okA != okB {
+				return okA
+			}
+			return va > vb
+		},
+	}
+	return col, fields, nil
+}
+
+// customColumns are the columns defined by columns.custom, in name order. Like Units they
+// are set whenever a config is applied, since the column lookups are package-wide.
+var customColumns []processColumn
+
+// compileCustomColumns compiles every columns.custom entry and returns the columns with
+// the fields they use.
+func (c ColumnsConfig) compileCustomColumns() ([]processColumn, map[string]bool, error) {
+	var cols []processColumn
+	uses := map[string]bool{}
+	names := make([]string, 0, len(c.Custom))
+	for name := range c.Custom {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		col, fields, err := customColumn(name, c.Custom[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		cols = append(cols, col)
+		for _, f := range fields {
+			uses[f] = true
+		}
+	}
+	return cols, uses, nil
+}
+
+// setCustomColumns makes the columns of c.Custom available to the process table. The
+// check of columns.custom has already rejected a config whose expressions don't compile.
+func (c ColumnsConfig) setCustomColumns() {
+	customColumns, _, _ = c.compileCustomColumns()
+}
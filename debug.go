@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// selfMetrics counts what the program itself spends on collecting and rendering, served by
+// the debug endpoint. A nil *selfMetrics records nothing, so callers need no checks when
+// the endpoint is off.
+type selfMetrics struct {
+	mu         sync.Mutex
+	collectors map[string]*durationStat
+	render     durationStat
+	// passes covers whole collection passes; lastPass is the most recent one.
+	passes   durationStat
+	lastPass time.Duration
+	// droppedTicks counts ticks skipped because the previous collection was still running;
+	// overruns counts collection passes that took longer than the refresh interval.
+	droppedTicks uint64
+	overruns     uint64
+	// statsd, when -statsd is given, supplies the counts of the packets it sent and failed to send.
+	statsd *statsdClient
+}
+
+// durationStat accumulates observations for a Prometheus summary.
+type durationStat struct {
+	count uint64
+	sum   time.Duration
+}
+
+func (d *durationStat) observe(v time.Duration) {
+	d.count++
+	d.sum += v
+}
+
+func newSelfMetrics() *selfMetrics {
+	return &selfMetrics{collectors: map[string]*durationStat{}}
+}
+
+// observeCollector records how long one collector took.
+func (s *selfMetrics) observeCollector(name string, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat, ok := s.collectors[name]
+	if !ok {
+		stat = &durationStat{}
+		s.collectors[name] = stat
+	}
+	stat.observe(d)
+}
+
+// observePass records a whole collection pass and whether it overran the interval.
+func (s *selfMetrics) observePass(d, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.passes.observe(d)
+	s.lastPass = d
+	if d > interval {
+		s.overruns++
+	}
+	s.mu.Unlock()
+}
+
+// dropTick records a tick that started no collection.
+func (s *selfMetrics) dropTick() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.droppedTicks++
+	s.mu.Unlock()
+}
+
+// observeRender records how long rendering a frame took.
+func (s *selfMetrics) observeRender(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.render.observe(d)
+	s.mu.Unlock()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (s *selfMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var b strings.Builder
+	summary := func(name, labels string, d durationStat) {
+		fmt.Fprintf(&b, "%s_sum%s %g\n", name, labels, d.sum.Seconds())
+		fmt.Fprintf(&b, "%s_count%s %d\n", name, labels, d.count)
+	}
+
+	s.mu.Lock()
+	b.WriteString("# HELP smtui_collector_duration_seconds Time spent in each collector.\n# TYPE smtui_collector_duration_seconds summary\n")
+	names := make([]string, 0, len(s.collectors))
+	for name := range s.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		summary("smtui_collector_duration_seconds", fmt.Sprintf("{collector=%q}", name), *s.collectors[name])
+	}
+	b.WriteString("# HELP smtui_collection_duration_seconds Time spent in whole collection passes.\n# TYPE smtui_collection_duration_seconds summary\n")
+	summary("smtui_collection_duration_seconds", "", s.passes)
+	fmt.Fprintf(&b, "# HELP smtui_collection_last_duration_seconds Duration of the latest collection pass.\n# TYPE smtui_collection_last_duration_seconds gauge\nsmtui_collection_last_duration_seconds %g\n", s.lastPass.Seconds())
+	b.WriteString("# HELP smtui_render_duration_seconds Time spent rendering frames.\n# TYPE smtui_render_duration_seconds summary\n")
+	summary("smtui_render_duration_seconds", "", s.render)
+	fmt.Fprintf(&b, "# HELP smtui_dropped_ticks_total Ticks skipped because a collection was still running.\n# TYPE smtui_dropped_ticks_total counter\nsmtui_dropped_ticks_total %d\n", s.droppedTicks)
+	fmt.Fprintf(&b, "# HELP smtui_tick_overruns_total Collection passes that took longer than the refresh interval.\n# TYPE smtui_tick_overruns_total counter\nsmtui_tick_overruns_total %d\n", s.overruns)
+	s.mu.Unlock()
+	if s.statsd != nil {
+		b.WriteString("# HELP smtui_statsd_packets_total StatsD packets pushed, by outcome.\n# TYPE smtui_statsd_packets_total counter\n")
+		fmt.Fprintf(&b, "smtui_statsd_packets_total{result=\"sent\"} %d\n", s.statsd.sent.Load())
+		fmt.Fprintf(&b, "smtui_statsd_packets_total{result=\"failed\"} %d\n", s.statsd.failed.Load())
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(&b, "# HELP smtui_allocs_total Heap objects allocated.\n# TYPE smtui_allocs_total counter\nsmtui_allocs_total %d\n", mem.Mallocs)
+	fmt.Fprintf(&b, "# HELP smtui_alloc_bytes_total Heap bytes allocated.\n# TYPE smtui_alloc_bytes_total counter\nsmtui_alloc_bytes_total %d\n", mem.TotalAlloc)
+	fmt.Fprintf(&b, "# HELP smtui_heap_bytes Heap bytes in use.\n# TYPE smtui_heap_bytes gauge\nsmtui_heap_bytes %d\n", mem.HeapAlloc)
+	fmt.Fprintf(&b, "# HELP smtui_goroutines Goroutines running.\n# TYPE smtui_goroutines gauge\nsmtui_goroutines %d\n", runtime.NumGoroutine())
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+// debugAddr binds a bare ":port" to localhost, so the profiler is never exposed to the
+// network unless a host is given explicitly.
+func debugAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "127.0.0.1" + addr
+	}
+	return addr
+}
+
+// startDebugServer serves /metrics and /debug/pprof/ on addr in the background.
+func startDebugServer(addr string, metrics *selfMetrics) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	ln, err := net.Listen("tcp", debugAddr(addr))
+	if err != nil {
+		return fmt.Errorf("could not start debug endpoint: %w", err)
+	}
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			slog.Error("Debug endpoint stopped", "error", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import "time"
+
+// delayTotals are the cumulative scheduler delays of a process, summed over its threads:
+// the time spent runnable but waiting for a CPU, and the time blocked on block I/O.
+type delayTotals struct {
+	createTime int64
+	run, blkio time.Duration
+	at         time.Time
+	// blkioKnown is false while delay accounting is off, when the kernel reports no I/O delay.
+	blkioKnown bool
+}
+
+// delayPercents returns the run and I/O delay between prev and cur as percentages of the
+// elapsed time. busy threads can push them past 100%, as with CPU%. ok is false when the
+// samples don't belong to the same process or no time has passed.
+func delayPercents(prev, cur delayTotals) (run, io float64, ioKnown, ok bool) {
+	if prev.at.IsZero() || prev.createTime != cur.createTime || !cur.at.After(prev.at) {
+		return 0, 0, false, false
+	}
+	elapsed := float64(cur.at.Sub(prev.at))
+	run = float64(max(cur.run-prev.run, 0)) / elapsed * 100
+	io = float64(max(cur.blkio-prev.blkio, 0)) / elapsed * 100
+	return run, io, prev.blkioKnown && cur.blkioKnown, true
+}
+
+// delayReading is the latest delay measurement of the detail process.
+type delayReading struct {
+	totals  delayTotals
+	run, io float64
+	// ok is false until two samples were taken; ioKnown additionally needs delay accounting.
+	ok, ioKnown bool
+	err         error
+}
+
+// next takes a new sample of pid and measures the delays since the previous one.
+func (r delayReading) next(pid int32, createTime int64) delayReading {
+	cur, err := readDelays(pid, createTime, delayAccountingEnabled())
+	if err != nil {
+		return delayReading{err: err}
+	}
+	run, io, ioKnown, ok := delayPercents(r.totals, cur)
+	return delayReading{totals: cur, run: run, io: io, ok: ok, ioKnown: ioKnown}
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// delaySupported reports whether per-process scheduler delays are read on this platform.
+const delaySupported = true
+
+// userHZ is the unit of the clock tick fields of /proc/<pid>/stat.
+const userHZ = 100
+
+// delayAccountingEnabled reports whether the kernel accounts block I/O delays. Kernels
+// before 5.14 have no switch and account them whenever built with delay accounting.
+func delayAccountingEnabled() bool {
+	data, err := os.ReadFile("/proc/sys/kernel/task_delayacct")
+	if err != nil {
+		return true
+	}
+	return strings.TrimSpace(string(data)) != "0"
+}
+
+// readDelays sums the run delay (the second field of schedstat) and the block I/O delay
+// (field 42 of stat) over every thread of pid, since /proc/<pid> only covers the main thread.
+func readDelays(pid int32, createTime int64, acct bool) (delayTotals, error) {
+	tasks, err := filepath.Glob(fmt.Sprintf("/proc/%d/task/[0-9]*", pid))
+	if err != nil || len(tasks) == 0 {
+		return delayTotals{}, fmt.Errorf("could not list the threads of PID %d", pid)
+	}
+	d := delayTotals{createTime: createTime, at: time.Now(), blkioKnown: acct}
+	for _, task := range tasks {
+		schedstat, err := os.ReadFile(filepath.Join(task, "schedstat"))
+		if err != nil {
+			// A thread that exited since the listing contributes nothing.
+			continue
+		}
+		fields := strings.Fields(string(schedstat))
+		if len(fields) < 2 {
+			return delayTotals{}, fmt.Errorf("malformed schedstat of PID %d", pid)
+		}
+		ns, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return delayTotals{}, fmt.Errorf("malformed schedstat of PID %d: %w", pid, err)
+		}
+		d.run += time.Duration(ns)
+
+		if !acct {
+			continue
+		}
+		stat, err := os.ReadFile(filepath.Join(task, "stat"))
+		if err != nil {
+			continue
+		}
+		// As in processSched, fields[0] is field 3, so field 42 is fields[39].
+		i := strings.LastIndexByte(string(stat), ')')
+		if i < 0 {
+			d.blkioKnown = false
+			continue
+		}
+		statFields := strings.Fields(string(stat[i+1:]))
+		if len(statFields) < 40 {
+			d.blkioKnown = false
+			continue
+		}
+		ticks, err := strconv.ParseInt(statFields[39], 10, 64)
+		if err != nil {
+			d.blkioKnown = false
+			continue
+		}
+		d.blkio += time.Duration(ticks) * time.Second / userHZ
+	}
+	return d, nil
+}
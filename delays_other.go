@@ -0,0 +1,16 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// delaySupported reports whether per-process scheduler delays are read on this platform.
+const delaySupported = false
+
+func delayAccountingEnabled() bool {
+	return false
+}
+
+func readDelays(pid int32, createTime int64, acct bool) (delayTotals, error) {
+	return delayTotals{}, errors.New("scheduler delays are only read on Linux")
+}
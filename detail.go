@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// selectedPID returns the PID of the row selected in the process table.
+func (m model) selectedPID() (int32, bool) {
+	row := m.processTable.SelectedRow()
+	if row == nil {
+		return 0, false
+	}
+	pid, err := strconv.ParseInt(stripTags(row[0]), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(pid), true
+}
+
+// actionPID returns the process that actions such as kill apply to: the detail process in
+// the detail view, the selected row in the process table otherwise.
+func (m model) actionPID() (int32, bool) {
+	switch m.view {
+	case viewDetail:
+		return m.detailPID, true
+	case viewProcesses:
+		return m.selectedPID()
+	}
+	return 0, false
+}
+
+// openDetail switches to the detail view for the process selected in the process table.
+func (m *model) openDetail() {
+	if pid, ok := m.selectedPID(); ok {
+		m.showDetail(pid)
+	}
+}
+
+// showDetail switches to the detail view for pid.
+func (m *model) showDetail(pid int32) {
+	m.detailPID = pid
+	m.view = viewDetail
+	m.detailDelay = delayReading{}
+	m.detailCtx = ctxReading{}
+	m.refreshDetail()
+}
+
+// refreshDetail re-reads the per-process data that is only collected for the detail view.
+func (m *model) refreshDetail() {
+	if m.view != viewDetail {
+		return
+	}
+	if ioniceSupported {
+		m.detailIOPrio, m.detailIOPrioErr = getIOPriority(m.detailPID)
+	}
+	if affinitySupported {
+		m.detailAffinity, m.detailAffinityErr = getAffinity(m.detailPID)
+	}
+	if delaySupported {
+		p, _ := m.detailProcess()
+		m.detailDelay = m.detailDelay.next(m.detailPID, p.CreateTime)
+	}
+	if ctxSwitchSupported {
+		p, _ := m.detailProcess()
+		m.detailCtx = m.detailCtx.next(m.detailPID, p.CreateTime)
+	}
+	if smapsSupported {
+		m.detailMem, m.detailMemErr = detailMemory(m.detailPID)
+	}
+}
+
+// detailProcess returns the latest data for the process shown in the detail view.
+func (m model) detailProcess() (ProcessInfo, bool) {
+	return m.findProcess(m.detailPID)
+}
+
+// findProcess returns the latest data for pid.
+func (m model) findProcess(pid int32) (ProcessInfo, bool) {
+	for _, p := range m.data.Procs {
+		if p.PID == pid {
+			return p, true
+		}
+	}
+	return ProcessInfo{}, false
+}
+
+// updateIONicePicker handles key presses while the I/O priority picker is open.
+func (m model) updateIONicePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h":
+		m.ionice.cycleClass(-1)
+	case "right", "l":
+		m.ionice.cycleClass(1)
+	case "up", "k":
+		m.ionice.adjustLevel(-1)
+	case "down", "j":
+		m.ionice.adjustLevel(1)
+	// Applies the chosen priority; failures such as missing permissions end up in the error banner.
+	case "enter":
+		err := setIOPriority(m.ionice.pid, m.ionice.prio)
+		p, _ := m.findProcess(m.ionice.pid)
+		m.actionLog.record(m.ionice.pid, p.Name, "ionice "+m.ionice.prio.String(), err)
+		if err != nil {
+			m.reportError(fmt.Errorf("could not set I/O priority of PID %d: %w", m.ionice.pid, err))
+		}
+		m.ionice = nil
+		m.refreshDetail()
+	// Closes the picker without applying; q only quits once no picker is open.
+	case "esc", "q":
+		m.ionice = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// openIONicePicker starts editing the I/O priority of the detail process, seeded with its current value.
+func (m *model) openIONicePicker() {
+	prio := m.detailIOPrio
+	if prio.Class == ioClassNone {
+		prio = ioPriority{Class: ioClassBestEffort, Level: 4}
+	}
+	m.ionice = &ionicePicker{pid: m.detailPID, prio: prio}
+}
+
+// updateAffinityPicker handles key presses while the CPU affinity picker is open.
+func (m model) updateAffinityPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h":
+		m.affinity.move(-1)
+	case "right", "l":
+		m.affinity.move(1)
+	case "up", "k":
+		m.affinity.move(-affinityRowLen)
+	case "down", "j":
+		m.affinity.move(affinityRowLen)
+	case " ", "x":
+		m.affinity.toggle()
+	case "a":
+		m.affinity.toggleAll()
+	// Applies the chosen cores; failures such as missing permissions end up in the error banner.
+	case "enter":
+		if m.affinity.empty() {
+			m.reportError(fmt.Errorf("select at least one core for PID %d", m.affinity.pid))
+			return m, nil
+		}
+		err := setAffinity(m.affinity.pid, m.affinity.cores)
+		p, _ := m.findProcess(m.affinity.pid)
+		m.actionLog.record(m.affinity.pid, p.Name, "affinity "+formatCoreList(m.affinity.cores), err)
+		if err != nil {
+			m.reportError(fmt.Errorf("could not set CPU affinity of PID %d: %w", m.affinity.pid, err))
+		}
+		m.affinity = nil
+		m.refreshDetail()
+	// Closes the picker without applying; q only quits once no picker is open.
+	case "esc", "q":
+		m.affinity = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// openAffinityPicker starts editing the CPU affinity of the detail process, seeded with its
+// current mask.
+func (m *model) openAffinityPicker() {
+	if m.detailAffinityErr != nil {
+		m.reportError(fmt.Errorf("could not read CPU affinity of PID %d: %w", m.detailPID, m.detailAffinityErr))
+		return
+	}
+	m.affinity = &affinityPicker{pid: m.detailPID, cores: slices.Clone(m.detailAffinity)}
+}
+
+// viewAffinityPicker renders the cores of the picker in rows, selected cores highlighted
+// and the cursor underlined.
+func (m model) viewAffinityPicker() []string {
+	width := len(fmt.Sprintf("%d", len(m.affinity.cores)-1))
+	var rows []string
+	var row []string
+	for i, on := range m.affinity.cores {
+		style := m.baseStyle.Foreground(Color.Secondary)
+		if on {
+			style = m.baseStyle.Foreground(Color.Highlight).Bold(true)
+		}
+		if i == m.affinity.cursor {
+			style = style.Reverse(true)
+		}
+		row = append(row, style.Render(fmt.Sprintf("%*d", width, i)))
+		if len(row) == affinityRowLen || i == len(m.affinity.cores)-1 {
+			rows = append(rows, strings.Join(row, " "))
+			row = nil
+		}
+	}
+	return rows
+}
+
+// viewDetail renders everything known about the selected process.
+func (m model) viewDetail() string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	key := m.baseStyle.Width(14).Render
+
+	p, ok := m.detailProcess()
+	if !ok {
+		return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+			title(fmt.Sprintf("Process %d", m.detailPID)),
+			hint("process has exited"),
+		))
+	}
+
+	fds := formatInt(p.NumFDs)
+	if !p.FDsKnown {
+		fds = hint("permission denied")
+	}
+	lines := []string{
+		title(fmt.Sprintf("Process %d (%s)", p.PID, p.Name)),
+		"",
+		key("User:") + p.Username,
+		key("Parent PID:") + fmt.Sprintf("%d", p.PPID),
+		key("CPU:") + formatPercent(p.CPUPercent, 2),
+		key("Memory:") + formatBytes(p.Memory),
+		key("CPU time:") + formatCPUTime(p.CPUTime),
+		key("Open FDs:") + fds,
+		key("Running:") + p.RunningTime,
+	}
+	if p.DisplayName != "" {
+		// The raw name is in the title; this is what the Name column shows for it.
+		lines = append(lines, key("Shown as:")+p.DisplayName)
+	}
+	if p.Flags != "" {
+		lines = append(lines, key("Flags:")+m.baseStyle.Foreground(Color.Red).Render(describeFlags(p.Flags)))
+	}
+	if m.procOpts.units {
+		unit := hint("none")
+		if p.Unit != "" {
+			unit = p.Unit
+			if p.Slice != "" {
+				unit += hint(" in " + p.Slice)
+			}
+		}
+		lines = append(lines, key("Unit:")+unit)
+	}
+
+	if delaySupported {
+		d := m.detailDelay
+		switch {
+		case d.err != nil:
+			lines = append(lines, key("Run delay:")+hint(d.err.Error()))
+		case !d.ok:
+			lines = append(lines, key("Run delay:")+hint("measuring…"), key("I/O delay:")+hint("measuring…"))
+		default:
+			lines = append(lines, key("Run delay:")+formatPercent(d.run, 1)+hint(" waiting for a CPU"))
+			if d.ioKnown {
+				lines = append(lines, key("I/O delay:")+formatPercent(d.io, 1)+hint(" blocked on disk"))
+			} else {
+				lines = append(lines, key("I/O delay:")+hint("delay accounting is off (sysctl kernel.task_delayacct=1)"))
+			}
+		}
+	}
+
+	if ctxSwitchSupported {
+		c := m.detailCtx
+		switch {
+		case c.err != nil:
+			lines = append(lines, key("Ctx switches:")+hint(c.err.Error()))
+		case !c.ok:
+			lines = append(lines, key("Ctx switches:")+hint("measuring…"))
+		default:
+			lines = append(lines, key("Ctx switches:")+fmt.Sprintf("%s/s voluntary · %s/s involuntary", formatCtxRate(c.vol), formatCtxRate(c.invol))+
+				hint(" (involuntary: preempted for another task)"))
+		}
+	}
+
+	if smapsSupported {
+		lines = append(lines, m.memoryLines(key, hint)...)
+	}
+
+	if ioniceSupported {
+		switch {
+		case m.ionice != nil:
+			level := "-"
+			if m.ionice.prio.Class != ioClassIdle {
+				level = fmt.Sprintf("%d", m.ionice.prio.Level)
+			}
+			lines = append(lines,
+				key("I/O priority:")+m.baseStyle.Foreground(Color.Highlight).Render(
+					fmt.Sprintf("< %s > level %s", m.ionice.prio.Class, level)),
+				hint("←/→: class · ↑/↓: level · enter: apply · esc: cancel"),
+			)
+		case m.detailIOPrioErr != nil:
+			lines = append(lines, key("I/O priority:")+hint(m.detailIOPrioErr.Error()))
+		default:
+			lines = append(lines, key("I/O priority:")+m.detailIOPrio.String())
+		}
+	}
+
+	if affinitySupported {
+		switch {
+		case m.affinity != nil:
+			lines = append(lines, key("CPU affinity:")+m.baseStyle.Foreground(Color.Highlight).Render(formatCoreList(m.affinity.cores)))
+			for _, row := range m.viewAffinityPicker() {
+				lines = append(lines, key("")+row)
+			}
+			lines = append(lines, hint("arrows: move · space: toggle · a: all/none · enter: apply · esc: cancel"))
+		case m.detailAffinityErr != nil:
+			lines = append(lines, key("CPU affinity:")+hint(m.detailAffinityErr.Error()))
+		default:
+			lines = append(lines, key("CPU affinity:")+formatCoreList(m.detailAffinity))
+		}
+	}
+
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
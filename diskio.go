@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// DiskIO is the activity of one block device over the last refresh interval.
+type DiskIO struct {
+	Name       string
+	ReadRate   float64 // bytes per second
+	WriteRate  float64 // bytes per second
+	Util       float64 // percentage of the interval the device was busy
+	ReadOps    uint64  // completed reads in the interval
+	WriteOps   uint64  // completed writes in the interval
+	ReadAwait  time.Duration
+	WriteAwait time.Duration
+}
+
+// diskSample is the cumulative counters of a device at the moment they were read.
+type diskSample struct {
+	counters disk.IOCountersStat
+	at       time.Time
+}
+
+// DiskIOCollector turns the cumulative disk counters into per-interval rates, utilization
+// and latency by diffing them against the previous read. Like ProcessCollector it is not
+// safe for concurrent use.
+type DiskIOCollector struct {
+	prev map[string]diskSample
+}
+
+func NewDiskIOCollector() *DiskIOCollector {
+	return &DiskIOCollector{prev: map[string]diskSample{}}
+}
+
+// virtualDiskPrefixes are devices that are not backed by a disk of their own.
+var virtualDiskPrefixes = []string{"loop", "ram", "zram"}
+
+// GetDiskIO returns the activity of every device since the previous call, sorted by name.
+// Devices seen for the first time are left out until there is a previous sample.
+func (c *DiskIOCollector) GetDiskIO() ([]DiskIO, error) {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]diskSample, len(counters))
+	var devices []DiskIO
+	for name, cur := range counters {
+		if hasAnyPrefix(name, virtualDiskPrefixes) {
+			continue
+		}
+		seen[name] = diskSample{counters: cur, at: now}
+
+		prev, ok := c.prev[name]
+		if !ok {
+			continue
+		}
+		elapsed := now.Sub(prev.at)
+		if elapsed <= 0 {
+			continue
+		}
+		d := DiskIO{
+			Name:      name,
+			ReadRate:  float64(delta(cur.ReadBytes, prev.counters.ReadBytes)) / elapsed.Seconds(),
+			WriteRate: float64(delta(cur.WriteBytes, prev.counters.WriteBytes)) / elapsed.Seconds(),
+			Util:      min(float64(delta(cur.IoTime, prev.counters.IoTime))/float64(elapsed.Milliseconds())*100, 100),
+			ReadOps:   delta(cur.ReadCount, prev.counters.ReadCount),
+			WriteOps:  delta(cur.WriteCount, prev.counters.WriteCount),
+		}
+		// await is the time spent on the completed operations divided by their number.
+		if d.ReadOps > 0 {
+			d.ReadAwait = time.Duration(delta(cur.ReadTime, prev.counters.ReadTime)) * time.Millisecond / time.Duration(d.ReadOps)
+		}
+		if d.WriteOps > 0 {
+			d.WriteAwait = time.Duration(delta(cur.WriteTime, prev.counters.WriteTime)) * time.Millisecond / time.Duration(d.WriteOps)
+		}
+		devices = append(devices, d)
+	}
+	// Only keep devices that still exist so unplugged disks don't linger.
+	c.prev = seen
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices, nil
+}
+
+// delta returns cur - prev for a cumulative counter, or 0 when the counter was reset.
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDiskLines bounds how many devices the disk panel lists.
+const maxDiskLines = 8
+
+// diskPanel lists the throughput, utilization and average latency of each device, the
+// operation rates and round trip times of the NFS mounts, then the mount state of each
+// filesystem. Latency tells a saturated fast disk from a failing
+// slow one, which utilization alone cannot; awaits above the configured threshold are
+// highlighted.
+func (m model) diskPanel() panel {
+	p := panel{collector: collectorDisk, title: "Disks"}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	// While focused, the devices get a selection marker to pick one with enter.
+	focused := m.focus == collectorDisk && m.panelFocused()
+	indent := ""
+	if focused {
+		indent = "  "
+	}
+	if len(m.data.Disks) > 0 {
+		p.lines = append(p.lines, hint(fmt.Sprintf("%s%-10s %12s %12s %5s %8s %8s", indent, "device", "read", "write", "util", "r_await", "w_await")))
+	}
+	for i, d := range m.data.Disks {
+		if i == maxDiskLines {
+			p.lines = append(p.lines, hint(fmt.Sprintf("… %d more devices", len(m.data.Disks)-maxDiskLines)))
+			break
+		}
+		if focused {
+			indent = m.linkCursor(collectorDisk, i, m.diskCursor)
+		}
+		p.lines = append(p.lines, fmt.Sprintf("%s%s %12s %12s %5s %s %s",
+			indent,
+			fit(d.Name, 10),
+			m.rateCell(formatBytes(uint64(d.ReadRate))+"/s"),
+			m.rateCell(formatBytes(uint64(d.WriteRate))+"/s"),
+			formatPercent(d.Util, 0),
+			m.viewAwait(d.ReadAwait, d.ReadOps),
+			m.viewAwait(d.WriteAwait, d.WriteOps)))
+	}
+	if m.hasCollector(collectorNFS) && len(m.data.NFS) > 0 {
+		if len(p.lines) > 0 {
+			p.lines = append(p.lines, "")
+		}
+		p.lines = append(p.lines, m.nfsLines()...)
+	}
+	if m.hasCollector(collectorFS) && len(m.data.Filesystems) > 0 {
+		if len(p.lines) > 0 {
+			p.lines = append(p.lines, "")
+		}
+		p.lines = append(p.lines, m.filesystemLines()...)
+	}
+	return p
+}
+
+// viewAwait renders an average latency in milliseconds, "--" when the device completed no
+// operations in the interval, and in red above the configured threshold.
+func (m model) viewAwait(await time.Duration, ops uint64) string {
+	if ops == 0 {
+		return fmt.Sprintf("%8s", "--")
+	}
+	value := fmt.Sprintf("%8s", formatFloat(float64(await)/float64(time.Millisecond), 1)+"ms")
+	if limit := m.cfg.Disk.AwaitWarn; limit > 0 && await > limit {
+		return m.baseStyle.Foreground(Color.Red).Render(value)
+	}
+	return value
+}
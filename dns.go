@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dnsTimeout bounds one reverse lookup so a slow resolver only delays that address.
+const dnsTimeout = 2 * time.Second
+
+// dnsTTL is how long a resolved name is kept before it is looked up again; expired
+// entries are dropped, which bounds the cache to the addresses seen recently.
+const dnsTTL = 5 * time.Minute
+
+// maxDNSLookups bounds how many lookups are started per refresh.
+const maxDNSLookups = 32
+
+// dnsEntry is the cached reverse lookup of one address. An empty name means the lookup
+// failed or is still pending; the address itself is shown meanwhile.
+type dnsEntry struct {
+	name    string
+	pending bool
+	expires time.Time
+}
+
+// dnsCache holds reverse lookups. It is only touched from Update, so it needs no locking;
+// the lookups themselves run as commands and report back with dnsResolvedMsg.
+type dnsCache struct {
+	entries map[string]dnsEntry
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{entries: map[string]dnsEntry{}}
+}
+
+// dnsResolvedMsg carries the result of a reverse lookup.
+type dnsResolvedMsg struct {
+	ip   string
+	name string
+}
+
+// name returns the resolved name of ip, or "" when it is not known.
+func (c *dnsCache) name(ip string) string {
+	return c.entries[ip].name
+}
+
+// lookups starts reverse lookups for the addresses that are neither cached nor pending,
+// and drops expired entries.
+func (c *dnsCache) lookups(ips []string, now time.Time) tea.Cmd {
+	for ip, e := range c.entries {
+		if !e.pending && now.After(e.expires) {
+			delete(c.entries, ip)
+		}
+	}
+	var cmds []tea.Cmd
+	for _, ip := range ips {
+		if _, ok := c.entries[ip]; ok || len(cmds) == maxDNSLookups {
+			continue
+		}
+		c.entries[ip] = dnsEntry{pending: true}
+		cmds = append(cmds, lookupAddrCmd(ip))
+	}
+	return tea.Batch(cmds...)
+}
+
+// store records the result of a lookup.
+func (c *dnsCache) store(msg dnsResolvedMsg, now time.Time) {
+	c.entries[msg.ip] = dnsEntry{name: msg.name, expires: now.Add(dnsTTL)}
+}
+
+// lookupAddrCmd resolves ip to a host name in the background.
+func lookupAddrCmd(ip string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+		defer cancel()
+		names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+		if err != nil || len(names) == 0 {
+			return dnsResolvedMsg{ip: ip}
+		}
+		return dnsResolvedMsg{ip: ip, name: strings.TrimSuffix(names[0], ".")}
+	}
+}
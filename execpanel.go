@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ExecPanel is one [[exec_panels]] entry of the config file: a panel showing what a
+// command prints, for site-specific data the monitor doesn't collect itself, such as
+// "zpool status -x" or a script.
+type ExecPanel struct {
+	// Title heads the panel; the command is shown when it is empty.
+	Title string `toml:"title"`
+	// Command is run by the shell, sh -c or cmd /C on Windows, so it may use pipes.
+	Command string `toml:"command"`
+	// Interval is the time between the end of one run and the start of the next; zero
+	// means defaultExecInterval.
+	Interval time.Duration `toml:"interval"`
+	// Timeout bounds each run, after which the command is killed; zero means
+	// defaultExecTimeout.
+	Timeout time.Duration `toml:"timeout"`
+	// Height is the number of output lines shown; longer output scrolls while the panel is
+	// focused. Zero means defaultExecHeight.
+	Height int `toml:"height"`
+}
+
+// Defaults of the exec panel settings left at zero.
+const (
+	defaultExecInterval = 10 * time.Second
+	defaultExecTimeout  = 5 * time.Second
+	defaultExecHeight   = 10
+)
+
+// execPanelWidth is the widest an output line is shown before it is cut.
+const execPanelWidth = 80
+
+// maxExecOutput bounds how much of a command's output is kept, so a runaway command
+// can't fill the memory.
+const maxExecOutput = 64 << 10
+
+// checkExecPanel validates one exec panel entry of the config.
+func checkExecPanel(p ExecPanel) error {
+	if strings.TrimSpace(p.Command) == "" {
+		return errors.New("command must not be empty")
+	}
+	if p.Interval != 0 && p.Interval < time.Second {
+		return fmt.Errorf("interval must be 0s or at least 1s, got %s", p.Interval)
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", p.Timeout)
+	}
+	if p.Height < 0 {
+		return fmt.Errorf("height must not be negative, got %d", p.Height)
+	}
+	return nil
+}
+
+// title returns the panel's title.
+func (p ExecPanel) title() string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return p.Command
+}
+
+func (p ExecPanel) interval() time.Duration {
+	if p.Interval > 0 {
+		return p.Interval
+	}
+	return defaultExecInterval
+}
+
+func (p ExecPanel) timeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return defaultExecTimeout
+}
+
+func (p ExecPanel) height() int {
+	if p.Height > 0 {
+		return p.Height
+	}
+	return defaultExecHeight
+}
+
+// execPanel is the state of one exec panel. It is only touched from Update; the runs
+// themselves are commands reporting back with execResultMsg.
+type execPanel struct {
+	cfg ExecPanel
+	// key names the panel for the focus and freezing, like a collector name does for the
+	// built-in panels.
+	key string
+	// lines are the output of the last run and err its failure; ran is zero until the
+	// first run finished.
+	lines []string
+	err   error
+	ran   time.Time
+	// offset is the first output line shown.
+	offset int
+}
+
+// execPanels are the exec panels of the running config. A reload that changes them
+// replaces the whole set; results of the runs of the previous set are dropped.
+type execPanels struct {
+	panels  []*execPanel
+	started bool
+}
+
+func newExecPanels(cfgs []ExecPanel) *execPanels {
+	s := &execPanels{}
+	for i, c := range cfgs {
+		s.panels = append(s.panels, &execPanel{cfg: c, key: fmt.Sprintf("exec %d", i+1)})
+	}
+	return s
+}
+
+// execResultMsg carries the output of one run of an exec panel's command.
+type execResultMsg struct {
+	panel *execPanel
+	lines []string
+	err   error
+	at    time.Time
+}
+
+// start runs every panel's command for the first time; it does nothing once they run.
+func (s *execPanels) start() tea.Cmd {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	cmds := make([]tea.Cmd, 0, len(s.panels))
+	for _, p := range s.panels {
+		cmds = append(cmds, p.runCmd(0))
+	}
+	return tea.Batch(cmds...)
+}
+
+// runCmd runs the panel's command after delay in the background.
+func (p *execPanel) runCmd(delay time.Duration) tea.Cmd {
+	cfg := p.cfg
+	run := func(time.Time) tea.Msg {
+		lines, err := runExecCommand(cfg)
+		return execResultMsg{panel: p, lines: lines, err: err, at: time.Now()}
+	}
+	if delay == 0 {
+		return func() tea.Msg { return run(time.Now()) }
+	}
+	return tea.Tick(delay, run)
+}
+
+// store records the result of a run and returns the command for the next one, or nil
+// when the panel no longer belongs to the running config. A frozen or paused panel keeps
+// its old output, though its command keeps running.
+func (s *execPanels) store(msg execResultMsg, keep bool) tea.Cmd {
+	if !slices.Contains(s.panels, msg.panel) {
+		return nil
+	}
+	p := msg.panel
+	if !keep {
+		p.lines, p.err, p.ran = msg.lines, msg.err, msg.at
+		p.offset = min(p.offset, max(len(p.lines)-p.cfg.height(), 0))
+	}
+	return p.runCmd(p.cfg.interval())
+}
+
+// execShell is the shell that runs exec panel commands.
+func execShell(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// runExecCommand runs the panel's command and returns its output lines. A failed run
+// reports the first line of stderr with the exit status; a run printing nothing but
+// stderr counts as failed too.
+func runExecCommand(cfg ExecPanel) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout())
+	defer cancel()
+	argv := execShell(cfg.Command)
+	var stdout, stderr cappedBuffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Children the shell started in the background may hold the output open after it
+	// was killed; stop waiting for them shortly after.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	lines := outputLines(stdout.buf.Bytes())
+	first, _, _ := strings.Cut(strings.TrimSpace(stderr.buf.String()), "\n")
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		err = fmt.Errorf("timed out after %s", cfg.timeout())
+	case err != nil && first != "":
+		err = fmt.Errorf("%w: %s", err, first)
+	case err == nil && len(lines) == 0 && first != "":
+		err = errors.New(first)
+	}
+	return lines, err
+}
+
+// cappedBuffer keeps the first maxExecOutput bytes written to it and discards the rest,
+// still taking them so the command isn't blocked on a full pipe. The buffer isn't
+// embedded: io.Copy would use its ReadFrom and read past the cap.
+type cappedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxExecOutput - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// outputLines splits command output into panel lines: escape sequences are dropped so
+// they can't repaint the screen, tabs are expanded and trailing blank lines removed.
+func outputLines(out []byte) []string {
+	text := strings.TrimRight(ansi.Strip(string(out)), " \t\r\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.ReplaceAll(strings.TrimRight(l, " \t\r"), "\t", "    ")
+	}
+	return lines
+}
+
+// execPanelViews builds the exec panels in config order.
+func (m model) execPanelViews() []panel {
+	if m.execPanels == nil {
+		return nil
+	}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	views := make([]panel, 0, len(m.execPanels.panels))
+	for _, p := range m.execPanels.panels {
+		v := panel{collector: p.key, title: truncate(p.cfg.title(), execPanelWidth), external: true}
+		if p.ran.IsZero() {
+			v.lines = []string{hint("running…")}
+			views = append(views, v)
+			continue
+		}
+		if p.err != nil {
+			v.lines = append(v.lines, m.baseStyle.Foreground(Color.Red).Render(truncate("error: "+p.err.Error(), execPanelWidth)))
+		}
+		height := p.cfg.height()
+		end := min(p.offset+height, len(p.lines))
+		for _, l := range p.lines[p.offset:end] {
+			v.lines = append(v.lines, truncate(l, execPanelWidth))
+		}
+		if len(p.lines) > height {
+			v.title += hint(fmt.Sprintf(" %d-%d of %d", p.offset+1, end, len(p.lines)))
+		}
+		if len(v.lines) == 0 {
+			v.lines = []string{hint("no output")}
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// focusedExecPanel returns the exec panel with the keyboard focus, nil when the focus is
+// elsewhere.
+func (m model) focusedExecPanel() *execPanel {
+	if m.execPanels == nil || !m.panelFocused() {
+		return nil
+	}
+	for _, p := range m.execPanels.panels {
+		if p.key == m.focus {
+			return p
+		}
+	}
+	return nil
+}
+
+// scrollExecPanel moves the output of the focused exec panel by step lines.
+func (m model) scrollExecPanel(step int) {
+	if p := m.focusedExecPanel(); p != nil {
+		p.offset = max(min(p.offset+step, len(p.lines)-p.cfg.height()), 0)
+	}
+}
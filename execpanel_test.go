@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	var b cappedBuffer
+	chunk := []byte(strings.Repeat("y\n", 1000))
+	for range 100 {
+		if n, err := b.Write(chunk); n != len(chunk) || err != nil {
+			t.Fatalf("Write = %d, %v; want every byte taken", n, err)
+		}
+	}
+	if b.buf.Len() != maxExecOutput {
+		t.Errorf("kept %d bytes, want %d", b.buf.Len(), maxExecOutput)
+	}
+	if !strings.HasPrefix(b.buf.String(), string(chunk)) {
+		t.Error("the first bytes written aren't the ones kept")
+	}
+}
+
+func TestRunExecCommandBounded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a Unix shell")
+	}
+	// Both yes write as fast as they can until the timeout kills them; through io.Copy,
+	// which used a buffer's ReadFrom, this once filled the memory.
+	lines, err := runExecCommand(ExecPanel{Command: "yes >&2 & yes", Timeout: 200 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if n := len(strings.Join(lines, "\n")); n > maxExecOutput {
+		t.Errorf("kept %d bytes of output, want at most %d", n, maxExecOutput)
+	}
+}
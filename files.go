@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeDoneMsg reports the outcome of a background file write.
+type writeDoneMsg struct {
+	path string
+	// what names the file in the banner once it is written, "" for none.
+	what string
+	err  error
+}
+
+// writeFileCmd returns a command that writes data to path in the background. The write is
+// registered with m.writes before the command starts so main can wait for it on exit and
+// never leave a half-written file behind. what names the file for the banner telling
+// where it was saved, "" to write it silently.
+func (m model) writeFileCmd(path string, data []byte, what string) tea.Cmd {
+	m.writes.Add(1)
+	return func() tea.Msg {
+		defer m.writes.Done()
+		return writeDoneMsg{path: path, what: what, err: writeFileAtomic(path, data)}
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers only ever see the complete file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inTempDir runs the rest of the test in an empty working directory.
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+	return tmp
+}
+
+// runWrite runs the file write cmd and hands its writeDoneMsg to m.
+func runWrite(t *testing.T, m model, cmd tea.Cmd) (model, writeDoneMsg) {
+	t.Helper()
+	msg, ok := cmd().(writeDoneMsg)
+	if !ok {
+		t.Fatalf("the command returned %T, want a writeDoneMsg", msg)
+	}
+	return send(m, msg), msg
+}
+
+func TestWriteFileCmd(t *testing.T) {
+	dir := t.TempDir()
+	m := newModel(defaultConfig())
+
+	m, msg := runWrite(t, m, m.writeFileCmd(filepath.Join(dir, "state.json"), []byte("{}\n"), ""))
+	if msg.err != nil || m.banner != "" {
+		t.Errorf("silent write: err %v, banner %q", msg.err, m.banner)
+	}
+
+	path := filepath.Join(dir, "missing", "report.txt")
+	m, msg = runWrite(t, m, m.writeFileCmd(path, []byte("x"), "report"))
+	if msg.err == nil || !m.bannerIsErr || !strings.Contains(m.banner, "could not write "+path) {
+		t.Errorf("failed write: err %v, banner %q", msg.err, m.banner)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*.tmp*")); len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestSaveScreen(t *testing.T) {
+	dir := inTempDir(t)
+	m := send(newModel(defaultConfig()), tea.WindowSizeMsg{Width: 120, Height: 30})
+	m, msg := runWrite(t, m, m.saveScreenCmd())
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if filepath.Dir(msg.path) != dir || !strings.HasPrefix(filepath.Base(msg.path), appName+"-screen-") {
+		t.Errorf("saved to %s, want a screen file in %s", msg.path, dir)
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "\x1b") || !strings.Contains(string(data), "Last update: waiting for first sample") {
+		t.Errorf("screen file isn't the plain screen:\n%s", data)
+	}
+	if want := "screen saved to " + msg.path; m.banner != want || m.bannerIsErr {
+		t.Errorf("banner = %q, want %q", m.banner, want)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// Filesystem is the usage and mount state of one mounted, device-backed filesystem.
+type Filesystem struct {
+	Mountpoint string
+	Device     string
+	Used       uint64
+	Total      uint64
+	// ReadOnly is set while the filesystem is mounted ro.
+	ReadOnly bool
+	// RemountRO is set when the kernel remounts the filesystem read-only on an error
+	// (errors=remount-ro).
+	RemountRO bool
+	// Errors is the error count the filesystem recorded; ErrorsKnown is false where the
+	// filesystem or platform doesn't expose one.
+	Errors      uint64
+	ErrorsKnown bool
+}
+
+// GetFilesystems lists the device-backed filesystems by mount point. Pseudo filesystems and
+// tmpfs, which the tmpfs panel covers, are left out.
+func GetFilesystems() ([]Filesystem, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var filesystems []Filesystem
+	seen := map[string]bool{}
+	for _, p := range partitions {
+		if p.Fstype == "tmpfs" || p.Fstype == "squashfs" || seen[p.Mountpoint] {
+			continue
+		}
+		seen[p.Mountpoint] = true
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		fs := Filesystem{
+			Mountpoint: p.Mountpoint,
+			Device:     p.Device,
+			Used:       usage.Used,
+			Total:      usage.Total,
+			ReadOnly:   slices.Contains(p.Opts, "ro"),
+			RemountRO:  slices.Contains(p.Opts, "errors=remount-ro"),
+		}
+		fs.Errors, fs.ErrorsKnown = fsErrorCount(p.Device, p.Fstype)
+		filesystems = append(filesystems, fs)
+	}
+	sort.Slice(filesystems, func(i, j int) bool {
+		return filesystems[i].Mountpoint < filesystems[j].Mountpoint
+	})
+	return filesystems, nil
+}
+
+// fsWatch follows the mount state of the filesystems over the session. A filesystem that
+// turns read-only while we watch, the classic symptom of a failing disk, and one whose
+// error count grows are reported until acknowledged.
+type fsWatch struct {
+	// readOnly is the mount state each filesystem had at the previous pass.
+	readOnly map[string]bool
+	// turnedRO are the filesystems that went from rw to ro during the session.
+	turnedRO map[string]bool
+	// errors is the error count each filesystem was last reported at.
+	errors map[string]uint64
+	// alerts are the reports not acknowledged yet, oldest first.
+	alerts []string
+}
+
+func newFSWatch() *fsWatch {
+	return &fsWatch{readOnly: map[string]bool{}, turnedRO: map[string]bool{}, errors: map[string]uint64{}}
+}
+
+// observe compares the latest filesystems against the previous pass and returns the new
+// reports, which are also kept until acknowledged. Filesystems that are already read-only
+// when first seen are not reported; they are usually mounted that way on purpose.
+func (w *fsWatch) observe(filesystems []Filesystem) []string {
+	var reports []string
+	for _, fs := range filesystems {
+		wasRO, seen := w.readOnly[fs.Mountpoint]
+		w.readOnly[fs.Mountpoint] = fs.ReadOnly
+		if seen && !wasRO && fs.ReadOnly {
+			w.turnedRO[fs.Mountpoint] = true
+			reports = append(reports, fmt.Sprintf("%s (%s) was remounted read-only", fs.Mountpoint, fs.Device))
+		}
+		// Errors only matter for filesystems the kernel would remount on an error: they
+		// tell a read-only remount is coming, or why it happened.
+		if fs.RemountRO && fs.ErrorsKnown && fs.Errors > w.errors[fs.Mountpoint] {
+			w.errors[fs.Mountpoint] = fs.Errors
+			reports = append(reports, fmt.Sprintf("%s (%s) has recorded %s filesystem errors", fs.Mountpoint, fs.Device, formatInt(fs.Errors)))
+		}
+	}
+	w.alerts = append(w.alerts, reports...)
+	return reports
+}
+
+// acknowledge clears the reports. Filesystems that turned read-only stay highlighted.
+func (w *fsWatch) acknowledge() {
+	w.alerts = nil
+}
+
+// fsAlertNotice summarises the unacknowledged filesystem reports for the footer, or
+// returns "" when there are none.
+func (m model) fsAlertNotice() string {
+	alerts := m.fsWatch.alerts
+	switch len(alerts) {
+	case 0:
+		return ""
+	case 1:
+		return alerts[0] + " · A: acknowledge"
+	}
+	return fmt.Sprintf("%s (+%d more) · A: acknowledge", alerts[len(alerts)-1], len(alerts)-1)
+}
+
+// filesystemLines renders the usage, growth and mount state of each filesystem for the disk
+// panel: the growth rate over the last hour and the change over the last day. Those that
+// turned read-only during the session, or report errors while set to remount read-only on
+// one, are red.
+func (m model) filesystemLines() []string {
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	lines := []string{hint(fmt.Sprintf("%-16s %5s %12s %10s %4s %s", "mount", "used", "growth", "24h", "mode", "errors"))}
+	now := time.Now()
+	for i, fs := range m.data.Filesystems {
+		if i == maxDiskLines {
+			lines = append(lines, hint(fmt.Sprintf("… %d more filesystems", len(m.data.Filesystems)-maxDiskLines)))
+			break
+		}
+		mode := "rw"
+		if fs.ReadOnly {
+			mode = "ro"
+		}
+		errs := "-"
+		if fs.ErrorsKnown {
+			errs = formatInt(fs.Errors)
+		}
+		line := fmt.Sprintf("%s %5s %12s %10s %4s %s", fit(fs.Mountpoint, 16), formatPercent(limitUsage(fs.Used, fs.Total), 0),
+			m.fsGrowth.hourlyGrowth(fs.Mountpoint, now), m.fsGrowth.dailyGrowth(fs.Mountpoint, now), mode, errs)
+		if m.fsWatch.turnedRO[fs.Mountpoint] || (fs.RemountRO && fs.Errors > 0) {
+			line = m.baseStyle.Foreground(Color.Red).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
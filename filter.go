@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// editSearch applies a key to a search being typed and reports whether typing goes on:
+// enter keeps the text, esc clears it, backspace deletes the last rune.
+func editSearch(text string, msg tea.KeyMsg) (string, bool) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		return text, false
+	case tea.KeyEsc:
+		return "", false
+	case tea.KeyBackspace:
+		if r := []rune(text); len(r) > 0 {
+			return string(r[:len(r)-1]), true
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		return text + string(msg.Runes), true
+	}
+	return text, true
+}
+
+// matchIndex returns the rune offset of the first case-insensitive match of filter in s,
+// or -1. Runes are lowered one at a time so the offset is valid in s itself.
+func matchIndex(s, filter string) int {
+	text, want := []rune(s), []rune(filter)
+	if len(want) == 0 {
+		return -1
+	}
+	for i := 0; i+len(want) <= len(text); i++ {
+		j := 0
+		for j < len(want) && unicode.ToLower(text[i+j]) == unicode.ToLower(want[j]) {
+			j++
+		}
+		if j == len(want) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesFilter reports whether the process name or command line contains filter.
+func matchesFilter(p ProcessInfo, filter string) bool {
+	return matchIndex(p.Name, filter) >= 0 || matchIndex(p.Cmdline, filter) >= 0
+}
+
+// filterColumns are the columns whose cells the process filter searches and highlights.
+var filterColumns = map[string]bool{columnName: true, columnCommand: true}
+
+// highlightMatch tags the first match of filter in a cell that is width cells wide. When
+// the match lies past the part of the cell that fits, the cell is shifted left behind an
+// ellipsis so the match is visible. Widths are computed before tagging, and the tags are
+// zero-width, so highlighting never changes the column widths.
+func highlightMatch(cell, filter string, width int) string {
+	i := matchIndex(cell, filter)
+	if i < 0 {
+		return cell
+	}
+	r := []rune(cell)
+	end := i + len([]rune(filter))
+	// An overlong cell is cut to width-1 cells plus an ellipsis.
+	if ansi.StringWidth(cell) > width && ansi.StringWidth(string(r[:end])) > width-1 && width > 2 {
+		from := 0
+		for from < i && ansi.StringWidth("…"+string(r[from:end])) > width-1 {
+			from++
+		}
+		r = append([]rune("…"), r[from:]...)
+		i, end = i-from+1, end-from+1
+	}
+	return string(r[:i]) + tagCell(string(r[i:end]), tagHighlight) + string(r[end:])
+}
+
+// updateProcessSearch handles typing into the process filter; the table filters as you type.
+func (m model) updateProcessSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m.interrupt()
+	}
+	m.procFilter, m.procSearching = editSearch(m.procFilter, msg)
+	m.processTable.GotoTop()
+	m.refreshRows()
+	return m, nil
+}
+
+// matchCount renders the number of filter matches, e.g. "17 matches".
+func matchCount(n int) string {
+	if n == 1 {
+		return "1 match"
+	}
+	return fmt.Sprintf("%s matches", formatInt(n))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// firewallTimeout bounds one run of nft or iptables; a ruleset with many thousand rules
+// takes a while to list.
+const firewallTimeout = 2 * time.Second
+
+// FirewallCounter is one of the firewall counters named in network.firewall_counters,
+// with its rates over the last refresh interval.
+type FirewallCounter struct {
+	Name           string
+	Packets, Bytes uint64
+	PacketRate     float64
+	ByteRate       float64
+	// RateKnown is false on the first read of the counter, and Found false while the
+	// firewall has no counter of that name.
+	RateKnown, Found bool
+}
+
+// firewallCollector reads the counters and diffs them against the previous read. Like
+// DiskIOCollector it is not safe for concurrent use.
+type firewallCollector struct {
+	names  []string
+	prev   map[string]FirewallCounter
+	prevAt time.Time
+}
+
+func newFirewallCollector(names []string) *firewallCollector {
+	return &firewallCollector{names: names}
+}
+
+// GetCounters returns the configured counters in config order.
+func (c *firewallCollector) GetCounters() ([]FirewallCounter, error) {
+	found, err := readFirewallCounters(c.names)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	elapsed := now.Sub(c.prevAt).Seconds()
+	counters := make([]FirewallCounter, 0, len(c.names))
+	for _, name := range c.names {
+		cur, ok := found[name]
+		cur.Name, cur.Found = name, ok
+		if old, ok := c.prev[name]; ok && cur.Found && elapsed > 0 {
+			cur.PacketRate = float64(delta(cur.Packets, old.Packets)) / elapsed
+			cur.ByteRate = float64(delta(cur.Bytes, old.Bytes)) / elapsed
+			cur.RateKnown = true
+		}
+		counters = append(counters, cur)
+	}
+	c.prev, c.prevAt = found, now
+	return counters, nil
+}
+
+// firewallLines renders the firewall counters for the network panel. A counter that
+// counted packets this interval is shown in yellow, since a drop counter is usually zero.
+func (m model) firewallLines() []string {
+	var lines []string
+	for _, c := range m.data.Firewall {
+		var line string
+		switch {
+		case !c.Found:
+			line = m.baseStyle.Foreground(Color.Secondary).Render(fmt.Sprintf("%-13s %9s", fit(c.Name, 13), "not found"))
+		case !c.RateKnown || m.data.ClockJump != 0:
+			line = fmt.Sprintf("%-13s %9s  %9s  total %s", fit(c.Name, 13), "-", "-", formatInt(c.Packets))
+		default:
+			line = fmt.Sprintf("%-13s %9s  %9s  total %s", fit(c.Name, 13), formatFloat(c.PacketRate, 1)+"/s", formatRate(c.ByteRate), formatInt(c.Packets))
+			if c.PacketRate > 0 {
+				line = m.baseStyle.Foreground(Color.Yellow).Render(line)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
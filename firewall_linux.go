@@ -0,0 +1,162 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// firewallSupported reports whether firewall counters are read on this platform.
+const firewallSupported = true
+
+// readFirewallCounters returns the counters of names, by name, from nftables' named
+// counters and, for the names nftables doesn't have, from the iptables rules carrying the
+// name as a comment or the chains of that name. Listing the ruleset needs CAP_NET_ADMIN,
+// so the collector fails its probe without root and the counters stay hidden.
+func readFirewallCounters(names []string) (map[string]FirewallCounter, error) {
+	if unprivileged() {
+		return nil, fmt.Errorf("listing the firewall needs root: %w", fs.ErrPermission)
+	}
+	_, nftErr := exec.LookPath("nft")
+	_, iptErr := exec.LookPath("iptables")
+	if nftErr != nil && iptErr != nil {
+		return nil, fmt.Errorf("neither nft nor iptables found: %w", fs.ErrNotExist)
+	}
+	found := map[string]FirewallCounter{}
+	if nftErr == nil {
+		nftErr = readNftCounters(names, found)
+	}
+	var missing []string
+	for _, name := range names {
+		if _, ok := found[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if iptErr != nil || len(missing) == 0 {
+		return found, nftErr
+	}
+	if err := readIptablesCounters("iptables", missing, found); err != nil {
+		if nftErr == nil {
+			return found, nil
+		}
+		return nil, err
+	}
+	// ip6tables may be missing or fail where IPv6 is off.
+	readIptablesCounters("ip6tables", missing, found)
+	return found, nil
+}
+
+// runFirewallTool runs nft or iptables with args and returns what it printed.
+func runFirewallTool(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not run %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// nftListing is the JSON of `nft -j list counters`: metainfo first, then one counter
+// object per entry.
+type nftListing struct {
+	Nftables []struct {
+		Counter *struct {
+			Table   string `json:"table"`
+			Name    string `json:"name"`
+			Packets uint64 `json:"packets"`
+			Bytes   uint64 `json:"bytes"`
+		} `json:"counter"`
+	} `json:"nftables"`
+}
+
+// readNftCounters adds the named counter objects of nftables to found. A name matches a
+// counter by its name alone, summed over the tables that have one, or as "table/name".
+func readNftCounters(names []string, found map[string]FirewallCounter) error {
+	out, err := runFirewallTool("nft", "-j", "list", "counters")
+	if err != nil {
+		return err
+	}
+	var listing nftListing
+	if err := json.Unmarshal(out, &listing); err != nil {
+		return fmt.Errorf("could not parse the nft counters: %w", err)
+	}
+	for _, entry := range listing.Nftables {
+		c := entry.Counter
+		if c == nil {
+			continue
+		}
+		for _, name := range names {
+			if name == c.Name || name == c.Table+"/"+c.Name {
+				f := found[name]
+				f.Packets += c.Packets
+				f.Bytes += c.Bytes
+				found[name] = f
+			}
+		}
+	}
+	return nil
+}
+
+// iptablesPolicy matches a chain header of `iptables -nvxL`, e.g.
+// "Chain INPUT (policy DROP 12 packets, 3456 bytes)".
+var iptablesPolicy = regexp.MustCompile(`^Chain (\S+) \(policy \S+ (\d+) packets, (\d+) bytes\)`)
+
+// readIptablesCounters adds the iptables counters of names to found: the rules whose
+// comment is the name, and the policy of the chain of that name, which counts the packets
+// no rule took.
+func readIptablesCounters(cmd string, names []string, found map[string]FirewallCounter) error {
+	out, err := runFirewallTool(cmd, "-nvxL")
+	if err != nil {
+		return err
+	}
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	add := func(name, packets, octets string) {
+		p, errP := strconv.ParseUint(packets, 10, 64)
+		b, errB := strconv.ParseUint(octets, 10, 64)
+		if errP != nil || errB != nil {
+			return
+		}
+		f := found[name]
+		f.Packets += p
+		f.Bytes += b
+		found[name] = f
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := iptablesPolicy.FindStringSubmatch(line); m != nil {
+			if wanted[m[1]] {
+				add(m[1], m[2], m[3])
+			}
+			continue
+		}
+		// Rule lines start with the packet and byte counts; -m comment puts
+		// "/* name */" among the match details at the end.
+		_, comment, ok := strings.Cut(line, "/* ")
+		if !ok {
+			continue
+		}
+		comment, _, ok = strings.Cut(comment, " */")
+		fields := strings.Fields(line)
+		if ok && wanted[comment] && len(fields) >= 2 {
+			add(comment, fields[0], fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read the %s rules: %w", cmd, err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// firewallSupported reports whether firewall counters are read on this platform.
+const firewallSupported = false
+
+// readFirewallCounters is a stub; firewall counters are only read on Linux.
+func readFirewallCounters([]string) (map[string]FirewallCounter, error) {
+	return nil, errors.New("firewall counters are only read on Linux")
+}
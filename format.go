@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// UnitPrefs controls how numbers are rendered. Every panel formats through the helpers
+// in this file so a value reads the same in the header, the tables and the side panels.
+type UnitPrefs struct {
+	// SI selects powers of 1000 (kB, MB, GB) instead of powers of 1024 (KiB, MiB, GiB).
+	SI bool
+	// Bits renders network rates in bits per second instead of bytes per second.
+	Bits bool
+	// DecimalSeparator replaces the "." in fractional numbers.
+	DecimalSeparator string
+	// ThousandsSeparator groups the digits of counts, "" for no grouping.
+	ThousandsSeparator string
+	// StartTimes shows when processes started in the Time column instead of how long they
+	// have been running.
+	StartTimes bool
+}
+
+// Units holds the active formatting preferences, set from the config and toggled at runtime.
+var Units = UnitPrefs{DecimalSeparator: ".", ThousandsSeparator: ","}
+
+// systemName returns the label of the active byte unit system.
+func (u UnitPrefs) systemName() string {
+	if u.SI {
+		return "SI"
+	}
+	return "IEC"
+}
+
+// rateName names the unit network rates are shown in, for the footer.
+func (u UnitPrefs) rateName() string {
+	if u.Bits {
+		return "bits"
+	}
+	return "bytes"
+}
+
+// scale returns the unit base and prefix labels for the active unit system.
+func (u UnitPrefs) scale() (float64, []string) {
+	if u.SI {
+		return 1000, []string{"k", "M", "G", "T", "P"}
+	}
+	return 1024, []string{"Ki", "Mi", "Gi", "Ti", "Pi"}
+}
+
+// formatFloat renders v with the given precision and the configured decimal separator.
+func formatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if Units.DecimalSeparator != "." {
+		s = strings.Replace(s, ".", Units.DecimalSeparator, 1)
+	}
+	return s
+}
+
+// integer is the set of types formatInt renders.
+type integer interface {
+	~int | ~int32 | ~int64 | ~uint | ~uint32 | ~uint64
+}
+
+// formatInt renders a count with its digits grouped in threes by the configured thousands
+// separator, e.g. "1,234,567". IDs such as PIDs are not counts and are never grouped.
+func formatInt[T integer](n T) string {
+	var s string
+	if n < 0 {
+		s = strconv.FormatInt(int64(n), 10)
+	} else {
+		s = strconv.FormatUint(uint64(n), 10)
+	}
+	sep := Units.ThousandsSeparator
+	digits := strings.TrimPrefix(s, "-")
+	if sep == "" || len(digits) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	if len(digits) < len(s) {
+		b.WriteByte('-')
+	}
+	lead := len(digits) % 3
+	if lead == 0 {
+		lead = 3
+	}
+	b.WriteString(digits[:lead])
+	for i := lead; i < len(digits); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// formatPercent renders a percentage with the given precision, including the % sign.
+func formatPercent(v float64, prec int) string {
+	return formatFloat(v, prec) + "%"
+}
+
+// scaleValue divides v by the unit base until it fits and returns the value and its prefix.
+func scaleValue(v float64) (float64, string) {
+	base, prefixes := Units.scale()
+	prefix := ""
+	for _, p := range prefixes {
+		if v < base {
+			break
+		}
+		v /= base
+		prefix = p
+	}
+	return v, prefix
+}
+
+// convertBytes returns a byte count as a value and unit pair, e.g. "1.50", "GiB".
+func convertBytes(bytes uint64) (string, string) {
+	v, prefix := scaleValue(float64(bytes))
+	if prefix == "" {
+		return strconv.FormatUint(bytes, 10), "B"
+	}
+	return formatFloat(v, 2), prefix + "B"
+}
+
+// formatBytes renders a byte count as a single string, e.g. "1.50 GiB".
+func formatBytes(bytes uint64) string {
+	value, unit := convertBytes(bytes)
+	return value + " " + unit
+}
+
+// formatRate renders a network throughput given in bytes per second, honouring the bits preference.
+func formatRate(bytesPerSec float64) string {
+	if bytesPerSec < 0 {
+		bytesPerSec = 0
+	}
+	suffix := "B/s"
+	if Units.Bits {
+		bytesPerSec *= 8
+		suffix = "b/s"
+	}
+	v, prefix := scaleValue(bytesPerSec)
+	if prefix == "" {
+		return fmt.Sprintf("%.0f %s", v, suffix)
+	}
+	return formatFloat(v, 2) + " " + prefix + suffix
+}
+
+// formatCPUTime renders cumulative CPU time like top's TIME+ column: m:ss.cc below an hour
+// and h:mm:ss from an hour on, so long-running processes don't overflow the column.
+func formatCPUTime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	// Built by hand rather than with Sprintf: the column is formatted for every process on
+	// every refresh.
+	b := make([]byte, 0, 16)
+	if d >= time.Hour {
+		b = strconv.AppendInt(b, int64(d/time.Hour), 10)
+		b = append(b, ':')
+		b = appendTwoDigits(b, int((d%time.Hour)/time.Minute))
+		b = append(b, ':')
+		b = appendTwoDigits(b, int((d%time.Minute)/time.Second))
+		return string(b)
+	}
+	b = strconv.AppendInt(b, int64(d/time.Minute), 10)
+	b = append(b, ':')
+	b = appendTwoDigits(b, int((d%time.Minute)/time.Second))
+	b = append(b, Units.DecimalSeparator...)
+	b = appendTwoDigits(b, int((d%time.Second)/(10*time.Millisecond)))
+	return string(b)
+}
+
+// appendTwoDigits appends n, below 100, zero-padded to two digits.
+func appendTwoDigits(b []byte, n int) []byte {
+	return append(b, byte('0'+n/10), byte('0'+n%10))
+}
+
+// formatAge renders an elapsed time compactly with its two largest units, e.g. "45s", "20m" or "2h5m".
+func formatAge(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+	switch {
+	case d < time.Minute:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	case d < time.Hour:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Hour < time.Minute:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	default:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h" + strconv.FormatInt(int64((d%time.Hour)/time.Minute), 10) + "m"
+	}
+}
+
+// formatStartTime renders when a process started: the time of day for today, the date
+// otherwise, with the year once it isn't this year's.
+func formatStartTime(start, now time.Time) string {
+	start = start.Local()
+	y, mo, d := start.Date()
+	ny, nmo, nd := now.Local().Date()
+	switch {
+	case y == ny && mo == nmo && d == nd:
+		return start.Format("15:04")
+	case y == ny:
+		return start.Format("Jan 2")
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+// timeCell renders the Time column from a process's create time, as its running time or
+// its start time depending on Units.StartTimes.
+func timeCell(p ProcessInfo) string {
+	if p.CreateTime == 0 {
+		return "-"
+	}
+	start := time.UnixMilli(p.CreateTime)
+	if Units.StartTimes {
+		return formatStartTime(start, time.Now())
+	}
+	return formatAge(time.Since(start))
+}
+
+// truncate shortens s to at most width terminal cells, marking the cut with an ellipsis.
+// Widths are display cells rather than bytes or runes, so CJK characters and most emoji
+// count double and combined emoji are never split.
+func truncate(s string, width int) string {
+	if ansi.StringWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return ansi.Truncate(s, width, "")
+	}
+	return ansi.Truncate(s, width, "…")
+}
+
+// fit truncates or pads s to exactly width terminal cells, for fixed-width columns where
+// fmt's %-10s would pad by runes and misalign wide characters.
+func fit(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+func init() {
+	// The bubbles table truncates cells with runewidth, which counts East Asian ambiguous
+	// characters as double width under CJK locales, while lipgloss pads them as single.
+	// Measure them the same way so cells are never cut short of the column width.
+	runewidth.DefaultCondition.EastAsianWidth = false
+}
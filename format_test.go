@@ -0,0 +1,233 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// withUnits sets the package-wide formatting preferences for the rest of the test.
+func withUnits(t *testing.T, u UnitPrefs) {
+	old := Units
+	Units = u
+	t.Cleanup(func() { Units = old })
+}
+
+var (
+	iecUnits = UnitPrefs{DecimalSeparator: ".", ThousandsSeparator: ","}
+	siUnits  = UnitPrefs{SI: true, DecimalSeparator: ".", ThousandsSeparator: ","}
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		units UnitPrefs
+		bytes uint64
+		want  string
+	}{
+		{iecUnits, 0, "0 B"},
+		{iecUnits, 1023, "1023 B"},
+		{iecUnits, 1024, "1.00 KiB"},
+		{iecUnits, 1536, "1.50 KiB"},
+		{iecUnits, 3 << 29, "1.50 GiB"},
+		{iecUnits, 5 << 40, "5.00 TiB"},
+		{siUnits, 999, "999 B"},
+		{siUnits, 1000, "1.00 kB"},
+		{siUnits, 1536, "1.54 kB"},
+		{siUnits, 1_500_000, "1.50 MB"},
+		{siUnits, 3 << 29, "1.61 GB"},
+		{UnitPrefs{DecimalSeparator: ","}, 1536, "1,50 KiB"},
+	}
+	for _, tt := range tests {
+		withUnits(t, tt.units)
+		if got := formatBytes(tt.bytes); got != tt.want {
+			t.Errorf("formatBytes(%d) with %s = %q, want %q", tt.bytes, tt.units.systemName(), got, tt.want)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	bits := UnitPrefs{SI: true, Bits: true, DecimalSeparator: "."}
+	tests := []struct {
+		units UnitPrefs
+		rate  float64
+		want  string
+	}{
+		{iecUnits, -5, "0 B/s"},
+		{iecUnits, 512, "512 B/s"},
+		{iecUnits, 2048, "2.00 KiB/s"},
+		{siUnits, 2048, "2.05 kB/s"},
+		{bits, 100, "800 b/s"},
+		{bits, 125_000, "1.00 Mb/s"},
+		{UnitPrefs{Bits: true, DecimalSeparator: "."}, 128, "1.00 Kib/s"},
+	}
+	for _, tt := range tests {
+		withUnits(t, tt.units)
+		if got := formatRate(tt.rate); got != tt.want {
+			t.Errorf("formatRate(%v) with %+v = %q, want %q", tt.rate, tt.units, got, tt.want)
+		}
+	}
+}
+
+func TestSeparators(t *testing.T) {
+	tests := []struct {
+		decimal, thousands string
+		int                string
+		float, cpuTime     string
+	}{
+		{".", ",", "1,234,567", "12.35%", "1:01.50"},
+		{",", ".", "1.234.567", "12,35%", "1:01,50"},
+		{",", " ", "1 234 567", "12,35%", "1:01,50"},
+		{".", "", "1234567", "12.35%", "1:01.50"},
+		{",", "\u202f", "1\u202f234\u202f567", "12,35%", "1:01,50"},
+	}
+	for _, tt := range tests {
+		withUnits(t, UnitPrefs{DecimalSeparator: tt.decimal, ThousandsSeparator: tt.thousands})
+		if got := formatInt(1234567); got != tt.int {
+			t.Errorf("formatInt(1234567) with %q = %q, want %q", tt.thousands, got, tt.int)
+		}
+		if got := formatPercent(12.345, 2); got != tt.float {
+			t.Errorf("formatPercent(12.345, 2) with %q = %q, want %q", tt.decimal, got, tt.float)
+		}
+		if got := formatCPUTime(61*time.Second + 500*time.Millisecond); got != tt.cpuTime {
+			t.Errorf("formatCPUTime(1m1.5s) with %q = %q, want %q", tt.decimal, got, tt.cpuTime)
+		}
+	}
+
+	withUnits(t, iecUnits)
+	for n, want := range map[int64]string{0: "0", 999: "999", 1000: "1,000", 123456: "123,456", -1234: "-1,234", -999: "-999"} {
+		if got := formatInt(n); got != want {
+			t.Errorf("formatInt(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// TestGroupedColumnAlignment renders a process table whose FDs column holds grouped
+// numbers of every length, with separators of one and of several bytes.
+func TestGroupedColumnAlignment(t *testing.T) {
+	cols := selectColumns([]string{columnPID, columnName, columnFDs})
+	fds := []int{7, 1234, 98765, 1234567, 12345678}
+	for _, sep := range []string{",", " ", "", "\u202f"} {
+		withUnits(t, UnitPrefs{DecimalSeparator: ".", ThousandsSeparator: sep})
+		var rows []table.Row
+		for i, n := range fds {
+			rows = append(rows, processRow(ProcessInfo{PID: int32(i + 1), Name: "worker", NumFDs: int32(n), FDsKnown: true}, cols))
+		}
+		columns := tableColumns(cols, rows, "", nil)
+		want := cellWidth(formatInt(fds[len(fds)-1]))
+		if got := columns[2].Width; got != want {
+			t.Errorf("FDs column with %q is %d cells wide, want %d", sep, got, want)
+		}
+
+		tbl := table.New(table.WithColumns(columns), table.WithRows(rows), table.WithHeight(len(rows)+1))
+		lines := strings.Split(ansi.Strip(tbl.View()), "\n")
+		width := lipgloss.Width(lines[0])
+		for i, line := range lines {
+			if w := lipgloss.Width(line); w != width {
+				t.Errorf("line %d with %q is %d cells wide, want %d: %q", i, sep, w, width, line)
+			}
+		}
+		for _, n := range fds {
+			if cell := formatInt(n); !strings.Contains(tbl.View(), cell) {
+				t.Errorf("%q is cut in the table with %q", cell, sep)
+			}
+		}
+	}
+}
+
+// TestByteCountEverywhere checks that one byte count reads the same in the process table,
+// the header and the headless output.
+func TestByteCountEverywhere(t *testing.T) {
+	const bytes = 3 << 29
+	for _, units := range []UnitPrefs{iecUnits, siUnits, {DecimalSeparator: ",", ThousandsSeparator: "."}} {
+		withUnits(t, units)
+		want := formatBytes(bytes)
+
+		p := ProcessInfo{PID: 1, Name: "postgres", Memory: bytes}
+		mem, _ := columnByID(columnMem)
+		if got := mem.cell(p); got != want {
+			t.Errorf("MEM column = %q, want %q", got, want)
+		}
+
+		m := newModel(defaultConfig())
+		m.width = 200
+		m.data.Mem.Used = bytes
+		if header := ansi.Strip(m.viewHeader()); !strings.Contains(header, "used: "+want) {
+			t.Errorf("header doesn't show %q:\n%s", "used: "+want, header)
+		}
+
+		var out strings.Builder
+		snap := Snapshot{Procs: []ProcessInfo{p}}
+		snap.Mem.Used, snap.Mem.Total = bytes, bytes
+		writeBatch(&out, snap, selectColumns([]string{columnPID, columnName, columnMem}), columnMem, 0)
+		summary, rows, _ := strings.Cut(out.String(), "\n")
+		if !strings.Contains(summary, "("+want+" / "+want+")") {
+			t.Errorf("headless summary doesn't show %q: %s", want, summary)
+		}
+		if !strings.Contains(rows, "postgres  "+want) {
+			t.Errorf("headless table doesn't show %q:\n%s", want, rows)
+		}
+	}
+}
+
+// widthCorpus are strings whose length in bytes, runes and terminal cells all differ.
+var widthCorpus = []string{
+	"postgres",
+	"数据库服务器进程",
+	"a数b据c库",
+	"👩‍💻 dev server",
+	"👨‍👩‍👧‍👦👨‍👩‍👧‍👦👨‍👩‍👧‍👦",
+	"🇩🇪🇫🇷 flags",
+	"café résumé",
+	"café résumé",
+	"Z͓͑͒a͔l͕g͖o",
+	"ｆｕｌｌｗｉｄｔｈ",
+	"",
+}
+
+func TestTruncateWidth(t *testing.T) {
+	for _, s := range widthCorpus {
+		full := ansi.StringWidth(s)
+		for width := 0; width <= full+2; width++ {
+			got := truncate(s, width)
+			if w := ansi.StringWidth(got); w > width {
+				t.Errorf("truncate(%q, %d) = %q, %d cells wide", s, width, got, w)
+			}
+			if full <= width && got != s {
+				t.Errorf("truncate(%q, %d) = %q, want it whole", s, width, got)
+			}
+			if full > width && width > 1 && !strings.HasSuffix(got, "…") {
+				t.Errorf("truncate(%q, %d) = %q, want the cut marked", s, width, got)
+			}
+			if full > width && width > 1 && !cutAtGrapheme(s, strings.TrimSuffix(got, "…")) {
+				t.Errorf("truncate(%q, %d) = %q splits a character", s, width, got)
+			}
+
+			if got := fit(s, width); ansi.StringWidth(got) != width {
+				t.Errorf("fit(%q, %d) = %q, %d cells wide", s, width, got, ansi.StringWidth(got))
+			}
+		}
+	}
+}
+
+// cutAtGrapheme reports whether kept is s cut between two characters: not before a
+// combining mark, next to a joiner or within a flag.
+func cutAtGrapheme(s, kept string) bool {
+	rest, ok := strings.CutPrefix(s, kept)
+	if !ok {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(kept)
+	next, _ := utf8.DecodeRuneInString(rest)
+	if last == '\u200d' || unicode.Is(unicode.Mn, next) || next == '\u200d' || next == '\ufe0f' {
+		return false
+	}
+	// Flags are pairs of regional indicators.
+	flag := func(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+	return !flag(next) || !flag(last) || utf8.RuneCountInString(kept)%2 == 0
+}
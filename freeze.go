@@ -0,0 +1,138 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Areas that take the keyboard focus and can be frozen. Panels use their collector name.
+const (
+	areaProcesses = collectorProcesses
+	areaHeader    = "header"
+)
+
+// frozenTag marks a frozen area in its title.
+const frozenTag = " [frozen]"
+
+// focusOrder lists the focusable areas in tab order: the process table, the header, then
+// every panel currently shown. Panels fed by the process list, such as the watched
+// processes, go with the process table.
+func (m model) focusOrder() []string {
+	order := []string{areaProcesses, areaHeader}
+	for _, p := range m.panels() {
+		if len(p.lines) > 0 && p.collector != areaProcesses {
+			order = append(order, p.collector)
+		}
+	}
+	return order
+}
+
+// nextFocus returns the area after the focused one in tab order.
+func (m model) nextFocus() string {
+	order := m.focusOrder()
+	i := slices.Index(order, m.focus)
+	return order[(i+1)%len(order)]
+}
+
+// panelFocused reports whether the keyboard focus is on one of the panels.
+func (m model) panelFocused() bool {
+	return m.focus != areaProcesses && m.focus != areaHeader
+}
+
+// isFrozen reports whether the area keeps showing old data.
+func (m model) isFrozen(area string) bool {
+	_, ok := m.frozen[area]
+	return ok
+}
+
+// toggleFreeze freezes the area on the data it currently shows, or thaws it so it shows
+// the latest data right away. Collection and history carry on either way.
+func (m *model) toggleFreeze(area string) {
+	if m.isFrozen(area) {
+		delete(m.frozen, area)
+	} else {
+		m.frozen[area] = m.data
+	}
+	if area == areaProcesses {
+		m.refreshRows()
+	}
+}
+
+// togglePause freezes or thaws the whole screen, graphs included.
+func (m *model) togglePause() {
+	if m.paused != nil {
+		m.paused = nil
+	} else {
+		snap := m.data
+		m.paused = &snap
+		m.pausedAt = time.Now()
+	}
+	m.refreshRows()
+}
+
+// snapshotFor returns the data the area shows: the paused or frozen snapshot, or the latest one.
+func (m model) snapshotFor(area string) Snapshot {
+	if m.paused != nil {
+		return *m.paused
+	}
+	if snap, ok := m.frozen[area]; ok {
+		return snap
+	}
+	return m.data
+}
+
+// withData returns a copy of the model whose data is what the area shows, so the area's
+// view functions render frozen data unchanged.
+func (m model) withData(area string) model {
+	m.data = m.snapshotFor(area)
+	return m
+}
+
+// updatePanelFocus handles keys while a panel is focused: space freezes or thaws it, tab
+// moves on and esc or q returns to the table. The audit panel also has a selection to hide,
+// the disk and interfaces panels one to filter the process table by, and the exec panels
+// scroll.
+func (m model) updatePanelFocus(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case " ":
+		m.toggleFreeze(m.focus)
+	case "tab":
+		m.focus = m.nextFocus()
+	// Moves the selection of the audit, disk or interfaces panel, or scrolls the output of
+	// an exec panel; x hides the selected audit finding.
+	case "up", "k", "down", "j":
+		step := 1
+		if msg.String() == "up" || msg.String() == "k" {
+			step = -1
+		}
+		if m.focus == collectorAudit {
+			m.moveAuditCursor(step)
+		}
+		m.moveLinkCursor(step)
+		m.scrollExecPanel(step)
+	case "x":
+		if m.focus == collectorAudit {
+			m.hideAuditFinding()
+		}
+	// Filters the process table to the processes using the selected disk or interface.
+	case "enter":
+		return m, m.linkSelected()
+	case "esc", "q":
+		m.focus = areaProcesses
+	case "p":
+		m.togglePause()
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// frozenBadge returns the frozen tag for the area, or an empty string while it is live.
+func (m model) frozenBadge(area string) string {
+	if !m.isFrozen(area) {
+		return ""
+	}
+	return m.baseStyle.Foreground(Color.Highlight).Bold(false).Render(frozenTag)
+}
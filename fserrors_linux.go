@@ -0,0 +1,33 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fsErrorCount reads the error count ext4 keeps in sysfs for the device, which also
+// covers ext2 and ext3 mounted by the ext4 driver. Other filesystems don't expose one.
+func fsErrorCount(device, fstype string) (uint64, bool) {
+	switch fstype {
+	case "ext2", "ext3", "ext4":
+	default:
+		return 0, false
+	}
+	// sysfs names the device by its kernel name, e.g. dm-0 for /dev/mapper/root.
+	if real, err := filepath.EvalSymlinks(device); err == nil {
+		device = real
+	}
+	data, err := os.ReadFile(filepath.Join("/sys/fs/ext4", filepath.Base(device), "errors_count"))
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// fsErrorCount is only read on Linux, where ext4 exposes the count in sysfs.
+func fsErrorCount(device, fstype string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package main
+
+import "time"
+
+// fsSampleEvery is how often the used bytes of each filesystem are recorded for its growth;
+// usage moves slowly, so finer samples would only cost memory and history rows.
+const fsSampleEvery = time.Minute
+
+// fsGrowthSpan is how far back the growth history of a filesystem reaches.
+const fsGrowthSpan = 24 * time.Hour
+
+// fsMinGrowthSpan is the history a growth needs before it is shown; a single minute of
+// writes says little about the hour.
+const fsMinGrowthSpan = 5 * time.Minute
+
+// fsSample is the used bytes of one filesystem at one moment.
+type fsSample struct {
+	at         time.Time
+	mountpoint string
+	device     string
+	used       uint64
+}
+
+// fsHistory is the usage history of one mount, oldest first.
+type fsHistory struct {
+	device  string
+	samples []fsSample
+}
+
+// fsGrowth keeps the used bytes of each filesystem once a minute for the last day, so the
+// disk panel can tell a disk that has sat at 85% for months from one that filled up today.
+// A mount that disappears loses its history, and one that reappears, or is mounted from
+// another device, starts afresh.
+type fsGrowth struct {
+	mounts map[string]*fsHistory
+	// persist keeps the new samples in unsaved for the history database.
+	persist bool
+	unsaved []fsSample
+}
+
+func newFSGrowth() *fsGrowth {
+	return &fsGrowth{mounts: map[string]*fsHistory{}}
+}
+
+// seed loads the stored samples of the last fsGrowthSpan, so the growth is known right
+// away after a restart, and keeps the new samples for the store from then on.
+func (g *fsGrowth) seed(store *historyStore, now time.Time) error {
+	g.persist = true
+	samples, err := store.fsSamples(now.Add(-fsGrowthSpan))
+	if err != nil {
+		return err
+	}
+	for _, s := range samples {
+		h, ok := g.mounts[s.mountpoint]
+		if !ok || h.device != s.device {
+			h = &fsHistory{device: s.device}
+			g.mounts[s.mountpoint] = h
+		}
+		h.samples = append(h.samples, s)
+	}
+	return nil
+}
+
+// observe records the usage of the filesystems that are due a sample.
+func (g *fsGrowth) observe(filesystems []Filesystem, now time.Time) {
+	seen := make(map[string]bool, len(filesystems))
+	for _, fs := range filesystems {
+		seen[fs.Mountpoint] = true
+		h, ok := g.mounts[fs.Mountpoint]
+		if !ok || h.device != fs.Device {
+			h = &fsHistory{device: fs.Device}
+			g.mounts[fs.Mountpoint] = h
+		}
+		if n := len(h.samples); n > 0 && now.Sub(h.samples[n-1].at) < fsSampleEvery {
+			continue
+		}
+		s := fsSample{at: now, mountpoint: fs.Mountpoint, device: fs.Device, used: fs.Used}
+		h.samples = append(h.samples, s)
+		cut := 0
+		for cut < len(h.samples) && h.samples[cut].at.Before(now.Add(-fsGrowthSpan)) {
+			cut++
+		}
+		h.samples = h.samples[cut:]
+		if g.persist {
+			g.unsaved = append(g.unsaved, s)
+		}
+	}
+	for mount := range g.mounts {
+		if !seen[mount] {
+			delete(g.mounts, mount)
+		}
+	}
+}
+
+// takeUnsaved returns the samples taken since the previous call.
+func (g *fsGrowth) takeUnsaved() []fsSample {
+	s := g.unsaved
+	g.unsaved = nil
+	return s
+}
+
+// change returns how many bytes the used space of a mount changed by over the last span,
+// and the time the history covers, which is shorter than span early on. ok is false until
+// the history covers fsMinGrowthSpan.
+func (g *fsGrowth) change(mount string, span time.Duration, now time.Time) (bytes int64, covered time.Duration, ok bool) {
+	h, found := g.mounts[mount]
+	if !found || len(h.samples) == 0 {
+		return 0, 0, false
+	}
+	last := h.samples[len(h.samples)-1]
+	first := last
+	for _, s := range h.samples {
+		if !s.at.Before(now.Add(-span)) {
+			first = s
+			break
+		}
+	}
+	covered = last.at.Sub(first.at)
+	if covered < fsMinGrowthSpan {
+		return 0, covered, false
+	}
+	return int64(last.used) - int64(first.used), covered, true
+}
+
+// formatSignedBytes renders a change in bytes with its sign, e.g. "+2.30 GiB".
+func formatSignedBytes(n int64) string {
+	if n < 0 {
+		return "-" + formatBytes(uint64(-n))
+	}
+	return "+" + formatBytes(uint64(n))
+}
+
+// hourlyGrowth renders the growth rate of a mount over the last hour, e.g. "+2.30 GiB/h",
+// or "-" while there is too little history.
+func (g *fsGrowth) hourlyGrowth(mount string, now time.Time) string {
+	bytes, covered, ok := g.change(mount, time.Hour, now)
+	if !ok {
+		return "-"
+	}
+	return formatSignedBytes(int64(float64(bytes)/covered.Hours())) + "/h"
+}
+
+// dailyGrowth renders the change of a mount over the last day, or over the history so
+// far once it covers more than the hour shown next to it; "-" before that.
+func (g *fsGrowth) dailyGrowth(mount string, now time.Time) string {
+	bytes, covered, ok := g.change(mount, fsGrowthSpan, now)
+	if !ok || covered <= time.Hour {
+		return "-"
+	}
+	return formatSignedBytes(bytes)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nvidiaSMITimeout bounds one run of nvidia-smi, which can hang for seconds while a driver
+// initialises a GPU.
+const nvidiaSMITimeout = 2 * time.Second
+
+// errNoGPU is returned by listGPUs on a host with nvidia-smi but no GPU it can query.
+var errNoGPU = errors.New("no NVIDIA GPU found")
+
+// gpuUsage is the GPU memory a process holds, summed over the GPUs it uses.
+type gpuUsage struct {
+	mem  uint64
+	gpus []int
+}
+
+// gpuDetected reports whether nvidia-smi is installed, the cheap check behind
+// columns.gpu = "auto"; the startup probe then makes sure it finds a GPU.
+func gpuDetected() bool {
+	_, err := exec.LookPath("nvidia-smi")
+	return err == nil
+}
+
+// runNvidiaSMI runs nvidia-smi with a CSV query and returns its non-empty lines split
+// into trimmed fields.
+func runNvidiaSMI(args ...string) ([][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nvidiaSMITimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi", append(args, "--format=csv,noheader,nounits")...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not run nvidia-smi: %w", err)
+	}
+	var rows [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		rows = append(rows, fields)
+	}
+	return rows, scanner.Err()
+}
+
+// listGPUs maps the UUID of every GPU to the index nvidia-smi and CUDA_VISIBLE_DEVICES
+// number it by.
+func listGPUs() (map[string]int, error) {
+	rows, err := runNvidiaSMI("--query-gpu=index,uuid")
+	if err != nil {
+		return nil, err
+	}
+	gpus := map[string]int{}
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		if index, err := strconv.Atoi(row[0]); err == nil {
+			gpus[row[1]] = index
+		}
+	}
+	if len(gpus) == 0 {
+		return nil, errNoGPU
+	}
+	return gpus, nil
+}
+
+// gpuAttributor reads the GPU memory of every compute process from nvidia-smi's
+// per-process accounting. Graphics-only clients such as a desktop compositor are not
+// listed there; CUDA and other compute workloads are.
+type gpuAttributor struct {
+	// gpus maps GPU UUIDs to their index, read on first use; noGPU is set once that read
+	// failed, so a host without a usable GPU runs nvidia-smi only once.
+	gpus  map[string]int
+	noGPU bool
+}
+
+func newGPUAttributor() *gpuAttributor {
+	return &gpuAttributor{}
+}
+
+// usage returns the GPU memory of each process using a GPU, keyed by PID, or nil when the
+// GPUs could not be listed.
+func (a *gpuAttributor) usage() (map[int32]gpuUsage, error) {
+	if a.noGPU {
+		return nil, nil
+	}
+	if a.gpus == nil {
+		gpus, err := listGPUs()
+		if err != nil {
+			a.noGPU = true
+			return nil, err
+		}
+		a.gpus = gpus
+	}
+	rows, err := runNvidiaSMI("--query-compute-apps=pid,gpu_uuid,used_memory")
+	if err != nil {
+		return nil, err
+	}
+	usage := map[int32]gpuUsage{}
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		pid, err := strconv.ParseInt(row[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		u := usage[int32(pid)]
+		// used_memory is in MiB, or "[N/A]" where the driver doesn't account it per process.
+		if mib, err := strconv.ParseUint(row[2], 10, 64); err == nil {
+			u.mem += mib << 20
+		}
+		if index, ok := a.gpus[row[1]]; ok && !slices.Contains(u.gpus, index) {
+			u.gpus = append(u.gpus, index)
+			slices.Sort(u.gpus)
+		}
+		usage[int32(pid)] = u
+	}
+	return usage, nil
+}
+
+// gpuCell renders the GPU-MEM column: the GPUs a process holds memory on, then the total,
+// e.g. "1: 2.0 GiB" or "0,1: 9.5 GiB".
+func gpuCell(p ProcessInfo) string {
+	if len(p.GPUs) == 0 && p.GPUMem == 0 {
+		return "-"
+	}
+	ids := make([]string, len(p.GPUs))
+	for i, g := range p.GPUs {
+		ids[i] = strconv.Itoa(g)
+	}
+	if len(ids) == 0 {
+		return formatBytes(p.GPUMem)
+	}
+	return strings.Join(ids, ",") + ": " + formatBytes(p.GPUMem)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparkBlocks are the block characters used for the eight levels of a sparkline cell.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// bucketize spreads samples between from and to over width cells by timestamp, so graphs
+// drawn with the same bounds line up in time. Each cell holds the maximum of its samples,
+// or NaN when no sample falls into it.
+func bucketize(samples []sample, from, to time.Time, width int) []float64 {
+	cells := make([]float64, width)
+	for i := range cells {
+		cells[i] = math.NaN()
+	}
+	span := to.Sub(from)
+	if width <= 0 || span <= 0 {
+		return cells
+	}
+	for _, smp := range samples {
+		i := cellIndex(smp.at, from, span, width)
+		if i < 0 {
+			continue
+		}
+		if math.IsNaN(cells[i]) || smp.value > cells[i] {
+			cells[i] = smp.value
+		}
+	}
+	return cells
+}
+
+// cellIndex returns the cell that time at falls into, or -1 when it lies outside [from, from+span].
+func cellIndex(at, from time.Time, span time.Duration, width int) int {
+	offset := at.Sub(from)
+	if offset < 0 || offset > span {
+		return -1
+	}
+	return min(int(float64(offset)/float64(span)*float64(width)), width-1)
+}
+
+// sparkline renders cells as block characters scaled so hi is a full block. The cell at
+// marker, if any, is drawn in the highlight color to pin the session peak.
+// Each cell is wrapped in the escape sequences of its style, worked out once per call
+// rather than rendered cell by cell, which made the graphs the costliest part of a frame.
+func sparkline(cells []float64, hi float64, marker int, baseStyle lipgloss.Style) string {
+	var b strings.Builder
+	linePrefix, lineSuffix := styleAffixes(baseStyle.Foreground(Color.Green))
+	peakPrefix, peakSuffix := styleAffixes(baseStyle.Foreground(Color.Red))
+	for i, v := range cells {
+		ch := " "
+		if !math.IsNaN(v) {
+			level := 0
+			if hi > 0 {
+				level = int(v / hi * float64(len(sparkBlocks)-1))
+			}
+			ch = string(sparkBlocks[min(max(level, 0), len(sparkBlocks)-1)])
+		}
+		if i == marker {
+			b.WriteString(peakPrefix + ch + peakSuffix)
+		} else {
+			b.WriteString(linePrefix + ch + lineSuffix)
+		}
+	}
+	return b.String()
+}
+
+// styleAffixes returns the escape sequences style.Render puts around a single cell, so
+// many cells can be wrapped without a Render each.
+func styleAffixes(style lipgloss.Style) (prefix, suffix string) {
+	prefix, suffix, _ = strings.Cut(style.Render("x"), "x")
+	return prefix, suffix
+}
+
+// windowBounds returns the time range the graphs cover for the selected window.
+func (m model) windowBounds(now time.Time) (time.Time, time.Time) {
+	if m.historyWindow == windowSession {
+		return m.started, now
+	}
+	return now.Add(-m.historyWindow.duration()), now
+}
+
+// viewGraphs renders the CPU and memory sparklines for the selected window with a marker
+// and label at the session peak, which stays visible after the spike scrolls out of view.
+func (m model) viewGraphs() string {
+	now := m.viewAt()
+	from, to := m.windowBounds(now)
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	label := m.baseStyle.Width(5).Bold(true).Render
+
+	const peakWidth = 28
+	width := max(m.width-5-peakWidth, 10)
+
+	row := func(name string, s *series) string {
+		marker := -1
+		if s.count > 0 {
+			marker = cellIndex(s.peak.at, from, to.Sub(from), width)
+		}
+		graph := sparkline(bucketize(s.samples(m.historyWindow, now), from, to, width), 100, marker, m.baseStyle)
+		peak := ""
+		if s.count > 0 {
+			peak = fmt.Sprintf(" peak %s %s ago", formatPercent(s.peak.value, 1), formatAge(now.Sub(s.peak.at)))
+		}
+		return label(name) + graph + hint(peak)
+	}
+
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		row("CPU", m.cpuHistory),
+		row("MEM", m.memHistory),
+		hint(fmt.Sprintf("window: %s · w: change", m.historyWindow)),
+	))
+}
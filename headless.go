@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// headless collects snapshots outside the TUI, for the batch, json, check and serve
+// subcommands. It uses the same collectors as the TUI, so every mode reports the same numbers.
+type headless struct {
+	collectors []Collector
+	snap       Snapshot
+	interval   time.Duration
+	// statsd receives every snapshot collected after prime, nil without -statsd.
+	statsd *statsdClient
+}
+
+func newHeadless(cfg Config, interval time.Duration) *headless {
+	return &headless{collectors: defaultCollectors(cfg.Columns.processOptions()), interval: interval}
+}
+
+// collect runs one collection pass and returns the updated snapshot.
+func (h *headless) collect() Snapshot {
+	runCollectors(h.collectors, &h.snap, nil, h.interval)
+	h.statsd.push(h.snap)
+	return h.snap
+}
+
+// prime runs a first pass and waits one interval, so rates such as per-process CPU% and
+// disk throughput in the next pass cover a real interval instead of the process lifetime.
+// The first pass isn't pushed to StatsD, since its rates are not meaningful yet.
+func (h *headless) prime() {
+	runCollectors(h.collectors, &h.snap, nil, h.interval)
+	time.Sleep(h.interval)
+}
+
+// pushStatsd sends every later snapshot to the StatsD agent at addr; "" sends nothing.
+func (h *headless) pushStatsd(addr string, cfg Config) error {
+	if addr == "" {
+		return nil
+	}
+	client, err := newStatsdClient(addr, cfg.Statsd)
+	if err != nil {
+		return err
+	}
+	h.statsd = client
+	return nil
+}
+
+// intervalFlag returns the refresh interval given on the command line, or the configured one.
+func intervalFlag(d time.Duration, cfg Config) time.Duration {
+	if d <= 0 {
+		return cfg.Interval
+	}
+	return max(d, minInterval)
+}
+
+// runBatch prints plain-text snapshots of the header and the process table, for logs and pipes.
+func runBatch(args []string) int {
+	fs := newFlagSet("batch")
+	configSrc := configFlag(fs)
+	iterations := fs.Int("n", 1, "number of snapshots to print, 0 to run until interrupted")
+	interval := fs.Duration("interval", 0, "time between snapshots (default: the configured interval)")
+	top := fs.Int("top", 20, "number of processes per snapshot, 0 for all")
+	sortBy := fs.String("sort", columnCPU, "column to sort the processes by")
+	statsdAddr := statsdFlag(fs)
+	fs.Parse(args)
+
+	cfg := loadConfigOrExit(*configSrc)
+	if col, ok := columnByID(*sortBy); !ok || col.less == nil {
+		fmt.Fprintf(os.Stderr, "batch: cannot sort by %q\n", *sortBy)
+		return 2
+	}
+	h := newHeadless(cfg, intervalFlag(*interval, cfg))
+	if err := h.pushStatsd(*statsdAddr, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 2
+	}
+	cols := selectColumns(cfg.Columns.columnIDs(cfg.Columns.processOptions()))
+
+	h.prime()
+	for i := 0; *iterations == 0 || i < *iterations; i++ {
+		if i > 0 {
+			time.Sleep(h.interval)
+			fmt.Println()
+		}
+		writeBatch(os.Stdout, h.collect(), cols, *sortBy, *top)
+	}
+	return 0
+}
+
+// writeBatch writes one snapshot to out: a summary line followed by the process table.
+func writeBatch(out io.Writer, s Snapshot, cols []processColumn, sortBy string, top int) {
+	summary := []string{
+		time.Now().Format(time.DateTime),
+		"cpu " + formatPercent(100-s.CPU.Idle, 1),
+		fmt.Sprintf("mem %s (%s / %s)", formatPercent(s.Mem.UsedPercent, 1), formatBytes(s.Mem.Used), formatBytes(s.Mem.Total)),
+	}
+	if s.Load != nil {
+		summary = append(summary, fmt.Sprintf("load %s %s %s", formatFloat(s.Load.Load1, 2), formatFloat(s.Load.Load5, 2), formatFloat(s.Load.Load15, 2)))
+	}
+	summary = append(summary, fmt.Sprintf("%s processes", formatInt(len(s.Procs))))
+	fmt.Fprintln(out, strings.Join(summary, "  "))
+
+	procs := append([]ProcessInfo{}, s.Procs...)
+	sortProcesses(procs, sortBy)
+	if top > 0 && len(procs) > top {
+		procs = procs[:top]
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+	fmt.Fprintln(w, strings.Join(titles, "\t"))
+	for _, p := range procs {
+		fmt.Fprintln(w, stripTags(strings.Join(processRow(p, cols), "\t")))
+	}
+	w.Flush()
+}
+
+// jsonSnapshot is the machine-readable form of a Snapshot shared by the json and serve
+// subcommands. Rates are per second, sizes in bytes and times in seconds.
+type jsonSnapshot struct {
+	Time      time.Time      `json:"time"`
+	CPU       jsonCPU        `json:"cpu"`
+	Memory    jsonMemory     `json:"memory"`
+	Load      *jsonLoad      `json:"load,omitempty"`
+	Sockets   map[string]int `json:"sockets"`
+	Disks     []jsonDisk     `json:"disks"`
+	Limits    *jsonLimits    `json:"limits,omitempty"`
+	Processes []jsonProcess  `json:"processes"`
+}
+
+type jsonCPU struct {
+	UsagePercent float64 `json:"usage_percent"`
+	User         float64 `json:"user"`
+	System       float64 `json:"system"`
+	Idle         float64 `json:"idle"`
+	Iowait       float64 `json:"iowait"`
+}
+
+type jsonMemory struct {
+	Total       uint64         `json:"total"`
+	Used        uint64         `json:"used"`
+	Available   uint64         `json:"available"`
+	UsedPercent float64        `json:"used_percent"`
+	Writeback   *jsonWriteback `json:"writeback,omitempty"`
+}
+
+type jsonWriteback struct {
+	Dirty     uint64  `json:"dirty"`
+	Writeback uint64  `json:"writeback"`
+	DirtyRate float64 `json:"dirty_bytes_per_sec"`
+}
+
+type jsonLoad struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+type jsonDisk struct {
+	Name        string  `json:"name"`
+	ReadRate    float64 `json:"read_bytes_per_sec"`
+	WriteRate   float64 `json:"write_bytes_per_sec"`
+	UtilPercent float64 `json:"util_percent"`
+}
+
+type jsonLimits struct {
+	EntropyAvail   uint64  `json:"entropy_avail"`
+	EntropyPool    uint64  `json:"entropy_pool"`
+	FilesOpen      uint64  `json:"files_open"`
+	FilesMax       uint64  `json:"files_max"`
+	PIDs           uint64  `json:"pids"`
+	PIDMax         uint64  `json:"pid_max"`
+	InotifyWatches *uint64 `json:"inotify_watches,omitempty"`
+	InotifyMax     uint64  `json:"inotify_max_user_watches"`
+}
+
+type jsonProcess struct {
+	PID        int32   `json:"pid"`
+	PPID       int32   `json:"ppid"`
+	Name       string  `json:"name"`
+	User       string  `json:"user"`
+	CPUPercent float64 `json:"cpu_percent"`
+	Memory     uint64  `json:"memory"`
+	CPUTime    float64 `json:"cpu_time"`
+	Container  string  `json:"container,omitempty"`
+}
+
+// toJSON converts s for output, with the processes in CPU order.
+func toJSON(s Snapshot, at time.Time) jsonSnapshot {
+	out := jsonSnapshot{
+		Time: at,
+		CPU: jsonCPU{
+			UsagePercent: 100 - s.CPU.Idle,
+			User:         s.CPU.User,
+			System:       s.CPU.System,
+			Idle:         s.CPU.Idle,
+			Iowait:       s.CPU.Iowait,
+		},
+		Memory: jsonMemory{
+			Total:       s.Mem.Total,
+			Used:        s.Mem.Used,
+			Available:   s.Mem.Available,
+			UsedPercent: s.Mem.UsedPercent,
+		},
+		Sockets:   s.Sockets.States,
+		Disks:     []jsonDisk{},
+		Processes: make([]jsonProcess, 0, len(s.Procs)),
+	}
+	if writebackShown() {
+		out.Memory.Writeback = &jsonWriteback{Dirty: s.Mem.Dirty, Writeback: s.Mem.WriteBack, DirtyRate: s.Mem.DirtyRate}
+	}
+	if s.Load != nil {
+		out.Load = &jsonLoad{s.Load.Load1, s.Load.Load5, s.Load.Load15}
+	}
+	if l := s.Limits; l != nil {
+		out.Limits = &jsonLimits{
+			EntropyAvail: l.EntropyAvail,
+			EntropyPool:  l.EntropyPool,
+			FilesOpen:    l.FilesOpen,
+			FilesMax:     l.FilesMax,
+			PIDs:         l.PIDs,
+			PIDMax:       l.PIDMax,
+			InotifyMax:   l.InotifyMax,
+		}
+		if l.InotifyKnown {
+			out.Limits.InotifyWatches = &l.InotifyWatches
+		}
+	}
+	for _, d := range s.Disks {
+		out.Disks = append(out.Disks, jsonDisk{d.Name, d.ReadRate, d.WriteRate, d.Util})
+	}
+	procs := append([]ProcessInfo{}, s.Procs...)
+	sortProcesses(procs, columnCPU)
+	for _, p := range procs {
+		out.Processes = append(out.Processes, jsonProcess{
+			PID:        p.PID,
+			PPID:       p.PPID,
+			Name:       p.Name,
+			User:       p.Username,
+			CPUPercent: p.CPUPercent,
+			Memory:     p.Memory,
+			CPUTime:    p.CPUTime.Seconds(),
+			Container:  p.Container,
+		})
+	}
+	return out
+}
+
+// runJSON prints one JSON snapshot, or one per line with -n.
+func runJSON(args []string) int {
+	fs := newFlagSet("json")
+	configSrc := configFlag(fs)
+	iterations := fs.Int("n", 1, "number of snapshots to print, 0 to run until interrupted")
+	interval := fs.Duration("interval", 0, "time between snapshots (default: the configured interval)")
+	fs.Parse(args)
+
+	cfg := loadConfigOrExit(*configSrc)
+	h := newHeadless(cfg, intervalFlag(*interval, cfg))
+
+	enc := json.NewEncoder(os.Stdout)
+	h.prime()
+	for i := 0; *iterations == 0 || i < *iterations; i++ {
+		if i > 0 {
+			time.Sleep(h.interval)
+		}
+		if err := enc.Encode(toJSON(h.collect(), time.Now())); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpKeys lists the main key bindings shown in the help view, in the order a new user
+// is likely to need them. Views with their own keys also list them in the footer.
+var helpKeys = [][2]string{
+	{"↑/↓, j/k", "move the selection"},
+	{"enter", "process details / filter by user"},
+	{"a", "actions for the process: signal, renice, pin, copy…"},
+	{"yp, yc, yn", "copy the PID, command line or name"},
+	{"V", "save the current view / recall one (V 1-9)"},
+	{"s", "cycle the sort column"},
+	{"/", "filter by name or command line"},
+	{"z, x, M", "only zombies and D-state / above the CPU / memory warning"},
+	{":", "jump to a PID (:1234) or a port's listener (:port 8080)"},
+	{"T", "process tree (←/→: collapse/expand)"},
+	{"ctrl+←/→", "narrow or widen the sort column"},
+	{"ctrl+↑/↓", "move the divider above the table"},
+	{"R", "re-sort now while the order is held"},
+	{"D, N", "select the top disk / network process"},
+	{"u", "per-user view"},
+	{"G", "per-unit view (systemd)"},
+	{"c", "connections view"},
+	{"del, K", "kill the process / its tree"},
+	{"i", "I/O priority (details)"},
+	{"a", "CPU affinity (details)"},
+	{"tab", "focus the header and panels"},
+	{"space", "freeze the focused area"},
+	{"p", "pause all updates"},
+	{"w", "cycle the graph window"},
+	{"g", "graphs on a shared time axis (←/→: cursor)"},
+	{"H", "browse recorded history"},
+	{"S", "session statistics (e: export)"},
+	{"L", "log of the actions taken this session"},
+	{"I", "interrupts and softirqs per CPU"},
+	{"Z", "clean up zombies and CLOSE_WAIT leaks"},
+	{"A", "acknowledge filesystem alerts"},
+	{"P", "save the screen to a text file"},
+	{"U", "switch SI/IEC units"},
+	{"B", "switch network rates between bits and bytes"},
+	{"t", "show start times instead of running times"},
+	{"n", "show raw process names instead of interpreter programs"},
+	{"r", "reload the config"},
+	{"q", "quit"},
+	{"ctrl+c", "quit, or interrupt the command of the run subcommand"},
+}
+
+// viewHelp renders the key bindings and the startup capability report.
+func (m model) viewHelp() string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	key := m.baseStyle.Width(14).Render
+
+	lines := []string{title("Keys"), ""}
+	for _, k := range helpKeys {
+		lines = append(lines, key(k[0])+k[1])
+	}
+
+	lines = append(lines, "", title("Capabilities"), "")
+	if len(m.caps) == 0 {
+		lines = append(lines, hint("not probed"))
+	}
+	for _, c := range m.caps {
+		status := c.status.String()
+		switch c.status {
+		case capPartial:
+			status = m.baseStyle.Foreground(Color.Yellow).Render(status)
+		case capUnavailable:
+			status = m.baseStyle.Foreground(Color.Red).Render(status)
+		}
+		line := m.baseStyle.Width(24).Render(c.name+":") + status
+		if c.reason != "" {
+			line += hint(" (" + c.reason + ")")
+		}
+		lines = append(lines, line)
+	}
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,170 @@
+package main
+
+import "time"
+
+// sample is one observation of a metric.
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// historyWindow selects how much history the graphs show.
+type historyWindow int
+
+const (
+	window1m historyWindow = iota
+	window5m
+	window15m
+	windowSession
+)
+
+func (w historyWindow) String() string {
+	switch w {
+	case window5m:
+		return "5m"
+	case window15m:
+		return "15m"
+	case windowSession:
+		return "session"
+	default:
+		return "1m"
+	}
+}
+
+// duration returns the time span of a fixed window; the session window has none.
+func (w historyWindow) duration() time.Duration {
+	switch w {
+	case window1m:
+		return time.Minute
+	case window5m:
+		return 5 * time.Minute
+	case window15m:
+		return 15 * time.Minute
+	}
+	return 0
+}
+
+// next returns the window that follows w when cycling through the options.
+func (w historyWindow) next() historyWindow {
+	return (w + 1) % (windowSession + 1)
+}
+
+// recentSpan is how long full-resolution samples are retained, enough for the widest fixed window.
+const recentSpan = 15 * time.Minute
+
+// sessionCap bounds the number of downsampled buckets kept for the session window.
+const sessionCap = 1024
+
+// series stores the history of one metric. Samples from the last recentSpan are kept at full
+// resolution for the fixed windows. For the session window, samples are grouped into buckets
+// of step samples; whenever sessionCap buckets are reached, neighbouring buckets are merged
+// and step doubles, so memory stays bounded however long the session runs. Buckets keep the
+// maximum of their samples, not the average, so short spikes are never smoothed away.
+type series struct {
+	recent []sample
+
+	session  []sample
+	step     int
+	pending  sample
+	pendingN int
+
+	// peak is the highest sample of the session; min, max, sum and count summarise it exactly.
+	peak     sample
+	min, max float64
+	sum      float64
+	count    int
+}
+
+func newSeries() *series {
+	return &series{step: 1}
+}
+
+// add records a new sample.
+func (s *series) add(at time.Time, value float64) {
+	smp := sample{at: at, value: value}
+
+	s.recent = append(s.recent, smp)
+	cut := 0
+	for cut < len(s.recent) && s.recent[cut].at.Before(at.Add(-recentSpan)) {
+		cut++
+	}
+	s.recent = s.recent[cut:]
+
+	if s.pendingN == 0 {
+		s.pending = smp
+	} else {
+		s.pending.value = max(s.pending.value, value)
+	}
+	s.pendingN++
+	if s.pendingN == s.step {
+		s.session = append(s.session, s.pending)
+		s.pendingN = 0
+		if len(s.session) >= sessionCap {
+			s.downsample()
+		}
+	}
+
+	if s.count == 0 || value > s.peak.value {
+		s.peak = smp
+	}
+	if s.count == 0 {
+		s.min, s.max = value, value
+	}
+	s.min = min(s.min, value)
+	s.max = max(s.max, value)
+	s.sum += value
+	s.count++
+}
+
+// downsample halves the number of session buckets by merging neighbours, keeping the maximum.
+func (s *series) downsample() {
+	merged := make([]sample, 0, len(s.session)/2+1)
+	for i := 0; i < len(s.session); i += 2 {
+		b := s.session[i]
+		if i+1 < len(s.session) {
+			b.value = max(b.value, s.session[i+1].value)
+		}
+		merged = append(merged, b)
+	}
+	s.session = merged
+	s.step *= 2
+}
+
+// samples returns the samples covering the window ending at now, oldest first.
+func (s *series) samples(w historyWindow, now time.Time) []sample {
+	if w == windowSession {
+		out := s.session
+		if s.pendingN > 0 {
+			out = append(out[:len(out):len(out)], s.pending)
+		}
+		return out
+	}
+
+	return s.since(now.Add(-w.duration()))
+}
+
+// since returns the full-resolution samples taken at or after from, oldest first.
+func (s *series) since(from time.Time) []sample {
+	for i, smp := range s.recent {
+		if !smp.at.Before(from) {
+			return s.recent[i:]
+		}
+	}
+	return nil
+}
+
+// last returns the most recent sample, if any.
+func (s *series) last() (sample, bool) {
+	if len(s.recent) == 0 {
+		return sample{}, false
+	}
+	return s.recent[len(s.recent)-1], true
+}
+
+// avg returns the mean of every sample of the session.
+func (s *series) avg() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
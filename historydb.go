@@ -0,0 +1,354 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	_ "modernc.org/sqlite"
+)
+
+// historySchema creates the tables of the history database. samples holds one row per
+// tick with the headline percentages as columns for ad-hoc queries, and the full CPU and
+// memory stats as JSON so the header can be redrawn exactly.
+const historySchema = `
+PRAGMA auto_vacuum = INCREMENTAL;
+CREATE TABLE IF NOT EXISTS samples (
+	at          INTEGER PRIMARY KEY, -- milliseconds since the epoch
+	cpu_percent REAL NOT NULL,
+	mem_percent REAL NOT NULL,
+	time_wait   INTEGER NOT NULL,
+	cpu         TEXT NOT NULL,
+	mem         TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS processes (
+	at          INTEGER NOT NULL REFERENCES samples(at) ON DELETE CASCADE,
+	pid         INTEGER NOT NULL,
+	name        TEXT NOT NULL,
+	username    TEXT NOT NULL,
+	cpu_percent REAL NOT NULL,
+	memory      INTEGER NOT NULL,
+	cpu_time    INTEGER NOT NULL -- nanoseconds
+);
+CREATE INDEX IF NOT EXISTS processes_at ON processes(at);
+CREATE TABLE IF NOT EXISTS fs_usage (
+	at         INTEGER NOT NULL, -- milliseconds since the epoch
+	mountpoint TEXT NOT NULL,
+	device     TEXT NOT NULL,
+	used       INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS fs_usage_at ON fs_usage(at);
+`
+
+// historyQueue bounds the records waiting for the writer. A full queue drops samples
+// instead of blocking the UI.
+const historyQueue = 256
+
+// pruneEvery is how often samples older than the retention are deleted.
+const pruneEvery = time.Hour
+
+// historyRecord is the data of one tick written to the history database.
+type historyRecord struct {
+	at  time.Time
+	cpu cpu.TimesStat
+	mem MemStats
+	// timeWait is the TIME_WAIT socket count.
+	timeWait int
+	procs    []ProcessInfo
+	// filesystems are the usage samples taken since the previous record, once a minute.
+	filesystems []fsSample
+}
+
+// historyStore persists tick summaries into SQLite. Records are queued and written by a
+// background goroutine in one transaction per flush interval, so a 1s refresh doesn't fsync
+// every second. Write errors never reach the live view; they are kept for takeErr.
+type historyStore struct {
+	db        *sql.DB
+	retention time.Duration
+	flush     time.Duration
+
+	records chan historyRecord
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// openHistoryStore opens the history backend named by spec, currently only "sqlite:/path/db".
+// The store's goroutine reports panics to crash, which may be nil.
+func openHistoryStore(spec string, cfg HistoryConfig, crash *crashReporter) (*historyStore, error) {
+	path, ok := strings.CutPrefix(spec, "sqlite:")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("unsupported history backend %q, want sqlite:/path/db", spec)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("could not open history database %s: %w", path, err)
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create history database %s: %w", path, err)
+	}
+
+	s := &historyStore{
+		db:        db,
+		retention: cfg.Retention,
+		flush:     cfg.FlushInterval,
+		records:   make(chan historyRecord, historyQueue),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer crash.guard()
+		s.run()
+	}()
+	return s, nil
+}
+
+// record queues r for the writer without blocking.
+func (s *historyStore) record(r historyRecord) {
+	select {
+	case s.records <- r:
+	default:
+		s.setErr(errors.New("history writer is falling behind, dropped a sample"))
+	}
+}
+
+// takeErr returns the latest write error once, or nil when there was none.
+func (s *historyStore) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+func (s *historyStore) setErr(err error) {
+	slog.Error("History write failed", "error", err)
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Close writes the queued records and closes the database.
+func (s *historyStore) Close() error {
+	close(s.records)
+	<-s.done
+	return s.db.Close()
+}
+
+// run is the writer goroutine: it batches records until the flush interval passes and
+// prunes old samples periodically.
+func (s *historyStore) run() {
+	defer close(s.done)
+	flush := time.NewTicker(s.flush)
+	defer flush.Stop()
+	prune := time.NewTicker(pruneEvery)
+	defer prune.Stop()
+	s.prune()
+
+	var batch []historyRecord
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.write(batch); err != nil {
+			s.setErr(err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case r, ok := <-s.records:
+			if !ok {
+				write()
+				return
+			}
+			batch = append(batch, r)
+		case <-flush.C:
+			write()
+		case <-prune.C:
+			s.prune()
+		}
+	}
+}
+
+// write stores a batch of records in a single transaction.
+func (s *historyStore) write(batch []historyRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not write history: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, r := range batch {
+		cpuJSON, err := json.Marshal(r.cpu)
+		if err != nil {
+			return err
+		}
+		memJSON, err := json.Marshal(r.mem)
+		if err != nil {
+			return err
+		}
+		at := r.at.UnixMilli()
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO samples (at, cpu_percent, mem_percent, time_wait, cpu, mem) VALUES (?, ?, ?, ?, ?, ?)`,
+			at, 100-r.cpu.Idle, r.mem.UsedPercent, r.timeWait, string(cpuJSON), string(memJSON)); err != nil {
+			return fmt.Errorf("could not write history: %w", err)
+		}
+		for _, p := range r.procs {
+			if _, err := tx.Exec(`INSERT INTO processes (at, pid, name, username, cpu_percent, memory, cpu_time) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				at, p.PID, p.Name, p.Username, p.CPUPercent, p.Memory, int64(p.CPUTime)); err != nil {
+				return fmt.Errorf("could not write history: %w", err)
+			}
+		}
+		for _, fs := range r.filesystems {
+			if _, err := tx.Exec(`INSERT INTO fs_usage (at, mountpoint, device, used) VALUES (?, ?, ?, ?)`,
+				fs.at.UnixMilli(), fs.mountpoint, fs.device, fs.used); err != nil {
+				return fmt.Errorf("could not write history: %w", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not write history: %w", err)
+	}
+	return nil
+}
+
+// prune deletes samples older than the retention and returns the freed pages to the file system.
+func (s *historyStore) prune() {
+	cutoff := time.Now().Add(-s.retention).UnixMilli()
+	if _, err := s.db.Exec(`DELETE FROM samples WHERE at < ?`, cutoff); err != nil {
+		s.setErr(fmt.Errorf("could not prune history: %w", err))
+		return
+	}
+	if _, err := s.db.Exec(`DELETE FROM fs_usage WHERE at < ?`, cutoff); err != nil {
+		s.setErr(fmt.Errorf("could not prune history: %w", err))
+		return
+	}
+	if _, err := s.db.Exec(`PRAGMA incremental_vacuum`); err != nil {
+		s.setErr(fmt.Errorf("could not vacuum history: %w", err))
+	}
+}
+
+// historyFrame is one stored sample with the history leading up to it, as shown by the scrubber.
+type historyFrame struct {
+	at    time.Time
+	cpu   cpu.TimesStat
+	mem   MemStats
+	procs []ProcessInfo
+	// first is the oldest sample loaded into the series, used as the session window start.
+	first      time.Time
+	cpuHistory *series
+	memHistory *series
+}
+
+// scrubDirection selects which stored sample frame returns relative to a time.
+type scrubDirection int
+
+const (
+	scrubLatest scrubDirection = iota // the newest sample at or before the time
+	scrubOlder                        // the newest sample before the time
+	scrubNewer                        // the oldest sample after the time
+)
+
+// errNoSample is returned by frame when there is no stored sample in the requested direction.
+var errNoSample = errors.New("no stored sample")
+
+// frame loads the stored sample next to t in the given direction, along with the samples of
+// the preceding recentSpan for the graphs and the top processes recorded with it.
+func (s *historyStore) frame(t time.Time, dir scrubDirection) (*historyFrame, error) {
+	query := `SELECT at, cpu, mem FROM samples WHERE at <= ? ORDER BY at DESC LIMIT 1`
+	switch dir {
+	case scrubOlder:
+		query = `SELECT at, cpu, mem FROM samples WHERE at < ? ORDER BY at DESC LIMIT 1`
+	case scrubNewer:
+		query = `SELECT at, cpu, mem FROM samples WHERE at > ? ORDER BY at ASC LIMIT 1`
+	}
+
+	var at int64
+	var cpuJSON, memJSON string
+	if err := s.db.QueryRow(query, t.UnixMilli()).Scan(&at, &cpuJSON, &memJSON); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSample
+		}
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	f := &historyFrame{at: time.UnixMilli(at), first: time.UnixMilli(at), cpuHistory: newSeries(), memHistory: newSeries()}
+	if err := json.Unmarshal([]byte(cpuJSON), &f.cpu); err != nil {
+		return nil, fmt.Errorf("could not decode history sample: %w", err)
+	}
+	if err := json.Unmarshal([]byte(memJSON), &f.mem); err != nil {
+		return nil, fmt.Errorf("could not decode history sample: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT at, cpu_percent, mem_percent FROM samples WHERE at BETWEEN ? AND ? ORDER BY at`,
+		f.at.Add(-recentSpan).UnixMilli(), at)
+	if err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	defer rows.Close()
+	for i := 0; rows.Next(); i++ {
+		var ms int64
+		var cpuPercent, memPercent float64
+		if err := rows.Scan(&ms, &cpuPercent, &memPercent); err != nil {
+			return nil, fmt.Errorf("could not read history: %w", err)
+		}
+		if i == 0 {
+			f.first = time.UnixMilli(ms)
+		}
+		f.cpuHistory.add(time.UnixMilli(ms), cpuPercent)
+		f.memHistory.add(time.UnixMilli(ms), memPercent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+
+	procs, err := s.db.Query(`SELECT pid, name, username, cpu_percent, memory, cpu_time FROM processes WHERE at = ?`, at)
+	if err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	defer procs.Close()
+	for procs.Next() {
+		var p ProcessInfo
+		var cpuTime int64
+		if err := procs.Scan(&p.PID, &p.Name, &p.Username, &p.CPUPercent, &p.Memory, &cpuTime); err != nil {
+			return nil, fmt.Errorf("could not read history: %w", err)
+		}
+		p.CPUTime = time.Duration(cpuTime)
+		f.procs = append(f.procs, p)
+	}
+	return f, procs.Err()
+}
+
+// fsSamples loads the filesystem usage samples taken since the given time, oldest first.
+func (s *historyStore) fsSamples(since time.Time) ([]fsSample, error) {
+	rows, err := s.db.Query(`SELECT at, mountpoint, device, used FROM fs_usage WHERE at >= ? ORDER BY at`, since.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	defer rows.Close()
+	var samples []fsSample
+	for rows.Next() {
+		var fs fsSample
+		var at int64
+		if err := rows.Scan(&at, &fs.mountpoint, &fs.device, &fs.used); err != nil {
+			return nil, fmt.Errorf("could not read history: %w", err)
+		}
+		fs.at = time.UnixMilli(at)
+		samples = append(samples, fs)
+	}
+	return samples, rows.Err()
+}
+
+// topProcesses returns the n processes using the most CPU, leaving procs untouched.
+func topProcesses(procs []ProcessInfo, n int) []ProcessInfo {
+	top := append([]ProcessInfo(nil), procs...)
+	sort.Slice(top, func(i, j int) bool { return top[i].CPUPercent > top[j].CPUPercent })
+	return top[:min(n, len(top))]
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inlineHeight is the height of the region the monitor redraws in with -inline, so it
+// leaves the rest of the terminal's history in view above it.
+const inlineHeight = 24
+
+// inlinePrompt renders the dialogs that take over the main region in full-screen mode as a
+// one-line footer prompt, so the process table stays in the small inline region; "" when
+// no dialog is open.
+func (m model) inlinePrompt() string {
+	prompt := m.baseStyle.Foreground(Color.Highlight).Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	switch {
+	case m.actions != nil:
+		item, _ := m.actions.selected()
+		return prompt(fmt.Sprintf("%s: %s", m.actions.title, item.label)) +
+			hint(fmt.Sprintf(" · %d/%d · ↑/↓: select · enter: run · esc: close", m.actions.cursor+1, len(m.actions.items)))
+	case m.viewsMenu != nil:
+		item, _ := m.viewsMenu.selected()
+		return prompt("view: "+item.label) +
+			hint(fmt.Sprintf(" · %d/%d · ↑/↓: select · enter: save or recall · 1-9: recall · x: delete · esc: close", m.viewsMenu.cursor+1, len(m.viewsMenu.items)))
+	case m.kill != nil:
+		d := m.kill
+		what := fmt.Sprintf("PID %d (%s)", d.root.PID, d.root.Name)
+		treeKey := "t: include descendants"
+		if d.tree {
+			what = fmt.Sprintf("PID %d (%s) and %d descendants", d.root.PID, d.root.Name, len(d.targets)-1)
+			treeKey = "t: only this process"
+		}
+		if d.confirming {
+			return m.baseStyle.Foreground(Color.Red).Bold(true).Render(d.protectedPrompt()) + hint(" · Y: send anyway · any other key: back")
+		}
+		keys := []string{"←/→: signal", treeKey, "enter: send", "esc: cancel"}
+		if len(d.protected) > 0 {
+			what += fmt.Sprintf(", %d protected", len(d.protected))
+		}
+		return prompt(fmt.Sprintf("Send %s to %s?", killSignals[d.signal].name, what)) + hint(" · "+strings.Join(keys, " · "))
+	case m.portPicker != nil:
+		p := m.portPicker
+		l := p.listeners[p.cursor]
+		owner := "unknown owner"
+		if l.pid != 0 {
+			owner = fmt.Sprintf("PID %d", l.pid)
+			if proc, ok := m.findProcess(l.pid); ok {
+				owner = fmt.Sprintf("PID %d (%s)", proc.PID, proc.Name)
+			}
+		}
+		return prompt(fmt.Sprintf("%d listening on %d: %s %s %s", len(p.listeners), p.port, l.proto, l.addr, owner)) +
+			hint(fmt.Sprintf(" · %d/%d · ↑/↓: select · enter: jump to process · esc: cancel", p.cursor+1, len(p.listeners)))
+	}
+	return ""
+}
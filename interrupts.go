@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// IRQSource is one line of /proc/interrupts diffed to rates: an interrupt number or an
+// architecture specific source such as LOC, with the rate it fired at on each CPU.
+type IRQSource struct {
+	Name string
+	// Desc is what the kernel lists after the counts: the controller, the trigger and the
+	// devices sharing the line, e.g. "IR-PCI-MSI 524288-edge eth0-TxRx-0".
+	Desc   string
+	PerCPU []float64 // interrupts per second on each CPU
+	Total  float64
+}
+
+// InterruptStats is the hard and soft interrupt activity of each CPU over the last refresh
+// interval. Softirqs are listed in the order of /proc/softirqs.
+type InterruptStats struct {
+	CPUs     []string // the CPU column names, e.g. CPU0; offline CPUs are not listed
+	Sources  []IRQSource
+	Softirqs []IRQSource
+}
+
+// irqCounts is an interrupt table as read: the CPU columns and each line's counters.
+type irqCounts struct {
+	cpus  []string
+	lines []irqLine
+}
+
+// irqLine is one line of an interrupt table with its cumulative count per CPU.
+type irqLine struct {
+	name   string
+	desc   string
+	counts []uint64
+}
+
+// readIRQTable parses a /proc/interrupts or /proc/softirqs style file. The header lists
+// the online CPUs; each line that follows is a label, up to one count per CPU and a
+// description. The number of counts varies: ERR and MIS have a single total, and some
+// architectures and kernel versions print fewer or more columns than CPUs, so the counts
+// end at the first field that isn't a number and missing ones are zero.
+func readIRQTable(path string) (irqCounts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return irqCounts{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// Lines with many CPUs and long device lists easily exceed the default 64 KiB.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return irqCounts{}, scanner.Err()
+	}
+	table := irqCounts{cpus: strings.Fields(scanner.Text())}
+	for scanner.Scan() {
+		label, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		line := irqLine{name: strings.TrimSpace(label), counts: make([]uint64, len(table.cpus))}
+		fields := strings.Fields(rest)
+		n := 0
+		for n < len(fields) && n < len(table.cpus) {
+			v, err := strconv.ParseUint(fields[n], 10, 64)
+			if err != nil {
+				break
+			}
+			line.counts[n] = v
+			n++
+		}
+		line.desc = strings.Join(fields[n:], " ")
+		table.lines = append(table.lines, line)
+	}
+	return table, scanner.Err()
+}
+
+// irqSample is the interrupt tables at the moment they were read.
+type irqSample struct {
+	hard, soft irqCounts
+	at         time.Time
+}
+
+// InterruptCollector turns the cumulative interrupt counters into per-interval rates by
+// diffing them against the previous read. Like the other collectors it is not safe for
+// concurrent use.
+type InterruptCollector struct {
+	prev *irqSample
+}
+
+func NewInterruptCollector() *InterruptCollector {
+	return &InterruptCollector{}
+}
+
+// GetInterrupts returns the interrupt rates since the previous call, or nil on the first
+// call, which has nothing to diff against.
+func (c *InterruptCollector) GetInterrupts() (*InterruptStats, error) {
+	hard, err := readIRQTable("/proc/interrupts")
+	if err != nil {
+		return nil, err
+	}
+	soft, err := readIRQTable("/proc/softirqs")
+	if err != nil {
+		return nil, err
+	}
+	cur := &irqSample{hard: hard, soft: soft, at: time.Now()}
+	prev := c.prev
+	c.prev = cur
+	if prev == nil {
+		return nil, nil
+	}
+	elapsed := cur.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return nil, nil
+	}
+	stats := &InterruptStats{
+		CPUs:     hard.cpus,
+		Sources:  irqRates(hard, prev.hard, elapsed),
+		Softirqs: irqRates(soft, prev.soft, elapsed),
+	}
+	sort.SliceStable(stats.Sources, func(i, j int) bool { return stats.Sources[i].Total > stats.Sources[j].Total })
+	return stats, nil
+}
+
+// irqRates diffs each line of cur against the same line of prev. A CPU that came online or
+// went offline shifts the columns, so the CPUs are matched by name.
+func irqRates(cur, prev irqCounts, elapsed float64) []IRQSource {
+	prevLines := make(map[string]irqLine, len(prev.lines))
+	for _, l := range prev.lines {
+		prevLines[l.name] = l
+	}
+	prevCPU := make(map[string]int, len(prev.cpus))
+	for i, cpu := range prev.cpus {
+		prevCPU[cpu] = i
+	}
+	var sources []IRQSource
+	for _, l := range cur.lines {
+		p, ok := prevLines[l.name]
+		if !ok {
+			continue
+		}
+		s := IRQSource{Name: l.name, Desc: l.desc, PerCPU: make([]float64, len(cur.cpus))}
+		for i, cpu := range cur.cpus {
+			j, ok := prevCPU[cpu]
+			if !ok {
+				continue
+			}
+			s.PerCPU[i] = float64(delta(l.counts[i], p.counts[j])) / elapsed
+			s.Total += s.PerCPU[i]
+		}
+		sources = append(sources, s)
+	}
+	return sources
+}
+
+// busiestCPU returns the CPU column the source fired on most and its share of the total.
+func (s IRQSource) busiestCPU() (cpu int, share float64) {
+	for i, v := range s.PerCPU {
+		if v > s.PerCPU[cpu] {
+			cpu = i
+		}
+	}
+	if s.Total > 0 {
+		share = s.PerCPU[cpu] / s.Total
+	}
+	return cpu, share
+}
+
+// maxIRQSources bounds how many interrupt sources the interrupts view lists.
+const maxIRQSources = 15
+
+// Above irqImbalanceRate interrupts per second, a source whose irqImbalanceShare or more
+// lands on a single CPU is highlighted: on a multi-core box that core is likely the
+// bottleneck, the classic case being every NIC queue steered to CPU0.
+const (
+	irqImbalanceRate  = 1000
+	irqImbalanceShare = 0.9
+)
+
+// viewInterrupts renders the busiest interrupt sources with the CPU each mostly lands on,
+// then the hardirq and softirq rates of each CPU.
+func (m model) viewInterrupts() string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	stats := m.data.Interrupts
+	if stats == nil {
+		return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, title("Interrupts"), "", hint("waiting for a second sample…")))
+	}
+	rate := func(v float64) string { return formatInt(uint64(v + 0.5)) }
+
+	lines := []string{title("Top interrupt sources (per second)"), ""}
+	lines = append(lines, hint(fmt.Sprintf("%-6s %10s %-12s %s", "irq", "total", "busiest", "device")))
+	for i, s := range stats.Sources {
+		if i == maxIRQSources || s.Total == 0 {
+			break
+		}
+		cpu, share := s.busiestCPU()
+		busiest := fmt.Sprintf("%s %s", strings.ToLower(stats.CPUs[cpu]), formatPercent(share*100, 0))
+		line := fmt.Sprintf("%-6s %10s %-12s %s", s.Name, rate(s.Total), busiest, s.Desc)
+		if len(stats.CPUs) > 1 && s.Total >= irqImbalanceRate && share >= irqImbalanceShare {
+			line = m.baseStyle.Foreground(Color.Red).Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", title("Per CPU (per second)"), "")
+	header := fmt.Sprintf("%-6s %9s", "cpu", "hardirq")
+	for _, s := range stats.Softirqs {
+		header += fmt.Sprintf(" %9s", truncate(s.Name, 9))
+	}
+	lines = append(lines, hint(header))
+	for i, cpu := range stats.CPUs {
+		var hard float64
+		for _, s := range stats.Sources {
+			hard += s.PerCPU[i]
+		}
+		line := fmt.Sprintf("%-6s %9s", strings.ToLower(cpu), rate(hard))
+		for _, s := range stats.Softirqs {
+			line += fmt.Sprintf(" %9s", rate(s.PerCPU[i]))
+		}
+		lines = append(lines, line)
+	}
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,6 @@
+//go:build linux
+
+package main
+
+// interruptsSupported reports whether the interrupt counters are read on this platform.
+const interruptsSupported = true
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package main
+
+// interruptsSupported reports whether the interrupt counters are read on this platform;
+// only Linux exposes them in /proc/interrupts and /proc/softirqs.
+const interruptsSupported = false
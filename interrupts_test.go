@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadIRQTable(t *testing.T) {
+	hard, err := readIRQTable(filepath.Join("testdata", "interrupts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CPU0", "CPU1", "CPU2", "CPU3"}; !slices.Equal(hard.cpus, want) {
+		t.Errorf("cpus = %v, want %v", hard.cpus, want)
+	}
+	if len(hard.lines) != 19 {
+		t.Errorf("%d lines, want 19", len(hard.lines))
+	}
+	tests := []struct {
+		name   string
+		counts []uint64
+		desc   string
+	}{
+		{"0", []uint64{36, 0, 0, 0}, "IR-IO-APIC 2-edge timer"},
+		{"127", []uint64{0, 88012, 0, 91023}, "IR-PCI-MSI 1048576-edge nvme0q0, nvme0q1"},
+		{"LOC", []uint64{2014340, 1804221, 1902284, 1788250}, "Local timer interrupts"},
+		// ERR and MIS have a single total and no description.
+		{"ERR", []uint64{0, 0, 0, 0}, ""},
+	}
+	for _, tt := range tests {
+		i := slices.IndexFunc(hard.lines, func(l irqLine) bool { return l.name == tt.name })
+		if i < 0 {
+			t.Errorf("no line %s", tt.name)
+			continue
+		}
+		if l := hard.lines[i]; !slices.Equal(l.counts, tt.counts) || l.desc != tt.desc {
+			t.Errorf("line %s = %v %q, want %v %q", tt.name, l.counts, l.desc, tt.counts, tt.desc)
+		}
+	}
+
+	soft, err := readIRQTable(filepath.Join("testdata", "softirqs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(soft.cpus) != 4 || len(soft.lines) != 10 {
+		t.Fatalf("softirqs: %d CPUs and %d lines, want 4 and 10", len(soft.cpus), len(soft.lines))
+	}
+	if l := soft.lines[3]; l.name != "NET_RX" || !slices.Equal(l.counts, []uint64{1920004, 1102, 987, 1230}) {
+		t.Errorf("softirqs line 3 = %s %v", l.name, l.counts)
+	}
+}
+
+// TestIRQRatesOfflineCPU diffs against a table read after CPU1 went offline: the columns
+// shift, and the rates must still land on the CPUs they were counted on.
+func TestIRQRatesOfflineCPU(t *testing.T) {
+	prev, err := readIRQTable(filepath.Join("testdata", "interrupts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := readIRQTable(filepath.Join("testdata", "interrupts_offline"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := irqRates(cur, prev, 10)
+	byName := map[string]IRQSource{}
+	for _, s := range sources {
+		byName[s.Name] = s
+	}
+	if _, ok := byName["128"]; ok {
+		t.Error("a line without a previous count has a rate")
+	}
+	tests := []struct {
+		name   string
+		perCPU []float64
+	}{
+		{"124", []float64{1000, 0, 0}},
+		{"1", []float64{0, 3.8, 0}},
+		{"127", []float64{0, 0, 50}},
+		{"LOC", []float64{1000, 1000, 1000}},
+		{"MIS", []float64{0.2, 0, 0}},
+	}
+	for _, tt := range tests {
+		s, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("no rate for %s", tt.name)
+			continue
+		}
+		if !slices.Equal(s.PerCPU, tt.perCPU) {
+			t.Errorf("%s per CPU = %v, want %v", tt.name, s.PerCPU, tt.perCPU)
+		}
+	}
+	if cpu, share := byName["124"].busiestCPU(); cpu != 0 || share != 1 {
+		t.Errorf("busiestCPU of 124 = %d, %v; want CPU0 with all of it", cpu, share)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import "fmt"
+
+// ioClass is an I/O scheduling class as understood by ioprio_set(2).
+type ioClass int
+
+const (
+	ioClassNone ioClass = iota
+	ioClassRealtime
+	ioClassBestEffort
+	ioClassIdle
+)
+
+// ioLevels is the number of priority levels within the realtime and best-effort classes.
+const ioLevels = 8
+
+func (c ioClass) String() string {
+	switch c {
+	case ioClassRealtime:
+		return "realtime"
+	case ioClassBestEffort:
+		return "best-effort"
+	case ioClassIdle:
+		return "idle"
+	default:
+		return "none"
+	}
+}
+
+// ioPriority is a process's I/O scheduling class and the level within it (0 is highest).
+type ioPriority struct {
+	Class ioClass
+	Level int
+}
+
+func (p ioPriority) String() string {
+	switch p.Class {
+	case ioClassRealtime, ioClassBestEffort:
+		return fmt.Sprintf("%s/%d", p.Class, p.Level)
+	case ioClassNone:
+		// Processes without an explicit class are scheduled best-effort with a level derived from their nice value.
+		return "none (best-effort by nice)"
+	default:
+		return p.Class.String()
+	}
+}
+
+// ionicePicker holds the I/O priority being edited for a process before it is applied.
+type ionicePicker struct {
+	pid  int32
+	prio ioPriority
+}
+
+// pickerClasses are the classes offered by the picker, in the order they are cycled.
+var pickerClasses = []ioClass{ioClassIdle, ioClassBestEffort, ioClassRealtime}
+
+// cycleClass moves the picker to the next (or previous, when delta is negative) class.
+func (p *ionicePicker) cycleClass(delta int) {
+	i := 0
+	for j, c := range pickerClasses {
+		if c == p.prio.Class {
+			i = j
+		}
+	}
+	i = (i + delta + len(pickerClasses)) % len(pickerClasses)
+	p.prio.Class = pickerClasses[i]
+}
+
+// adjustLevel changes the level by delta, clamped to the valid range.
+func (p *ionicePicker) adjustLevel(delta int) {
+	p.prio.Level = min(max(p.prio.Level+delta, 0), ioLevels-1)
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// ioniceSupported reports whether I/O priorities can be read and changed on this platform.
+const ioniceSupported = true
+
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioLevelMask  = 1<<ioprioClassShift - 1
+)
+
+// getIOPriority reads the I/O scheduling class and level of a process via ioprio_get(2).
+func getIOPriority(pid int32) (ioPriority, error) {
+	r, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return ioPriority{}, errno
+	}
+	return ioPriority{
+		Class: ioClass(r >> ioprioClassShift),
+		Level: int(r & ioprioLevelMask),
+	}, nil
+}
+
+// setIOPriority changes the I/O scheduling class and level of a process via ioprio_set(2).
+func setIOPriority(pid int32, p ioPriority) error {
+	level := p.Level
+	if p.Class == ioClassIdle {
+		level = 0
+	}
+	value := uintptr(p.Class)<<ioprioClassShift | uintptr(level)
+	_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), value)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// ioniceSupported reports whether I/O priorities can be read and changed on this platform.
+const ioniceSupported = false
+
+var errIONiceUnsupported = errors.New("I/O priorities are not supported on this platform")
+
+func getIOPriority(pid int32) (ioPriority, error) {
+	return ioPriority{}, errIONiceUnsupported
+}
+
+func setIOPriority(pid int32, p ioPriority) error {
+	return errIONiceUnsupported
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// signalOption is a signal offered in the kill dialog.
+type signalOption struct {
+	name string
+	sig  syscall.Signal
+}
+
+// killDialog confirms sending a signal to a process, or to a process and all its descendants.
+type killDialog struct {
+	root ProcessInfo
+	// targets are the processes to signal in order: descendants deepest first, root last.
+	targets []ProcessInfo
+	tree    bool
+	// signal is an index into killSignals.
+	signal int
+	// protected are the targets on the safety list; with any, enter only arms the dialog
+	// and confirming is set until Y sends the signal.
+	protected  []protectedTarget
+	confirming bool
+}
+
+// maxListedTargets bounds how many target processes the dialog lists by name.
+const maxListedTargets = 12
+
+// descendants returns every process below root in the PPID graph, children before their
+// parents, so signalling in this order never leaves an orphan to be re-parented mid-walk.
+func descendants(procs []ProcessInfo, root int32) []ProcessInfo {
+	children := map[int32][]ProcessInfo{}
+	for _, p := range procs {
+		if p.PID != p.PPID {
+			children[p.PPID] = append(children[p.PPID], p)
+		}
+	}
+
+	var out []ProcessInfo
+	visited := map[int32]bool{root: true}
+	var walk func(pid int32)
+	walk = func(pid int32) {
+		for _, c := range children[pid] {
+			if visited[c.PID] {
+				continue
+			}
+			visited[c.PID] = true
+			walk(c.PID)
+			out = append(out, c)
+		}
+	}
+	walk(root)
+	return out
+}
+
+// openKillDialog starts confirming a signal to pid, including its descendants when tree is set.
+func (m *model) openKillDialog(pid int32, tree bool) {
+	p, ok := m.findProcess(pid)
+	if !ok {
+		return
+	}
+	m.kill = &killDialog{root: p}
+	m.kill.setTree(m.data.Procs, tree, m.cfg.Kill.Protected)
+}
+
+// setTree switches the dialog between signalling only the root and the whole tree, and
+// checks the new targets against the safety list.
+func (d *killDialog) setTree(procs []ProcessInfo, tree bool, patterns []string) {
+	d.tree = tree
+	d.targets = nil
+	if tree {
+		d.targets = descendants(procs, d.root.PID)
+	}
+	d.targets = append(d.targets, d.root)
+	d.protected = protectedTargets(d.targets, procs, patterns)
+	d.confirming = false
+}
+
+// sendSignals delivers the chosen signal to every target in order and returns how many
+// processes received it. Processes that exited in the meantime, or whose PID now belongs
+// to a different process, are skipped rather than treated as failures. Every delivery is
+// recorded in actions.
+func (d *killDialog) sendSignals(actions *actionLog) (int, []error) {
+	sig := killSignals[d.signal]
+	sent := 0
+	var errs []error
+	for _, t := range d.targets {
+		if !sameProcess(t) {
+			continue
+		}
+		err := sendSignal(t.PID, sig.sig)
+		actions.record(t.PID, t.Name, sig.name, err)
+		if err != nil {
+			if !isNoSuchProcess(err) {
+				errs = append(errs, fmt.Errorf("could not send %s to PID %d (%s): %w", sig.name, t.PID, t.Name, err))
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, errs
+}
+
+// sameProcess reports whether t's PID still refers to the process that was listed in the
+// dialog, guarding against PID reuse between confirmation and delivery.
+func sameProcess(t ProcessInfo) bool {
+	p, err := process.NewProcess(t.PID)
+	if err != nil {
+		return false
+	}
+	created, err := p.CreateTime()
+	if err != nil {
+		// Keep going rather than refusing to signal when the create time is not readable.
+		return true
+	}
+	return t.CreateTime == 0 || created == t.CreateTime
+}
+
+// updateKillDialog handles key presses while the kill dialog is open.
+func (m model) updateKillDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.kill.confirming {
+		return m.updateProtectedConfirm(msg)
+	}
+	switch msg.String() {
+	case "left", "h":
+		m.kill.signal = (m.kill.signal - 1 + len(killSignals)) % len(killSignals)
+	case "right", "l":
+		m.kill.signal = (m.kill.signal + 1) % len(killSignals)
+	// Toggles between signalling only the selected process and its whole tree.
+	case "t":
+		m.kill.setTree(m.data.Procs, !m.kill.tree, m.cfg.Kill.Protected)
+	case "enter", "y":
+		if err := m.kill.refused(); err != nil {
+			m.actionLog.record(m.kill.root.PID, m.kill.root.Name, killSignals[m.kill.signal].name, err)
+			m.reportError(err)
+			return m, nil
+		}
+		if len(m.kill.protected) > 0 {
+			m.kill.confirming = true
+			return m, nil
+		}
+		m.sendKill()
+	case "esc", "n", "q":
+		m.kill = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// updateProtectedConfirm handles the second confirmation of a kill that hits the safety
+// list. Only a capital Y sends, so the enter or y that armed it can't also fire it.
+func (m model) updateProtectedConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "Y":
+		m.sendKill()
+	case "ctrl+c":
+		return m.interrupt()
+	default:
+		m.kill.confirming = false
+	}
+	return m, nil
+}
+
+// sendKill sends the dialog's signal, closes it and reports the outcome.
+func (m *model) sendKill() {
+	sent, errs := m.kill.sendSignals(m.actionLog)
+	total := len(m.kill.targets)
+	sig := killSignals[m.kill.signal].name
+	m.kill = nil
+	if len(errs) > 0 {
+		m.reportError(fmt.Errorf("sent %s to %d of %d processes; %w", sig, sent, total, errs[0]))
+	} else {
+		m.reportInfo(fmt.Sprintf("sent %s to %d of %d processes", sig, sent, total))
+	}
+}
+
+// viewKillDialog renders the confirmation with the chosen signal and every target process.
+func (m model) viewKillDialog() string {
+	d := m.kill
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	sig := m.baseStyle.Foreground(Color.Red).Bold(true).Render(killSignals[d.signal].name)
+
+	what := fmt.Sprintf("PID %d (%s)", d.root.PID, d.root.Name)
+	if d.tree {
+		what = fmt.Sprintf("PID %d (%s) and %d descendants", d.root.PID, d.root.Name, len(d.targets)-1)
+	}
+
+	lines := []string{title("Send ") + sig + title(" to "+what+"?"), ""}
+	// Protected targets are listed first, so a tree kill can't hide them past the cut.
+	red := m.baseStyle.Foreground(Color.Red).Bold(true).Render
+	listed := 0
+	for _, t := range d.protected {
+		lines = append(lines, red(fmt.Sprintf("  %-8d %s  protected: %s", t.proc.PID, t.proc.Name, t.reason)))
+		listed++
+	}
+	for _, t := range d.targets {
+		if slices.ContainsFunc(d.protected, func(p protectedTarget) bool { return p.proc.PID == t.PID }) {
+			continue
+		}
+		if listed == maxListedTargets {
+			lines = append(lines, hint(fmt.Sprintf("  … and %d more", len(d.targets)-maxListedTargets)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-8d %s", t.PID, t.Name))
+		listed++
+	}
+	if err := d.refused(); err != nil {
+		lines = append(lines, "", red(err.Error()))
+	}
+	if d.confirming {
+		lines = append(lines, "", red(d.protectedPrompt()), hint("Y: send anyway · any other key: back"))
+		return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	treeKey := "t: include descendants"
+	if d.tree {
+		treeKey = "t: only this process"
+	}
+	keys := []string{"←/→: signal", treeKey, "enter: send", "esc: cancel"}
+	lines = append(lines, "", hint(strings.Join(keys, " · ")))
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
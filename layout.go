@@ -0,0 +1,93 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Minimum heights of the two regions the screen is split into: the upper one holds the
+// header, graphs and panels, the main one the table or view below them.
+const (
+	minUpperHeight = 3
+	minMainHeight  = 6
+)
+
+// viewUpper renders everything above the main region at its natural height.
+func (m model) viewUpper() string {
+	// Sets the width of the column to the width of the terminal (m.width) and adds padding of 1 unit on the top.
+	column := m.baseStyle.Width(m.width).Padding(1, 0, 0, 0).Render
+	return lipgloss.JoinVertical(lipgloss.Left,
+		column(m.withData(areaHeader).viewHeader()),
+		m.withData(areaProcesses).viewOffenders(),
+		column(m.viewGraphs()),
+		m.viewPanels(),
+	)
+}
+
+// layout splits the terminal height between the upper and main regions. The upper region
+// takes its natural height unless the divider was moved, and both keep their minimums
+// while the terminal is tall enough. It returns the upper region cut or padded to its
+// height, the main region's height and the height left for the lines below it.
+func (m model) layout() (upper string, mainHeight int, below []string) {
+	below = []string{m.viewBanner(), m.viewFooter()}
+	free := m.height
+	for _, s := range below {
+		free -= lipgloss.Height(m.region(s, 0))
+	}
+
+	upper = m.viewUpper()
+	if m.height == 0 {
+		return m.region(upper, 0), 0, below
+	}
+	height := lipgloss.Height(m.region(upper, 0))
+	if m.state.UpperHeight > 0 {
+		height = m.state.UpperHeight
+	}
+	height = min(height, free-minMainHeight)
+	height = max(height, minUpperHeight)
+	return m.region(upper, height), max(free-height, 0), below
+}
+
+// region renders s at the terminal width and, when height > 0, cuts or pads it to that many
+// lines, so a region never pushes the ones below it off the screen.
+func (m model) region(s string, height int) string {
+	style := m.baseStyle.Width(m.width)
+	if height > 0 {
+		style = style.Height(height).MaxHeight(height)
+	}
+	return style.Render(s)
+}
+
+// fitTables sizes the tables to the main region, leaving room for its top padding and for
+// the stale or frozen badge, so the last visible row is never cut off.
+func (m *model) fitTables() {
+	if m.height == 0 || m.compact() {
+		return
+	}
+	_, height, _ := m.layout()
+	height--
+	if m.staleBadge(collectorProcesses)+m.frozenBadge(areaProcesses) != "" {
+		height--
+	}
+	// The header and its border take two lines; keep at least one row.
+	height = max(height, 3)
+	m.processTable.SetHeight(height)
+	m.userTable.SetHeight(height)
+	m.unitTable.SetHeight(height)
+	m.connTable.SetHeight(height)
+}
+
+// moveDivider grows (step > 0) or shrinks the upper region by step lines and remembers the
+// position in the state file.
+func (m *model) moveDivider(step int) tea.Cmd {
+	if m.height == 0 || m.compact() {
+		return nil
+	}
+	upper, _, _ := m.layout()
+	m.state.UpperHeight = max(lipgloss.Height(upper)+step, minUpperHeight)
+	if _, height, _ := m.layout(); height < minMainHeight && step > 0 {
+		m.state.UpperHeight -= step
+	}
+	m.fitTables()
+	return m.saveStateCmd()
+}
@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+// KernelLimits are the system limits whose exhaustion causes the most confusing failures,
+// each with its current usage.
+type KernelLimits struct {
+	// EntropyAvail is the entropy in the kernel's random pool of EntropyPool bits.
+	EntropyAvail, EntropyPool uint64
+	// FilesOpen file handles are allocated system-wide out of FilesMax.
+	FilesOpen, FilesMax uint64
+	// PIDs are in use, counting threads, out of PIDMax.
+	PIDs, PIDMax uint64
+	// InotifyWatches is the largest number of inotify watches held by one user, out of the
+	// per-user InotifyMax. InotifyKnown is false until the watches were counted.
+	InotifyWatches, InotifyMax uint64
+	InotifyKnown               bool
+}
+
+// limitUsage returns used as a percentage of limit.
+func limitUsage(used, limit uint64) float64 {
+	if limit == 0 {
+		return 0
+	}
+	return float64(used) / float64(limit) * 100
+}
+
+// formatCount renders a count compactly with a decimal suffix, e.g. "1234" or "9.2E".
+func formatCount(n uint64) string {
+	if n < 10000 {
+		return formatInt(n)
+	}
+	v := float64(n)
+	suffixes := []string{"k", "M", "G", "T", "P", "E"}
+	i := -1
+	for v >= 1000 && i < len(suffixes)-1 {
+		v /= 1000
+		i++
+	}
+	return formatFloat(v, 1) + suffixes[i]
+}
+
+// limitsPanel shows each kernel limit with its usage, red once the usage reaches the
+// configured percentage. Entropy counts as used up as the pool drains.
+func (m model) limitsPanel() panel {
+	p := panel{collector: collectorLimits, title: "Kernel limits"}
+	l := m.data.Limits
+	if l == nil {
+		return p
+	}
+	line := func(name string, current, limit uint64, usage float64) {
+		s := fmt.Sprintf("%-15s %7s / %-7s", name, formatCount(current), formatCount(limit))
+		if warn := m.cfg.Limits.WarnPercent; warn > 0 && usage >= warn {
+			s = m.baseStyle.Foreground(Color.Red).Render(s)
+		}
+		p.lines = append(p.lines, s)
+	}
+	line("entropy", l.EntropyAvail, l.EntropyPool, 100-limitUsage(l.EntropyAvail, l.EntropyPool))
+	line("open files", l.FilesOpen, l.FilesMax, limitUsage(l.FilesOpen, l.FilesMax))
+	line("PIDs", l.PIDs, l.PIDMax, limitUsage(l.PIDs, l.PIDMax))
+	if l.InotifyKnown {
+		line("inotify watches", l.InotifyWatches, l.InotifyMax, limitUsage(l.InotifyWatches, l.InotifyMax))
+	}
+	return p
+}
@@ -0,0 +1,150 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// limitsSupported reports whether the kernel limits are read on this platform.
+const limitsSupported = true
+
+// inotifyRefresh is how often the inotify watches are recounted. Counting them means
+// reading every file descriptor of every process, far too slow for every tick.
+const inotifyRefresh = 30 * time.Second
+
+// limitsCollector reads the kernel limits from /proc and remembers the last inotify count
+// between its refreshes.
+type limitsCollector struct {
+	watches   uint64
+	counted   bool
+	countedAt time.Time
+}
+
+func newLimitsCollector() *limitsCollector {
+	return &limitsCollector{}
+}
+
+// GetKernelLimits reads the current kernel limits and their usage.
+func (c *limitsCollector) GetKernelLimits() (*KernelLimits, error) {
+	var l KernelLimits
+	var err error
+	read := func(path string) uint64 {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = readProcUint(path)
+		return v
+	}
+	l.EntropyAvail = read("/proc/sys/kernel/random/entropy_avail")
+	l.EntropyPool = read("/proc/sys/kernel/random/poolsize")
+	l.PIDMax = read("/proc/sys/kernel/pid_max")
+	l.InotifyMax = read("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return nil, err
+	}
+
+	// file-nr holds the allocated handles, the allocated but unused ones, and the maximum.
+	fields, err := readProcFields("/proc/sys/fs/file-nr", 3)
+	if err != nil {
+		return nil, err
+	}
+	l.FilesOpen, l.FilesMax = fields[0]-min(fields[1], fields[0]), fields[2]
+
+	// The fourth field of loadavg is "running/total" scheduling entities, i.e. PIDs in use.
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil, err
+	}
+	loadFields := strings.Fields(string(data))
+	if len(loadFields) < 4 {
+		return nil, fmt.Errorf("malformed /proc/loadavg")
+	}
+	_, total, _ := strings.Cut(loadFields[3], "/")
+	if l.PIDs, err = strconv.ParseUint(total, 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed /proc/loadavg: %w", err)
+	}
+
+	if now := time.Now(); !c.counted || now.Sub(c.countedAt) >= inotifyRefresh {
+		c.watches, c.counted, c.countedAt = countInotifyWatches(), true, now
+	}
+	l.InotifyWatches, l.InotifyKnown = c.watches, c.counted
+	return &l, nil
+}
+
+// readProcUint reads a file holding a single number.
+func readProcUint(path string) (uint64, error) {
+	fields, err := readProcFields(path, 1)
+	if err != nil {
+		return 0, err
+	}
+	return fields[0], nil
+}
+
+// readProcFields reads the first n whitespace-separated numbers of a file.
+func readProcFields(path string, n int) ([]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < n {
+		return nil, fmt.Errorf("malformed %s", path)
+	}
+	values := make([]uint64, n)
+	for i := range values {
+		if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+			return nil, fmt.Errorf("malformed %s: %w", path, err)
+		}
+	}
+	return values, nil
+}
+
+// countInotifyWatches returns the most inotify watches held by the processes of one user,
+// which is what max_user_watches limits. Only the processes we may inspect are counted.
+func countInotifyWatches() uint64 {
+	byUser := map[uint32]uint64{}
+	fdDirs, _ := filepath.Glob("/proc/[0-9]*/fd")
+	for _, dir := range fdDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		var uid uint32
+		if info, err := os.Stat(filepath.Dir(dir)); err == nil {
+			if st, ok := info.Sys().(*syscall.Stat_t); ok {
+				uid = st.Uid
+			}
+		}
+		for _, e := range entries {
+			target, err := os.Readlink(filepath.Join(dir, e.Name()))
+			if err != nil || target != "anon_inode:inotify" {
+				continue
+			}
+			info, err := os.ReadFile(filepath.Join(filepath.Dir(dir), "fdinfo", e.Name()))
+			if err != nil {
+				continue
+			}
+			scanner := bufio.NewScanner(bytes.NewReader(info))
+			for scanner.Scan() {
+				if strings.HasPrefix(scanner.Text(), "inotify wd:") {
+					byUser[uid]++
+				}
+			}
+		}
+	}
+	var most uint64
+	for _, n := range byUser {
+		most = max(most, n)
+	}
+	return most
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// limitsSupported reports whether the kernel limits are read on this platform.
+const limitsSupported = false
+
+// limitsCollector exists so the collector list builds everywhere; it is never used here.
+type limitsCollector struct{}
+
+func newLimitsCollector() *limitsCollector {
+	return &limitsCollector{}
+}
+
+func (c *limitsCollector) GetKernelLimits() (*KernelLimits, error) {
+	return nil, errors.New("kernel limits are only read on Linux")
+}
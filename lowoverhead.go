@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// lowOverheadInterval is the shortest refresh interval in low-overhead mode.
+const lowOverheadInterval = 5 * time.Second
+
+// lowOverheadProcesses is how many of the busiest processes a low-overhead pass reads in
+// full; the rest only have their CPU times read to rank them.
+const lowOverheadProcesses = 50
+
+// renderCache holds the last frame rendered in low-overhead mode, so a render that follows
+// nothing but ticks returns it instead of laying out every panel again. It is shared by
+// pointer because View has a value receiver.
+type renderCache struct {
+	gen   uint64
+	frame string
+	ok    bool
+}
+
+// enableLowOverhead switches to the low-overhead mode of -low-overhead: a refresh interval
+// of at least lowOverheadInterval, no FDs, I/O counters, scheduler delays, traffic or
+// containers per process, only the busiest processes read in full and audited and no re-rendering
+// while nothing changed. It must run before the probe, which checks the collectors.
+func (m *model) enableLowOverhead() {
+	m.lowOverhead = true
+	m.interval = m.refreshInterval(m.cfg)
+	m.procOpts = processOptions{lite: true, audit: m.procOpts.audit}
+	m.collectors = defaultCollectors(m.procOpts)
+	m.setColumns(selectColumns(m.cfg.Columns.columnIDs(m.procOpts)))
+	m.render = &renderCache{}
+}
+
+// refreshInterval is the configured refresh interval, raised to lowOverheadInterval in
+// low-overhead mode.
+func (m model) refreshInterval(cfg Config) time.Duration {
+	interval := cfg.Interval
+	if m.onBattery() {
+		interval = max(interval, cfg.Battery.Interval)
+	}
+	if m.lowOverhead {
+		return max(interval, lowOverheadInterval)
+	}
+	return interval
+}
+
+// cachedView returns the last frame when no message other than a tick arrived since it was
+// rendered, and renders and keeps a new one otherwise.
+func (m model) cachedView() string {
+	if !m.render.ok || m.render.gen != m.gen {
+		m.render.frame, m.render.gen, m.render.ok = m.renderFrame(), m.gen, true
+	}
+	return m.render.frame
+}
+
+// viewSelfUsage shows the CPU and memory this program uses itself, from its own row of the
+// latest process list, or "" while it isn't listed.
+func (m model) viewSelfUsage() string {
+	self, ok := m.findProcess(selfPID)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("self: %s CPU · %s", formatPercent(self.CPUPercent, 1), formatBytes(self.Memory))
+}
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
@@ -9,38 +15,292 @@ import (
 )
 
 func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// runTUI runs the interactive monitor, the default subcommand.
+func runTUI(args []string) int {
+	return runMonitor("tui", args)
+}
+
+// runRun starts a command and runs the monitor following it, the run subcommand.
+func runRun(args []string) int {
+	return runMonitor("run", args)
+}
+
+// runMonitor runs the interactive monitor for the tui and run subcommands, which share
+// their flags; run adds the ones about its command.
+func runMonitor(name string, args []string) int {
+	fs := newFlagSet(name)
+	configSrc := configFlag(fs)
+	confirmQuit := fs.Bool("confirm-quit", false, "ask for confirmation before quitting")
+	noSummary := fs.Bool("no-summary", false, "don't print the session summary on quit, whatever quit_summary says")
+	compact := fs.Bool("compact", false, "show only a line or two of CPU, memory, load and the top process")
+	quiet := fs.Bool("quiet", false, "never ring the bell or flash the header for alerts")
+	history := fs.String("history", "", "persist tick summaries, e.g. sqlite:/path/history.db")
+	showCaps := fs.Bool("capabilities", false, "print which features work on this host and exit")
+	inline := fs.Bool("inline", false, "draw in a region of the terminal below the prompt instead of the alternate screen, keeping the last frame on exit")
+	ascii := fs.Bool("ascii", false, "draw the header bars with \"|\" cells instead of Unicode blocks, whatever meters.ascii says")
+	maxFPS := fs.Int("max-fps", 60, "redraw at most this many times per second (1-120), lower for slow terminals over SSH")
+	lowOverhead := fs.Bool("low-overhead", false, "refresh at most every 5s, read only the busiest processes in full and skip their FDs and I/O")
+	once := fs.Bool("once", false, "print a single plain-text snapshot and exit instead of starting the TUI")
+	var failIf failConditions
+	fs.Var(&failIf, "fail-if", "with -once, exit 1 when the condition holds, e.g. \"mem>90\" or \"disk:/var>=95\"; metrics are cpu, mem, swap, load1 and disk:<path> (repeatable)")
+	statsdAddr := statsdFlag(fs)
+	actionLogPath := fs.String("action-log", "", "append every signal, renice, I/O priority and affinity change made in the TUI to this file")
+	noTitle := fs.Bool("no-title", false, "never set the terminal title, whatever title.mode says")
+	debugListen := fs.String("debug-listen", "", "serve pprof and self-metrics on this address, e.g. :6060 (localhost only unless a host is given)")
+	var output *string
+	var summary *bool
+	if name == "run" {
+		output = fs.String("output", os.DevNull, "write the command's output to this file; the terminal belongs to the monitor")
+		summary = fs.Bool("summary", true, "record the command's CPU and memory use and print a summary on exit")
+	}
+	fs.Parse(args)
+	if name == "run" && (fs.NArg() == 0 || *once) {
+		fmt.Fprintf(os.Stderr, "Usage: %s run [flags] -- command [args...]\n", programName())
+		return 2
+	}
+	if len(failIf) > 0 && !*once {
+		fmt.Fprintln(os.Stderr, "-fail-if needs -once")
+		return 2
+	}
+	if *maxFPS < 1 || *maxFPS > 120 {
+		fmt.Fprintln(os.Stderr, "-max-fps must be between 1 and 120")
+		return 2
+	}
+	if *once {
+		return runOnce(*configSrc, failIf)
+	}
+
+	cfg, cfgErr := loadMonitorConfig(*configSrc)
+	if *confirmQuit {
+		cfg.ConfirmQuit = true
+	}
+	if *ascii {
+		cfg.Meters.ASCII = true
+	}
+	Color = cfg.Theme.theme()
+
+	m := newModel(cfg)
+	if *lowOverhead {
+		m.enableLowOverhead()
+	}
+	m.probe()
+	if *showCaps {
+		fmt.Println(m.caps)
+		return 0
+	}
+	m.configSrc = *configSrc
+	m.confirmQuitFlag = *confirmQuit
+	m.asciiFlag = *ascii
+	m.quiet = *quiet
+	m.compactFlag = *compact
+	m.inline = *inline
+	if *statsdAddr != "" {
+		client, err := newStatsdClient(*statsdAddr, cfg.Statsd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m.statsd = client
+	}
+	if *debugListen != "" {
+		m.metrics = newSelfMetrics()
+		m.metrics.statsd = m.statsd
+		if err := startDebugServer(*debugListen, m.metrics); err != nil {
+			log.Fatal(err)
+		}
+	}
+	m.privilegeHint()
+	m.statePath = defaultStatePath()
+	state, err := loadUIState(m.statePath)
+	if err != nil {
+		// A broken state file only costs the remembered layout; the program still starts.
+		m.reportError(err)
+	}
+	m.state = state
+	Units.StartTimes = state.StartTimes
+	if cfgErr != nil {
+		m.reportConfigError(cfgErr, "used the defaults")
+	}
+	m.crash = newCrashReporter(m.statePath)
+	if *history != "" {
+		store, err := openHistoryStore(*history, cfg.History, m.crash)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m.history = store
+		if err := m.fsGrowth.seed(store, time.Now()); err != nil {
+			m.reportError(err)
+		}
+	}
+	if *actionLogPath != "" {
+		if err := m.actionLog.openFile(*actionLogPath, m.crash); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	m.title = newTerminalTitle(cfg.Title, *noTitle)
+	if cfg.Crashes.Watch {
+		if src, err := findCrashSource(); err == nil {
+			m.crashWatch, _ = watchCrashes(src)
+		}
+	}
+
+	if name == "run" {
+		out, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+		child, err := startChild(fs.Args(), out, *summary)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m.child = child
+		m.pinned = &child.key
+	}
+
+	// Create a new Bubble Tea program with the model and enable alternate screen, unless
+	// drawing inline. Frames are coalesced to the FPS cap, so a resize storm costs at most
+	// that many redraws a second.
+	opts := []tea.ProgramOption{tea.WithFPS(*maxFPS)}
+	if !*inline {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(m, opts...)
+	m.crash.setProgram(p)
+
+	// Reload the config on SIGHUP, like the r key does. Notify with no signals would
+	// relay every signal, so skip it on platforms without a reload signal.
+	if len(reloadSignals) > 0 {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, reloadSignals...)
+		go func() {
+			for range reload {
+				p.Send(reloadRequestMsg{})
+			}
+		}()
+	}
+
+	// Push UPower's battery changes, so AC plugging shows between refreshes.
+	if w := m.procOpts.upower; w != nil {
+		go w.run(p.Send)
+		defer w.close()
+	}
+	// Push the core dumps as they happen.
+	if w := m.crashWatch; w != nil {
+		go w.run(p.Send)
+		defer w.close()
+	}
+
+	// Run the program and handle any errors
+	final, err := p.Run()
+	// Also after a panic, whose terminal restore leaves the title alone.
+	m.title.restore()
+	// Let background writes such as exports finish so quitting never leaves a partial file.
+	m.writes.Wait()
+	// A panic has already restored the terminal; point at the crash file and panic again.
+	// This comes before closing the history, whose goroutine may be the one that panicked.
+	m.crash.rethrow()
+	if m.history != nil {
+		if err := m.history.Close(); err != nil {
+			log.Printf("Could not close history: %v", err)
+		}
+	}
+	if err := m.actionLog.close(); err != nil {
+		log.Printf("Could not write the action log: %v", err)
+	}
+	if err != nil {
+		log.Fatalf("Error running program: %v", err)
+	}
+	// The final model carries the config as last reloaded.
+	if fm, ok := final.(model); ok && fm.cfg.QuitSummary && !*noSummary {
+		fmt.Print(fm.quitSummary(time.Now()))
+	}
+	if m.child != nil {
+		fmt.Print(m.child.summary())
+		return m.child.exitCode()
+	}
+	return 0
+}
+
+// newModel builds the initial model from the config, with empty tables and the default styles.
+func newModel(cfg Config) model {
 	tableStyle := table.DefaultStyles()
 	tableStyle.Selected = lipgloss.NewStyle().Background(Color.Highlight)
 
+	procOpts := cfg.Columns.processOptions()
+	procOpts.audit = cfg.Audit.Panel
+	procOpts.netns = cfg.Network.Namespaces
+	procOpts.powerCommand = cfg.Sensors.PowerCommand
+	procOpts.firewallCounters = cfg.Network.FirewallCounters
+	if procOpts.ctxSwitch {
+		procOpts.ctxTargets = &ctxTargets{}
+	}
+	if batterySupported && cfg.Battery.UPower {
+		// Without UPower the battery collector reads sysfs on every refresh instead;
+		// the capabilities report says so.
+		if w, err := watchUPower(); err == nil {
+			procOpts.upower = w
+		}
+	}
+	columns := selectColumns(cfg.Columns.columnIDs(procOpts))
+	sortColumn := columnCPU
+	if !slices.ContainsFunc(columns, func(c processColumn) bool { return c.id == sortColumn }) {
+		sortColumn = nextSortColumn(columns, "")
+	}
+
 	// Creates a new table with specified columns and initial empty rows.
 	processTable := table.New(
 		// We use this to define our table "header"
-		table.WithColumns([]table.Column{
-			{Title: "PID", Width: 10},
-			{Title: "Name", Width: 25},
-			{Title: "CPU", Width: 12},
-			{Title: "MEM", Width: 12},
-			{Title: "Username", Width: 12},
-			{Title: "Time", Width: 12},
-		}),
+		table.WithColumns(tableColumns(columns, nil, sortColumn, nil)),
 		table.WithRows([]table.Row{}),
 		table.WithFocused(true),
 		table.WithHeight(20),
 		table.WithStyles(tableStyle),
 	)
 
-	m := model{
-		processTable: processTable,
-		tableStyle:   tableStyle,
-		baseStyle:    lipgloss.NewStyle(),
-		viewStyle:    lipgloss.NewStyle(),
-	}
-
-	// Create a new Bubble Tea program with the model and enable alternate screen
-	p := tea.NewProgram(m, tea.WithAltScreen())
-
-	// Run the program and handle any errors
-	if _, err := p.Run(); err != nil {
-		log.Fatalf("Error running program: %v", err)
+	return model{
+		cfg:             cfg,
+		interval:        cfg.Interval,
+		procOpts:        procOpts,
+		meterStyles:     cfg.Meters.styles(),
+		state:           uiState{ColumnWidths: map[string]int{}},
+		focus:           areaProcesses,
+		frozen:          map[string]Snapshot{},
+		webhookStatus:   map[string]webhookStatus{},
+		started:         time.Now(),
+		collectors:      defaultCollectors(procOpts),
+		lastSuccess:     map[string]time.Time{},
+		cpuHistory:      newSeries(),
+		memHistory:      newSeries(),
+		availHistory:    newSeries(),
+		diskHistory:     newSeries(),
+		netHistory:      newSeries(),
+		timeWaitHistory: newSeries(),
+		loadHistory:     newSeries(),
+		sessionStats:    newSessionStats(),
+		fsWatch:         newFSWatch(),
+		fsGrowth:        newFSGrowth(),
+		respawns:        newRespawnWatch(),
+		exits:           newExitWatch(),
+		anomalies:       newAnomalyWatch(),
+		actionLog:       newActionLog(),
+		auditHidden:     map[string]bool{},
+		collapsed:       map[int32]int64{},
+		confirmQuit:     cfg.ConfirmQuit,
+		writes:          &sync.WaitGroup{},
+		processTable:    processTable,
+		columns:         columns,
+		sortColumn:      sortColumn,
+		userTable:       newUserTable(tableStyle),
+		unitTable:       newUnitTable(tableStyle),
+		connTable:       newConnTable(tableStyle),
+		dns:             newDNSCache(),
+		execPanels:      newExecPanels(cfg.ExecPanels),
+		tableStyle:      tableStyle,
+		baseStyle:       lipgloss.NewStyle(),
+		viewStyle:       lipgloss.NewStyle(),
 	}
 }
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// platformMemStats adds the figures Activity Monitor is built around: the memory held by
+// the compressor and the kernel's memory pressure level. Wired, active and inactive come
+// from gopsutil already.
+func platformMemStats(s *MemStats) error {
+	if compressed, err := unix.SysctlUint64("vm.compressor_bytes_used"); err == nil {
+		s.Compressed = compressed
+	}
+	if level, err := unix.SysctlUint32("kern.memorystatus_vm_pressure_level"); err == nil {
+		s.Pressure = memPressure(level)
+	}
+	return nil
+}
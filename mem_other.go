@@ -0,0 +1,11 @@
+//go:build !windows && !darwin
+
+package main
+
+// platformMemStats fills the figures gopsutil doesn't report here: the swap devices and
+// vm.swappiness. Both are optional, as /proc/swaps only exists on Linux.
+func platformMemStats(s *MemStats) error {
+	s.SwapDevices, _ = readSwaps("/proc/swaps")
+	s.Swappiness = readSwappiness("/proc/sys/vm/swappiness")
+	return nil
+}
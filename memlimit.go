@@ -0,0 +1,105 @@
+package main
+
+import (
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// memLimitWarnPercent is the share of its cgroup's memory limit at which the MEM/LIM cell
+// turns red: the cgroup is close to the OOM killer or to reclaim stalls.
+const memLimitWarnPercent = 90
+
+// cgroupMem is the usage of the memory limit that binds a cgroup: the limit of the cgroup
+// or of an ancestor, whichever is closest to being hit, and the usage it counts against.
+type cgroupMem struct {
+	current, limit uint64
+}
+
+// percent returns the usage as a percentage of the limit.
+func (c cgroupMem) percent() float64 {
+	return float64(c.current) / float64(c.limit) * 100
+}
+
+// memLimitEntry is the cached cgroup of one process.
+type memLimitEntry struct {
+	createTime int64
+	cgroup     string
+}
+
+// memLimitResolver maps processes to the memory limit of their cgroup for the MEM/LIM
+// column. The cgroup of each process is cached per PID like the systemd unit, the usage of
+// each cgroup once per pass, since every process of a container shares it. Like
+// ProcessCollector it is not safe for concurrent use.
+type memLimitResolver struct {
+	byPID map[int32]memLimitEntry
+	// pass holds the usage of the cgroups read during the current pass, ok false for those
+	// without a limit.
+	pass      map[string]cgroupMemResult
+	hostTotal uint64
+}
+
+// cgroupMemResult is a cached cgroupMem lookup.
+type cgroupMemResult struct {
+	mem cgroupMem
+	ok  bool
+}
+
+func newMemLimitResolver() *memLimitResolver {
+	return &memLimitResolver{byPID: map[int32]memLimitEntry{}}
+}
+
+// begin starts a pass: the usage of the cgroups is read afresh.
+func (r *memLimitResolver) begin() {
+	r.pass = map[string]cgroupMemResult{}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		r.hostTotal = vm.Total
+	}
+}
+
+// lookup returns the usage of the process's cgroup as a percentage of its memory limit,
+// with the limit; without one, the RSS as a percentage of host RAM and a zero limit.
+func (r *memLimitResolver) lookup(pid int32, createTime int64, rss uint64) (percent float64, limit uint64, ok bool) {
+	e, cached := r.byPID[pid]
+	if !cached || e.createTime != createTime {
+		e = memLimitEntry{createTime: createTime, cgroup: cgroupOf(pid)}
+		r.byPID[pid] = e
+	}
+	if e.cgroup != "" {
+		res, read := r.pass[e.cgroup]
+		if !read {
+			res.mem, res.ok = readCgroupMem(e.cgroup, r.hostTotal)
+			r.pass[e.cgroup] = res
+		}
+		if res.ok {
+			return res.mem.percent(), res.mem.limit, true
+		}
+	}
+	if r.hostTotal == 0 {
+		return 0, 0, false
+	}
+	return float64(rss) / float64(r.hostTotal) * 100, 0, true
+}
+
+// prune forgets processes that no longer exist.
+func (r *memLimitResolver) prune(alive map[int32]cpuSample) {
+	for pid := range r.byPID {
+		if _, ok := alive[pid]; !ok {
+			delete(r.byPID, pid)
+		}
+	}
+}
+
+// memLimitCell renders the MEM/LIM column: "97.0% of 512 MiB" under a limit, red close to
+// it, or the share of host RAM without one.
+func memLimitCell(p ProcessInfo) string {
+	if !p.MemLimitKnown {
+		return "-"
+	}
+	if p.MemLimit == 0 {
+		return formatPercent(p.MemLimitPercent, 1)
+	}
+	cell := formatPercent(p.MemLimitPercent, 1) + " of " + formatBytes(p.MemLimit)
+	if p.MemLimitPercent >= memLimitWarnPercent {
+		return tagCell(cell, tagRed)
+	}
+	return cell
+}
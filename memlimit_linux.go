@@ -0,0 +1,72 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// memLimitSupported reports whether cgroup memory limits are read on this platform.
+const memLimitSupported = true
+
+// cgroupRoot is where the cgroup hierarchies are mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupOf returns the memory cgroup of pid as a directory under cgroupRoot: the path of
+// the unified hierarchy, or of the v1 memory controller; "" when it can't be read.
+func cgroupOf(pid int32) string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return ""
+	}
+	unified := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller == "memory" {
+				return path.Join(cgroupRoot, "memory", parts[2])
+			}
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = path.Join(cgroupRoot, parts[2])
+		}
+	}
+	return unified
+}
+
+// readCgroupMem finds the memory limit that binds cgroup: of the cgroup and its ancestors
+// with a limit, the one closest to being hit. A limit at or above host RAM can't be the one
+// the OOM killer enforces first and counts as none.
+func readCgroupMem(cgroup string, hostTotal uint64) (cgroupMem, bool) {
+	// v2 names the files memory.max and memory.current, v1 limit_in_bytes and
+	// usage_in_bytes, with a huge number for no limit.
+	limitFile, usageFile := "memory.max", "memory.current"
+	if strings.HasPrefix(cgroup, cgroupRoot+"/memory/") || cgroup == cgroupRoot+"/memory" {
+		limitFile, usageFile = "memory.limit_in_bytes", "memory.usage_in_bytes"
+	}
+	var best cgroupMem
+	found := false
+	for dir := cgroup; strings.HasPrefix(dir, cgroupRoot+"/"); dir = path.Dir(dir) {
+		limit, ok := readSysUint(path.Join(dir, limitFile))
+		if !ok || limit == 0 || (hostTotal > 0 && limit >= hostTotal) {
+			continue
+		}
+		current, ok := readSysUint(path.Join(dir, usageFile))
+		if !ok {
+			continue
+		}
+		m := cgroupMem{current: current, limit: limit}
+		if !found || m.percent() > best.percent() {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+// memLimitSupported reports whether cgroup memory limits are read on this platform; cgroups
+// are a Linux feature.
+const memLimitSupported = false
+
+func cgroupOf(pid int32) string {
+	return ""
+}
+
+func readCgroupMem(cgroup string, hostTotal uint64) (cgroupMem, bool) {
+	return cgroupMem{}, false
+}
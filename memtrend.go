@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// memTrendWindow is how much of the available-memory history the exhaustion estimate fits
+// its rate to; memTrendMinSpan is the least it needs, so a single allocation burst right
+// after startup doesn't predict an imminent OOM.
+const (
+	memTrendWindow  = 5 * time.Minute
+	memTrendMinSpan = time.Minute
+)
+
+// maxExhaustionHorizon bounds memory.exhaustion_horizon: a rate fitted over a few minutes
+// says nothing about the next day.
+const maxExhaustionHorizon = 24 * time.Hour
+
+// trendSlope fits a least-squares line through samples and returns its slope in value per
+// second.
+func trendSlope(samples []sample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	t0 := samples[0].at
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.at.Sub(t0).Seconds()
+		sumX += x
+		sumY += s.value
+		sumXY += x * s.value
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+// memExhaustion estimates how long the available memory lasts at the rate it shrank over
+// samples, the recent history of MemAvailable. The headroom is what is left after the
+// horizon at that rate; the estimate is only made when it is negative. It needs
+// memTrendMinSpan of history, and the shrinking must be sustained, holding both over the
+// whole window and over its last memTrendMinSpan, so it disappears as soon as the trend
+// reverses.
+func memExhaustion(samples []sample, available uint64, horizon time.Duration) (time.Duration, bool) {
+	if horizon <= 0 || len(samples) < 3 {
+		return 0, false
+	}
+	last := samples[len(samples)-1]
+	if last.at.Sub(samples[0].at) < memTrendMinSpan {
+		return 0, false
+	}
+	rate := trendSlope(samples)
+	if rate >= 0 {
+		return 0, false
+	}
+	from := last.at.Add(-memTrendMinSpan)
+	tail := samples
+	for i, s := range samples {
+		if !s.at.Before(from) {
+			tail = samples[i:]
+			break
+		}
+	}
+	if trendSlope(tail) >= 0 {
+		return 0, false
+	}
+	if headroom := float64(available) + rate*horizon.Seconds(); headroom > 0 {
+		return 0, false
+	}
+	return time.Duration(float64(available) / -rate * float64(time.Second)), true
+}
+
+// formatExhaustion renders the time left in minutes, the resolution a rate fitted over a
+// few minutes supports.
+func formatExhaustion(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1 min"
+	case d < 2*time.Hour:
+		return fmt.Sprintf("~%d min", d.Round(time.Minute)/time.Minute)
+	}
+	return "~" + formatAge(d.Round(time.Minute))
+}
+
+// viewExhaustion renders the memory exhaustion warning shown next to the last update time,
+// "" while available memory is not on course to run out within the horizon.
+func (m model) viewExhaustion() string {
+	last, ok := m.availHistory.last()
+	if !ok {
+		return ""
+	}
+	samples := m.availHistory.since(last.at.Add(-memTrendWindow))
+	eta, ok := memExhaustion(samples, m.data.Mem.Available, m.cfg.Memory.ExhaustionHorizon)
+	if !ok {
+		return ""
+	}
+	return m.baseStyle.Foreground(Color.Red).Render(formatExhaustion(eta) + " until memory exhaustion at current rate")
+}
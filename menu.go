@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// menuItem is one entry of a menu. An item with a disabled reason is listed greyed out
+// with the reason and cannot be chosen.
+type menuItem struct {
+	id       string
+	label    string
+	disabled string
+}
+
+// menu is a vertical list to pick one item from with the arrow keys, skipping the
+// disabled ones.
+type menu struct {
+	title  string
+	items  []menuItem
+	cursor int
+}
+
+// newMenu returns a menu with the cursor on the first item that can be chosen.
+func newMenu(title string, items []menuItem) *menu {
+	mn := &menu{title: title, items: items, cursor: -1}
+	mn.move(1)
+	return mn
+}
+
+// move steps the cursor to the next enabled item in the direction of step, wrapping
+// around; it stays put when no other item is enabled.
+func (mn *menu) move(step int) {
+	n := len(mn.items)
+	for i, at := 1, mn.cursor; i <= n; i++ {
+		next := ((at+i*step)%n + n) % n
+		if mn.items[next].disabled == "" {
+			mn.cursor = next
+			return
+		}
+	}
+}
+
+// selected returns the item under the cursor, false when every item is disabled.
+func (mn *menu) selected() (menuItem, bool) {
+	if mn.cursor < 0 || mn.cursor >= len(mn.items) {
+		return menuItem{}, false
+	}
+	return mn.items[mn.cursor], true
+}
+
+// viewMenu renders a menu in the main region, the cursor highlighted and disabled items
+// dimmed with their reason.
+func (m model) viewMenu(mn *menu, keys []string) string {
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	lines := []string{title(mn.title), ""}
+	for i, item := range mn.items {
+		switch {
+		case item.disabled != "":
+			lines = append(lines, hint("  "+item.label+" ("+item.disabled+")"))
+		case i == mn.cursor:
+			lines = append(lines, m.baseStyle.Background(Color.Highlight).Render("> "+item.label))
+		default:
+			lines = append(lines, "  "+item.label)
+		}
+	}
+	lines = append(lines, "", hint(strings.Join(keys, " · ")))
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// meterInput is what a header meter draws: the current percentage plus the recent values
+// for styles that show a trend, oldest first.
+type meterInput struct {
+	value   float64
+	history []float64
+	fill    lipgloss.AdaptiveColor
+}
+
+// meter renders one header gauge within width cells.
+type meter interface {
+	render(in meterInput, width int) string
+}
+
+// meterStyle selects how a header meter is drawn.
+type meterStyle int
+
+const (
+	meterStyleBar meterStyle = iota
+	meterStyleGraph
+	meterStyleNumeric
+)
+
+func (s meterStyle) String() string {
+	switch s {
+	case meterStyleGraph:
+		return "graph"
+	case meterStyleNumeric:
+		return "numeric"
+	default:
+		return "bar"
+	}
+}
+
+// next returns the style that follows s when cycling through the options.
+func (s meterStyle) next() meterStyle {
+	return (s + 1) % (meterStyleNumeric + 1)
+}
+
+// parseMeterStyle converts a config value into a meter style.
+func parseMeterStyle(name string) (meterStyle, error) {
+	for s := meterStyleBar; s <= meterStyleNumeric; s++ {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("must be \"bar\", \"graph\" or \"numeric\", got %q", name)
+}
+
+// newMeter returns the meter drawing the given style; ascii draws bars without partial
+// blocks.
+func newMeter(s meterStyle, baseStyle lipgloss.Style, ascii bool) meter {
+	switch s {
+	case meterStyleGraph:
+		return graphMeter{baseStyle}
+	case meterStyleNumeric:
+		return numericMeter{baseStyle}
+	default:
+		return barMeter{baseStyle, ascii}
+	}
+}
+
+// meterValue formats a meter percentage.
+func meterValue(v float64) string {
+	return formatFloat(v, 1) + "%"
+}
+
+// clampPercent bounds v to 0–100 for drawing. Rounding elsewhere can report a little over
+// 100% or under 0, and a NaN, which min and max pass through, draws as empty.
+func clampPercent(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return min(max(v, 0), 100)
+}
+
+// valueWidth is the room reserved after a bar or graph for " 100.0%", so the gauge keeps
+// its width as the value changes.
+const valueWidth = 7
+
+// paddedValue formats v after a space, padded to valueWidth.
+func paddedValue(v float64) string {
+	return fmt.Sprintf("%-*s", valueWidth, " "+meterValue(v))
+}
+
+// barBlocks are the partial blocks drawing the last cell of a bar, an eighth wider each.
+var barBlocks = []rune("▏▎▍▌▋▊▉█")
+
+// asciiBarCell is the cell an ASCII bar is filled with.
+const asciiBarCell = "|"
+
+// barMeter is the bracketed "[█████▍    ] 42.0%" bar. The last filled cell takes one of
+// eight partial blocks, so a 20-cell bar moves in steps of 1/160 rather than 5%. In ASCII
+// it is "[||||||    ] 42.0%", rounded to whole cells.
+type barMeter struct {
+	baseStyle lipgloss.Style
+	ascii     bool
+}
+
+func (b barMeter) render(in meterInput, width int) string {
+	value := paddedValue(in.value)
+	totalBars := max(width-2-valueWidth, 1)
+	share := clampPercent(in.value) / 100
+	var fill string
+	var cells int
+	if b.ascii {
+		cells = int(math.Round(share * float64(totalBars)))
+		fill = strings.Repeat(asciiBarCell, cells)
+	} else {
+		eighths := int(math.Round(share * float64(totalBars*8)))
+		full, part := eighths/8, eighths%8
+		fill = strings.Repeat(string(barBlocks[7]), full)
+		cells = full
+		if part > 0 {
+			fill += string(barBlocks[part-1])
+			cells++
+		}
+	}
+	filled := b.baseStyle.Foreground(in.fill).Render(fill)
+	empty := b.baseStyle.Render(strings.Repeat(" ", totalBars-cells))
+	return b.baseStyle.Render("[" + filled + empty + "]" + value)
+}
+
+// graphRows is the height of the braille graph; each row adds four levels of resolution.
+const graphRows = 2
+
+// brailleDots are the dot bits of the left and right braille columns, bottom dot first.
+var brailleDots = [2][4]rune{
+	{0x40, 0x04, 0x02, 0x01},
+	{0x80, 0x20, 0x10, 0x08},
+}
+
+// graphMeter draws the recent values as a braille area graph, two samples per cell,
+// with the current value after the top row.
+type graphMeter struct{ baseStyle lipgloss.Style }
+
+func (g graphMeter) render(in meterInput, width int) string {
+	value := paddedValue(in.value)
+	cells := max(width-valueWidth, 1)
+
+	// The newest samples fill the right-hand side; missing older ones stay blank.
+	history := in.history[max(len(in.history)-cells*2, 0):]
+	levels := make([]int, cells*2)
+	for i := range levels {
+		levels[i] = -1
+	}
+	for i, v := range history {
+		levels[len(levels)-len(history)+i] = int(math.Round(clampPercent(v) / 100 * graphRows * 4))
+	}
+
+	rows := make([]string, graphRows)
+	for r := range rows {
+		// base is the number of dot levels below this row.
+		base := (graphRows - 1 - r) * 4
+		var b strings.Builder
+		for c := 0; c < cells; c++ {
+			ch := rune(0x2800)
+			for side := 0; side < 2; side++ {
+				filled := min(max(levels[c*2+side]-base, 0), 4)
+				for d := 0; d < filled; d++ {
+					ch |= brailleDots[side][d]
+				}
+			}
+			b.WriteRune(ch)
+		}
+		rows[r] = g.baseStyle.Foreground(in.fill).Render(b.String())
+	}
+	rows[0] += g.baseStyle.Render(value)
+	return strings.Join(rows, "\n")
+}
+
+// numericMeter shows only the percentage, for narrow terminals or minimal headers.
+type numericMeter struct{ baseStyle lipgloss.Style }
+
+func (n numericMeter) render(in meterInput, width int) string {
+	return n.baseStyle.Foreground(in.fill).Width(min(width, 7)).Render(meterValue(in.value))
+}
+
+// Indexes of the header meters, in display order.
+const (
+	meterCPU = iota
+	meterMem
+	meterCount
+)
+
+// meterNames label the header meters.
+var meterNames = [meterCount]string{"CPU", "MEM"}
+
+// meterWidth returns the width of the header meters: the full bar when the terminal is
+// wide enough, a compact one otherwise.
+func (m model) meterWidth() int {
+	if m.width > 0 && m.width < 110 {
+		return 17
+	}
+	return 29
+}
+
+// recentValues returns up to n of the newest samples of s, oldest first.
+func recentValues(s *series, n int) []float64 {
+	recent := s.recent[max(len(s.recent)-n, 0):]
+	values := make([]float64, len(recent))
+	for i, smp := range recent {
+		values[i] = smp.value
+	}
+	return values
+}
+
+// viewMeter renders the header meter with index i, with its label highlighted while it is
+// selected in the focused header.
+func (m model) viewMeter(i int) string {
+	width := m.meterWidth()
+	var in meterInput
+	switch i {
+	case meterCPU:
+		in = meterInput{value: 100 - m.data.CPU.Idle, history: recentValues(m.cpuHistory, width*2), fill: Color.Green}
+	case meterMem:
+		in = meterInput{value: m.data.Mem.UsedPercent, history: recentValues(m.memHistory, width*2), fill: memBarColor(m.data.Mem)}
+	}
+	label := m.baseStyle.Render(meterNames[i] + ":")
+	if m.focus == areaHeader && m.meterFocus == i {
+		label = m.baseStyle.Foreground(Color.Highlight).Bold(true).Render(meterNames[i] + ":")
+	}
+	// CPU usage is a rate over the interval, which a clock jump spoils.
+	if i == meterCPU && m.data.ClockJump != 0 {
+		return label + " " + discardedRate
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, label+" ", newMeter(m.meterStyles[i], m.baseStyle, m.cfg.Meters.ASCII).render(in, width))
+}
+
+// updateHeaderFocus handles keys while the header is focused: left/right select a meter,
+// enter cycles its style, space freezes the header, tab moves on and esc or q
+// returns to the table.
+func (m model) updateHeaderFocus(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h", "up", "k":
+		m.meterFocus = (m.meterFocus + meterCount - 1) % meterCount
+	case "right", "l", "down", "j":
+		m.meterFocus = (m.meterFocus + 1) % meterCount
+	case "enter":
+		m.meterStyles[m.meterFocus] = m.meterStyles[m.meterFocus].next()
+	case " ":
+		m.toggleFreeze(areaHeader)
+	case "tab":
+		m.focus = m.nextFocus()
+	case "esc", "q":
+		m.focus = areaProcesses
+	case "p":
+		m.togglePause()
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
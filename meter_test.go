@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// withPlainProfile renders without colors for the rest of the test, so golden files hold
+// just the drawing.
+func withPlainProfile(t testing.TB) {
+	old := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	t.Cleanup(func() { lipgloss.SetColorProfile(old) })
+}
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// meterValues are the values each golden file draws, the clamps below 0 and above 100
+// and a NaN included.
+var meterValues = []float64{-5, 0, 0.4, 42.5, 99.9, 100, 120, math.NaN()}
+
+func TestMeterGolden(t *testing.T) {
+	withPlainProfile(t)
+	history := []float64{-10, 0, 5, 12.5, 25, 37.5, 50, 62.5, 75, 87.5, 100, 130, math.NaN(), 60, 30}
+	for s := meterStyleBar; s <= meterStyleNumeric; s++ {
+		// The header's compact and full widths; see meterWidth.
+		for _, width := range []int{17, 29} {
+			var b strings.Builder
+			for _, v := range meterValues {
+				out := newMeter(s, lipgloss.NewStyle(), false).render(meterInput{value: v, history: history}, width)
+				for _, line := range strings.Split(out, "\n") {
+					if w := lipgloss.Width(line); w > width {
+						t.Errorf("%s meter at %v%% is %d cells wide, want at most %d", s, v, w, width)
+					}
+				}
+				fmt.Fprintf(&b, "%6.1f%% |%s|\n", v, strings.ReplaceAll(out, "\n", "|\n        |"))
+			}
+			checkGolden(t, fmt.Sprintf("meter_%s_%d.golden", s, width), b.String())
+		}
+	}
+}
+
+func TestMeterASCIIGolden(t *testing.T) {
+	withPlainProfile(t)
+	for _, width := range []int{17, 29} {
+		var b strings.Builder
+		for _, v := range meterValues {
+			out := newMeter(meterStyleBar, lipgloss.NewStyle(), true).render(meterInput{value: v}, width)
+			if lipgloss.Width(out) != width {
+				t.Errorf("ASCII bar at %v%% is %d cells wide, want %d", v, lipgloss.Width(out), width)
+			}
+			bar, _, _ := strings.Cut(strings.TrimPrefix(out, "["), "]")
+			if strings.Trim(bar, "| ") != "" || strings.Contains(strings.TrimRight(bar, " "), " ") {
+				t.Errorf("ASCII bar at %v%% is %q, want whole | cells", v, bar)
+			}
+			fmt.Fprintf(&b, "%6.1f%% |%s|\n", v, out)
+		}
+		checkGolden(t, fmt.Sprintf("meter_bar_ascii_%d.golden", width), b.String())
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MetricsConfig is the [metrics] section of the config file: which processes the /metrics
+// endpoint of the serve subcommand exports. Every process would mean a label set per PID
+// and new series on every restart, so by default none are exported; Top and Allow pick
+// the ones worth having, and MaxSeries caps them whatever the host runs.
+type MetricsConfig struct {
+	// Top exports the Top busiest processes by CPU and the Top largest by memory, 0 for
+	// none.
+	Top int `toml:"top"`
+	// Allow exports the processes whose name matches one of these patterns, e.g. "nginx"
+	// or "postgres*", on top of the Top ones.
+	Allow []string `toml:"allow"`
+	// MaxSeries is the most processes, or names with ByName, exported at once; the busiest
+	// by CPU are kept and the rest counted in smtui_process_series_dropped_total.
+	MaxSeries int `toml:"max_series"`
+	// ByName sums the processes of each name into one series without a pid label, so a
+	// restarted service keeps its series.
+	ByName bool `toml:"by_name"`
+}
+
+// checkMetrics validates the [metrics] section.
+func checkMetrics(c MetricsConfig) error {
+	if c.Top < 0 {
+		return fmt.Errorf("top must not be negative, got %d", c.Top)
+	}
+	if c.MaxSeries < 1 {
+		return fmt.Errorf("max_series must be at least 1, got %d", c.MaxSeries)
+	}
+	for _, pattern := range c.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("allow pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// procSeries is one exported process, or every process of a name with by_name.
+type procSeries struct {
+	name string
+	// pid is unused in a series summed by name; count is how many processes it sums.
+	pid   int32
+	count int
+	cpu   float64
+	mem   uint64
+}
+
+// enabled reports whether any process is exported.
+func (c MetricsConfig) enabled() bool {
+	return c.Top > 0 || len(c.Allow) > 0
+}
+
+// allowed reports whether name matches an allow pattern.
+func (c MetricsConfig) allowed(name string) bool {
+	for _, pattern := range c.Allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// processSeries picks the series to export from procs, busiest by CPU first, and returns
+// how many more were picked than max_series lets through.
+func (c MetricsConfig) processSeries(procs []ProcessInfo) (series []procSeries, dropped int) {
+	if !c.enabled() {
+		return nil, 0
+	}
+	var all []procSeries
+	if c.ByName {
+		index := map[string]int{}
+		for _, p := range procs {
+			i, ok := index[p.Name]
+			if !ok {
+				i = len(all)
+				index[p.Name] = i
+				all = append(all, procSeries{name: p.Name})
+			}
+			all[i].count++
+			all[i].cpu += p.CPUPercent
+			all[i].mem += p.Memory
+		}
+	} else {
+		for _, p := range procs {
+			all = append(all, procSeries{name: p.Name, pid: p.PID, count: 1, cpu: p.CPUPercent, mem: p.Memory})
+		}
+	}
+
+	picked := make([]bool, len(all))
+	for i, s := range all {
+		picked[i] = c.allowed(s.name)
+	}
+	if c.Top > 0 {
+		order := make([]int, len(all))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int { return cmp.Compare(all[b].cpu, all[a].cpu) })
+		for _, i := range order[:min(c.Top, len(order))] {
+			picked[i] = true
+		}
+		slices.SortFunc(order, func(a, b int) int { return cmp.Compare(all[b].mem, all[a].mem) })
+		for _, i := range order[:min(c.Top, len(order))] {
+			picked[i] = true
+		}
+	}
+	for i, s := range all {
+		if picked[i] {
+			series = append(series, s)
+		}
+	}
+	// Ties fall back to the name and PID, so the same processes survive the cap from one
+	// collection to the next.
+	slices.SortFunc(series, func(a, b procSeries) int {
+		return cmp.Or(cmp.Compare(b.cpu, a.cpu), cmp.Compare(b.mem, a.mem), cmp.Compare(a.name, b.name), cmp.Compare(a.pid, b.pid))
+	})
+	if len(series) > c.MaxSeries {
+		dropped = len(series) - c.MaxSeries
+		series = series[:c.MaxSeries]
+	}
+	return series, dropped
+}
+
+// promLabelEscaper escapes a label value for the Prometheus text format, which only knows
+// these three escapes; %q would also turn non-ASCII names into Go escapes.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels renders the label set of a series, without the pid when summed by name.
+func (s procSeries) labels(byName bool) string {
+	if byName {
+		return `{name="` + promLabelEscaper.Replace(s.name) + `"}`
+	}
+	return fmt.Sprintf(`{pid="%d",name="%s"}`, s.pid, promLabelEscaper.Replace(s.name))
+}
+
+// writeProcessMetrics adds the per-process metrics of series to b, with the running count
+// of series dropped by the cap.
+func writeProcessMetrics(b *strings.Builder, series []procSeries, byName bool, dropped uint64) {
+	b.WriteString("# HELP smtui_process_cpu_percent CPU usage of a process.\n# TYPE smtui_process_cpu_percent gauge\n")
+	for _, s := range series {
+		fmt.Fprintf(b, "smtui_process_cpu_percent%s %g\n", s.labels(byName), s.cpu)
+	}
+	b.WriteString("# HELP smtui_process_memory_bytes Resident memory of a process.\n# TYPE smtui_process_memory_bytes gauge\n")
+	for _, s := range series {
+		fmt.Fprintf(b, "smtui_process_memory_bytes%s %d\n", s.labels(byName), s.mem)
+	}
+	if byName {
+		b.WriteString("# HELP smtui_process_count Processes running under a name.\n# TYPE smtui_process_count gauge\n")
+		for _, s := range series {
+			fmt.Fprintf(b, "smtui_process_count%s %d\n", s.labels(byName), s.count)
+		}
+	}
+	fmt.Fprintf(b, "# HELP smtui_process_series_dropped_total Process series left out by max_series, summed over collections.\n# TYPE smtui_process_series_dropped_total counter\nsmtui_process_series_dropped_total %d\n", dropped)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// NamesConfig is the [names] section of the config file.
+type NamesConfig struct {
+	// Friendly shows processes of known interpreters by the program they run, e.g.
+	// "python3: celery worker" or "java: kafka.Kafka", in the Name column; n switches
+	// between these and the raw names for the session. The detail view shows both.
+	Friendly bool `toml:"friendly"`
+	// Keys also matches highlight.names against the friendly names, so the watched panel
+	// and the restart counts can tell one Python service from another. The names are
+	// guessed from the command line, so this is off by default.
+	Keys bool `toml:"keys"`
+}
+
+// namePrefs is the package-wide copy of [names] that the Name column and the watched
+// process lookups read, like Units; Friendly is toggled with n.
+type namePrefs struct {
+	Friendly, Keys bool
+}
+
+// Names is the active name display.
+var Names namePrefs
+
+// interpreter describes the command line of an interpreter, for finding the program it
+// runs.
+type interpreter struct {
+	// names are the process names it runs as, with any version suffix such as "3.11"
+	// removed.
+	names []string
+	// valueFlags take the next argument as their value, which is not the program.
+	valueFlags []string
+	// programFlags take the program as their value, e.g. python's -m module or java's
+	// -jar file.
+	programFlags []string
+	// codeFlags run code given on the command line, which has no name to show.
+	codeFlags []string
+}
+
+// interpreters are the interpreters whose programs are named. Flags not listed are
+// skipped as if they took no value, unless given as --flag=value.
+var interpreters = []interpreter{
+	{
+		names:        []string{"python", "pypy"},
+		valueFlags:   []string{"-W", "-X", "-Q", "--check-hash-based-pycs"},
+		programFlags: []string{"-m"},
+		codeFlags:    []string{"-c"},
+	},
+	{
+		names: []string{"java"},
+		valueFlags: []string{"-cp", "-classpath", "--class-path", "-p", "--module-path", "--upgrade-module-path",
+			"--add-modules", "--add-opens", "--add-exports", "--add-reads", "--patch-module", "--enable-native-access"},
+		programFlags: []string{"-jar", "-m", "--module"},
+	},
+	{
+		names:      []string{"node", "nodejs"},
+		valueFlags: []string{"-r", "--require", "--import", "--loader", "--experimental-loader", "--env-file", "--title"},
+		codeFlags:  []string{"-e", "--eval", "-p", "--print"},
+	},
+	{
+		names:      []string{"ruby"},
+		valueFlags: []string{"-I", "-r", "-E", "--encoding"},
+		codeFlags:  []string{"-e"},
+	},
+	{
+		names:      []string{"perl"},
+		valueFlags: []string{"-I", "-M", "-m"},
+		codeFlags:  []string{"-e", "-E"},
+	},
+	{
+		names:      []string{"php"},
+		valueFlags: []string{"-c", "-d", "-z"},
+		codeFlags:  []string{"-r"},
+	},
+	{
+		names:      []string{"sh", "bash", "dash", "zsh", "ksh"},
+		valueFlags: []string{"-o", "+o", "-O", "+O", "--rcfile", "--init-file"},
+		codeFlags:  []string{"-c"},
+	},
+	{
+		names:      []string{"dotnet"},
+		valueFlags: []string{"--additionalprobingpath", "--depsfile", "--runtimeconfig", "--fx-version", "--roll-forward"},
+	},
+}
+
+// versionSuffix is the version an interpreter's name may end in, as in python3.11.
+var versionSuffix = regexp.MustCompile(`[0-9.]+$`)
+
+// programExts are the file extensions dropped from program names.
+var programExts = []string{".py", ".pyc", ".pyz", ".js", ".mjs", ".cjs", ".ts", ".jar", ".rb", ".pl", ".php", ".sh", ".bash", ".dll"}
+
+// genericPrograms are entry points named by convention rather than after what they do;
+// the directory they are in is shown with them, as in "shop/manage runserver".
+var genericPrograms = []string{"main", "index", "app", "server", "run", "start", "cli", "manage", "__main__", "wsgi", "asgi"}
+
+// buildDirs are skipped when naming a generic entry point by its directory, so that
+// /srv/api/dist/index.js is "api/index".
+var buildDirs = []string{"dist", "build", "out", "src", "lib", "bin"}
+
+// subcommandWord matches a bare word following the program, shown with it as in
+// "celery worker". Paths, options and anything with punctuation are left out.
+var subcommandWord = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// findInterpreter returns the interpreter a process named name is, if any.
+func findInterpreter(name string) (interpreter, bool) {
+	base := versionSuffix.ReplaceAllString(name, "")
+	for _, in := range interpreters {
+		if slices.Contains(in.names, base) {
+			return in, true
+		}
+	}
+	return interpreter{}, false
+}
+
+// displayName derives a friendlier name for an interpreter process from its command line,
+// e.g. "python3: celery worker" for "python3 -m celery worker -A proj". It returns "" when
+// name isn't a known interpreter or the program can't be told, such as for code passed
+// with -c. The command line is split at spaces, as gopsutil joins it.
+func displayName(name, cmdline string) string {
+	in, ok := findInterpreter(name)
+	if !ok {
+		return ""
+	}
+	args := strings.Fields(cmdline)
+	if len(args) < 2 {
+		return ""
+	}
+	program, rest := "", []string(nil)
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "+") {
+			if arg == "--" {
+				i++
+			}
+			if i < len(args) {
+				program, rest = interpretedProgram(args[i], false), args[i+1:]
+			}
+			break
+		}
+		flag, _, hasValue := strings.Cut(arg, "=")
+		switch {
+		case slices.Contains(in.codeFlags, flag):
+			return ""
+		case slices.Contains(in.programFlags, flag):
+			if i+1 < len(args) {
+				program, rest = interpretedProgram(args[i+1], flag == "-m" || flag == "--module"), args[i+2:]
+			}
+		case slices.Contains(in.valueFlags, flag) && !hasValue:
+			i++
+			continue
+		default:
+			continue
+		}
+		break
+	}
+	if program == "" {
+		return ""
+	}
+	if len(rest) > 0 && subcommandWord.MatchString(rest[0]) {
+		program += " " + rest[0]
+	}
+	return name + ": " + program
+}
+
+// interpretedProgram shortens the program argument of an interpreter: the file name
+// without its extension, after the directory it is in for generic entry points such as
+// index.js. Modules and main classes are kept whole, as in http.server or kafka.Kafka.
+func interpretedProgram(arg string, module bool) string {
+	if module || !strings.Contains(arg, "/") && !slices.ContainsFunc(programExts, func(ext string) bool { return strings.HasSuffix(arg, ext) }) {
+		return arg
+	}
+	base := path.Base(arg)
+	for _, ext := range programExts {
+		if trimmed, ok := strings.CutSuffix(base, ext); ok && trimmed != "" {
+			base = trimmed
+			break
+		}
+	}
+	if slices.Contains(genericPrograms, base) {
+		dir := path.Dir(arg)
+		for slices.Contains(buildDirs, path.Base(dir)) {
+			dir = path.Dir(dir)
+		}
+		if name := path.Base(dir); name != "." && name != "/" {
+			return name + "/" + base
+		}
+	}
+	return base
+}
+
+// shownName is the name the Name column shows for p.
+func shownName(p ProcessInfo) string {
+	if Names.Friendly && p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return p.Name
+}
+
+// nameMatches reports whether p goes by name, which is its raw name unless names.keys
+// also lets the friendly name match.
+func nameMatches(p ProcessInfo, name string) bool {
+	return p.Name == name || Names.Keys && p.DisplayName != "" && p.DisplayName == name
+}
+
+// watchedName returns the name among names that p goes by, if any.
+func watchedName(p ProcessInfo, names []string) (string, bool) {
+	i := slices.IndexFunc(names, func(name string) bool { return nameMatches(p, name) })
+	if i < 0 {
+		return "", false
+	}
+	return names[i], true
+}
+
+// sameName reports whether a and b go by the same name, which with names.keys includes
+// their friendly names: two Python services aren't the same process restarting.
+func sameName(a, b ProcessInfo) bool {
+	return a.Name == b.Name && (!Names.Keys || a.DisplayName == b.DisplayName)
+}
+
+// prefs returns the package-wide copy of c.
+func (c NamesConfig) prefs() namePrefs {
+	return namePrefs{Friendly: c.Friendly, Keys: c.Keys}
+}
@@ -0,0 +1,130 @@
+package main
+
+import "testing"
+
+// displayNameCorpus are command lines as /proc/<pid>/cmdline has them, joined by spaces
+// the way gopsutil reads them, with the name the Name column should show.
+var displayNameCorpus = []struct {
+	name, cmdline, want string
+}{
+	// Python
+	{"python3", "/usr/bin/python3 -m celery worker -A proj --loglevel=info", "python3: celery worker"},
+	{"python3", "python3 -m http.server 8000", "python3: http.server"},
+	{"python3.11", "/usr/local/bin/python3.11 -W ignore /srv/shop/manage.py runserver 0.0.0.0:8000", "python3.11: shop/manage runserver"},
+	{"python3", "python3 -u /opt/exporter/node_exporter_textfile.py", "python3: node_exporter_textfile"},
+	{"python3", "/usr/bin/python3 -X dev -s /usr/bin/gunicorn app:app -w 4", "python3: gunicorn"},
+	{"python3", "python3 -c import time; time.sleep(100)", ""},
+	{"python3", "python3", ""},
+	{"python", "python -- /tmp/job.py", "python: job"},
+	{"pypy3", "pypy3 bench.py", "pypy3: bench"},
+	// Java
+	{"java", "/usr/lib/jvm/java-17/bin/java -Xmx1G -Xms1G -server -cp /opt/kafka/libs/* -Dlog4j.configuration=file:/opt/kafka/config/log4j.properties kafka.Kafka /opt/kafka/config/server.properties", "java: kafka.Kafka"},
+	{"java", "java -jar /opt/app/build/payments-1.2.jar --port 8080", "java: payments-1.2"},
+	{"java", "java -classpath lib/a.jar:lib/b.jar org.example.Main", "java: org.example.Main"},
+	{"java", "java --add-opens java.base/java.lang=ALL-UNNAMED -m com.example.app/com.example.App", "java: com.example.app/com.example.App"},
+	{"java", "java -version", ""},
+	// Node
+	{"node", "node -r dotenv/config /srv/api/dist/index.js", "node: api/index"},
+	{"node", "/usr/bin/node --require ts-node/register src/server.ts", "node: server"},
+	{"node", "node /usr/lib/node_modules/npm/bin/npm-cli.js install", "node: npm-cli install"},
+	{"node", "node --max-old-space-size=4096 build/main.js", "node: main"},
+	{"node", "node -e require('http').createServer().listen(3000)", ""},
+	{"nodejs", "nodejs --eval console.log(1)", ""},
+	// Ruby, Perl, PHP
+	{"ruby", "ruby bin/rails server -p 3000", "ruby: rails server"},
+	{"ruby", "ruby -e puts 1", ""},
+	{"perl", "/usr/bin/perl -I /opt/lib /usr/sbin/munin-node", "perl: munin-node"},
+	{"php", "php -d memory_limit=-1 artisan queue:work", "php: artisan"},
+	// Shells
+	{"bash", "/bin/bash /usr/local/bin/backup.sh nightly", "bash: backup nightly"},
+	{"bash", "bash -c sleep 10", ""},
+	{"sh", "/bin/sh -c cd /app && exec ./run", ""},
+	{"bash", "-bash", ""},
+	{"zsh", "zsh", ""},
+	{"dash", "/bin/dash -e /etc/init.d/ssh start", "dash: ssh start"},
+	// dotnet
+	{"dotnet", "dotnet /app/Api.dll --urls http://*:80", "dotnet: Api"},
+	// Not interpreters
+	{"nginx", "nginx: master process /usr/sbin/nginx -g daemon off;", ""},
+	{"postgres", "postgres: checkpointer", ""},
+	{"pythonista", "pythonista -m foo", ""},
+}
+
+func TestDisplayName(t *testing.T) {
+	for _, c := range displayNameCorpus {
+		if got := displayName(c.name, c.cmdline); got != c.want {
+			t.Errorf("displayName(%q, %q) = %q, want %q", c.name, c.cmdline, got, c.want)
+		}
+	}
+}
+
+func TestInterpretedProgram(t *testing.T) {
+	tests := []struct {
+		arg    string
+		module bool
+		want   string
+	}{
+		{"celery", true, "celery"},
+		{"http.server", true, "http.server"},
+		{"kafka.Kafka", false, "kafka.Kafka"},
+		{"script.py", false, "script"},
+		{"/opt/tools/sync.rb", false, "sync"},
+		{"/srv/api/dist/index.js", false, "api/index"},
+		{"/srv/api/build/out/main.mjs", false, "api/main"},
+		{"dist/index.js", false, "index"},
+		{"/index.js", false, "index"},
+		{"/srv/web/app.py", false, "web/app"},
+		{"/srv/shop/manage.py", false, "shop/manage"},
+		{"/usr/bin/gunicorn", false, "gunicorn"},
+		{".py", false, ".py"},
+	}
+	for _, tt := range tests {
+		if got := interpretedProgram(tt.arg, tt.module); got != tt.want {
+			t.Errorf("interpretedProgram(%q, %v) = %q, want %q", tt.arg, tt.module, got, tt.want)
+		}
+	}
+}
+
+// withNames sets the package-wide name preferences for the rest of the test.
+func withNames(t *testing.T, p namePrefs) {
+	old := Names
+	Names = p
+	t.Cleanup(func() { Names = old })
+}
+
+func TestNameKeysOptIn(t *testing.T) {
+	celery := ProcessInfo{Name: "python3", DisplayName: "python3: celery worker"}
+	flower := ProcessInfo{Name: "python3", DisplayName: "python3: flower"}
+
+	withNames(t, namePrefs{Friendly: true})
+	if !nameMatches(celery, "python3") {
+		t.Error("raw name doesn't match")
+	}
+	if nameMatches(celery, "python3: celery worker") {
+		t.Error("friendly name matches without names.keys")
+	}
+	if !sameName(celery, flower) {
+		t.Error("processes with the same raw name differ without names.keys")
+	}
+	if shownName(celery) != "python3: celery worker" {
+		t.Errorf("shownName = %q with friendly names on", shownName(celery))
+	}
+
+	withNames(t, namePrefs{Friendly: false, Keys: true})
+	if !nameMatches(celery, "python3: celery worker") || !nameMatches(celery, "python3") {
+		t.Error("names.keys doesn't match both names")
+	}
+	if sameName(celery, flower) {
+		t.Error("different programs are the same with names.keys")
+	}
+	if name, ok := watchedName(flower, []string{"python3: celery worker", "python3: flower"}); !ok || name != "python3: flower" {
+		t.Errorf("watchedName = %q, %v", name, ok)
+	}
+	if shownName(celery) != "python3" {
+		t.Errorf("shownName = %q with friendly names off", shownName(celery))
+	}
+	// A process without a friendly name never matches the empty one.
+	if nameMatches(ProcessInfo{Name: "sshd"}, "") {
+		t.Error("empty display name matches")
+	}
+}
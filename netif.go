@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// NetInterface is the traffic of one network interface over the last interval.
+type NetInterface struct {
+	Name               string
+	RecvRate, SentRate float64
+	// Virtual is set for bridges, veth pairs, tunnels and the like, which carry traffic the
+	// physical interfaces already count.
+	Virtual bool
+	// Peer is the container at the other end of a veth pair, "" when unknown.
+	Peer string
+}
+
+// NetNamespace is a network namespace other than the host's, with the traffic of its
+// interfaces; only listed with network.namespaces.
+type NetNamespace struct {
+	// Label is the name under /run/netns, else the container of its processes, else the
+	// PID of one of them.
+	Label      string
+	Processes  int
+	Interfaces []NetInterface
+}
+
+// virtualPrefixes name the interfaces made by container runtimes, hypervisors and VPNs,
+// the fallback where sysfs doesn't tell virtual devices apart.
+var virtualPrefixes = []string{
+	"veth", "docker", "br-", "cni", "flannel", "cali", "vxlan", "virbr", "vnet", "tun", "tap",
+	"utun", "bridge", "vmnet", "vboxnet", "podman", "weave", "kube-", "lxc", "wg", "awdl", "llw",
+}
+
+// virtualByName reports whether an interface name is one of virtualPrefixes.
+func virtualByName(name string) bool {
+	for _, prefix := range virtualPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nsTraffic is what otherNamespaces reads of one network namespace.
+type nsTraffic struct {
+	// id is the inode of the namespace, label as in NetNamespace.
+	id, label string
+	processes int
+	// counters are the cumulative bytes of its interfaces, by name.
+	counters map[string]ifaceCounters
+}
+
+// ifaceCounters are the bytes received and sent by an interface since it was created.
+type ifaceCounters struct {
+	recv, sent uint64
+}
+
+// ifaceSample is the counters of one interface at one point in time.
+type ifaceSample struct {
+	recv, sent uint64
+	at         time.Time
+}
+
+// ifaceCollector turns the interface counters into rates. Like DiskIOCollector it is not
+// safe for concurrent use.
+type ifaceCollector struct {
+	// prev holds the last sample of every interface, keyed by namespace and name.
+	prev       map[string]ifaceSample
+	namespaces bool
+}
+
+func newIfaceCollector(namespaces bool) *ifaceCollector {
+	return &ifaceCollector{prev: map[string]ifaceSample{}, namespaces: namespaces}
+}
+
+// rates returns the traffic of an interface since its previous sample, 0 on the first, and
+// remembers the new one.
+func (c *ifaceCollector) rates(key string, cur ifaceSample, seen map[string]ifaceSample) (recv, sent float64) {
+	seen[key] = cur
+	prev, ok := c.prev[key]
+	if !ok {
+		return 0, 0
+	}
+	elapsed := cur.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return float64(delta(cur.recv, prev.recv)) / elapsed, float64(delta(cur.sent, prev.sent)) / elapsed
+}
+
+// GetInterfaces lists the host's interfaces, physical ones first, with veth pairs labelled
+// by the container at their other end, and with network.namespaces the interfaces of the
+// other namespaces procs run in.
+func (c *ifaceCollector) GetInterfaces(procs []ProcessInfo) ([]NetInterface, []NetNamespace, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	seen := map[string]ifaceSample{}
+	peers := vethPeers(procs)
+	var ifaces []NetInterface
+	for _, ctr := range counters {
+		if ctr.Name == "lo" || ctr.Name == "lo0" {
+			continue
+		}
+		iface := NetInterface{Name: ctr.Name, Virtual: virtualInterface(ctr.Name), Peer: peers[ctr.Name]}
+		iface.RecvRate, iface.SentRate = c.rates("/"+ctr.Name, ifaceSample{ctr.BytesRecv, ctr.BytesSent, now}, seen)
+		ifaces = append(ifaces, iface)
+	}
+	sortInterfaces(ifaces)
+
+	var namespaces []NetNamespace
+	if c.namespaces {
+		for _, ns := range otherNamespaces(procs) {
+			n := NetNamespace{Label: ns.label, Processes: ns.processes}
+			for name, ctr := range ns.counters {
+				if name == "lo" {
+					continue
+				}
+				iface := NetInterface{Name: name, Virtual: virtualByName(name)}
+				iface.RecvRate, iface.SentRate = c.rates(ns.id+"/"+name, ifaceSample{ctr.recv, ctr.sent, now}, seen)
+				n.Interfaces = append(n.Interfaces, iface)
+			}
+			sortInterfaces(n.Interfaces)
+			namespaces = append(namespaces, n)
+		}
+	}
+	c.prev = seen
+	return ifaces, namespaces, nil
+}
+
+// sortInterfaces puts the physical interfaces before the virtual ones, each by name.
+func sortInterfaces(ifaces []NetInterface) {
+	sort.Slice(ifaces, func(i, j int) bool {
+		if ifaces[i].Virtual != ifaces[j].Virtual {
+			return !ifaces[i].Virtual
+		}
+		return ifaces[i].Name < ifaces[j].Name
+	})
+}
+
+// maxVirtualLines bounds how many virtual interfaces the interfaces panel lists; a host
+// running many containers has one veth each.
+const maxVirtualLines = 10
+
+// interfacesPanel lists the traffic of each interface, the physical ones apart from the
+// bridges and veth pairs whose traffic they also carry, then the other namespaces.
+func (m model) interfacesPanel() panel {
+	p := panel{collector: collectorIfaces, title: "Interfaces"}
+	if len(m.data.Interfaces) == 0 {
+		return p
+	}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	line := func(indent, name string, i NetInterface) string {
+		return fmt.Sprintf("%s%s rx %11s  tx %11s", indent, fit(name, 22-len(indent)), m.rateCell(formatRate(i.RecvRate)), m.rateCell(formatRate(i.SentRate)))
+	}
+	virtual, shown := 0, 0
+	for i, iface := range m.data.Interfaces {
+		if iface.Virtual && (i == 0 || !m.data.Interfaces[i-1].Virtual) {
+			p.lines = append(p.lines, hint("virtual"))
+		} else if i == 0 {
+			p.lines = append(p.lines, hint("physical"))
+		}
+		if iface.Virtual {
+			if virtual++; virtual > maxVirtualLines {
+				continue
+			}
+		}
+		name := iface.Name
+		if iface.Peer != "" {
+			name += " → " + iface.Peer
+		}
+		// The host interfaces can be selected, as listed by shownInterfaces, to filter the
+		// process table by.
+		p.lines = append(p.lines, line(m.linkCursor(collectorIfaces, shown, m.ifaceCursor), name, iface))
+		shown++
+	}
+	if virtual > maxVirtualLines {
+		p.lines = append(p.lines, hint(fmt.Sprintf("  +%d more", virtual-maxVirtualLines)))
+	}
+	for _, ns := range m.data.Namespaces {
+		p.lines = append(p.lines, hint(fmt.Sprintf("netns %s (%d processes)", ns.Label, ns.Processes)))
+		for _, iface := range ns.Interfaces {
+			p.lines = append(p.lines, line("  ", iface.Name, iface))
+		}
+	}
+	return p
+}
@@ -0,0 +1,145 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// virtualInterface reports whether the interface is a virtual device: sysfs files those
+// under /sys/devices/virtual, whatever their name.
+func virtualInterface(name string) bool {
+	target, err := os.Readlink("/sys/class/net/" + name)
+	if err != nil {
+		return virtualByName(name)
+	}
+	return strings.Contains(target, "/devices/virtual/")
+}
+
+// vethPeers maps the host end of each container's veth pair to the container's name. Inside
+// the container, the iflink of its interface is the ifindex of the host end; the container's
+// sysfs is read through the root of one of its processes, which takes root.
+func vethPeers(procs []ProcessInfo) map[string]string {
+	containers := map[string]int32{}
+	for _, p := range procs {
+		if _, ok := containers[p.Container]; p.Container != "" && !ok {
+			containers[p.Container] = p.PID
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+	byIndex := map[uint64]string{}
+	for name, pid := range containers {
+		links, _ := filepath.Glob("/proc/" + strconv.Itoa(int(pid)) + "/root/sys/class/net/*/iflink")
+		for _, link := range links {
+			index, ok1 := readSysUint(filepath.Join(filepath.Dir(link), "ifindex"))
+			iflink, ok2 := readSysUint(link)
+			// An interface that is its own link, such as lo, has no peer outside.
+			if ok1 && ok2 && iflink != index {
+				byIndex[iflink] = name
+			}
+		}
+	}
+	peers := map[string]string{}
+	hostIfaces, _ := filepath.Glob("/sys/class/net/*/ifindex")
+	for _, path := range hostIfaces {
+		if index, ok := readSysUint(path); ok && byIndex[index] != "" {
+			peers[filepath.Base(filepath.Dir(path))] = byIndex[index]
+		}
+	}
+	return peers
+}
+
+// netnsInode returns the inode of a network namespace file, such as /proc/<pid>/ns/net or a
+// name bound under /run/netns.
+func netnsInode(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return st.Ino, true
+}
+
+// otherNamespaces groups procs by network namespace and reads the interface counters of
+// every namespace but the host's through /proc/<pid>/net/dev of one of its processes. A
+// namespace named under /run/netns with no process in it can't be read that way and is
+// left out.
+func otherNamespaces(procs []ProcessInfo) []nsTraffic {
+	host, ok := netnsInode("/proc/self/ns/net")
+	if !ok {
+		return nil
+	}
+	named := map[uint64]string{}
+	if entries, err := os.ReadDir("/run/netns"); err == nil {
+		for _, e := range entries {
+			if ino, ok := netnsInode(filepath.Join("/run/netns", e.Name())); ok {
+				named[ino] = e.Name()
+			}
+		}
+	}
+	byInode := map[uint64]*nsTraffic{}
+	members := map[uint64]int32{}
+	for _, p := range procs {
+		ino, ok := netnsInode("/proc/" + strconv.Itoa(int(p.PID)) + "/ns/net")
+		if !ok || ino == host {
+			continue
+		}
+		ns := byInode[ino]
+		if ns == nil {
+			ns = &nsTraffic{id: strconv.FormatUint(ino, 10), label: named[ino]}
+			byInode[ino] = ns
+			members[ino] = p.PID
+		}
+		if ns.label == "" && p.Container != "" {
+			ns.label = p.Container
+		}
+		ns.processes++
+	}
+	var out []nsTraffic
+	for ino, ns := range byInode {
+		if ns.label == "" {
+			ns.label = "pid " + strconv.Itoa(int(members[ino]))
+		}
+		f, err := os.Open("/proc/" + strconv.Itoa(int(members[ino])) + "/net/dev")
+		if err != nil {
+			continue
+		}
+		ns.counters = parseNetDev(f)
+		f.Close()
+		out = append(out, *ns)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].label < out[j].label })
+	return out
+}
+
+// parseNetDev reads the byte counters of every interface from /proc/net/dev: after two
+// header lines, each line is "name: rx_bytes ... (8 receive fields) tx_bytes ...".
+func parseNetDev(r io.Reader) map[string]ifaceCounters {
+	counters := map[string]ifaceCounters{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(rest)
+		if !ok || len(fields) < 9 {
+			continue
+		}
+		recv, err1 := strconv.ParseUint(fields[0], 10, 64)
+		sent, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 == nil && err2 == nil {
+			counters[strings.TrimSpace(name)] = ifaceCounters{recv, sent}
+		}
+	}
+	return counters
+}
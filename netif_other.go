@@ -0,0 +1,19 @@
+//go:build !linux
+
+package main
+
+// virtualInterface reports whether the interface name is virtual. Only Linux marks
+// virtual devices in sysfs, so elsewhere the name decides.
+func virtualInterface(name string) bool {
+	return virtualByName(name)
+}
+
+// vethPeers is empty: veth pairs and network namespaces are Linux features.
+func vethPeers(procs []ProcessInfo) map[string]string {
+	return nil
+}
+
+// otherNamespaces is empty: network namespaces are a Linux feature.
+func otherNamespaces(procs []ProcessInfo) []nsTraffic {
+	return nil
+}
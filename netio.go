@@ -0,0 +1,25 @@
+package main
+
+import "github.com/shirou/gopsutil/v4/net"
+
+// NetTotals are the bytes received and sent over all interfaces since boot.
+type NetTotals struct {
+	Recv, Sent uint64
+}
+
+// GetNetTotals sums the traffic counters of every network interface except loopback.
+func GetNetTotals() (NetTotals, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return NetTotals{}, err
+	}
+	var totals NetTotals
+	for _, c := range counters {
+		if c.Name == "lo" || c.Name == "lo0" {
+			continue
+		}
+		totals.Recv += c.BytesRecv
+		totals.Sent += c.BytesSent
+	}
+	return totals, nil
+}
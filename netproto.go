@@ -0,0 +1,62 @@
+package main
+
+import "fmt"
+
+// NetProtocols is the protocol-level network activity over the last refresh interval, in
+// events per second unless noted.
+type NetProtocols struct {
+	// V4Recv, V4Sent, V6Recv and V6Sent are the IP traffic of each family in bytes per
+	// second. The kernel counts them system-wide, loopback included, not per interface.
+	// V6Known is false where IPv6 is disabled and the counters don't exist.
+	V4Recv, V4Sent float64
+	V6Recv, V6Sent float64
+	V6Known        bool
+	// TCPOutSegs are the TCP segments sent; TCPRetrans the ones that were sent again.
+	TCPOutSegs, TCPRetrans float64
+	// UDPInErrors are datagrams that could not be delivered, UDPSendErrors those dropped
+	// for lack of send buffer space.
+	UDPInErrors, UDPSendErrors float64
+	ICMPIn, ICMPOut            float64
+	ICMPInErrors               float64
+}
+
+// RetransPercent is the share of the sent TCP segments that were retransmissions.
+func (p NetProtocols) RetransPercent() float64 {
+	if p.TCPOutSegs == 0 {
+		return 0
+	}
+	return p.TCPRetrans / p.TCPOutSegs * 100
+}
+
+// networkPanel shows the traffic of each IP family and the protocol error counters. The
+// TCP retransmission rate turns red past the configured share of the sent segments: it is
+// the cheapest signal of a lossy path, long before throughput drops.
+// The firewall counters of network.firewall_counters follow, where they can be listed.
+func (m model) networkPanel() panel {
+	p := panel{collector: collectorProto, title: "Network"}
+	n := m.data.Protocols
+	if n == nil {
+		return p
+	}
+	perSec := func(v float64) string { return formatFloat(v, 1) + "/s" }
+	p.lines = append(p.lines, fmt.Sprintf("%-10s rx %11s  tx %11s", "IPv4", m.rateCell(formatRate(n.V4Recv)), m.rateCell(formatRate(n.V4Sent))))
+	if n.V6Known {
+		p.lines = append(p.lines, fmt.Sprintf("%-10s rx %11s  tx %11s", "IPv6", m.rateCell(formatRate(n.V6Recv)), m.rateCell(formatRate(n.V6Sent))))
+	}
+	retrans := fmt.Sprintf("%-13s %9s  %6s of sent", "TCP retrans", perSec(n.TCPRetrans), formatPercent(n.RetransPercent(), 2))
+	if warn := m.cfg.Network.RetransWarnPercent; warn > 0 && n.RetransPercent() > warn {
+		retrans = m.baseStyle.Foreground(Color.Red).Render(retrans)
+	}
+	p.lines = append(p.lines, retrans,
+		fmt.Sprintf("%-13s %9s  send %9s", "UDP errors", perSec(n.UDPInErrors), perSec(n.UDPSendErrors)),
+		fmt.Sprintf("%-13s %9s  out %10s", "ICMP in", perSec(n.ICMPIn), perSec(n.ICMPOut)))
+	if n.ICMPInErrors > 0 {
+		p.lines = append(p.lines, fmt.Sprintf("%-13s %9s", "ICMP errors", perSec(n.ICMPInErrors)))
+	}
+	// The firewall section is left out where the counters can't be listed.
+	if m.hasCollector(collectorFirewall) && len(m.data.Firewall) > 0 {
+		p.lines = append(p.lines, m.baseStyle.Bold(true).Render("Firewall"))
+		p.lines = append(p.lines, m.firewallLines()...)
+	}
+	return p
+}
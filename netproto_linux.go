@@ -0,0 +1,146 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// protocolsSupported reports whether the protocol counters are read on this platform.
+const protocolsSupported = true
+
+// protoCounters are the cumulative counters behind NetProtocols, keyed like
+// "tcp.RetransSegs", "ipext.InOctets" or "ip6.Ip6InOctets".
+type protoCounters map[string]uint64
+
+// protocolCollector turns the cumulative protocol counters into rates by diffing them
+// against the previous read. Like DiskIOCollector it is not safe for concurrent use.
+type protocolCollector struct {
+	prev   protoCounters
+	prevAt time.Time
+}
+
+func newProtocolCollector() *protocolCollector {
+	return &protocolCollector{}
+}
+
+// GetProtocols returns the protocol activity since the previous call, nil on the first.
+func (c *protocolCollector) GetProtocols() (*NetProtocols, error) {
+	cur, err := readProtoCounters()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	prev, elapsed := c.prev, now.Sub(c.prevAt)
+	c.prev, c.prevAt = cur, now
+	if prev == nil || elapsed <= 0 {
+		return nil, nil
+	}
+	rate := func(key string) float64 {
+		return float64(delta(cur[key], prev[key])) / elapsed.Seconds()
+	}
+	_, v6 := cur["ip6.Ip6InOctets"]
+	return &NetProtocols{
+		V4Recv:        rate("ipext.InOctets"),
+		V4Sent:        rate("ipext.OutOctets"),
+		V6Recv:        rate("ip6.Ip6InOctets"),
+		V6Sent:        rate("ip6.Ip6OutOctets"),
+		V6Known:       v6,
+		TCPOutSegs:    rate("tcp.OutSegs"),
+		TCPRetrans:    rate("tcp.RetransSegs"),
+		UDPInErrors:   rate("udp.InErrors"),
+		UDPSendErrors: rate("udp.SndbufErrors"),
+		ICMPIn:        rate("icmp.InMsgs"),
+		ICMPOut:       rate("icmp.OutMsgs"),
+		ICMPInErrors:  rate("icmp.InErrors"),
+	}, nil
+}
+
+// readProtoCounters reads the TCP, UDP and ICMP counters of /proc/net/snmp through
+// gopsutil, and the per-family byte counts it doesn't expose: IPv4 from the IpExt lines of
+// /proc/net/netstat and IPv6 from /proc/net/snmp6, which is missing when IPv6 is disabled.
+func readProtoCounters() (protoCounters, error) {
+	stats, err := net.ProtoCounters([]string{"tcp", "udp", "icmp"})
+	if err != nil {
+		return nil, err
+	}
+	counters := protoCounters{}
+	for _, s := range stats {
+		for name, v := range s.Stats {
+			// Counters such as tcp.MaxConn are signed, with -1 for "no limit"; none of the
+			// ones diffed here are.
+			if v >= 0 {
+				counters[s.Protocol+"."+name] = uint64(v)
+			}
+		}
+	}
+	if err := readNetstatPairs("/proc/net/netstat", "IpExt:", counters); err != nil {
+		return nil, err
+	}
+	if err := readSNMP6(counters); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// readNetstatPairs reads one section of a /proc/net/netstat style file, where a line of
+// counter names is followed by a line of their values, both starting with the prefix.
+func readNetstatPairs(path, prefix string, counters protoCounters) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var names []string
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != prefix {
+			continue
+		}
+		if names == nil {
+			names = fields[1:]
+			continue
+		}
+		if len(fields)-1 != len(names) {
+			return fmt.Errorf("%s: %s has %d values for %d names", path, prefix, len(fields)-1, len(names))
+		}
+		section := strings.ToLower(strings.TrimSuffix(prefix, ":"))
+		for i, name := range names {
+			if v, err := strconv.ParseUint(fields[i+1], 10, 64); err == nil {
+				counters[section+"."+name] = v
+			}
+		}
+		return nil
+	}
+	return scanner.Err()
+}
+
+// readSNMP6 reads /proc/net/snmp6, one "name value" pair per line.
+func readSNMP6(counters protoCounters) error {
+	f, err := os.Open("/proc/net/snmp6")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			counters["ip6."+fields[0]] = v
+		}
+	}
+	return scanner.Err()
+}
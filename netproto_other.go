@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// protocolsSupported reports whether the protocol counters are read on this platform;
+// gopsutil only implements them for Linux.
+const protocolsSupported = false
+
+// protocolCollector exists so the collector list builds everywhere; it is never used here.
+type protocolCollector struct{}
+
+func newProtocolCollector() *protocolCollector {
+	return &protocolCollector{}
+}
+
+func (c *protocolCollector) GetProtocols() (*NetProtocols, error) {
+	return nil, errors.New("protocol counters are only read on Linux")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NFSMount is the activity of one NFS mount over the last refresh interval, from the RPC
+// statistics of the client. Slow NFS doesn't show in the local disk counters at all.
+type NFSMount struct {
+	Mountpoint string
+	Server     string  // the export, e.g. server:/export
+	ReadRate   float64 // READ operations per second
+	WriteRate  float64 // WRITE operations per second
+	ReadOps    uint64  // READ operations completed in the interval
+	WriteOps   uint64  // WRITE operations completed in the interval
+	// ReadRTT and WriteRTT are the average round trip time of the operations in the interval.
+	ReadRTT  time.Duration
+	WriteRTT time.Duration
+	// Retrans counts the RPC retransmissions of all operations in the interval.
+	Retrans uint64
+}
+
+// nfsOpStats is one line of the per-op statistics of a mount. The kernel keeps the
+// counters cumulative since the mount.
+type nfsOpStats struct {
+	ops   uint64
+	trans uint64
+	rtt   time.Duration
+}
+
+// nfsMountStats is the statistics of one NFS mount as read from mountstats.
+type nfsMountStats struct {
+	mountpoint string
+	server     string
+	ops        map[string]nfsOpStats
+}
+
+// retrans is the number of retransmissions over all operations: each RPC is transmitted
+// once plus once per retry.
+func (s nfsMountStats) retrans() uint64 {
+	var n uint64
+	for _, op := range s.ops {
+		n += delta(op.trans, op.ops)
+	}
+	return n
+}
+
+// mountEscapes undoes the octal escapes the kernel writes for whitespace and backslashes
+// in device names and mount points.
+var mountEscapes = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+// readMountstats parses a /proc/self/mountstats style file and returns its NFS mounts.
+// Each mount starts with a "device X mounted on Y with fstype Z" line; the per-op
+// statistics that follow give, per operation, the operations, transmissions, major
+// timeouts, bytes sent and received, and the cumulative queue, RTT and execution times
+// in milliseconds. Other filesystems have no statistics and are skipped.
+func readMountstats(path string) ([]nfsMountStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []nfsMountStats
+	var cur *nfsMountStats
+	perOp := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "device" {
+			cur, perOp = nil, false
+			// device <server> mounted on <mountpoint> with fstype <type> [statvers=...]
+			if len(fields) < 8 || fields[2] != "mounted" || fields[3] != "on" || fields[5] != "with" || fields[6] != "fstype" {
+				continue
+			}
+			if fields[7] != "nfs" && fields[7] != "nfs4" {
+				continue
+			}
+			mounts = append(mounts, nfsMountStats{
+				server:     mountEscapes.Replace(fields[1]),
+				mountpoint: mountEscapes.Replace(fields[4]),
+				ops:        map[string]nfsOpStats{},
+			})
+			cur = &mounts[len(mounts)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if strings.TrimSpace(line) == "per-op statistics" {
+			perOp = true
+			continue
+		}
+		if !perOp || len(fields) < 8 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		ops, err1 := strconv.ParseUint(fields[1], 10, 64)
+		trans, err2 := strconv.ParseUint(fields[2], 10, 64)
+		rtt, err3 := strconv.ParseUint(fields[7], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		cur.ops[strings.TrimSuffix(fields[0], ":")] = nfsOpStats{ops: ops, trans: trans, rtt: time.Duration(rtt) * time.Millisecond}
+	}
+	return mounts, scanner.Err()
+}
+
+// nfsSample is the statistics of a mount at the moment they were read.
+type nfsSample struct {
+	stats nfsMountStats
+	at    time.Time
+}
+
+// NFSCollector turns the cumulative NFS client statistics into per-interval rates by
+// diffing them against the previous read, like DiskIOCollector does for block devices.
+type NFSCollector struct {
+	path string
+	prev map[string]nfsSample
+}
+
+func NewNFSCollector() *NFSCollector {
+	return &NFSCollector{path: "/proc/self/mountstats", prev: map[string]nfsSample{}}
+}
+
+// GetNFSMounts returns the activity of every NFS mount since the previous call, sorted by
+// mount point. Mounts seen for the first time are left out until there is a previous
+// sample; a remount starts its counters over and is treated the same way.
+func (c *NFSCollector) GetNFSMounts() ([]NFSMount, error) {
+	mounts, err := readMountstats(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]nfsSample, len(mounts))
+	var result []NFSMount
+	for _, cur := range mounts {
+		seen[cur.mountpoint] = nfsSample{stats: cur, at: now}
+		prev, ok := c.prev[cur.mountpoint]
+		if !ok || prev.stats.server != cur.server {
+			continue
+		}
+		elapsed := now.Sub(prev.at)
+		if elapsed <= 0 {
+			continue
+		}
+		read, prevRead := cur.ops["READ"], prev.stats.ops["READ"]
+		write, prevWrite := cur.ops["WRITE"], prev.stats.ops["WRITE"]
+		m := NFSMount{
+			Mountpoint: cur.mountpoint,
+			Server:     cur.server,
+			ReadOps:    delta(read.ops, prevRead.ops),
+			WriteOps:   delta(write.ops, prevWrite.ops),
+			Retrans:    delta(cur.retrans(), prev.stats.retrans()),
+		}
+		m.ReadRate = float64(m.ReadOps) / elapsed.Seconds()
+		m.WriteRate = float64(m.WriteOps) / elapsed.Seconds()
+		if m.ReadOps > 0 && read.rtt >= prevRead.rtt {
+			m.ReadRTT = (read.rtt - prevRead.rtt) / time.Duration(m.ReadOps)
+		}
+		if m.WriteOps > 0 && write.rtt >= prevWrite.rtt {
+			m.WriteRTT = (write.rtt - prevWrite.rtt) / time.Duration(m.WriteOps)
+		}
+		result = append(result, m)
+	}
+	// Only keep mounts that still exist so unmounted shares don't linger.
+	c.prev = seen
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Mountpoint < result[j].Mountpoint })
+	return result, nil
+}
+
+// nfsLines renders the NFS mounts for the disk panel. The round trip times share the
+// await threshold of the local devices; retransmissions, a sign of a lossy link or an
+// overloaded server, are red.
+func (m model) nfsLines() []string {
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	lines := []string{hint(fmt.Sprintf("%-16s %8s %8s %8s %8s %7s", "nfs mount", "read/s", "write/s", "r_rtt", "w_rtt", "retrans"))}
+	for i, n := range m.data.NFS {
+		if i == maxDiskLines {
+			lines = append(lines, hint(fmt.Sprintf("… %d more NFS mounts", len(m.data.NFS)-maxDiskLines)))
+			break
+		}
+		retrans := fmt.Sprintf("%7s", formatInt(n.Retrans))
+		if n.Retrans > 0 {
+			retrans = m.baseStyle.Foreground(Color.Red).Render(retrans)
+		}
+		lines = append(lines, fmt.Sprintf("%s %8s %8s %s %s %s",
+			fit(n.Mountpoint, 16),
+			formatFloat(n.ReadRate, 1),
+			formatFloat(n.WriteRate, 1),
+			m.viewAwait(n.ReadRTT, n.ReadOps),
+			m.viewAwait(n.WriteRTT, n.WriteOps),
+			retrans))
+	}
+	return lines
+}
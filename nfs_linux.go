@@ -0,0 +1,6 @@
+//go:build linux
+
+package main
+
+// nfsSupported reports whether the NFS client statistics are read on this platform.
+const nfsSupported = true
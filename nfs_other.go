@@ -0,0 +1,7 @@
+//go:build !linux
+
+package main
+
+// nfsSupported reports whether the NFS client statistics are read on this platform; only
+// Linux exposes them in /proc/self/mountstats.
+const nfsSupported = false
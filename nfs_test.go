@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadMountstats(t *testing.T) {
+	tests := []struct {
+		file         string
+		mountpoint   string
+		server       string
+		read, write  nfsOpStats
+		retrans, ops int
+	}{
+		{
+			file:       "mountstats_nfs3",
+			mountpoint: "/mnt/home dirs",
+			server:     "nas:/export/home",
+			read:       nfsOpStats{ops: 2000, trans: 2004, rtt: 9 * time.Second},
+			write:      nfsOpStats{ops: 500, trans: 500, rtt: 2500 * time.Millisecond},
+			retrans:    4,
+			ops:        6,
+		},
+		{
+			file:       "mountstats_nfs4",
+			mountpoint: "/srv/data",
+			server:     "fileserver:/",
+			read:       nfsOpStats{ops: 800, trans: 800, rtt: 3200 * time.Millisecond},
+			write:      nfsOpStats{ops: 160, trans: 163, rtt: 960 * time.Millisecond},
+			retrans:    4,
+			ops:        7,
+		},
+	}
+	for _, tt := range tests {
+		mounts, err := readMountstats(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mounts) != 1 {
+			t.Fatalf("%s: %d NFS mounts, want 1", tt.file, len(mounts))
+		}
+		m := mounts[0]
+		if m.mountpoint != tt.mountpoint || m.server != tt.server {
+			t.Errorf("%s: mount %q from %q, want %q from %q", tt.file, m.mountpoint, m.server, tt.mountpoint, tt.server)
+		}
+		if len(m.ops) != tt.ops {
+			t.Errorf("%s: %d operations, want %d", tt.file, len(m.ops), tt.ops)
+		}
+		if m.ops["READ"] != tt.read || m.ops["WRITE"] != tt.write {
+			t.Errorf("%s: READ %+v, WRITE %+v, want %+v, %+v", tt.file, m.ops["READ"], m.ops["WRITE"], tt.read, tt.write)
+		}
+		if got := m.retrans(); got != uint64(tt.retrans) {
+			t.Errorf("%s: %d retransmissions, want %d", tt.file, got, tt.retrans)
+		}
+	}
+}
+
+func TestGetNFSMounts(t *testing.T) {
+	first, err := os.ReadFile(filepath.Join("testdata", "mountstats_nfs3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "mountstats")
+	if err := os.WriteFile(path, first, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &NFSCollector{path: path, prev: map[string]nfsSample{}}
+	if mounts, err := c.GetNFSMounts(); err != nil || len(mounts) != 0 {
+		t.Fatalf("first read = %v, %v, want no mounts before there is a previous sample", mounts, err)
+	}
+	// Backdate the first sample so the rates are over 10s.
+	prev := c.prev["/mnt/home dirs"]
+	prev.at = prev.at.Add(-10 * time.Second)
+	c.prev["/mnt/home dirs"] = prev
+
+	// 100 more READs taking 500ms of round trips, two of them retransmitted.
+	later := strings.Replace(string(first), "READ: 2000 2004 4 320000 262400000 150 9000 9500 0", "READ: 2100 2106 4 336000 275507200 158 9500 10020 0", 1)
+	if err := os.WriteFile(path, []byte(later), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mounts, err := c.GetNFSMounts()
+	if err != nil || len(mounts) != 1 {
+		t.Fatalf("second read = %v, %v, want the mount", mounts, err)
+	}
+	m := mounts[0]
+	if m.ReadOps != 100 || m.WriteOps != 0 || m.Retrans != 2 {
+		t.Errorf("ops = %d read, %d written, %d retransmitted; want 100, 0, 2", m.ReadOps, m.WriteOps, m.Retrans)
+	}
+	if m.ReadRTT != 5*time.Millisecond {
+		t.Errorf("ReadRTT = %s, want 5ms", m.ReadRTT)
+	}
+	if m.ReadRate < 9.9 || m.ReadRate > 10 {
+		t.Errorf("ReadRate = %.2f/s, want 10/s", m.ReadRate)
+	}
+}
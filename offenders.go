@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// topOffender returns the process with the highest rate, skipping processes whose rate
+// is unknown. ok is false when no process had any traffic.
+func topOffender(procs []ProcessInfo, rate func(p ProcessInfo) (float64, bool)) (top ProcessInfo, ok bool) {
+	best := 0.0
+	for _, p := range procs {
+		if r, known := rate(p); known && r > best {
+			top, best, ok = p, r, true
+		}
+	}
+	return top, ok
+}
+
+func diskRate(p ProcessInfo) (float64, bool)  { return p.DiskRead + p.DiskWrite, p.DiskKnown }
+func netRateOf(p ProcessInfo) (float64, bool) { return p.NetRx + p.NetTx, p.NetKnown }
+
+// viewOffenders renders one line each for the process with the most disk I/O and, with
+// the NET column enabled, the most network traffic over the last interval. They answer
+// "why is everything slow" when the CPU and memory sorted table looks fine.
+func (m model) viewOffenders() string {
+	label := m.baseStyle.Bold(true).Width(10).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	who := func(p ProcessInfo) string { return fmt.Sprintf("%s (%d)", p.Name, p.PID) }
+
+	var lines []string
+	if procIOSupported {
+		line := label("Top disk:") + hint("idle")
+		if p, ok := topOffender(m.data.Procs, diskRate); ok {
+			line = label("Top disk:") + fmt.Sprintf("%s  R %s/s  W %s/s", who(p), formatBytes(uint64(p.DiskRead)), formatBytes(uint64(p.DiskWrite))) + hint("  D: select")
+		}
+		lines = append(lines, line)
+	}
+	if m.procOpts.net {
+		line := label("Top net:") + hint("idle")
+		if p, ok := topOffender(m.data.Procs, netRateOf); ok {
+			line = label("Top net:") + fmt.Sprintf("%s  %s", who(p), formatRate(p.NetRx+p.NetTx)) + hint("  N: select")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selectOffender moves the process table selection to the top process by rate, clearing
+// filters that hide it.
+func (m *model) selectOffender(rate func(p ProcessInfo) (float64, bool)) {
+	p, ok := topOffender(m.snapshotFor(areaProcesses).Procs, rate)
+	if !ok {
+		return
+	}
+	m.selectPID(p.PID)
+}
+
+// selectPID shows the process table with pid selected, clearing filters that hide it. It
+// reports whether the process is in the table.
+func (m *model) selectPID(pid int32) bool {
+	m.view = viewProcesses
+	if m.userFilter != "" || m.unitFilter != "" || m.procFilter != "" || m.linkFilter != nil {
+		m.userFilter, m.unitFilter, m.procFilter, m.linkFilter = "", "", "", nil
+		m.refreshRows()
+	}
+	for i, row := range m.processTable.Rows() {
+		if stripTags(row[0]) == fmt.Sprintf("%d", pid) {
+			m.setProcessCursor(i)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// failCondition is one -fail-if expression, e.g. "mem>90" or "disk:/var>=95".
+type failCondition struct {
+	expr   string
+	metric string
+	// path is the filesystem of a disk:<path> metric.
+	path  string
+	op    string
+	value float64
+}
+
+// failOperators are the comparators of a condition, two-character ones first so ">="
+// isn't read as ">" followed by "=90".
+var failOperators = []string{">=", "<=", ">", "<"}
+
+// failMetrics are the values a condition can test, as percentages except for load1.
+var failMetrics = map[string]func(s Snapshot) (float64, bool){
+	"cpu":   alertMetrics["cpu"],
+	"mem":   alertMetrics["mem"],
+	"load1": alertMetrics["load1"],
+	"swap": func(s Snapshot) (float64, bool) {
+		if s.Mem.SwapTotal == 0 {
+			return 0, s.Mem.Total > 0
+		}
+		return float64(s.Mem.SwapTotal-s.Mem.SwapFree) / float64(s.Mem.SwapTotal) * 100, true
+	},
+}
+
+// parseFailCondition parses "<metric><op><number>", where metric is cpu, mem, swap, load1
+// or disk:<path> and op one of >, >=, < and <=. Spaces around the parts are allowed.
+func parseFailCondition(expr string) (failCondition, error) {
+	c := failCondition{expr: expr}
+	at := -1
+	for _, op := range failOperators {
+		if i := strings.Index(expr, op); i >= 0 && (at < 0 || i < at) {
+			at, c.op = i, op
+		}
+	}
+	if at < 0 {
+		return c, fmt.Errorf("invalid condition %q: want <metric><op><value> with op one of >, >=, <, <=", expr)
+	}
+	c.metric = strings.TrimSpace(expr[:at])
+	value, err := strconv.ParseFloat(strings.TrimSpace(expr[at+len(c.op):]), 64)
+	if err != nil {
+		return c, fmt.Errorf("invalid condition %q: %q is not a number", expr, strings.TrimSpace(expr[at+len(c.op):]))
+	}
+	c.value = value
+	if path, ok := strings.CutPrefix(c.metric, "disk:"); ok {
+		if !filepath.IsAbs(path) {
+			return c, fmt.Errorf("invalid condition %q: disk needs an absolute path, e.g. disk:/var", expr)
+		}
+		c.metric, c.path = "disk", filepath.Clean(path)
+		return c, nil
+	}
+	if _, ok := failMetrics[c.metric]; !ok {
+		return c, fmt.Errorf("invalid condition %q: unknown metric %q, want cpu, mem, swap, load1 or disk:<path>", expr, c.metric)
+	}
+	return c, nil
+}
+
+// measure returns the condition's metric in s, or an error saying why it isn't available.
+func (c failCondition) measure(s Snapshot) (float64, error) {
+	if c.metric == "disk" {
+		fs, ok := filesystemOf(s.Filesystems, c.path)
+		if !ok {
+			return 0, fmt.Errorf("no filesystem found for %s", c.path)
+		}
+		return limitUsage(fs.Used, fs.Total), nil
+	}
+	value, ok := failMetrics[c.metric](s)
+	if !ok {
+		return 0, errors.New(c.metric + " not available")
+	}
+	return value, nil
+}
+
+// name is the metric as written in the condition, e.g. disk:/var.
+func (c failCondition) name() string {
+	if c.metric == "disk" {
+		return "disk:" + c.path
+	}
+	return c.metric
+}
+
+// holds compares value against the condition's threshold.
+func (c failCondition) holds(value float64) bool {
+	switch c.op {
+	case ">=":
+		return value >= c.value
+	case "<=":
+		return value <= c.value
+	case ">":
+		return value > c.value
+	}
+	return value < c.value
+}
+
+// filesystemOf returns the filesystem holding path: the one with the longest mount point
+// that is path or one of its parents.
+func filesystemOf(filesystems []Filesystem, path string) (Filesystem, bool) {
+	var best Filesystem
+	found := false
+	for _, fs := range filesystems {
+		mp := fs.Mountpoint
+		if path != mp && !strings.HasPrefix(path, strings.TrimSuffix(mp, "/")+"/") {
+			continue
+		}
+		if !found || len(mp) > len(best.Mountpoint) {
+			best, found = fs, true
+		}
+	}
+	return best, found
+}
+
+// failConditions collects the repeatable -fail-if flag.
+type failConditions []failCondition
+
+func (f *failConditions) String() string {
+	exprs := make([]string, len(*f))
+	for i, c := range *f {
+		exprs[i] = c.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (f *failConditions) Set(expr string) error {
+	c, err := parseFailCondition(expr)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, c)
+	return nil
+}
+
+// runOnce prints a single plain-text snapshot instead of starting the TUI, for scripts and
+// Makefile pre-checks. The conditions that hold are reported on stderr. It exits 0 when
+// none holds, 1 when one does and 2 when a condition's metric cannot be read.
+func runOnce(src configSource, conditions failConditions) int {
+	cfg := loadConfigOrExit(src)
+	h := newHeadless(cfg, cfg.Interval)
+	cols := selectColumns(cfg.Columns.columnIDs(cfg.Columns.processOptions()))
+
+	h.prime()
+	snap := h.collect()
+	writeBatch(os.Stdout, snap, cols, columnCPU, 20)
+
+	code := 0
+	for _, c := range conditions {
+		value, err := c.measure(snap)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fail-if %s: %v\n", c.expr, err)
+			code = 2
+			continue
+		}
+		if c.holds(value) {
+			fmt.Fprintf(os.Stderr, "fail-if %s: %s is %s\n", c.expr, c.name(), formatFloat(value, 2))
+			code = max(code, 1)
+		}
+	}
+	return code
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// panel is one bordered box in the row of panels between the graphs and the main table.
+type panel struct {
+	// collector is the collector feeding the panel, used for the stale indicator.
+	collector string
+	title     string
+	lines     []string
+	// external is set for the exec panels, which no collector feeds: they are always
+	// shown and report their own failures instead of going stale.
+	external bool
+}
+
+// viewPanels renders every panel that has something to show, wrapping onto further rows
+// when they don't fit the terminal width.
+func (m model) viewPanels() string {
+	var boxes []string
+	for _, p := range m.panels() {
+		// Panels whose collector failed the startup probe are hidden rather than shown empty.
+		if len(p.lines) == 0 || !p.external && !m.hasCollector(p.collector) {
+			continue
+		}
+		border := Color.Border
+		if m.focus == p.collector && m.panelFocused() {
+			border = Color.Highlight
+		}
+		style, stale := m.baseStyle, ""
+		if !p.external {
+			style, stale = m.panelStyle(p.collector, style), m.staleBadge(p.collector)
+		}
+		box := style.
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(border).
+			Padding(0, 1).
+			Render(lipgloss.JoinVertical(lipgloss.Left,
+				append([]string{m.baseStyle.Bold(true).Render(p.title) + stale + m.frozenBadge(p.collector)}, p.lines...)...))
+		boxes = append(boxes, box)
+	}
+	if len(boxes) == 0 {
+		return ""
+	}
+
+	var rows []string
+	var row []string
+	rowWidth := 0
+	for _, box := range boxes {
+		w := lipgloss.Width(box)
+		if len(row) > 0 && m.width > 0 && rowWidth+w > m.width {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row, rowWidth = nil, 0
+		}
+		row = append(row, box)
+		rowWidth += w
+	}
+	rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	return strings.Join(rows, "\n")
+}
+
+// panels lists the panels in display order, the exec panels last. Each panel is built
+// from the data it shows, which is an older snapshot while the panel is frozen.
+func (m model) panels() []panel {
+	return append([]panel{
+		m.withData(collectorProcesses).watchedPanel(),
+		m.withData(collectorMem).swapPanel(),
+		m.withData(collectorDisk).diskPanel(),
+		m.withData(collectorTmpfs).tmpfsPanel(),
+		m.withData(collectorProto).networkPanel(),
+		m.withData(collectorIfaces).interfacesPanel(),
+		m.withData(collectorSockets).socketsPanel(),
+		m.withData(collectorLimits).limitsPanel(),
+		m.withData(collectorSlab).slabPanel(),
+		m.withData(collectorSoC).socPanel(),
+		m.withData(collectorSensors).sensorsPanel(),
+		m.withData(collectorAudit).auditPanel(),
+		m.crashesPanel(),
+	}, m.execPanelViews()...)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shirou/gopsutil/v4/cpu"
+)
+
+// cpuField is one CPU time breakdown shown in the header's CPU section.
+type cpuField struct {
+	label string
+	value func(cpu.TimesStat) float64
+}
+
+var (
+	cpuUser    = cpuField{"user", func(t cpu.TimesStat) float64 { return t.User }}
+	cpuSys     = cpuField{"sys", func(t cpu.TimesStat) float64 { return t.System }}
+	cpuIdle    = cpuField{"idle", func(t cpu.TimesStat) float64 { return t.Idle }}
+	cpuNice    = cpuField{"nice", func(t cpu.TimesStat) float64 { return t.Nice }}
+	cpuIowait  = cpuField{"iowait", func(t cpu.TimesStat) float64 { return t.Iowait }}
+	cpuIrq     = cpuField{"irq", func(t cpu.TimesStat) float64 { return t.Irq }}
+	cpuSoftirq = cpuField{"softirq", func(t cpu.TimesStat) float64 { return t.Softirq }}
+	cpuSteal   = cpuField{"steal", func(t cpu.TimesStat) float64 { return t.Steal }}
+	cpuGuest   = cpuField{"guest", func(t cpu.TimesStat) float64 { return t.Guest }}
+)
+
+// cpuFieldsByOS lists the CPU time fields each platform actually reports. gopsutil leaves
+// the others at zero, and a column of permanent zeros is noise rather than information.
+var cpuFieldsByOS = map[string][]cpuField{
+	"linux":   {cpuUser, cpuSys, cpuIdle, cpuNice, cpuIowait, cpuIrq, cpuSoftirq, cpuSteal, cpuGuest},
+	"windows": {cpuUser, cpuSys, cpuIdle},
+	"darwin":  {cpuUser, cpuSys, cpuIdle, cpuNice},
+	"freebsd": {cpuUser, cpuSys, cpuIdle, cpuNice, cpuIrq},
+}
+
+// cpuFieldsFor returns the CPU fields shown on goos, falling back to the Linux set.
+func cpuFieldsFor(goos string) []cpuField {
+	if fields, ok := cpuFieldsByOS[goos]; ok {
+		return fields
+	}
+	return cpuFieldsByOS["linux"]
+}
+
+// memField is one figure in the header's MEM section.
+type memField struct {
+	label string
+	value func(MemStats) uint64
+}
+
+var (
+	memTotal     = memField{"total", func(s MemStats) uint64 { return s.Total }}
+	memUsed      = memField{"used", func(s MemStats) uint64 { return s.Used }}
+	memFree      = memField{"free", func(s MemStats) uint64 { return s.Available }}
+	memActive    = memField{"active", func(s MemStats) uint64 { return s.Active }}
+	memBuffers   = memField{"buffers", func(s MemStats) uint64 { return s.Buffers }}
+	memCached    = memField{"cached", func(s MemStats) uint64 { return s.Cached }}
+	memShmem     = memField{"shmem", func(s MemStats) uint64 { return s.Shared }}
+	memCommitted = memField{"committed", func(s MemStats) uint64 { return s.CommitTotal }}
+	memPaged     = memField{"paged", func(s MemStats) uint64 { return s.PagedPool }}
+	memNonPaged  = memField{"nonpaged", func(s MemStats) uint64 { return s.NonPagedPool }}
+	memWired     = memField{"wired", func(s MemStats) uint64 { return s.Wired }}
+	memInactive  = memField{"inactive", func(s MemStats) uint64 { return s.Inactive }}
+	memCompress  = memField{"compressed", func(s MemStats) uint64 { return s.Compressed }}
+)
+
+// Hugepage figures from /proc/meminfo. The static pool is counted in pages, shown here in
+// bytes of the configured page size; AnonHugePages is the memory backed by transparent hugepages.
+var (
+	memHugeTotal = memField{"total", func(s MemStats) uint64 { return s.HugePagesTotal * s.HugePageSize }}
+	memHugeFree  = memField{"free", func(s MemStats) uint64 { return s.HugePagesFree * s.HugePageSize }}
+	memHugeRsvd  = memField{"rsvd", func(s MemStats) uint64 { return s.HugePagesRsvd * s.HugePageSize }}
+	memHugeSize  = memField{"page", func(s MemStats) uint64 { return s.HugePageSize }}
+	memAnonHuge  = memField{"thp", func(s MemStats) uint64 { return s.AnonHugePages }}
+)
+
+// hugePageFields returns the hugepage figures worth showing: the pool only when hugepages
+// are preallocated, THP only when some memory uses it. Elsewhere the section is omitted.
+func hugePageFields(s MemStats) []memField {
+	var fields []memField
+	if s.HugePagesTotal > 0 {
+		fields = append(fields, memHugeTotal, memHugeFree, memHugeRsvd, memHugeSize)
+	}
+	if s.AnonHugePages > 0 {
+		fields = append(fields, memAnonHuge)
+	}
+	return fields
+}
+
+// memFieldsByOS lists the memory figures that are meaningful on each platform.
+var memFieldsByOS = map[string][]memField{
+	// shmem counts tmpfs files and shared memory segments, which are part of cached but
+	// belong to no process; listing it lets used + cached be reconciled with the tmpfs panel.
+	"linux":   {memTotal, memUsed, memFree, memActive, memBuffers, memCached, memShmem},
+	"windows": {memTotal, memUsed, memFree, memCommitted, memPaged, memNonPaged},
+	// macOS keeps most "free" memory in use as inactive pages, so the breakdown follows
+	// Activity Monitor instead of the Linux buffers/cache split.
+	"darwin": {memTotal, memUsed, memFree, memWired, memActive, memInactive, memCompress},
+}
+
+// memFieldsFor returns the memory fields shown on goos, falling back to the Linux set.
+func memFieldsFor(goos string) []memField {
+	if fields, ok := memFieldsByOS[goos]; ok {
+		return fields
+	}
+	return memFieldsByOS["linux"]
+}
+
+// hostOS is the platform the field tables are looked up for.
+var hostOS = runtime.GOOS
+
+// Available memory below these shares of RAM turns the MEM usage bar yellow and red.
+const (
+	memAvailWarnPercent     = 10
+	memAvailCriticalPercent = 5
+)
+
+// memBarColor returns the fill color of the MEM usage bar. Where the kernel reports a memory
+// pressure level (macOS) it drives the color, since a nearly full but unpressured memory is
+// normal there. Elsewhere the color follows the available memory rather than the free
+// memory, so page cache that could be reclaimed at once never raises an alarm.
+func memBarColor(s MemStats) lipgloss.AdaptiveColor {
+	switch s.Pressure {
+	case memPressureWarn:
+		return Color.Yellow
+	case memPressureCritical:
+		return Color.Red
+	}
+	if s.Pressure == memPressureUnknown && s.Total > 0 {
+		switch available := float64(s.Available) / float64(s.Total) * 100; {
+		case available < memAvailCriticalPercent:
+			return Color.Red
+		case available < memAvailWarnPercent:
+			return Color.Yellow
+		}
+	}
+	return Color.Green
+}
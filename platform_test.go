@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMemFieldsFor(t *testing.T) {
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"total", "used", "free", "active", "buffers", "cached", "shmem"}},
+		{"darwin", []string{"total", "used", "free", "wired", "active", "inactive", "compressed"}},
+		{"windows", []string{"total", "used", "free", "committed", "paged", "nonpaged"}},
+		{"plan9", []string{"total", "used", "free", "active", "buffers", "cached", "shmem"}},
+	}
+	for _, tt := range tests {
+		var labels []string
+		for _, f := range memFieldsFor(tt.goos) {
+			labels = append(labels, f.label)
+		}
+		if !slices.Equal(labels, tt.want) {
+			t.Errorf("memFieldsFor(%q) = %v, want %v", tt.goos, labels, tt.want)
+		}
+	}
+}
+
+func TestMemBarColor(t *testing.T) {
+	const gib = 1 << 30
+	tests := []struct {
+		name string
+		mem  MemStats
+		want string
+	}{
+		{"plenty available", memStats(16*gib, 8*gib, memPressureUnknown), "green"},
+		{"little available", memStats(16*gib, gib, memPressureUnknown), "yellow"},
+		{"almost none available", memStats(16*gib, gib/2, memPressureUnknown), "red"},
+		// macOS keeps free memory low on purpose; the pressure level decides.
+		{"full but normal pressure", memStats(16*gib, gib/4, memPressureNormal), "green"},
+		{"warn pressure", memStats(16*gib, 8*gib, memPressureWarn), "yellow"},
+		{"critical pressure", memStats(16*gib, 8*gib, memPressureCritical), "red"},
+		{"nothing read", MemStats{}, "green"},
+	}
+	names := map[string]string{Color.Green.Dark: "green", Color.Yellow.Dark: "yellow", Color.Red.Dark: "red"}
+	for _, tt := range tests {
+		if got := names[memBarColor(tt.mem).Dark]; got != tt.want {
+			t.Errorf("%s: memBarColor = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func memStats(total, available uint64, pressure memPressure) MemStats {
+	var s MemStats
+	s.Total, s.Available, s.Pressure = total, available, pressure
+	return s
+}
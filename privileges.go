@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// unprivileged reports whether the monitor runs without root, so it can only read some
+// per-process data of its own user's processes. Windows is not checked.
+func unprivileged() bool {
+	return runtime.GOOS != "windows" && os.Geteuid() != 0
+}
+
+// currentUsername is the name of the user the monitor runs as, "" when it can't be looked up.
+var currentUsername = sync.OnceValue(func() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+})
+
+// ownProcess reports whether the monitor may signal or reprioritise p: always as root,
+// otherwise only the user's own processes.
+func ownProcess(p ProcessInfo) bool {
+	return !unprivileged() || p.Username == currentUsername()
+}
+
+// privilegedColumns names the data each column only shows for the user's own processes
+// when running unprivileged.
+var privilegedColumns = map[string]string{
+	columnFDs: "open FDs",
+	columnNet: "TCP traffic",
+}
+
+// privilegeHint explains, once per session, why some rows of a privileged column stay
+// empty. The rows that can be read are shown as usual.
+func (m *model) privilegeHint() {
+	if m.privilegeHinted || !unprivileged() {
+		return
+	}
+	var missing []string
+	for _, c := range m.columns {
+		if what, ok := privilegedColumns[c.id]; ok {
+			missing = append(missing, what)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	m.privilegeHinted = true
+	m.reportInfo("run with sudo for " + strings.Join(missing, " and ") + " of all users' processes")
+}
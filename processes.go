@@ -0,0 +1,365 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// processColumn describes one column of the process table.
+type processColumn struct {
+	id    string
+	title string
+	// minWidth and maxWidth bound the width auto-fit picks from the content.
+	minWidth, maxWidth int
+	cell               func(p ProcessInfo) string
+	// less orders two processes the way this column sorts them, or is nil when the column is not sortable.
+	less func(a, b ProcessInfo) bool
+}
+
+// Column IDs, also used by the config to refer to columns.
+const (
+	columnPID       = "pid"
+	columnName      = "name"
+	columnCommand   = "command"
+	columnCPU       = "cpu"
+	columnNice      = "nice"
+	columnMem       = "mem"
+	columnCPUTime   = "cputime"
+	columnFDs       = "fds"
+	columnContainer = "container"
+	columnNet       = "net"
+	columnGPUMem    = "gpumem"
+	columnUnit      = "unit"
+	columnFlags     = "flags"
+	columnRunDelay  = "rundelay"
+	columnIODelay   = "iodelay"
+	columnMemLimit  = "memlimit"
+	columnCtxSwitch = "ctxsw"
+	columnUser      = "user"
+	columnTime      = "time"
+)
+
+// processColumns lists every available column in display order.
+var processColumns = []processColumn{
+	{
+		id: columnPID, title: "PID", minWidth: 5, maxWidth: 10,
+		cell: func(p ProcessInfo) string { return fmt.Sprintf("%d", p.PID) },
+		less: func(a, b ProcessInfo) bool { return a.PID < b.PID },
+	},
+	{
+		id: columnName, title: "Name", minWidth: 10, maxWidth: 40,
+		// Interpreters may show the program they run instead; see NamesConfig.
+		cell: shownName,
+	},
+	{
+		// L, S and F mark leak suspects, spinning processes and fork storms; see AnomaliesConfig.
+		id: columnFlags, title: "!", minWidth: 3, maxWidth: 3,
+		cell: flagsCell,
+		less: func(a, b ProcessInfo) bool { return len(a.Flags) > len(b.Flags) },
+	},
+	{
+		id: columnCPU, title: "CPU", minWidth: 6, maxWidth: 10,
+		cell: func(p ProcessInfo) string { return formatPercent(p.CPUPercent, 2) },
+		less: func(a, b ProcessInfo) bool { return a.CPUPercent > b.CPUPercent },
+	},
+	{
+		// Negative nice values take CPU from everyone else, so they stand out; positive ones
+		// yield it and are dimmed. Nice doesn't apply to real-time policies.
+		id: columnNice, title: "NI", minWidth: 3, maxWidth: 4,
+		cell: func(p ProcessInfo) string {
+			switch {
+			case p.Realtime:
+				return tagCell("RT", tagRed)
+			case p.Nice < 0:
+				return tagCell(fmt.Sprintf("%d", p.Nice), tagRed)
+			case p.Nice > 0:
+				return tagCell(fmt.Sprintf("%d", p.Nice), tagDim)
+			}
+			return "0"
+		},
+		// Highest priority first: real-time, then the lowest nice value.
+		less: func(a, b ProcessInfo) bool {
+			if a.Realtime != b.Realtime {
+				return a.Realtime
+			}
+			return a.Nice < b.Nice
+		},
+	},
+	{
+		id: columnMem, title: "MEM", minWidth: 8, maxWidth: 12,
+		cell: func(p ProcessInfo) string { return formatBytes(p.Memory) },
+		less: func(a, b ProcessInfo) bool { return a.Memory > b.Memory },
+	},
+	{
+		// Usage against the cgroup's memory limit, the number that predicts an OOM kill in a
+		// container; of host RAM for processes without a limit.
+		id: columnMemLimit, title: "MEM/LIM", minWidth: 7, maxWidth: 22,
+		cell: memLimitCell,
+		less: func(a, b ProcessInfo) bool { return a.MemLimitPercent > b.MemLimitPercent },
+	},
+	{
+		id: columnCPUTime, title: "TIME+", minWidth: 7, maxWidth: 12,
+		cell: func(p ProcessInfo) string { return formatCPUTime(p.CPUTime) },
+		less: func(a, b ProcessInfo) bool { return a.CPUTime > b.CPUTime },
+	},
+	{
+		id: columnFDs, title: "FDs", minWidth: 4, maxWidth: 10,
+		cell: func(p ProcessInfo) string {
+			if !p.FDsKnown {
+				return "-"
+			}
+			return formatInt(p.NumFDs)
+		},
+		less: func(a, b ProcessInfo) bool { return a.NumFDs > b.NumFDs },
+	},
+	{
+		// The ~ marks the value as an approximation; see netAttributor.
+		id: columnNet, title: "NET~", minWidth: 8, maxWidth: 14,
+		cell: func(p ProcessInfo) string {
+			if !p.NetKnown {
+				return "-"
+			}
+			return formatRate(p.NetRx + p.NetTx)
+		},
+		less: func(a, b ProcessInfo) bool { return a.NetRx+a.NetTx > b.NetRx+b.NetTx },
+	},
+	{
+		id: columnGPUMem, title: "GPU-MEM", minWidth: 8, maxWidth: 18,
+		cell: gpuCell,
+		less: func(a, b ProcessInfo) bool { return a.GPUMem > b.GPUMem },
+	},
+	{
+		// Time spent runnable but waiting for a CPU, from the scheduler statistics.
+		id: columnRunDelay, title: "RUN DLY", minWidth: 7, maxWidth: 9,
+		cell: func(p ProcessInfo) string {
+			if !p.RunDelayKnown {
+				return "-"
+			}
+			return formatPercent(p.RunDelay, 1)
+		},
+		less: func(a, b ProcessInfo) bool { return a.RunDelay > b.RunDelay },
+	},
+	{
+		// Time blocked on block I/O, from delay accounting.
+		id: columnIODelay, title: "IO DLY", minWidth: 6, maxWidth: 9,
+		cell: func(p ProcessInfo) string {
+			if !p.IODelayKnown {
+				return "-"
+			}
+			return formatPercent(p.IODelay, 1)
+		},
+		less: func(a, b ProcessInfo) bool { return a.IODelay > b.IODelay },
+	},
+	{
+		// Voluntary/involuntary context switches per second; sorted by the involuntary ones,
+		// the processes preempted the most, which is what CPU contention looks like.
+		id: columnCtxSwitch, title: "CSW/s", minWidth: 7, maxWidth: 15,
+		cell: ctxSwitchCell,
+		less: func(a, b ProcessInfo) bool { return a.InvolCtxRate > b.InvolCtxRate },
+	},
+	{
+		id: columnUser, title: "Username", minWidth: 6, maxWidth: 16,
+		cell: func(p ProcessInfo) string { return p.Username },
+	},
+	{
+		id: columnContainer, title: "Container", minWidth: 6, maxWidth: 24,
+		cell: func(p ProcessInfo) string {
+			if p.Container == "" {
+				return "-"
+			}
+			return p.Container
+		},
+		// Group processes by container, with host processes last.
+		less: func(a, b ProcessInfo) bool {
+			if (a.Container == "") != (b.Container == "") {
+				return b.Container == ""
+			}
+			return a.Container < b.Container
+		},
+	},
+	{
+		id: columnUnit, title: "Unit", minWidth: 6, maxWidth: 32,
+		cell: func(p ProcessInfo) string {
+			if p.Unit == "" {
+				return "-"
+			}
+			return p.Unit
+		},
+		// Group processes by unit, with those in none last.
+		less: func(a, b ProcessInfo) bool {
+			if (a.Unit == "") != (b.Unit == "") {
+				return b.Unit == ""
+			}
+			return a.Unit < b.Unit
+		},
+	},
+	{
+		id: columnTime, title: "Time", minWidth: 6, maxWidth: 14,
+		cell: timeCell,
+		// Newest first, whether the column shows running or start times.
+		less: func(a, b ProcessInfo) bool { return a.CreateTime > b.CreateTime },
+	},
+	{
+		id: columnCommand, title: "Command", minWidth: 10, maxWidth: 60,
+		cell: func(p ProcessInfo) string {
+			if p.Cmdline == "" {
+				return p.Name
+			}
+			return p.Cmdline
+		},
+	},
+}
+
+// defaultColumnIDs are the columns shown unless an optional column is switched on.
+var defaultColumnIDs = []string{columnPID, columnName, columnFlags, columnCPU, columnNice, columnMem, columnCPUTime, columnUser, columnTime}
+
+// selectColumns returns the columns with the given IDs, in the display order of
+// processColumns, with the custom columns before Command.
+func selectColumns(ids []string) []processColumn {
+	want := map[string]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	var cols []processColumn
+	for _, c := range processColumns {
+		if want[c.id] && c.id != columnCommand {
+			cols = append(cols, c)
+		}
+	}
+	for _, c := range customColumns {
+		if want[c.id] {
+			cols = append(cols, c)
+		}
+	}
+	if want[columnCommand] {
+		col, _ := columnByID(columnCommand)
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// maxManualWidth caps the width of a column resized by hand.
+const maxManualWidth = 80
+
+// tableColumns converts cols into table columns, marking the one the table is sorted by.
+// Each column is as wide as its widest cell or title within the column's bounds, unless
+// overrides holds a width set by hand. Widths are measured in terminal cells, so wide
+// runes in process names count double and the columns stay aligned.
+func tableColumns(cols []processColumn, rows []table.Row, sortID string, overrides map[string]int) []table.Column {
+	out := make([]table.Column, 0, len(cols))
+	for i, c := range cols {
+		title := c.title
+		if c.id == sortID {
+			title += " ▼"
+		}
+		width, ok := overrides[c.id]
+		if !ok {
+			width = lipgloss.Width(title)
+			for _, row := range rows {
+				width = max(width, cellWidth(row[i]))
+			}
+			width = min(width, c.maxWidth)
+		}
+		out = append(out, table.Column{Title: title, Width: max(width, c.minWidth)})
+	}
+	return out
+}
+
+// cellWidth is lipgloss.Width for a table cell. Cells are a single line but for the odd
+// command line with a newline in an argument, and measuring them directly spares the line
+// split lipgloss.Width makes, which was most of the allocations of a refresh.
+func cellWidth(s string) int {
+	if strings.Contains(s, "\n") {
+		return lipgloss.Width(s)
+	}
+	return ansi.StringWidth(s)
+}
+
+// columnByID returns the built-in or custom process column with the given ID.
+func columnByID(id string) (processColumn, bool) {
+	for _, c := range processColumns {
+		if c.id == id {
+			return c, true
+		}
+	}
+	for _, c := range customColumns {
+		if c.id == id {
+			return c, true
+		}
+	}
+	return processColumn{}, false
+}
+
+// nextSortColumn returns the sortable column after current among cols, wrapping around.
+func nextSortColumn(cols []processColumn, current string) string {
+	var sortable []string
+	for _, c := range cols {
+		if c.less != nil {
+			sortable = append(sortable, c.id)
+		}
+	}
+	if len(sortable) == 0 {
+		return current
+	}
+	for i, id := range sortable {
+		if id == current {
+			return sortable[(i+1)%len(sortable)]
+		}
+	}
+	return sortable[0]
+}
+
+// sortProcesses orders procs by the column with the given ID. Ties are broken by PID so
+// rows keep their relative order between ticks.
+func sortProcesses(procs []ProcessInfo, sortID string) {
+	col, ok := columnByID(sortID)
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		if ok && col.less != nil {
+			if col.less(a, b) {
+				return true
+			}
+			if col.less(b, a) {
+				return false
+			}
+		}
+		return a.PID < b.PID
+	})
+}
+
+// processRow formats one process for the process table.
+func processRow(p ProcessInfo, cols []processColumn) table.Row {
+	row := make(table.Row, len(cols))
+	for i, c := range cols {
+		row[i] = c.cell(p)
+	}
+	return row
+}
+
+// sortTitle returns the title of the column with the given ID, for the footer.
+func sortTitle(sortID string) string {
+	if c, ok := columnByID(sortID); ok {
+		return c.title
+	}
+	return sortID
+}
+
+// resizeColumn changes the width of the column with the given ID by step cells, starting
+// from its current auto-fit width, and saves the override to the state file.
+func (m *model) resizeColumn(id string, step int) tea.Cmd {
+	i := slices.IndexFunc(m.columns, func(c processColumn) bool { return c.id == id })
+	if i < 0 {
+		return nil
+	}
+	width := m.processTable.Columns()[i].Width + step
+	m.state.ColumnWidths[id] = min(max(width, m.columns[i].minWidth), maxManualWidth)
+	m.refreshRows()
+	return m.saveStateCmd()
+}
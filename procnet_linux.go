@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procNetSupported reports whether the per-process NET column can be collected here.
+const procNetSupported = true
+
+// ssTimeout bounds one run of ss, so a hung netlink query cannot stall the collection.
+const ssTimeout = 2 * time.Second
+
+// socketBytes is the cumulative traffic of one TCP socket.
+type socketBytes struct {
+	sent     uint64
+	received uint64
+}
+
+// netRate is the traffic of one process over the last interval, in bytes per second.
+type netRate struct {
+	rx, tx float64
+}
+
+// netAttributor approximates per-process bandwidth. The kernel keeps byte counters per TCP
+// socket, which ss reads over netlink; the sockets are mapped to processes through the
+// socket inodes in /proc/<pid>/fd. Without root only the caller's own processes can be
+// mapped, and UDP and closed sockets are not counted, so the result is a lower bound.
+type netAttributor struct {
+	prev map[uint64]socketBytes
+	at   time.Time
+}
+
+func newNetAttributor() *netAttributor {
+	return &netAttributor{prev: map[uint64]socketBytes{}}
+}
+
+// rates returns each process's traffic since the previous call, keyed by PID.
+func (a *netAttributor) rates() (map[int32]netRate, error) {
+	counters, err := readSocketBytes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	prev, elapsed := a.prev, now.Sub(a.at).Seconds()
+	a.prev, a.at = counters, now
+
+	owners := socketOwners()
+	rates := map[int32]netRate{}
+	for inode, cur := range counters {
+		pid, ok := owners[inode]
+		if !ok {
+			continue
+		}
+		r := rates[pid]
+		if old, ok := prev[inode]; ok && elapsed > 0 {
+			r.tx += float64(delta(cur.sent, old.sent)) / elapsed
+			r.rx += float64(delta(cur.received, old.received)) / elapsed
+		}
+		rates[pid] = r
+	}
+	return rates, nil
+}
+
+// readSocketBytes runs `ss -tinHe` and returns the byte counters of every TCP socket by inode.
+// Each socket is a line with "ino:<n>" followed by an indented line of tcp_info fields.
+func readSocketBytes() (map[uint64]socketBytes, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ssTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ss", "-tinHe").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not run ss: %w", err)
+	}
+
+	counters := map[uint64]socketBytes{}
+	var inode uint64
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " ") {
+			inode = 0
+			for _, f := range strings.Fields(line) {
+				if v, ok := strings.CutPrefix(f, "ino:"); ok {
+					inode, _ = strconv.ParseUint(v, 10, 64)
+				}
+			}
+			continue
+		}
+		if inode == 0 {
+			continue
+		}
+		var c socketBytes
+		for _, f := range strings.Fields(line) {
+			key, value, ok := strings.Cut(f, ":")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "bytes_sent":
+				c.sent = n
+			case "bytes_received":
+				c.received = n
+			}
+		}
+		counters[inode] = c
+	}
+	return counters, scanner.Err()
+}
+
+// socketOwners maps socket inodes to the PID holding them, for every process whose file
+// descriptors are readable.
+func socketOwners() map[uint64]int32 {
+	owners := map[uint64]int32{}
+	dirs, _ := filepath.Glob("/proc/[0-9]*/fd")
+	for _, dir := range dirs {
+		pid, err := strconv.ParseInt(filepath.Base(filepath.Dir(dir)), 10, 32)
+		if err != nil {
+			continue
+		}
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			if v, ok := strings.CutPrefix(target, "socket:["); ok {
+				if inode, err := strconv.ParseUint(strings.TrimSuffix(v, "]"), 10, 64); err == nil {
+					owners[inode] = int32(pid)
+				}
+			}
+		}
+	}
+	return owners
+}
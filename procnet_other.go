@@ -0,0 +1,24 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// procNetSupported reports whether the per-process NET column can be collected here.
+const procNetSupported = false
+
+// netRate is the traffic of one process over the last interval, in bytes per second.
+type netRate struct {
+	rx, tx float64
+}
+
+// netAttributor is a stub; per-process traffic is only attributed on Linux.
+type netAttributor struct{}
+
+func newNetAttributor() *netAttributor {
+	return &netAttributor{}
+}
+
+func (a *netAttributor) rates() (map[int32]netRate, error) {
+	return nil, errors.New("per-process network traffic is only supported on Linux")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "github.com/shirou/gopsutil/v4/process"
+
+// processNames returns names for processes that process.Name cannot resolve; nothing is
+// needed outside Windows.
+func processNames() map[int32]string {
+	return nil
+}
+
+// processUsername resolves the owner of a process.
+func processUsername(p *process.Process) (string, error) {
+	return p.Username()
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"golang.org/x/sys/windows"
+)
+
+// processNames returns the executable name of every process from a Toolhelp snapshot.
+// Unlike process.Name, this does not need to open the process, so it also works for
+// protected and other users' processes.
+func processNames() map[int32]string {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseHandle(snapshot)
+
+	names := map[int32]string{}
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		names[int32(entry.ProcessID)] = windows.UTF16ToString(entry.ExeFile[:])
+	}
+	return names
+}
+
+// processUsername resolves the owner of a process. It opens the process with limited query
+// rights, which unlike gopsutil's full query access is granted for most system processes.
+func processUsername(p *process.Process) (string, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(p.Pid))
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(h)
+
+	var token windows.Token
+	if err := windows.OpenProcessToken(h, windows.TOKEN_QUERY, &token); err != nil {
+		return "", err
+	}
+	defer token.Close()
+
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+	account, domain, _, err := user.User.Sid.LookupAccount("")
+	if err != nil {
+		return "", err
+	}
+	return domain + `\` + account, nil
+}
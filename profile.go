@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// errUnknownProfile is returned when -profile names a profile the config file doesn't have.
+var errUnknownProfile = errors.New("unknown profile")
+
+// configSource is where the config comes from: the file and the profile picked with
+// -profile, or "" to let the profiles' match rules choose.
+type configSource struct {
+	path    string
+	profile string
+}
+
+// configFile is the layout of the config file: the base settings plus the named
+// [profile.<name>] tables that override them.
+type configFile struct {
+	Config
+	Profile map[string]toml.Primitive `toml:"profile"`
+}
+
+// profileMatch is the part of a profile table that isn't a config key: the rule that
+// selects the profile when -profile isn't given.
+type profileMatch struct {
+	Match string `toml:"match"`
+}
+
+// Chassis types a match rule can name.
+const (
+	chassisLaptop  = "laptop"
+	chassisDesktop = "desktop"
+	chassisServer  = "server"
+)
+
+// matchRule checks a profile's match rule against this host. The rules are "battery",
+// "no-battery" and "chassis=laptop|desktop|server"; a host whose chassis or battery
+// can't be read matches none of them.
+func matchRule(rule string) (bool, error) {
+	switch rule {
+	case "battery", "no-battery":
+		has, err := hostHasBattery()
+		if err != nil {
+			return false, nil
+		}
+		return has == (rule == "battery"), nil
+	}
+	if want, ok := strings.CutPrefix(rule, "chassis="); ok {
+		switch want {
+		case chassisLaptop, chassisDesktop, chassisServer:
+			return hostChassis() == want, nil
+		}
+	}
+	return false, fmt.Errorf("must be \"battery\", \"no-battery\" or \"chassis=laptop|desktop|server\", got %q", rule)
+}
+
+// profileNames returns the profiles in the order they appear in the file.
+func profileNames(md toml.MetaData) []string {
+	var names []string
+	for _, k := range md.Keys() {
+		if len(k) == 2 && k[0] == "profile" {
+			names = append(names, k[1])
+		}
+	}
+	return names
+}
+
+// applyProfile overlays the profile picked by name, or the first one in the file whose
+// match rule fits this host when name is "", on top of cfg. Only the keys a profile sets
+// change; a profile that sets alerts or webhooks replaces the whole list. It returns the
+// profile applied, "" for none.
+func (f configFile) applyProfile(md toml.MetaData, cfg *Config, name string) (string, error) {
+	names := profileNames(md)
+	if name == "" {
+		for _, n := range names {
+			var m profileMatch
+			if err := md.PrimitiveDecode(f.Profile[n], &m); err != nil {
+				return "", err
+			}
+			if m.Match == "" {
+				continue
+			}
+			ok, err := matchRule(m.Match)
+			if err != nil {
+				return "", fmt.Errorf("profile.%s.match %w", n, err)
+			}
+			if ok {
+				name = n
+				break
+			}
+		}
+		if name == "" {
+			return "", nil
+		}
+	}
+
+	prim, ok := f.Profile[name]
+	if !ok {
+		if len(names) == 0 {
+			return "", fmt.Errorf("%w %q: the config file defines no profiles", errUnknownProfile, name)
+		}
+		slices.Sort(names)
+		return "", fmt.Errorf("%w %q, available: %s", errUnknownProfile, name, strings.Join(names, ", "))
+	}
+	return name, md.PrimitiveDecode(prim, cfg)
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hostHasBattery reports whether the kernel lists a battery among the power supplies.
+func hostHasBattery() (bool, error) {
+	dirs, err := filepath.Glob("/sys/class/power_supply/*")
+	if err != nil {
+		return false, err
+	}
+	for _, dir := range dirs {
+		typ, err := os.ReadFile(filepath.Join(dir, "type"))
+		if err == nil && strings.TrimSpace(string(typ)) == "Battery" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hostChassis returns the chassis kind from the SMBIOS chassis type, or "" when it is
+// missing or doesn't fit one of the kinds a match rule can name. Virtual machines
+// usually report "Other" and match nothing.
+func hostChassis() string {
+	data, err := os.ReadFile("/sys/class/dmi/id/chassis_type")
+	if err != nil {
+		return ""
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return ""
+	}
+	// The numbers are the chassis types of the SMBIOS specification.
+	switch n {
+	case 8, 9, 10, 14, 30, 31, 32: // portable, laptop, notebook, sub notebook, tablet, convertible, detachable
+		return chassisLaptop
+	case 3, 4, 5, 6, 7, 13, 15, 16, 35, 36: // desktops, towers, all-in-one, mini PC, stick PC
+		return chassisDesktop
+	case 17, 23, 25, 28, 29: // main server, rack mount, multi-system, blade, blade enclosure
+		return chassisServer
+	}
+	return ""
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// hostHasBattery is only answered on Linux; elsewhere the battery match rules never match.
+func hostHasBattery() (bool, error) {
+	return false, errors.New("battery detection is only supported on Linux")
+}
+
+// hostChassis is only answered on Linux; elsewhere the chassis match rules never match.
+func hostChassis() string {
+	return ""
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"syscall"
+)
+
+// KillConfig is the [kill] section of the config file.
+type KillConfig struct {
+	// Protected are name patterns, e.g. "sshd" or "postgres*", of processes whose kill asks
+	// for a second, explicit confirmation, on top of init, the kernel threads, the shell the
+	// monitor runs in and the monitor itself.
+	Protected []string `toml:"protected"`
+}
+
+// checkKill validates the [kill] section: every pattern must be a valid glob.
+func checkKill(c KillConfig) error {
+	for _, pattern := range c.Protected {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("protected pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// protectedTarget is a kill target on the safety list, with why.
+type protectedTarget struct {
+	proc   ProcessInfo
+	reason string
+}
+
+// protectedTargets returns the targets on the safety list. The monitor's ancestors are its
+// shell, terminal and session: killing one takes the session, and the monitor, with it.
+func protectedTargets(targets, procs []ProcessInfo, patterns []string) []protectedTarget {
+	self := int32(os.Getpid())
+	byPID := make(map[int32]ProcessInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	ancestors := map[int32]bool{}
+	for pid := byPID[self].PPID; pid > 1 && !ancestors[pid]; pid = byPID[pid].PPID {
+		ancestors[pid] = true
+	}
+
+	var out []protectedTarget
+	for _, t := range targets {
+		reason := ""
+		switch {
+		case t.PID == 1:
+			reason = "init"
+		case isKernelThread(t):
+			reason = "kernel thread"
+		case t.PID == self:
+			reason = "this monitor"
+		case ancestors[t.PID]:
+			reason = "runs this monitor's session"
+		default:
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, t.Name); ok {
+					reason = "protected name " + pattern
+					break
+				}
+			}
+		}
+		if reason != "" {
+			out = append(out, protectedTarget{t, reason})
+		}
+	}
+	return out
+}
+
+// protectedPrompt is the second confirmation, worded apart from the first so it is read.
+func (d *killDialog) protectedPrompt() string {
+	if len(d.protected) == 1 {
+		t := d.protected[0]
+		return fmt.Sprintf("PID %d (%s) is protected (%s). Really send %s?", t.proc.PID, t.proc.Name, t.reason, killSignals[d.signal].name)
+	}
+	return fmt.Sprintf("%d targets are protected. Really send %s to all %d processes?", len(d.protected), killSignals[d.signal].name, len(d.targets))
+}
+
+// errKillInit refuses SIGKILL to PID 1: the kernel panics when init dies, and a container's
+// init takes the container down.
+var errKillInit = errors.New("SIGKILL to PID 1 is refused; init can't survive it")
+
+// refused returns why the dialog won't send its signal at all, nil when it may.
+func (d *killDialog) refused() error {
+	if killSignals[d.signal].sig != syscall.SIGKILL {
+		return nil
+	}
+	for _, t := range d.targets {
+		if t.PID == 1 {
+			return errKillInit
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import "strings"
+
+// quickFilter is a set of the single-key filters of the process table. They narrow the
+// rows further on top of the text, user, unit and link filters, and each other: a row is
+// shown only when it passes all of them.
+type quickFilter uint8
+
+const (
+	// quickStuck keeps the zombies and the processes in uninterruptible sleep.
+	quickStuck quickFilter = 1 << iota
+	// quickCPU and quickMem keep the processes at or above the CPU and memory warning
+	// thresholds of the [rows] section, the point where row tinting starts.
+	quickCPU
+	quickMem
+)
+
+// quickFilterKeys maps the keys of the quick filters to the filter each toggles.
+var quickFilterKeys = map[string]quickFilter{"z": quickStuck, "x": quickCPU, "M": quickMem}
+
+// matches reports whether p passes every filter of f. memTotal is the RAM the memory
+// share is computed against, as for the row bands.
+func (f quickFilter) matches(p ProcessInfo, rows RowsConfig, memTotal uint64) bool {
+	if f&quickStuck != 0 && !p.Zombie && !p.Blocked {
+		return false
+	}
+	if f&quickCPU != 0 && p.CPUPercent < rows.CPUWarning {
+		return false
+	}
+	if f&quickMem != 0 && (memTotal == 0 || float64(p.Memory)/float64(memTotal)*100 < rows.MemWarning) {
+		return false
+	}
+	return true
+}
+
+// labels names the filters of f for the footer chips, e.g. "cpu ≥ 50%".
+func (f quickFilter) labels(rows RowsConfig) []string {
+	var labels []string
+	if f&quickStuck != 0 {
+		labels = append(labels, "zombie/D")
+	}
+	if f&quickCPU != 0 {
+		labels = append(labels, "cpu ≥ "+formatPercent(rows.CPUWarning, 0))
+	}
+	if f&quickMem != 0 {
+		labels = append(labels, "mem ≥ "+formatPercent(rows.MemWarning, 0))
+	}
+	return labels
+}
+
+// viewQuickChips renders the active quick filters as chips in front of the footer hints,
+// "" when none is on.
+func (m model) viewQuickChips() string {
+	labels := m.quickFilters.labels(m.cfg.Rows)
+	if len(labels) == 0 {
+		return ""
+	}
+	chip := m.baseStyle.Foreground(Color.Highlight).Render
+	chips := make([]string, len(labels))
+	for i, l := range labels {
+		chips[i] = chip("[" + l + "]")
+	}
+	return strings.Join(chips, " ") + m.baseStyle.Foreground(Color.Secondary).Render(" · ")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends the key named key to m and returns the model and whether the command it
+// returned quits.
+func press(m model, key string) (model, bool) {
+	next, cmd := m.Update(keys(key)[0])
+	return next.(model), quits(cmd)
+}
+
+// quits reports whether cmd is tea.Quit.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestScrubQuit(t *testing.T) {
+	m := newModel(defaultConfig())
+	m.scrub = &historyFrame{}
+	m, quit := press(m, "q")
+	if quit || m.scrub != nil {
+		t.Fatalf("q in history mode: quit %v, still scrubbing %v; want it to return to the live view", quit, m.scrub != nil)
+	}
+	if _, quit := press(m, "q"); !quit {
+		t.Error("a second q doesn't quit")
+	}
+
+	m.confirmQuit = true
+	m, _ = press(m, "q")
+	if !m.quitPrompt {
+		t.Error("q after history mode skips confirm_quit")
+	}
+
+	m = newModel(defaultConfig())
+	m.scrub = &historyFrame{}
+	if _, quit := press(m, "ctrl+c"); !quit {
+		t.Error("ctrl+c in history mode doesn't quit")
+	}
+}
+
+func TestFocusQuit(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *model)
+	}{
+		{"header", func(m *model) { m.focus = areaHeader }},
+		{"panel", func(m *model) { m.focus = collectorDisk }},
+		{"cleanup", func(m *model) { m.view = viewCleanup }},
+	}
+	for _, tt := range tests {
+		m := newModel(defaultConfig())
+		m.confirmQuit = true
+		tt.setup(&m)
+		m, quit := press(m, "q")
+		if quit || m.quitPrompt || m.focus != areaProcesses || m.view != viewProcesses {
+			t.Errorf("q with the %s focused: quit %v, prompt %v, focus %q, view %v; want back to the table", tt.name, quit, m.quitPrompt, m.focus, m.view)
+		}
+		if m, _ = press(m, "q"); !m.quitPrompt {
+			t.Errorf("a second q after the %s skips confirm_quit", tt.name)
+		}
+	}
+}
+
+func TestCtrlCReachesChild(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *model)
+	}{
+		{"process table", func(m *model) {}},
+		{"kill dialog", func(m *model) { m.kill = &killDialog{} }},
+		{"actions menu", func(m *model) { m.actions = &actionMenu{} }},
+		{"renice picker", func(m *model) { m.renice = &renicePicker{} }},
+		{"views menu", func(m *model) { m.viewsMenu = &menu{} }},
+		{": prompt", func(m *model) { m.commanding = true }},
+		{"search", func(m *model) { m.procSearching = true }},
+		{"history mode", func(m *model) { m.scrub = &historyFrame{} }},
+		{"header", func(m *model) { m.focus = areaHeader }},
+		{"panel", func(m *model) { m.focus = collectorDisk }},
+		{"cleanup", func(m *model) { m.view = viewCleanup }},
+	}
+	for _, tt := range tests {
+		m := newModel(defaultConfig())
+		// No such process group exists, so the interrupt fails and says so.
+		m.child = &childRun{argv: []string{"make", "test"}, key: procKey{pid: 1<<31 - 1}}
+		tt.setup(&m)
+		m, quit := press(m, "ctrl+c")
+		if quit {
+			t.Errorf("ctrl+c in the %s quits while the command runs", tt.name)
+		}
+		if !strings.Contains(m.banner, "could not interrupt make test") {
+			t.Errorf("ctrl+c in the %s isn't forwarded to the command: banner %q", tt.name, m.banner)
+		}
+	}
+
+	m := newModel(defaultConfig())
+	m.kill = &killDialog{}
+	if _, quit := press(m, "ctrl+c"); !quit {
+		t.Error("ctrl+c without a command doesn't quit")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// terminalOutput counts what the renderer writes, standing in for a pty.
+type terminalOutput struct {
+	mu            sync.Mutex
+	bytes, writes int
+}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.bytes += len(p)
+	o.writes++
+	return len(p), nil
+}
+
+// take returns the bytes and writes since the last call.
+func (o *terminalOutput) take() (bytes, writes int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	bytes, writes = o.bytes, o.writes
+	o.bytes, o.writes = 0, 0
+	return bytes, writes
+}
+
+// quietModel runs a model without its commands, so no collector or timer runs and only
+// the messages the test sends change the frame.
+type quietModel struct{ model }
+
+func (q quietModel) Init() tea.Cmd { return nil }
+
+func (q quietModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, _ := q.model.Update(msg)
+	return quietModel{next.(model)}, nil
+}
+
+// runProgram runs a model sized 160x40 in a Bubble Tea program redrawing at most fps times
+// per second into out, and returns it after the first frame was written.
+func runProgram(t *testing.T, fps int, out *terminalOutput) *tea.Program {
+	withPlainProfile(t)
+	withHostOS(t, "linux")
+	m := newModel(defaultConfig())
+	m.interval = time.Second
+	p := tea.NewProgram(quietModel{m}, tea.WithOutput(out), tea.WithInput(nil), tea.WithoutSignalHandler(), tea.WithFPS(fps))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := p.Run(); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(func() {
+		p.Quit()
+		<-done
+	})
+	p.Send(tea.WindowSizeMsg{Width: 160, Height: 40})
+	settle(fps)
+	out.take()
+	return p
+}
+
+// settle waits for a few frames at fps to be flushed.
+func settle(fps int) {
+	time.Sleep(3*time.Second/time.Duration(fps) + 20*time.Millisecond)
+}
+
+// TestRedrawBytes sends ticks to a running program and measures what each writes to the
+// terminal: one whose rows didn't change rewrites the header's update time only.
+func TestRedrawBytes(t *testing.T) {
+	out := &terminalOutput{}
+	p := runProgram(t, 120, out)
+
+	at := time.Now()
+	p.Send(tick(at, busyProcs(25)))
+	settle(120)
+	full, _ := out.take()
+
+	p.Send(tick(at.Add(time.Second), busyProcs(25)))
+	settle(120)
+	unchanged, _ := out.take()
+
+	moved := busyProcs(25)
+	for i := range moved {
+		moved[i].CPUTime = time.Duration(i+1) * time.Second
+	}
+	p.Send(tick(at.Add(2*time.Second), moved))
+	settle(120)
+	changed, _ := out.take()
+
+	t.Logf("bytes written: first frame %d, unchanged rows %d, every row changed %d", full, unchanged, changed)
+	if unchanged == 0 || unchanged > full/10 {
+		t.Errorf("a tick with unchanged rows wrote %d bytes, want some but at most a tenth of the %d of a full frame", unchanged, full)
+	}
+	if changed <= 2*unchanged {
+		t.Errorf("a tick changing every row wrote %d bytes, no more than twice the %d of one changing none", changed, unchanged)
+	}
+}
+
+// TestMaxFPS sends a storm of frames and counts how many reach the terminal at a low and a
+// high -max-fps.
+func TestMaxFPS(t *testing.T) {
+	storm := func(fps int) (bytes, writes int) {
+		out := &terminalOutput{}
+		p := runProgram(t, fps, out)
+		// 50 resizes over 250ms, as when a window is dragged.
+		for i := range 50 {
+			p.Send(tea.WindowSizeMsg{Width: 120 + i%2*40, Height: 40})
+			time.Sleep(5 * time.Millisecond)
+		}
+		settle(fps)
+		return out.take()
+	}
+	slowBytes, slow := storm(4)
+	fastBytes, fast := storm(120)
+	t.Logf("during a resize storm: -max-fps 4 wrote %d bytes in %d frames, -max-fps 120 %d bytes in %d frames", slowBytes, slow, fastBytes, fast)
+	if slow > 3 {
+		t.Errorf("-max-fps 4 flushed %d frames in about 250ms, want at most 3", slow)
+	}
+	if fast < 3*slow || fastBytes < 3*slowBytes {
+		t.Errorf("-max-fps 120 flushed %d frames of %d bytes, want far more than the %d of %d bytes at 4", fast, fastBytes, slow, slowBytes)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reloadRequestMsg asks the model to re-read its config file, e.g. after SIGHUP.
+type reloadRequestMsg struct{}
+
+// configLoadedMsg carries the result of re-reading the config file. cfg is always
+// usable: keys that failed validation already hold their previous values.
+type configLoadedMsg struct {
+	cfg Config
+	err error
+}
+
+// reloadConfigCmd re-reads the config file in the background, falling back to the
+// running config for every key that fails validation.
+func (m model) reloadConfigCmd() tea.Cmd {
+	src, current := m.configSrc, m.cfg
+	return func() tea.Msg {
+		cfg, err := loadConfig(src, current)
+		return configLoadedMsg{cfg: cfg, err: err}
+	}
+}
+
+// restartKeys are config keys whose new values only take effect after a restart, with
+// the accessor used to compare them. The Container, NET and GPU-MEM columns, the audit
+// panel, the network namespaces, the firewall counters, the power command and UPower
+// need the collectors to be rebuilt, which would reset the CPU% samples, and the history
+// writer and the StatsD client read their settings once when they are opened. The
+// terminal title is saved at startup, before it is first changed, and the core dump feed
+// is opened then too.
+var restartKeys = []struct {
+	key     string
+	value   func(c Config) string
+	restore func(dst *Config, src Config)
+}{
+	{"columns.container", func(c Config) string { return c.Columns.Container },
+		func(dst *Config, src Config) { dst.Columns.Container = src.Columns.Container }},
+	{"columns.net", func(c Config) string { return strconv.FormatBool(c.Columns.Net) },
+		func(dst *Config, src Config) { dst.Columns.Net = src.Columns.Net }},
+	{"columns.gpu", func(c Config) string { return c.Columns.GPU },
+		func(dst *Config, src Config) { dst.Columns.GPU = src.Columns.GPU }},
+	{"audit.panel", func(c Config) string { return strconv.FormatBool(c.Audit.Panel) },
+		func(dst *Config, src Config) { dst.Audit.Panel = src.Audit.Panel }},
+	{"network.namespaces", func(c Config) string { return strconv.FormatBool(c.Network.Namespaces) },
+		func(dst *Config, src Config) { dst.Network.Namespaces = src.Network.Namespaces }},
+	{"network.firewall_counters", func(c Config) string { return strings.Join(c.Network.FirewallCounters, ",") },
+		func(dst *Config, src Config) { dst.Network.FirewallCounters = src.Network.FirewallCounters }},
+	{"sensors.power_command", func(c Config) string { return strings.Join(c.Sensors.PowerCommand, " ") },
+		func(dst *Config, src Config) { dst.Sensors.PowerCommand = src.Sensors.PowerCommand }},
+	{"history.retention", func(c Config) string { return c.History.Retention.String() },
+		func(dst *Config, src Config) { dst.History.Retention = src.History.Retention }},
+	{"history.flush_interval", func(c Config) string { return c.History.FlushInterval.String() },
+		func(dst *Config, src Config) { dst.History.FlushInterval = src.History.FlushInterval }},
+	{"title.mode", func(c Config) string { return c.Title.Mode },
+		func(dst *Config, src Config) { dst.Title.Mode = src.Title.Mode }},
+	{"crashes.watch", func(c Config) string { return strconv.FormatBool(c.Crashes.Watch) },
+		func(dst *Config, src Config) { dst.Crashes.Watch = src.Crashes.Watch }},
+	{"battery.upower", func(c Config) string { return strconv.FormatBool(c.Battery.UPower) },
+		func(dst *Config, src Config) { dst.Battery.UPower = src.Battery.UPower }},
+	{"statsd.prefix", func(c Config) string { return c.Statsd.Prefix },
+		func(dst *Config, src Config) { dst.Statsd.Prefix = src.Statsd.Prefix }},
+	{"statsd.tags", func(c Config) string { return c.Statsd.tagSuffix() },
+		func(dst *Config, src Config) { dst.Statsd.Tags = src.Statsd.Tags }},
+}
+
+// applyConfig switches the running program to cfg: theme, units, thresholds, refresh
+// interval, meter styles and visible columns change immediately. Keys listed in
+// restartKeys keep their running value and are returned so the caller can tell the user.
+func (m *model) applyConfig(cfg Config) (needRestart []string) {
+	for _, k := range restartKeys {
+		if k.value(cfg) != k.value(m.cfg) {
+			needRestart = append(needRestart, k.key)
+			k.restore(&cfg, m.cfg)
+		}
+	}
+	needRestart = append(needRestart, m.uncollectedColumns(&cfg)...)
+	if m.confirmQuitFlag {
+		cfg.ConfirmQuit = true
+	}
+	if m.asciiFlag {
+		cfg.Meters.ASCII = true
+	}
+
+	// Edited rules start over; unchanged ones keep their firing state and cooldown.
+	if !slices.Equal(cfg.Alerts, m.cfg.Alerts) {
+		m.alertStates = nil
+	}
+	// Edited exec panels start over with their first run; the runs of the old ones are
+	// left to finish and dropped.
+	if !slices.Equal(cfg.ExecPanels, m.cfg.ExecPanels) {
+		if m.focusedExecPanel() != nil {
+			m.focus = areaProcesses
+		}
+		m.execPanels = newExecPanels(cfg.ExecPanels)
+	}
+	m.pruneWebhookStatus(cfg)
+	// n switches the names for the session; only a change to [names] undoes that.
+	if cfg.Names != m.cfg.Names {
+		Names = cfg.Names.prefs()
+	}
+	m.cfg = cfg
+	m.interval = m.refreshInterval(cfg)
+	m.meterStyles = cfg.Meters.styles()
+	m.confirmQuit = cfg.ConfirmQuit
+	Units = cfg.Units.unitPrefs()
+	Units.StartTimes = m.state.StartTimes
+	cfg.Columns.setCustomColumns()
+
+	Color = cfg.Theme.theme()
+	if m.processTable.Focused() {
+		m.tableStyle.Selected = m.baseStyle.Background(Color.Highlight)
+	}
+	m.processTable.SetStyles(m.tableStyle)
+	m.userTable.SetStyles(m.tableStyle)
+	m.unitTable.SetStyles(m.tableStyle)
+	m.connTable.SetStyles(m.tableStyle)
+
+	m.setColumns(selectColumns(cfg.Columns.columnIDs(m.procOpts)))
+	return needRestart
+}
+
+// uncollectedColumns returns the keys of cfg that show process data the collector was not
+// started with: the columns of columns.visible that columnIDs leaves out until a restart,
+// and, where the thread count is read separately, the custom columns that use it, which
+// keep their running definitions.
+// Low-overhead mode collects none of them, so a restart wouldn't help.
+func (m model) uncollectedColumns(cfg *Config) []string {
+	if m.procOpts.lite {
+		return nil
+	}
+	want := cfg.Columns.processOptions()
+	var ids []string
+	for _, c := range []struct {
+		id         string
+		want, have bool
+	}{
+		{columnRunDelay, want.delays && slices.Contains(cfg.Columns.Visible, columnRunDelay), m.procOpts.delays},
+		{columnIODelay, want.delays && slices.Contains(cfg.Columns.Visible, columnIODelay), m.procOpts.delays},
+		{columnMemLimit, want.memLimits, m.procOpts.memLimits},
+		{columnCtxSwitch, want.ctxSwitch, m.procOpts.ctxSwitch},
+	} {
+		if c.want && !c.have {
+			ids = append(ids, c.id)
+		}
+	}
+	var keys []string
+	if len(ids) > 0 {
+		keys = append(keys, "columns.visible ("+strings.Join(ids, ", ")+")")
+	}
+	if want.threads && !m.procOpts.threads && !schedReadsThreads {
+		keys = append(keys, "columns.custom (threads)")
+		cfg.Columns.Custom = m.cfg.Columns.Custom
+	}
+	return keys
+}
+
+// setColumns replaces the visible process table columns, keeping the sort column when it
+// is still shown.
+func (m *model) setColumns(cols []processColumn) {
+	if slices.EqualFunc(cols, m.columns, func(a, b processColumn) bool { return a.id == b.id }) {
+		// A custom column may have kept its name but changed its expression.
+		m.columns = cols
+		m.refreshRows()
+		return
+	}
+	if !slices.ContainsFunc(cols, func(c processColumn) bool { return c.id == m.sortColumn }) {
+		m.sortColumn = nextSortColumn(cols, "")
+	}
+	m.columns = cols
+	m.privilegeHint()
+	// The rows must match the column count before refreshRows sets the new columns.
+	m.processTable.SetRows([]table.Row{})
+	m.refreshRows()
+}
+
+// applyReload switches to the reloaded config and tells the user how the reload went.
+func (m *model) applyReload(msg configLoadedMsg) {
+	var notes []string
+	if needRestart := m.applyConfig(msg.cfg); len(needRestart) > 0 {
+		notes = append(notes, "restart to apply "+strings.Join(needRestart, ", "))
+	}
+	if msg.err != nil {
+		m.reportConfigError(msg.err, append([]string{"kept previous values"}, notes...)...)
+		return
+	}
+	m.reportInfo(strings.Join(append([]string{"config reloaded"}, notes...), "; "))
+}
+
+// reportConfigError shows the keys of the config file that failed validation in the
+// banner, followed by notes on what was done instead.
+func (m *model) reportConfigError(err error, notes ...string) {
+	// One entry per invalid key, joined so the banner stays on a single line.
+	notes = append([]string{strings.ReplaceAll(err.Error(), "\n", "; ")}, notes...)
+	m.reportError(errors.New(strings.Join(notes, "; ")))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReloadFlagsUncollectedColumns(t *testing.T) {
+	if !ctxSwitchSupported || !delaySupported || !memLimitSupported {
+		t.Skip("the optional columns aren't collected on this platform")
+	}
+	m := newModel(defaultConfig())
+	cfg := defaultConfig()
+	cfg.Columns.Visible = append(cfg.Columns.Visible, columnCtxSwitch, columnIODelay, columnMemLimit)
+	cfg.Columns.Custom = map[string]string{"tpc": "threads / cpu"}
+	needRestart := m.applyConfig(cfg)
+	// The thread count comes with the rest of /proc/<pid>/stat on Linux.
+	want := []string{"columns.visible (iodelay, memlimit, ctxsw)"}
+	if !slices.Equal(needRestart, want) {
+		t.Errorf("needRestart = %q, want %q", needRestart, want)
+	}
+	if slices.ContainsFunc(m.columns, func(c processColumn) bool { return c.id == columnCtxSwitch }) {
+		t.Errorf("columns without data are shown: %v", columnIDsOf(m))
+	}
+	if m.cfg.Columns.Custom["tpc"] == "" {
+		t.Errorf("custom columns = %v, want the new one", m.cfg.Columns.Custom)
+	}
+
+	m.applyReload(configLoadedMsg{cfg: cfg})
+	if !strings.Contains(m.banner, "restart to apply columns.visible (iodelay, memlimit, ctxsw)") {
+		t.Errorf("banner = %q", m.banner)
+	}
+
+	// Columns whose data is collected apply at once.
+	cfg = defaultConfig()
+	cfg.Columns.Visible = slices.DeleteFunc(cfg.Columns.Visible, func(id string) bool { return id == columnTime })
+	if needRestart := m.applyConfig(cfg); len(needRestart) != 0 {
+		t.Errorf("needRestart = %q for a column that is collected", needRestart)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// withTrueColor renders under a true-colour profile for the rest of the benchmark, so
+// styles emit the escape codes a real terminal gets.
+func withTrueColor(b *testing.B) {
+	old := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	b.Cleanup(func() { lipgloss.SetColorProfile(old) })
+}
+
+func BenchmarkSparkline(b *testing.B) {
+	withTrueColor(b)
+	cells := make([]float64, 120)
+	for i := range cells {
+		cells[i] = 50 + 50*math.Sin(float64(i)/7)
+	}
+	cells[10] = math.NaN()
+	style := lipgloss.NewStyle()
+	b.ReportAllocs()
+	for range b.N {
+		sparkline(cells, 100, 60, style)
+	}
+}
+
+// benchRows are table cells as a refresh builds them: plain text, a colored cell and the
+// odd wide rune.
+func benchRows() []table.Row {
+	rows := make([]table.Row, 500)
+	for i := range rows {
+		rows[i] = table.Row{
+			fmt.Sprintf("%d", 1000+i),
+			fmt.Sprintf("worker-%d", i),
+			tagCell("12.50%", tagRed),
+			"/usr/bin/python3 -m celery worker 日本語",
+		}
+	}
+	return rows
+}
+
+func BenchmarkCellWidth(b *testing.B) {
+	rows := benchRows()
+	b.ReportAllocs()
+	for range b.N {
+		for _, row := range rows {
+			for _, cell := range row {
+				cellWidth(cell)
+			}
+		}
+	}
+}
+
+func BenchmarkTableColumns(b *testing.B) {
+	rows := benchRows()
+	cols := selectColumns([]string{columnPID, columnName, columnCPU, columnCommand})
+	b.ReportAllocs()
+	for range b.N {
+		tableColumns(cols, rows, columnCPU, nil)
+	}
+}
+
+func BenchmarkFormatInt(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		formatInt(1234567)
+		formatInt(-42)
+		formatInt(uint64(9876543210))
+	}
+}
+
+func BenchmarkFormatBytes(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		formatBytes(512)
+		formatBytes(3 << 20)
+		formatBytes(17 << 30)
+	}
+}
+
+func BenchmarkFormatCPUTime(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		formatCPUTime(83*time.Second + 450*time.Millisecond)
+		formatCPUTime(26*time.Hour + 3*time.Minute)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// respawnWindow is how long a watched process that exited is remembered, waiting for its
+// replacement. Supervisors typically restart within seconds.
+const respawnWindow = time.Minute
+
+// createTimeSlack absorbs the coarse start times some platforms report, which derive from
+// a boot time in whole seconds.
+const createTimeSlack = time.Second
+
+// goneProc is a watched process that exited and may still be replaced.
+type goneProc struct {
+	proc ProcessInfo
+	// seenAt is the last pass it was listed in; it exited after that.
+	seenAt time.Time
+	// pinned is set when it was the pinned process, whose pin moves to the replacement.
+	pinned bool
+}
+
+// respawnWatch follows the watched and pinned processes across passes to catch the ones
+// that respawn: a crash-looping service only ever shows as a young process, which is easy
+// to miss. A process that appears counts as a restart of one that exited when it has the
+// same name, started after the old one was last seen and has the same parent or the same
+// command line. The heuristic is conservative on purpose; a miss costs less than a false
+// alarm.
+type respawnWatch struct {
+	live map[procKey]ProcessInfo
+	// pinned is the key of the pinned process in live, if any.
+	pinned procKey
+	gone   []goneProc
+	// lastAt is when the previous pass was observed, zero before the first.
+	lastAt time.Time
+	// restarts counts the restarts of each name this session.
+	restarts map[string]int
+}
+
+func newRespawnWatch() *respawnWatch {
+	return &respawnWatch{live: map[procKey]ProcessInfo{}, restarts: map[string]int{}}
+}
+
+// observe compares the watched processes among procs, and the pinned one, with the
+// previous pass and returns a report of each restart found. When the pinned process
+// restarted, repin is the key of its replacement.
+func (w *respawnWatch) observe(procs []ProcessInfo, names []string, pinned *procKey, now time.Time) (reports []string, repin *procKey) {
+	// The table unpins a process once it is gone, so the replacement of a pinned process
+	// is followed by its name.
+	pinnedName := func(p ProcessInfo) bool {
+		return slices.ContainsFunc(w.gone, func(g goneProc) bool { return g.pinned && sameName(g.proc, p) })
+	}
+	cur := map[procKey]ProcessInfo{}
+	for _, p := range procs {
+		key := procKey{p.PID, p.CreateTime}
+		if _, ok := watchedName(p, names); ok || pinned != nil && *pinned == key || pinnedName(p) {
+			cur[key] = p
+		}
+	}
+	for key, p := range w.live {
+		if _, ok := cur[key]; !ok {
+			w.gone = append(w.gone, goneProc{proc: p, seenAt: w.lastAt, pinned: key == w.pinned})
+		}
+	}
+	// A process that is listed again hadn't exited, e.g. in low-overhead mode, which
+	// only lists the busiest processes.
+	w.gone = slices.DeleteFunc(w.gone, func(g goneProc) bool {
+		_, ok := cur[procKey{g.proc.PID, g.proc.CreateTime}]
+		return ok || now.Sub(g.seenAt) > respawnWindow
+	})
+
+	var started []ProcessInfo
+	if !w.lastAt.IsZero() {
+		for key, p := range cur {
+			if _, ok := w.live[key]; !ok {
+				started = append(started, p)
+			}
+		}
+	}
+	slices.SortFunc(started, func(a, b ProcessInfo) int { return cmp.Compare(a.CreateTime, b.CreateTime) })
+	w.pinned = procKey{}
+	if pinned != nil {
+		w.pinned = *pinned
+	}
+	for _, p := range started {
+		i := slices.IndexFunc(w.gone, func(g goneProc) bool { return respawnOf(g, p) })
+		if i < 0 {
+			continue
+		}
+		g := w.gone[i]
+		w.gone = slices.Delete(w.gone, i, i+1)
+		name, ok := watchedName(p, names)
+		if !ok {
+			name = shownName(p)
+		}
+		if g.pinned {
+			key := procKey{p.PID, p.CreateTime}
+			repin, w.pinned = &key, key
+		}
+		w.restarts[name]++
+		reports = append(reports, fmt.Sprintf("%s restarted (PID %d → %d), %s× this session", name, g.proc.PID, p.PID, formatInt(w.restarts[name])))
+	}
+	w.live, w.lastAt = cur, now
+	return reports, repin
+}
+
+// respawnOf reports whether p looks like the replacement of the exited process g.
+func respawnOf(g goneProc, p ProcessInfo) bool {
+	old := g.proc
+	if !sameName(p, old) || p.CreateTime <= old.CreateTime {
+		return false
+	}
+	if time.UnixMilli(p.CreateTime).Before(g.seenAt.Add(-createTimeSlack)) {
+		return false
+	}
+	return p.PPID == old.PPID || (old.Cmdline != "" && p.Cmdline == old.Cmdline)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRespawnOf(t *testing.T) {
+	seen := time.Unix(1_000_000, 0)
+	old := ProcessInfo{PID: 100, Name: "nginx", PPID: 1, Cmdline: "nginx -g daemon off;", CreateTime: seen.Add(-time.Hour).UnixMilli()}
+	after := seen.Add(2 * time.Second).UnixMilli()
+	tests := []struct {
+		name string
+		p    ProcessInfo
+		want bool
+	}{
+		{"same name and parent", ProcessInfo{PID: 200, Name: "nginx", PPID: 1, Cmdline: "nginx", CreateTime: after}, true},
+		{"same name and command line", ProcessInfo{PID: 200, Name: "nginx", PPID: 4242, Cmdline: old.Cmdline, CreateTime: after}, true},
+		{"different parent and command line", ProcessInfo{PID: 200, Name: "nginx", PPID: 4242, Cmdline: "nginx -c /tmp/test.conf", CreateTime: after}, false},
+		{"different name", ProcessInfo{PID: 200, Name: "apache2", PPID: 1, Cmdline: old.Cmdline, CreateTime: after}, false},
+		{"started before the old one was last seen", ProcessInfo{PID: 200, Name: "nginx", PPID: 1, Cmdline: old.Cmdline, CreateTime: seen.Add(-time.Minute).UnixMilli()}, false},
+		{"started within the slack", ProcessInfo{PID: 200, Name: "nginx", PPID: 1, CreateTime: seen.Add(-createTimeSlack / 2).UnixMilli()}, true},
+		{"started before the old one", ProcessInfo{PID: 200, Name: "nginx", PPID: 1, CreateTime: old.CreateTime - 1}, false},
+		{"no command line to compare", ProcessInfo{PID: 200, Name: "nginx", PPID: 4242, CreateTime: after}, false},
+	}
+	for _, tt := range tests {
+		if got := respawnOf(goneProc{proc: old, seenAt: seen}, tt.p); got != tt.want {
+			t.Errorf("%s: respawnOf = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Without a command line on either side, two empty ones aren't the same program.
+	noCmd := old
+	noCmd.Cmdline = ""
+	if respawnOf(goneProc{proc: noCmd, seenAt: seen}, ProcessInfo{PID: 200, Name: "nginx", PPID: 4242, CreateTime: after}) {
+		t.Error("empty command lines match")
+	}
+}
+
+// respawnPass is one pass of the processes a respawnWatch sees.
+type respawnPass struct {
+	at    time.Duration
+	procs []ProcessInfo
+}
+
+func TestRespawnWatch(t *testing.T) {
+	start := time.Unix(1_000_000, 0)
+	proc := func(pid int32, started time.Duration) ProcessInfo {
+		return ProcessInfo{PID: pid, Name: "nginx", PPID: 1, Cmdline: "nginx", CreateTime: start.Add(started).UnixMilli()}
+	}
+	other := ProcessInfo{PID: 7, Name: "sshd", PPID: 1, CreateTime: start.Add(-time.Hour).UnixMilli()}
+	tests := []struct {
+		name   string
+		passes []respawnPass
+		want   []string
+	}{
+		{"restart", []respawnPass{
+			{0, []ProcessInfo{proc(100, -time.Hour), other}},
+			{2 * time.Second, []ProcessInfo{proc(200, time.Second), other}},
+		}, []string{"nginx restarted (PID 100 → 200), 1× this session"}},
+		{"restart a pass later", []respawnPass{
+			{0, []ProcessInfo{proc(100, -time.Hour)}},
+			{2 * time.Second, nil},
+			{4 * time.Second, []ProcessInfo{proc(200, 3*time.Second)}},
+		}, []string{"nginx restarted (PID 100 → 200), 1× this session"}},
+		{"crash loop", []respawnPass{
+			{0, []ProcessInfo{proc(100, -time.Hour)}},
+			{2 * time.Second, []ProcessInfo{proc(200, time.Second)}},
+			{4 * time.Second, []ProcessInfo{proc(300, 3*time.Second)}},
+		}, []string{"nginx restarted (PID 100 → 200), 1× this session", "nginx restarted (PID 200 → 300), 2× this session"}},
+		// Low-overhead mode only lists the busiest processes, so one can drop out of a
+		// pass without having exited.
+		{"listed again with the same key", []respawnPass{
+			{0, []ProcessInfo{proc(100, -time.Hour)}},
+			{2 * time.Second, nil},
+			{4 * time.Second, []ProcessInfo{proc(100, -time.Hour)}},
+			{6 * time.Second, []ProcessInfo{proc(100, -time.Hour), proc(200, 5*time.Second)}},
+		}, nil},
+		{"replacement after the window", []respawnPass{
+			{0, []ProcessInfo{proc(100, -time.Hour)}},
+			{2 * time.Second, nil},
+			{respawnWindow + time.Second, nil},
+			{respawnWindow + 3*time.Second, []ProcessInfo{proc(200, respawnWindow+2*time.Second)}},
+		}, nil},
+		{"first pass", []respawnPass{
+			{0, []ProcessInfo{proc(200, -time.Second)}},
+		}, nil},
+	}
+	for _, tt := range tests {
+		w := newRespawnWatch()
+		var got []string
+		for _, pass := range tt.passes {
+			reports, _ := w.observe(pass.procs, []string{"nginx"}, nil, start.Add(pass.at))
+			got = append(got, reports...)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: reports = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRespawnMovesPin(t *testing.T) {
+	start := time.Now()
+	old := ProcessInfo{PID: 100, Name: "worker", PPID: 50, CreateTime: start.Add(-time.Hour).UnixMilli()}
+	next := ProcessInfo{PID: 200, Name: "worker", PPID: 50, CreateTime: start.Add(3 * time.Second).UnixMilli()}
+	m := newModel(defaultConfig())
+	m = send(m, tea.WindowSizeMsg{Width: 160, Height: 40}, tick(start, []ProcessInfo{old}))
+	m.togglePin(old)
+	m = send(m, tick(start.Add(time.Second), []ProcessInfo{old}))
+
+	// The replacement starts a pass after the old process exited, by when the table has
+	// dropped the pin.
+	m = send(m, tick(start.Add(2*time.Second), nil), tick(start.Add(4*time.Second), []ProcessInfo{next}))
+	if !m.isPinned(next) {
+		t.Fatalf("pinned = %v, want the replacement PID %d", m.pinned, next.PID)
+	}
+	if want := "worker restarted (PID 100 → 200), 1× this session"; m.banner != want {
+		t.Errorf("banner = %q, want %q", m.banner, want)
+	}
+	if rows := m.processTable.Rows(); len(rows) == 0 || stripTags(rows[0][0]) != "200" {
+		t.Errorf("rows = %v, want the pinned PID 200 first", rows)
+	}
+}
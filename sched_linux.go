@@ -0,0 +1,61 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Scheduling policies of /proc/<pid>/stat under which nice values don't apply.
+const (
+	schedFIFO     = 1
+	schedRR       = 2
+	schedDeadline = 6
+)
+
+// schedReadsThreads reports whether processSched reads the thread count, which /proc/<pid>/stat
+// has anyway.
+const schedReadsThreads = true
+
+// processSched returns the nice value of p, whether it runs under a real-time policy,
+// whether it is a zombie or blocked in uninterruptible sleep, and its thread count. All
+// come from /proc/<pid>/stat: gopsutil's Nice reports the raw getpriority value
+// (20 - nice) on Linux and has no notion of the scheduling policy.
+func processSched(p *process.Process) (sched procSched, err error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", p.Pid))
+	if err != nil {
+		return procSched{}, err
+	}
+	// The command name may contain spaces and parentheses; the fields start after the last ')'.
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return procSched{}, fmt.Errorf("malformed stat of PID %d", p.Pid)
+	}
+	// fields[0] is field 3 (state) of proc(5), so nice (19) is fields[16], num_threads (20)
+	// fields[17] and policy (41) fields[38].
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 39 {
+		return procSched{}, fmt.Errorf("short stat of PID %d", p.Pid)
+	}
+	n, err := strconv.ParseInt(fields[16], 10, 32)
+	if err != nil {
+		return procSched{}, err
+	}
+	policy, err := strconv.Atoi(fields[38])
+	if err != nil {
+		return procSched{}, err
+	}
+	threads, _ := strconv.ParseInt(fields[17], 10, 32)
+	return procSched{
+		nice:     int32(n),
+		realtime: policy == schedFIFO || policy == schedRR || policy == schedDeadline,
+		zombie:   fields[0] == "Z",
+		blocked:  fields[0] == "D",
+		threads:  int32(threads),
+	}, nil
+}
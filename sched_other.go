@@ -0,0 +1,28 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"slices"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// schedReadsThreads reports whether processSched reads the thread count; here it is read
+// separately, only for a custom column that uses it.
+const schedReadsThreads = false
+
+// processSched returns the nice value of p and whether it is a zombie or blocked in
+// uninterruptible sleep. Real-time scheduling isn't detected here.
+func processSched(p *process.Process) (procSched, error) {
+	nice, err := p.Nice()
+	if err != nil {
+		return procSched{}, err
+	}
+	status, err := p.Status()
+	return procSched{
+		nice:    nice,
+		zombie:  err == nil && slices.Contains(status, process.Zombie),
+		blocked: err == nil && slices.Contains(status, process.Blocked),
+	}, nil
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package main
+
+import "github.com/shirou/gopsutil/v4/process"
+
+// windowsNice maps the base priorities gopsutil reports for the Windows priority classes
+// onto nice values, so the NI column reads the same as on Unix.
+var windowsNice = map[int32]int32{
+	4:  19,  // idle
+	6:  10,  // below normal
+	8:  0,   // normal
+	10: -5,  // above normal
+	13: -10, // high
+}
+
+// schedReadsThreads reports whether processSched reads the thread count; here it is read
+// separately, only for a custom column that uses it.
+const schedReadsThreads = false
+
+// processSched returns the nice equivalent of p's priority class and whether it is in the
+// realtime class. Windows has no zombie or uninterruptible processes.
+func processSched(p *process.Process) (procSched, error) {
+	prio, err := p.Nice()
+	if err != nil {
+		return procSched{}, err
+	}
+	if prio == 24 {
+		return procSched{realtime: true}, nil
+	}
+	return procSched{nice: windowsNice[prio]}, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// plainScreen strips every escape sequence from a rendered view, along with the padding
+// lipgloss adds to the end of each line, so the layout pastes cleanly as plain text.
+func plainScreen(view string) string {
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// saveScreenCmd writes the screen as it is rendered right now to a timestamped text file
+// in the working directory, for pasting into a chat or ticket where a screenshot is
+// overkill.
+func (m model) saveScreenCmd() tea.Cmd {
+	path := fmt.Sprintf("%s-screen-%s.txt", appName, time.Now().Format("20060102-150405"))
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return m.writeFileCmd(path, []byte(plainScreen(m.View())), "screen")
+}
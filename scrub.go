@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyFrameMsg carries a sample loaded from the history database for the scrubber.
+type historyFrameMsg struct {
+	frame *historyFrame
+	err   error
+}
+
+// loadFrameCmd loads the stored sample next to t in the background.
+func (m model) loadFrameCmd(t time.Time, dir scrubDirection) tea.Cmd {
+	store := m.history
+	return func() tea.Msg {
+		frame, err := store.frame(t, dir)
+		return historyFrameMsg{frame: frame, err: err}
+	}
+}
+
+// recordHistory queues the latest snapshot for the history database and surfaces write
+// errors from earlier batches in the banner. The live view carries on either way.
+func (m *model) recordHistory(at time.Time) {
+	// A pass the clock jumped in is left out, as in the graphs.
+	if m.history == nil || m.data.ClockJump != 0 {
+		return
+	}
+	r := historyRecord{at: at, cpu: m.data.CPU, mem: m.data.Mem, timeWait: m.data.Sockets.States["TIME_WAIT"]}
+	if n := m.cfg.History.TopProcesses; n > 0 {
+		r.procs = topProcesses(m.data.Procs, n)
+	}
+	r.filesystems = m.fsGrowth.takeUnsaved()
+	m.history.record(r)
+	if err := m.history.takeErr(); err != nil {
+		m.reportError(err)
+	}
+}
+
+// applyFrame shows a loaded sample, or reports why none could be loaded.
+func (m *model) applyFrame(msg historyFrameMsg) {
+	switch {
+	case errors.Is(msg.err, errNoSample):
+		if m.scrub == nil {
+			m.reportInfo("history is empty")
+		}
+	case msg.err != nil:
+		m.reportError(msg.err)
+	default:
+		m.scrub = msg.frame
+	}
+}
+
+// updateScrub handles keys while history mode is shown: the arrows step through stored
+// samples, esc, H or q returns to the live view, where a second q quits.
+func (m model) updateScrub(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h":
+		return m, m.loadFrameCmd(m.scrub.at, scrubOlder)
+	case "right", "l":
+		return m, m.loadFrameCmd(m.scrub.at, scrubNewer)
+	case "esc", "H", "q":
+		m.scrub = nil
+	case "ctrl+c":
+		return m.interrupt()
+	}
+	return m, nil
+}
+
+// viewAt is the moment the graphs describe: the scrubbed sample in history mode, the
+// moment of the pause while paused, otherwise now.
+func (m model) viewAt() time.Time {
+	if m.scrub != nil {
+		return m.scrub.at
+	}
+	if m.paused != nil {
+		return m.pausedAt
+	}
+	return time.Now()
+}
+
+// scrubbed returns a copy of the model whose data and history are replaced by the scrubbed
+// sample, so the normal widgets render it unchanged.
+func (m model) scrubbed() model {
+	f := m.scrub
+	m.data.CPU = f.cpu
+	m.data.Mem = f.mem
+	m.data.Procs = f.procs
+	m.cpuHistory = f.cpuHistory
+	m.memHistory = f.memHistory
+	m.started = f.first
+	m.refreshRows()
+	return m
+}
+
+// viewScrubFooter describes the sample shown in history mode.
+func (m model) viewScrubFooter() string {
+	status := fmt.Sprintf("history %s (%s ago)", m.scrub.at.Format("2006-01-02 15:04:05"), formatAge(time.Since(m.scrub.at)))
+	if m.cfg.History.TopProcesses == 0 {
+		status += " · processes not recorded"
+	}
+	return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render(status) +
+		m.baseStyle.Foreground(Color.Secondary).Render(" · ←/→: scrub · esc: live")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SensorsConfig is the [sensors] section of the config file.
+type SensorsConfig struct {
+	// PowerCommand is run on every refresh to read the system power draw from a source the
+	// kernel doesn't expose, such as a BMC. It must print the watts as the first number of
+	// its first line, e.g. ["sh", "-c", "ipmitool dcmi power reading | awk '/Instantaneous/ {print $4}'"].
+	PowerCommand []string `toml:"power_command"`
+}
+
+// powerCommandTimeout bounds one run of the power command; ipmitool over a slow BMC can
+// take seconds.
+const powerCommandTimeout = 3 * time.Second
+
+// maxSaneWatts is the most a single power reading may be; anything above, like a negative
+// reading, is a sensor reporting garbage.
+const maxSaneWatts = 20000
+
+// errNoSensors fails the sensors collector at the startup probe on hosts without any.
+var errNoSensors = fmt.Errorf("no fan or power sensors found: %w", fs.ErrNotExist)
+
+// raplSample is the energy counter of a RAPL domain at one point in time.
+type raplSample struct {
+	uj uint64
+	at time.Time
+}
+
+// FanReading is the speed of one fan.
+type FanReading struct {
+	Name string
+	RPM  uint64
+}
+
+// PowerReading is the power draw of one domain, such as a CPU package or the whole
+// system, with the energy it used since the monitor started.
+type PowerReading struct {
+	Name   string
+	Watts  float64
+	Joules float64
+}
+
+// SensorStats are the fan and power sensors of the host.
+type SensorStats struct {
+	Fans  []FanReading
+	Power []PowerReading
+}
+
+// saneWatts reports whether a power reading is plausible.
+func saneWatts(w float64) bool {
+	return w >= 0 && w <= maxSaneWatts && !math.IsNaN(w)
+}
+
+// parseWatts reads the first number of the first line of a power command's output.
+func parseWatts(out []byte) (float64, error) {
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	scanner := bufio.NewScanner(bytes.NewReader(line))
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		if w, err := strconv.ParseFloat(strings.TrimRight(scanner.Text(), "Ww"), 64); err == nil {
+			return w, nil
+		}
+	}
+	return 0, fmt.Errorf("no number in power command output %q", string(line))
+}
+
+// sensorCollector reads the fans and power sensors and integrates the power draw into the
+// session energy. Like DiskIOCollector it is not safe for concurrent use.
+type sensorCollector struct {
+	command []string
+	// energy is the session energy of each power domain, keyed by name, and prevAt when the
+	// watts of the sources without an energy counter were last added to it.
+	energy map[string]float64
+	prevAt time.Time
+	// rapl holds the previous energy counter of each RAPL domain.
+	rapl map[string]raplSample
+}
+
+func newSensorCollector(command []string) *sensorCollector {
+	return &sensorCollector{command: command, energy: map[string]float64{}, rapl: map[string]raplSample{}}
+}
+
+// GetSensors reads every fan and power sensor; sensors reporting impossible values, such as
+// a fan header with nothing connected, are left out.
+func (c *sensorCollector) GetSensors() (*SensorStats, error) {
+	now := time.Now()
+	elapsed := 0.0
+	if !c.prevAt.IsZero() {
+		elapsed = now.Sub(c.prevAt).Seconds()
+	}
+	c.prevAt = now
+
+	s := &SensorStats{Fans: readFans()}
+	for _, p := range c.readRAPL(now) {
+		if saneWatts(p.Watts) {
+			c.energy[p.Name] += p.Joules
+			p.Joules = c.energy[p.Name]
+			s.Power = append(s.Power, p)
+		}
+	}
+	// The other sources report watts only, so the energy is the integral over the passes.
+	watts := readHwmonPower()
+	var cmdErr error
+	if len(c.command) > 0 {
+		w, err := runPowerCommand(c.command)
+		if err == nil && !saneWatts(w) {
+			err = fmt.Errorf("power command reported %v W", w)
+		}
+		if err == nil {
+			watts = append(watts, PowerReading{Name: "system (" + c.command[0] + ")", Watts: w})
+		}
+		cmdErr = err
+	}
+	for _, p := range watts {
+		if saneWatts(p.Watts) {
+			c.energy[p.Name] += p.Watts * elapsed
+			p.Joules = c.energy[p.Name]
+			s.Power = append(s.Power, p)
+		}
+	}
+	// RAPL needs two passes for a reading; its domains alone make the sensors available.
+	if len(s.Fans) == 0 && len(s.Power) == 0 && len(c.rapl) == 0 {
+		if cmdErr != nil {
+			return nil, cmdErr
+		}
+		return nil, errNoSensors
+	}
+	return s, nil
+}
+
+// runPowerCommand runs the power command and parses its watts.
+func runPowerCommand(command []string) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), powerCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("could not run power command: %w", err)
+	}
+	return parseWatts(out)
+}
+
+// formatWh renders an energy in watt-hours, e.g. "12.3 Wh".
+func formatWh(joules float64) string {
+	if joules >= 3.6e6 {
+		return formatFloat(joules/3.6e6, 2) + " kWh"
+	}
+	return formatFloat(joules/3600, 1) + " Wh"
+}
+
+// sensorsPanel shows the fan speeds and the power draw of each domain with the energy it
+// used this session.
+func (m model) sensorsPanel() panel {
+	p := panel{collector: collectorSensors, title: "Sensors"}
+	s := m.data.Sensors
+	if s == nil {
+		return p
+	}
+	for _, f := range s.Fans {
+		p.lines = append(p.lines, fmt.Sprintf("%s %9s RPM", fit(f.Name, 24), formatInt(f.RPM)))
+	}
+	for _, w := range s.Power {
+		p.lines = append(p.lines, fmt.Sprintf("%s %9s W  %s this session", fit(w.Name, 24), formatFloat(w.Watts, 1), formatWh(w.Joules)))
+	}
+	return p
+}
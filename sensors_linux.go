@@ -0,0 +1,114 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hwmonLabel names a hwmon sensor: its label file, else the channel, after the chip name,
+// e.g. "cpu_fan (nct6798)".
+func hwmonLabel(dir, channel string) string {
+	chip := readSysString(filepath.Join(dir, "name"))
+	label := readSysString(filepath.Join(dir, channel+"_label"))
+	if label == "" {
+		label = channel
+	}
+	if chip == "" {
+		return label
+	}
+	return label + " (" + chip + ")"
+}
+
+// readSysString reads a sysfs file holding one line, "" when it can't be read.
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readFans reads the fans hwmon reports. A speed of 0 is a header with no fan connected,
+// or a fanless machine, rather than a stalled fan, so it is left out.
+func readFans() []FanReading {
+	inputs, _ := filepath.Glob("/sys/class/hwmon/hwmon*/fan*_input")
+	var fans []FanReading
+	for _, input := range inputs {
+		rpm, ok := readSysUint(input)
+		if !ok || rpm == 0 {
+			continue
+		}
+		channel := strings.TrimSuffix(filepath.Base(input), "_input")
+		fans = append(fans, FanReading{Name: hwmonLabel(filepath.Dir(input), channel), RPM: rpm})
+	}
+	return fans
+}
+
+// readHwmonPower reads the power sensors hwmon reports, in microwatts, such as those of
+// server PSUs and GPUs.
+func readHwmonPower() []PowerReading {
+	var readings []PowerReading
+	for _, suffix := range []string{"_input", "_average"} {
+		inputs, _ := filepath.Glob("/sys/class/hwmon/hwmon*/power*" + suffix)
+		for _, input := range inputs {
+			channel := strings.TrimSuffix(filepath.Base(input), suffix)
+			// A chip may offer both files for a channel; the instant reading wins.
+			if suffix == "_average" {
+				if _, err := os.Stat(filepath.Join(filepath.Dir(input), channel+"_input")); err == nil {
+					continue
+				}
+			}
+			data := readSysString(input)
+			uw, err := strconv.ParseInt(data, 10, 64)
+			if err != nil {
+				continue
+			}
+			readings = append(readings, PowerReading{Name: hwmonLabel(filepath.Dir(input), channel), Watts: float64(uw) / 1e6})
+		}
+	}
+	return readings
+}
+
+// readRAPL reads the energy counters of the top-level RAPL domains, the CPU packages and on
+// some machines the DRAM or platform, which Intel and recent AMD CPUs expose through
+// powercap. Subdomains such as the cores are part of their package and not added again.
+// The counters are readable only by root on current kernels.
+func (c *sensorCollector) readRAPL(now time.Time) []PowerReading {
+	zones, _ := filepath.Glob("/sys/class/powercap/intel-rapl:[0-9]*")
+	var readings []PowerReading
+	for _, zone := range zones {
+		if strings.Count(filepath.Base(zone), ":") != 1 {
+			continue
+		}
+		uj, ok := readSysUint(filepath.Join(zone, "energy_uj"))
+		if !ok {
+			continue
+		}
+		name := readSysString(filepath.Join(zone, "name"))
+		if name == "" {
+			name = filepath.Base(zone)
+		}
+		prev, seen := c.rapl[zone]
+		c.rapl[zone] = raplSample{uj, now}
+		if !seen {
+			continue
+		}
+		used := float64(uj) - float64(prev.uj)
+		// The counter wraps at max_energy_range_uj.
+		if used < 0 {
+			wrap, _ := readSysUint(filepath.Join(zone, "max_energy_range_uj"))
+			used += float64(wrap)
+		}
+		elapsed := now.Sub(prev.at).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+		readings = append(readings, PowerReading{Name: "RAPL " + name, Watts: used / 1e6 / elapsed, Joules: used / 1e6})
+	}
+	return readings
+}
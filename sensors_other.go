@@ -0,0 +1,21 @@
+//go:build !linux
+
+package main
+
+import "time"
+
+// readFans is empty: fans are only read from Linux's hwmon.
+func readFans() []FanReading {
+	return nil
+}
+
+// readHwmonPower is empty: power sensors are only read from Linux's hwmon; the power
+// command works everywhere.
+func readHwmonPower() []PowerReading {
+	return nil
+}
+
+// readRAPL is empty: RAPL is only read from Linux's powercap.
+func (c *sensorCollector) readRAPL(now time.Time) []PowerReading {
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotServer keeps the latest snapshot for the serve subcommand's HTTP handlers.
+type snapshotServer struct {
+	// metrics picks the processes exported on /metrics.
+	metrics MetricsConfig
+
+	mu   sync.Mutex
+	snap Snapshot
+	at   time.Time
+	// series are the processes of snap exported on /metrics; dropped counts the series
+	// max_series left out since the start.
+	series  []procSeries
+	dropped uint64
+}
+
+// run collects a snapshot every interval until the program exits.
+func (s *snapshotServer) run(h *headless) {
+	for {
+		snap := h.collect()
+		series, dropped := s.metrics.processSeries(snap.Procs)
+		s.mu.Lock()
+		s.snap, s.at = snap, time.Now()
+		s.series = series
+		s.dropped += uint64(dropped)
+		s.mu.Unlock()
+		time.Sleep(h.interval)
+	}
+}
+
+func (s *snapshotServer) latest() (Snapshot, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snap, s.at
+}
+
+// serveSnapshot writes the latest snapshot in the same format as the json subcommand.
+func (s *snapshotServer) serveSnapshot(w http.ResponseWriter, _ *http.Request) {
+	snap, at := s.latest()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toJSON(snap, at))
+}
+
+// serveMetrics writes the host metrics in the Prometheus text exposition format.
+func (s *snapshotServer) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	snap, _ := s.latest()
+	var series []procSeries
+	var dropped uint64
+	if s.metrics.enabled() {
+		s.mu.Lock()
+		series, dropped = s.series, s.dropped
+		s.mu.Unlock()
+	}
+	var b strings.Builder
+	writeMetrics(&b, snap, s.metrics, series, dropped)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+// writeMetrics writes the host metrics of snap in the Prometheus text exposition format,
+// with the process series picked by metrics.
+func writeMetrics(b *strings.Builder, snap Snapshot, metrics MetricsConfig, series []procSeries, dropped uint64) {
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+	gauge("smtui_cpu_usage_percent", "CPU usage.", 100-snap.CPU.Idle)
+	gauge("smtui_memory_used_bytes", "Memory in use.", float64(snap.Mem.Used))
+	gauge("smtui_memory_total_bytes", "Total memory.", float64(snap.Mem.Total))
+	if snap.Load != nil {
+		gauge("smtui_load1", "1 minute load average.", snap.Load.Load1)
+		gauge("smtui_load5", "5 minute load average.", snap.Load.Load5)
+		gauge("smtui_load15", "15 minute load average.", snap.Load.Load15)
+	}
+	gauge("smtui_processes", "Running processes.", float64(len(snap.Procs)))
+	if metrics.enabled() {
+		writeProcessMetrics(b, series, metrics.ByName, dropped)
+	}
+	b.WriteString("# HELP smtui_tcp_sockets TCP sockets by state.\n# TYPE smtui_tcp_sockets gauge\n")
+	for _, state := range socketStates {
+		fmt.Fprintf(b, "smtui_tcp_sockets{state=%q} %d\n", state, snap.Sockets.States[state])
+	}
+	b.WriteString("# HELP smtui_disk_util_percent Share of time each disk was busy.\n# TYPE smtui_disk_util_percent gauge\n")
+	for _, d := range snap.Disks {
+		fmt.Fprintf(b, "smtui_disk_util_percent{device=%q} %g\n", d.Name, d.Util)
+	}
+}
+
+// runServe serves /snapshot (JSON) and /metrics (Prometheus) until interrupted.
+func runServe(args []string) int {
+	fs := newFlagSet("serve")
+	configSrc := configFlag(fs)
+	listen := fs.String("listen", ":9464", "address to listen on (localhost only unless a host is given)")
+	interval := fs.Duration("interval", 0, "time between collections (default: the configured interval)")
+	statsdAddr := statsdFlag(fs)
+	fs.Parse(args)
+
+	cfg := loadConfigOrExit(*configSrc)
+	h := newHeadless(cfg, intervalFlag(*interval, cfg))
+	if err := h.pushStatsd(*statsdAddr, cfg); err != nil {
+		log.Print(err)
+		return 2
+	}
+	s := &snapshotServer{metrics: cfg.Metrics}
+	h.prime()
+	go s.run(h)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/snapshot", s.serveSnapshot)
+	mux.HandleFunc("/metrics", s.serveMetrics)
+	log.Printf("Serving on http://%s/ (/snapshot, /metrics)", debugAddr(*listen))
+	if err := http.ListenAndServe(debugAddr(*listen), mux); err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
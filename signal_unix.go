@@ -0,0 +1,56 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// killSignals are the signals offered by the kill dialog; the first one is the default.
+var killSignals = []signalOption{
+	{"SIGTERM", syscall.SIGTERM},
+	{"SIGKILL", syscall.SIGKILL},
+	{"SIGINT", syscall.SIGINT},
+	{"SIGHUP", syscall.SIGHUP},
+	{"SIGSTOP", syscall.SIGSTOP},
+	{"SIGCONT", syscall.SIGCONT},
+}
+
+// reapSignal is sent to a parent that leaves zombie children unreaped, as a nudge to run
+// its SIGCHLD handler.
+var reapSignal = &signalOption{"SIGCHLD", syscall.SIGCHLD}
+
+// sendSignal delivers sig to the process pid.
+func sendSignal(pid int32, sig syscall.Signal) error {
+	return syscall.Kill(int(pid), sig)
+}
+
+// reniceSupported reports whether processes can be reniced on this platform.
+const reniceSupported = true
+
+// setNice sets the nice value of the process pid.
+func setNice(pid int32, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, int(pid), nice)
+}
+
+// isNoSuchProcess reports whether err means the process has already exited.
+func isNoSuchProcess(err error) bool {
+	return errors.Is(err, syscall.ESRCH)
+}
+
+// reloadSignals make the program re-read its config file.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
+
+// childSysProcAttr puts the command of the run subcommand in its own process group, out of
+// reach of the terminal's job control signals.
+func childSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interruptProcessGroup sends SIGINT to the process group led by pid, as ctrl+c in a shell
+// would.
+func interruptProcessGroup(pid int32) error {
+	return syscall.Kill(-int(pid), syscall.SIGINT)
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// killSignals are the signals offered by the kill dialog. Windows can only terminate
+// processes, so SIGKILL is the single option.
+var killSignals = []signalOption{
+	{"SIGKILL", syscall.SIGKILL},
+}
+
+// reapSignal is nil: Windows has neither zombie processes nor SIGCHLD.
+var reapSignal *signalOption
+
+// reniceSupported is false: Windows has priority classes rather than nice values.
+const reniceSupported = false
+
+// setNice exists so the actions menu builds everywhere; it is never used here.
+func setNice(pid int32, nice int) error {
+	return errors.ErrUnsupported
+}
+
+// sendSignal terminates the process pid; sig is always SIGKILL on Windows.
+func sendSignal(pid int32, sig syscall.Signal) error {
+	p, err := os.FindProcess(int(pid))
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+// isNoSuchProcess reports whether err means the process has already exited.
+func isNoSuchProcess(err error) bool {
+	return errors.Is(err, os.ErrProcessDone) || errors.Is(err, windows.ERROR_INVALID_PARAMETER)
+}
+
+// reloadSignals is empty: Windows has no SIGHUP, so the config is only reloaded with the r key.
+var reloadSignals []os.Signal
+
+// childSysProcAttr starts the command of the run subcommand in a new process group, so
+// the console's ctrl+c doesn't reach it directly.
+func childSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP}
+}
+
+// interruptProcessGroup terminates the process pid: Windows can't deliver ctrl+c to a
+// process of another console group.
+func interruptProcessGroup(pid int32) error {
+	return sendSignal(pid, syscall.SIGKILL)
+}
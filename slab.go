@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Slab figures from /proc/meminfo: the kernel's object caches, split into what it can free
+// under pressure, such as the dentry and inode caches, and what it cannot.
+var (
+	memSlab       = memField{"total", func(s MemStats) uint64 { return s.Slab }}
+	memSReclaim   = memField{"reclaim", func(s MemStats) uint64 { return s.Sreclaimable }}
+	memSUnreclaim = memField{"unreclaim", func(s MemStats) uint64 { return s.Sunreclaim }}
+)
+
+// slabFields returns the slab figures where the platform reports them, nil elsewhere.
+func slabFields(s MemStats) []memField {
+	if s.Slab == 0 {
+		return nil
+	}
+	return []memField{memSlab, memSReclaim, memSUnreclaim}
+}
+
+// SlabCache is one cache of /proc/slabinfo.
+type SlabCache struct {
+	Name string
+	// ActiveObjs of Objs objects are in use.
+	ActiveObjs, Objs uint64
+	// Size is the memory the cache's slabs take up.
+	Size uint64
+}
+
+// parseSlabinfo parses /proc/slabinfo. The first line names the format version; only 2.x,
+// the format of every kernel since 2.6, is understood. The "# name" header that follows
+// labels each column, so the columns are looked up by name rather than by position.
+// pageSize converts slabs to bytes.
+func parseSlabinfo(r io.Reader, pageSize uint64) ([]SlabCache, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("slabinfo is empty")
+	}
+	version, ok := strings.CutPrefix(scanner.Text(), "slabinfo - version: ")
+	if !ok || !strings.HasPrefix(version, "2.") {
+		return nil, fmt.Errorf("unsupported slabinfo format %q", scanner.Text())
+	}
+
+	columns := map[string]int{}
+	var caches []SlabCache
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && fields[0] == "#" {
+			// The data lines carry the same ": tunables" and ": slabdata" separators as the
+			// header, so its tokens line up with theirs once the "#" is dropped.
+			for i, name := range fields[1:] {
+				columns[strings.Trim(name, "<>")] = i
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		value := func(column string) (uint64, bool) {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return 0, false
+			}
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			return v, err == nil
+		}
+		c := SlabCache{Name: fields[0]}
+		c.ActiveObjs, _ = value("active_objs")
+		var objSize uint64
+		if c.Objs, ok = value("num_objs"); !ok {
+			return nil, fmt.Errorf("slabinfo line %q has no object count", scanner.Text())
+		}
+		objSize, _ = value("objsize")
+		c.Size = c.Objs * objSize
+		// Whole slabs are what the cache holds, partly empty ones included.
+		if slabs, ok := value("num_slabs"); ok {
+			if pages, ok := value("pagesperslab"); ok {
+				c.Size = slabs * pages * pageSize
+			}
+		}
+		caches = append(caches, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(caches, func(i, j int) bool { return caches[i].Size > caches[j].Size })
+	return caches, nil
+}
+
+// maxSlabLines bounds how many caches the slab panel lists.
+const maxSlabLines = 8
+
+// slabPanel lists the largest slab caches. A cache that keeps growing, such as dentry after
+// a find over a huge tree or one of a leaking module, is memory no process accounts for.
+func (m model) slabPanel() panel {
+	p := panel{collector: collectorSlab, title: "Slab caches"}
+	if len(m.data.Slabs) == 0 {
+		return p
+	}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	p.lines = append(p.lines, hint(fmt.Sprintf("%-20s %10s %15s", "cache", "size", "objects")))
+	for _, c := range m.data.Slabs[:min(len(m.data.Slabs), maxSlabLines)] {
+		p.lines = append(p.lines, fmt.Sprintf("%s %10s %15s", fit(c.Name, 20), formatBytes(c.Size),
+			formatCount(c.ActiveObjs)+"/"+formatCount(c.Objs)))
+	}
+	return p
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package main
+
+import "os"
+
+// slabSupported reports whether the slab caches are read on this platform.
+const slabSupported = true
+
+// GetSlabCaches reads /proc/slabinfo, largest cache first. The file is readable by root
+// only, so the collector fails the startup probe without it.
+func GetSlabCaches() ([]SlabCache, error) {
+	f, err := os.Open("/proc/slabinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseSlabinfo(f, uint64(os.Getpagesize()))
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// slabSupported reports whether the slab caches are read on this platform; only Linux
+// has /proc/slabinfo.
+const slabSupported = false
+
+// GetSlabCaches exists so the collector list builds everywhere; it is never used here.
+func GetSlabCaches() ([]SlabCache, error) {
+	return nil, errors.New("slab caches are only read on Linux")
+}
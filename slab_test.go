@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSlabinfo(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "slabinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	caches, err := parseSlabinfo(f, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Largest first, sized by whole slabs rather than by the objects in them.
+	want := []SlabCache{
+		{"dentry", 512340, 513870, 24470 * 4096},
+		{"ext4_inode_cache", 41250, 41412, 1534 * 8 * 4096},
+		{"radix_tree_node", 20433, 20720, 740 * 4 * 4096},
+		{"kmalloc-64", 30012, 30208, 472 * 4096},
+		{"buffer_head", 10024, 10296, 264 * 4096},
+		{"kmalloc-8k", 0, 0, 0},
+	}
+	if len(caches) != len(want) {
+		t.Fatalf("%d caches, want %d", len(caches), len(want))
+	}
+	for i := range want {
+		if caches[i] != want[i] {
+			t.Errorf("cache %d = %+v, want %+v", i, caches[i], want[i])
+		}
+	}
+}
+
+func TestParseSlabinfoErrors(t *testing.T) {
+	tests := []struct {
+		name, in string
+	}{
+		{"empty", ""},
+		{"version 1", "slabinfo - version: 1.1\nkmem_cache 60 78 100 1\n"},
+		{"no object count", "slabinfo - version: 2.1\n# name <active_objs>\ndentry 100\n"},
+	}
+	for _, tt := range tests {
+		if _, err := parseSlabinfo(strings.NewReader(tt.in), 4096); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+// TestParseSlabinfoWithoutSlabdata sizes caches by their objects when the slabdata
+// columns are missing.
+func TestParseSlabinfoWithoutSlabdata(t *testing.T) {
+	in := "slabinfo - version: 2.0\n# name <active_objs> <num_objs> <objsize>\ndentry 90 100 192\n"
+	caches, err := parseSlabinfo(strings.NewReader(in), 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caches) != 1 || caches[0].Size != 100*192 {
+		t.Errorf("caches = %+v, want dentry at %d bytes", caches, 100*192)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// memoryMap is the memory of the process in the detail view. With rollup the numbers come
+// from /proc/<pid>/smaps_rollup; otherwise only rss and vms are known, from the basic
+// memory info every platform has.
+type memoryMap struct {
+	rollup bool
+	rss    uint64
+	// pss is rss with each shared page divided among the processes mapping it, so the
+	// PSS of all processes adds up to the memory in use where their RSS overcounts.
+	pss uint64
+	// anon is the resident anonymous memory, the heap and stacks; the rest of rss is file
+	// pages, such as the executable, libraries and mapped files. shared is the part of rss
+	// that other processes map too.
+	anon, shared uint64
+	swap         uint64
+	vms          uint64
+}
+
+// detailMemory reads the memory of pid for the detail view. Walking the mappings for
+// smaps_rollup takes the kernel a while for a large process, so it is only read for the
+// process shown. When it can't be read, the basic numbers are returned with the reason.
+func detailMemory(pid int32) (memoryMap, error) {
+	mm, err := readSmapsRollup(pid)
+	if err == nil {
+		return mm, nil
+	}
+	proc, perr := process.NewProcess(pid)
+	if perr != nil {
+		return memoryMap{}, err
+	}
+	info, perr := proc.MemoryInfo()
+	if perr != nil {
+		return memoryMap{}, err
+	}
+	return memoryMap{rss: info.RSS, vms: info.VMS}, err
+}
+
+// memoryLines renders the memory of the detail process, with key for the labels and
+// hint for the explanations.
+func (m model) memoryLines(key, hint func(...string) string) []string {
+	mm, err := m.detailMem, m.detailMemErr
+	if !mm.rollup {
+		if mm.rss == 0 && err != nil {
+			return []string{key("Memory map:") + hint(err.Error())}
+		}
+		lines := []string{key("RSS:") + formatBytes(mm.rss) + hint(" (virtual "+formatBytes(mm.vms)+")")}
+		if err != nil {
+			lines = append(lines, key("PSS:")+hint("unavailable: "+err.Error()))
+		}
+		return lines
+	}
+	return []string{
+		key("RSS:") + formatBytes(mm.rss) + hint(fmt.Sprintf(" (anon %s · file %s · %s shared with other processes)",
+			formatBytes(mm.anon), formatBytes(mm.rss-min(mm.anon, mm.rss)), formatBytes(mm.shared))),
+		key("PSS:") + formatBytes(mm.pss) + hint(" (RSS with shared pages split among the processes mapping them)"),
+		key("Swap:") + formatBytes(mm.swap),
+	}
+}
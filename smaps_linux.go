@@ -0,0 +1,57 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// smapsSupported reports whether the memory of the detail process is broken down on this
+// platform.
+const smapsSupported = true
+
+// errNoMappings is returned for kernel threads, which have no memory of their own.
+var errNoMappings = errors.New("no memory mappings")
+
+// readSmapsRollup reads /proc/<pid>/smaps_rollup, the sums of /proc/<pid>/smaps over
+// every mapping, available since Linux 4.14. Other users' processes need root.
+func readSmapsRollup(pid int32) (memoryMap, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/smaps_rollup", pid))
+	if errors.Is(err, syscall.ESRCH) {
+		// Reading the maps of a kernel thread fails as if it didn't exist.
+		return memoryMap{}, errNoMappings
+	}
+	if err != nil {
+		return memoryMap{}, err
+	}
+	mm := memoryMap{rollup: true}
+	fields := map[string]*uint64{"Rss": &mm.rss, "Pss": &mm.pss, "Anonymous": &mm.anon, "Swap": &mm.swap}
+	var sharedClean, sharedDirty uint64
+	fields["Shared_Clean"], fields["Shared_Dirty"] = &sharedClean, &sharedDirty
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// "Rss:    1392 kB"; the first line is the address range of the rollup.
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		dst := fields[name]
+		if !ok || dst == nil {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			return memoryMap{}, fmt.Errorf("invalid smaps_rollup line %q", scanner.Text())
+		}
+		*dst = kb * 1024
+	}
+	if mm.rss == 0 && mm.pss == 0 {
+		return memoryMap{}, errNoMappings
+	}
+	mm.shared = sharedClean + sharedDirty
+	return mm, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// smapsSupported reports whether the memory of the detail process is broken down on this
+// platform.
+const smapsSupported = false
+
+func readSmapsRollup(pid int32) (memoryMap, error) {
+	return memoryMap{}, errors.New("smaps_rollup is only read on Linux")
+}
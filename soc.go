@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bits of the status vcgencmd get_throttled reports on a Raspberry Pi. The low bits are
+// the current state, the same bits shifted by 16 whether it happened since boot.
+const (
+	socUndervoltage = 1 << 0
+	socFreqCapped   = 1 << 1
+	socThrottled    = 1 << 2
+	socSoftTemp     = 1 << 3
+	socSinceBoot    = 16
+)
+
+// socTempWarn is the SoC temperature at which a Raspberry Pi starts to cap its clock, the
+// soft limit of its firmware; the panel turns it red from there.
+const socTempWarn = 80
+
+// SoCStatus is the temperature and power state of an ARM system-on-chip board.
+type SoCStatus struct {
+	// Model is the board from the device tree, e.g. "Raspberry Pi 4 Model B Rev 1.4".
+	Model string
+	// TempC is the SoC temperature, from thermal zone 0; TempKnown is false without one.
+	TempC     float64
+	TempKnown bool
+	// Flags is the vcgencmd get_throttled status; FlagsKnown is false without vcgencmd.
+	Flags      uint32
+	FlagsKnown bool
+}
+
+// undervoltage reports whether the supply is too weak now or was since boot. Under-voltage
+// makes the firmware throttle without a word, quietly skewing any benchmark run meanwhile.
+func (s SoCStatus) undervoltage() bool {
+	return s.FlagsKnown && s.Flags&(socUndervoltage|socUndervoltage<<socSinceBoot) != 0
+}
+
+// parseThrottled parses the output of vcgencmd get_throttled, e.g. "throttled=0x50005".
+func parseThrottled(out string) (uint32, error) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(out), "throttled=")
+	if !ok {
+		return 0, fmt.Errorf("unexpected vcgencmd output %q", out)
+	}
+	flags, err := strconv.ParseUint(value, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected vcgencmd status %q: %w", value, err)
+	}
+	return uint32(flags), nil
+}
+
+// socConditions names the vcgencmd status bits, in the order the panel lists them.
+var socConditions = []struct {
+	bit  uint32
+	name string
+}{
+	{socUndervoltage, "undervoltage"},
+	{socFreqCapped, "frequency capped"},
+	{socThrottled, "throttled"},
+	{socSoftTemp, "soft temperature limit"},
+}
+
+// socPanel shows the SoC temperature and every condition vcgencmd reports, now or since
+// boot.
+func (m model) socPanel() panel {
+	p := panel{collector: collectorSoC, title: "SoC"}
+	s := m.data.SoC
+	if s == nil {
+		return p
+	}
+	if s.Model != "" {
+		p.title = "SoC · " + s.Model
+	}
+	red := m.baseStyle.Foreground(Color.Red).Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	if s.TempKnown {
+		temp := formatFloat(s.TempC, 1) + " °C"
+		if s.TempC >= socTempWarn {
+			temp = red(temp)
+		}
+		p.lines = append(p.lines, fmt.Sprintf("%-24s %s", "temperature", temp))
+	}
+	if !s.FlagsKnown {
+		return p
+	}
+	for _, c := range socConditions {
+		switch {
+		case s.Flags&c.bit != 0:
+			p.lines = append(p.lines, fmt.Sprintf("%-24s %s", c.name, red("now")))
+		case s.Flags&(c.bit<<socSinceBoot) != 0:
+			p.lines = append(p.lines, fmt.Sprintf("%-24s %s", c.name, "since boot"))
+		}
+	}
+	if s.Flags == 0 {
+		p.lines = append(p.lines, hint("no undervoltage or throttling since boot"))
+	}
+	return p
+}
+
+// viewUndervoltageBadge renders the UNDERVOLTAGE badge next to the CPU title while the
+// board reports under-voltage now or since boot, "" otherwise.
+func (m model) viewUndervoltageBadge() string {
+	s := m.data.SoC
+	if s == nil || !s.undervoltage() {
+		return ""
+	}
+	badge := " UNDERVOLTAGE DETECTED"
+	if s.Flags&socUndervoltage == 0 {
+		badge += " (since boot)"
+	}
+	return m.baseStyle.Foreground(Color.Red).Bold(true).Render(badge)
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// socSupported reports whether SoC boards are detected on this platform.
+const socSupported = true
+
+// vcgencmdTimeout bounds one run of vcgencmd, which waits on the VideoCore firmware.
+const vcgencmdTimeout = time.Second
+
+// socCollector reads the state of an ARM SoC board. The board is recognised by its device
+// tree and vcgencmd looked up once, at the startup probe; PCs have no device tree, which
+// fails the collector there.
+type socCollector struct {
+	model    string
+	vcgencmd string
+	detected bool
+}
+
+func newSoCCollector() *socCollector {
+	return &socCollector{}
+}
+
+// GetSoC reads the SoC temperature and, with vcgencmd, the throttled status.
+func (c *socCollector) GetSoC() (*SoCStatus, error) {
+	if !c.detected {
+		data, err := os.ReadFile("/sys/firmware/devicetree/base/model")
+		if err != nil {
+			return nil, fmt.Errorf("no device tree: %w", fs.ErrNotExist)
+		}
+		// The device tree property is NUL-terminated.
+		c.model = strings.TrimRight(string(data), "\x00\n")
+		c.vcgencmd, _ = exec.LookPath("vcgencmd")
+		c.detected = true
+	}
+	s := &SoCStatus{Model: c.model}
+	if data, err := os.ReadFile("/sys/class/thermal/thermal_zone0/temp"); err == nil {
+		if milli, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			s.TempC, s.TempKnown = float64(milli)/1000, true
+		}
+	}
+	if c.vcgencmd != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), vcgencmdTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, c.vcgencmd, "get_throttled").Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not run vcgencmd: %w", err)
+		}
+		if s.Flags, err = parseThrottled(string(out)); err != nil {
+			return nil, err
+		}
+		s.FlagsKnown = true
+	}
+	if !s.TempKnown && !s.FlagsKnown {
+		return nil, fmt.Errorf("no SoC temperature or vcgencmd: %w", fs.ErrNotExist)
+	}
+	return s, nil
+}
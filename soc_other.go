@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// socSupported reports whether SoC boards are detected on this platform; the Raspberry Pi
+// and its kin run Linux.
+const socSupported = false
+
+// socCollector exists so the collector list builds everywhere; it is never used here.
+type socCollector struct{}
+
+func newSoCCollector() *socCollector {
+	return &socCollector{}
+}
+
+func (c *socCollector) GetSoC() (*SoCStatus, error) {
+	return nil, errors.New("SoC boards are only detected on Linux")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestSoCThrottled decodes the output vcgencmd get_throttled gave on boards in each state
+// into the lines of the SoC panel.
+func TestSoCThrottled(t *testing.T) {
+	tests := []struct {
+		file         string
+		flags        uint32
+		undervoltage bool
+		lines        []string
+	}{
+		{"get_throttled_ok", 0, false, []string{"no undervoltage or throttling since boot"}},
+		{"get_throttled_undervoltage", 0x50005, true, []string{"undervoltage now", "throttled now"}},
+		{"get_throttled_since_boot", 0x50000, true, []string{"undervoltage since boot", "throttled since boot"}},
+		{"get_throttled_soft_temp", 0xe0008, false, []string{"frequency capped since boot", "throttled since boot", "soft temperature limit now"}},
+	}
+	for _, tt := range tests {
+		out, err := os.ReadFile(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		flags, err := parseThrottled(string(out))
+		if err != nil || flags != tt.flags {
+			t.Errorf("%s: parseThrottled = %#x, %v, want %#x", tt.file, flags, err, tt.flags)
+			continue
+		}
+		s := &SoCStatus{Flags: flags, FlagsKnown: true}
+		if s.undervoltage() != tt.undervoltage {
+			t.Errorf("%s: undervoltage = %v, want %v", tt.file, s.undervoltage(), tt.undervoltage)
+		}
+		m := newModel(defaultConfig())
+		m.data.SoC = s
+		var lines []string
+		for _, line := range m.socPanel().lines {
+			lines = append(lines, strings.Join(strings.Fields(ansi.Strip(line)), " "))
+		}
+		if !slices.Equal(lines, tt.lines) {
+			t.Errorf("%s: panel lines = %q, want %q", tt.file, lines, tt.lines)
+		}
+	}
+}
+
+func TestParseThrottledErrors(t *testing.T) {
+	for _, out := range []string{"", "0x50005", "throttled=", "throttled=0xzz", "throttled=0x100000000", "error=1 error_msg=\"Command not registered\""} {
+		if flags, err := parseThrottled(out); err == nil {
+			t.Errorf("parseThrottled(%q) = %#x, want an error", out, flags)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// socketStates are the TCP states counted by the sockets panel, in display order.
+var socketStates = []string{"ESTABLISHED", "TIME_WAIT", "CLOSE_WAIT", "SYN_RECV", "LISTEN"}
+
+// SocketStats holds the TCP sockets of one collection pass. Conns is kept for views that
+// list individual connections, so sockets are only enumerated once per tick.
+type SocketStats struct {
+	Conns []net.ConnectionStat
+	// States counts the sockets by TCP state name.
+	States map[string]int
+}
+
+// GetSocketStats lists the TCP sockets and counts them by state in the same pass.
+func GetSocketStats() (SocketStats, error) {
+	conns, err := net.Connections("tcp")
+	if err != nil {
+		return SocketStats{}, err
+	}
+	states := make(map[string]int, len(socketStates))
+	for _, c := range conns {
+		states[c.Status]++
+	}
+	return SocketStats{Conns: conns, States: states}, nil
+}
+
+// trendWidth is the number of cells in the TIME_WAIT sparkline.
+const trendWidth = 24
+
+// socketsPanel shows the TCP state counts and a TIME_WAIT trend over the graph window.
+// CLOSE_WAIT is highlighted past the configured threshold, since a steadily growing count
+// means an application is not closing its sockets.
+func (m model) socketsPanel() panel {
+	p := panel{collector: collectorSockets, title: "TCP sockets"}
+	if m.data.Sockets.States == nil {
+		return p
+	}
+	for _, state := range socketStates {
+		n := m.data.Sockets.States[state]
+		line := fmt.Sprintf("%-12s %7s", state, formatInt(n))
+		if state == "CLOSE_WAIT" && m.cfg.Sockets.CloseWaitWarn > 0 && n > m.cfg.Sockets.CloseWaitWarn {
+			line = m.baseStyle.Foreground(Color.Red).Render(line)
+		}
+		p.lines = append(p.lines, line)
+	}
+
+	now := time.Now()
+	from, to := m.windowBounds(now)
+	cells := bucketize(m.timeWaitHistory.samples(m.historyWindow, now), from, to, trendWidth)
+	// Scale to the busiest cell in view; unlike the percentage graphs there is no fixed maximum.
+	hi := 0.0
+	for _, v := range cells {
+		if !math.IsNaN(v) {
+			hi = max(hi, v)
+		}
+	}
+	p.lines = append(p.lines, m.baseStyle.Foreground(Color.Secondary).Render("TIME_WAIT "+m.historyWindow.String()),
+		sparkline(cells, hi, -1, m.baseStyle))
+	return p
+}
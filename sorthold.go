@@ -0,0 +1,67 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkSortHold validates sort_hold: 0 turns the hold off, and a hold longer than a
+// minute would leave the table unsorted for good.
+func checkSortHold(d time.Duration) error {
+	if d < 0 || d > time.Minute {
+		return fmt.Errorf("must be between 0s and 1m, got %s", d)
+	}
+	return nil
+}
+
+// sortHoldMsg is sent when a sort hold may have run out, so the table re-sorts without
+// waiting for the next tick.
+type sortHoldMsg struct{}
+
+// holdSort keeps the process table in its current order for sort_hold after a navigation
+// key, so a refresh can't move a different process under the cursor just before enter or
+// kill. The rows still update; only their order stays.
+func (m *model) holdSort() tea.Cmd {
+	if m.cfg.SortHold <= 0 {
+		return nil
+	}
+	m.sortHeldUntil = time.Now().Add(m.cfg.SortHold)
+	return tea.Tick(m.cfg.SortHold, func(time.Time) tea.Msg { return sortHoldMsg{} })
+}
+
+// sortHeld reports whether the process table keeps its order.
+func (m model) sortHeld() bool {
+	return time.Now().Before(m.sortHeldUntil)
+}
+
+// keepOrder reorders procs, already sorted, into the order they were last shown in, which
+// shown maps by PID. Processes that weren't shown follow in their sorted order, so a new
+// process never pushes the rows above the cursor down.
+func keepOrder(procs []ProcessInfo, shown map[int32]int) {
+	slices.SortStableFunc(procs, func(a, b ProcessInfo) int {
+		ia, okA := shown[a.PID]
+		ib, okB := shown[b.PID]
+		switch {
+		case okA && okB:
+			return cmp.Compare(ia, ib)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return 0
+	})
+}
+
+// shownOrder maps the PIDs of procs to their position, for keepOrder.
+func shownOrder(procs []ProcessInfo) map[int32]int {
+	order := make(map[int32]int, len(procs))
+	for i, p := range procs {
+		order[p.PID] = i
+	}
+	return order
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// uiState is what the program remembers between runs, as opposed to the user-edited config.
+type uiState struct {
+	// ColumnWidths are the widths set by hand, keyed by column ID. They replace auto-fit.
+	ColumnWidths map[string]int `json:"column_widths,omitempty"`
+	// UpperHeight is the height of the region above the main table after the divider was
+	// moved; zero leaves it at its natural height.
+	UpperHeight int `json:"upper_height,omitempty"`
+	// Views are the views saved with V.
+	Views []SavedView `json:"views,omitempty"`
+	// StartTimes is whether the Time column shows start times, toggled with t.
+	StartTimes bool `json:"start_times,omitempty"`
+}
+
+// defaultStatePath returns the state file location: $XDG_STATE_HOME (or ~/.local/state) on
+// Unix systems, the user config directory elsewhere.
+func defaultStatePath() string {
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+			return filepath.Join(dir, appName, "state.json")
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "state", appName, "state.json")
+		}
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, appName, "state.json")
+}
+
+// loadUIState reads the state file at path. A missing file yields the empty state.
+func loadUIState(path string) (uiState, error) {
+	state := uiState{ColumnWidths: map[string]int{}}
+	if path == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+		return state, fmt.Errorf("could not read state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return uiState{ColumnWidths: map[string]int{}}, fmt.Errorf("could not read state %s: %w", path, err)
+	}
+	if state.ColumnWidths == nil {
+		state.ColumnWidths = map[string]int{}
+	}
+	return state, nil
+}
+
+// saveStateCmd writes the current UI state to the state file in the background.
+func (m *model) saveStateCmd() tea.Cmd {
+	if m.statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.state, "", "  ")
+	if err != nil {
+		m.reportError(fmt.Errorf("could not save state: %w", err))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0o755); err != nil {
+		m.reportError(fmt.Errorf("could not save state: %w", err))
+		return nil
+	}
+	return m.writeFileCmd(m.statePath, append(data, '\n'), "")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionTopN is how many processes the session statistics rank by CPU time.
+const sessionTopN = 5
+
+// procKey identifies a process across PID reuse.
+type procKey struct {
+	pid        int32
+	createTime int64
+}
+
+// procCPU is the CPU time a process used while the monitor watched it: last minus base,
+// where base is its CPU time when first seen, or zero if it started during the session.
+type procCPU struct {
+	name       string
+	base, last time.Duration
+}
+
+func (p procCPU) used() time.Duration { return p.last - p.base }
+
+// sessionStats accumulates what the session statistics screen shows beyond the graph
+// series: the first, latest and fullest usage of each filesystem, the network counters and
+// the CPU time of each process. Only the top exited processes are kept, so it stays bounded.
+type sessionStats struct {
+	fsFirst, fsLast map[string]Filesystem
+	// fsPeak is the highest usage percentage seen on each filesystem.
+	fsPeak   map[string]float64
+	netFirst NetTotals
+	netLast  NetTotals
+	netSeen  bool
+	procs    map[procKey]procCPU
+}
+
+func newSessionStats() *sessionStats {
+	return &sessionStats{fsFirst: map[string]Filesystem{}, fsLast: map[string]Filesystem{}, fsPeak: map[string]float64{}, procs: map[procKey]procCPU{}}
+}
+
+// observe folds the parts of snap whose collectors succeeded into the statistics.
+func (s *sessionStats) observe(snap Snapshot, ok []string, started time.Time) {
+	if slices.Contains(ok, collectorFS) {
+		for _, fs := range snap.Filesystems {
+			if _, seen := s.fsFirst[fs.Mountpoint]; !seen {
+				s.fsFirst[fs.Mountpoint] = fs
+			}
+			s.fsLast[fs.Mountpoint] = fs
+			if fs.Total > 0 {
+				s.fsPeak[fs.Mountpoint] = max(s.fsPeak[fs.Mountpoint], float64(fs.Used)/float64(fs.Total)*100)
+			}
+		}
+	}
+	if slices.Contains(ok, collectorNet) {
+		if !s.netSeen {
+			s.netFirst, s.netSeen = snap.Net, true
+		}
+		s.netLast = snap.Net
+	}
+	if slices.Contains(ok, collectorProcesses) {
+		live := make(map[procKey]bool, len(snap.Procs))
+		for _, p := range snap.Procs {
+			key := procKey{p.PID, p.CreateTime}
+			live[key] = true
+			cpu, seen := s.procs[key]
+			if !seen {
+				cpu = procCPU{name: p.Name}
+				if !time.UnixMilli(p.CreateTime).After(started) {
+					cpu.base = p.CPUTime
+				}
+			}
+			cpu.last = max(p.CPUTime, cpu.base)
+			s.procs[key] = cpu
+		}
+		s.pruneExited(live)
+	}
+}
+
+// pruneExited forgets exited processes except those that could still rank in the top list.
+func (s *sessionStats) pruneExited(live map[procKey]bool) {
+	var exited []procKey
+	for key := range s.procs {
+		if !live[key] {
+			exited = append(exited, key)
+		}
+	}
+	if len(exited) <= sessionTopN {
+		return
+	}
+	sort.Slice(exited, func(i, j int) bool { return s.procs[exited[i]].used() > s.procs[exited[j]].used() })
+	for _, key := range exited[sessionTopN:] {
+		delete(s.procs, key)
+	}
+}
+
+// topProcs returns the processes that used the most CPU time during the session.
+func (s *sessionStats) topProcs(n int) []procKey {
+	keys := make([]procKey, 0, len(s.procs))
+	for key, cpu := range s.procs {
+		if cpu.used() > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := s.procs[keys[i]].used(), s.procs[keys[j]].used()
+		if a != b {
+			return a > b
+		}
+		return keys[i].pid < keys[j].pid
+	})
+	return keys[:min(n, len(keys))]
+}
+
+// signedBytes formats a change in size with an explicit sign.
+func signedBytes(before, after uint64) string {
+	if after >= before {
+		return "+" + formatBytes(after-before)
+	}
+	return "-" + formatBytes(before-after)
+}
+
+// sessionReport renders the session statistics as plain text, shared by the screen and
+// the export. The min/avg/max figures come from the same series the graphs draw.
+func (m model) sessionReport(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session statistics on %s since %s (%s)\n\n", hostname, m.started.Format(time.DateTime), formatAge(now.Sub(m.started)))
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tCURRENT\tMIN\tAVG\tMAX")
+	row := func(name string, s *series, format func(float64) string) {
+		last, ok := s.last()
+		if !ok {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\n", name)
+			return
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, format(last.value), format(s.min), format(s.avg()), format(s.max))
+	}
+	percent := func(v float64) string { return formatPercent(v, 1) }
+	row("CPU", m.cpuHistory, percent)
+	row("Memory", m.memHistory, percent)
+	row("Load 1m", m.loadHistory, func(v float64) string { return formatFloat(v, 2) })
+	w.Flush()
+
+	stats := m.sessionStats
+	if len(stats.fsLast) > 0 {
+		b.WriteString("\n")
+		w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILESYSTEM\tUSED\tSIZE\tGROWTH")
+		mounts := make([]string, 0, len(stats.fsLast))
+		for mount := range stats.fsLast {
+			mounts = append(mounts, mount)
+		}
+		sort.Strings(mounts)
+		for _, mount := range mounts {
+			fs := stats.fsLast[mount]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mount, formatBytes(fs.Used), formatBytes(fs.Total), signedBytes(stats.fsFirst[mount].Used, fs.Used))
+		}
+		w.Flush()
+	}
+
+	if stats.netSeen {
+		recv := stats.netLast.Recv - min(stats.netFirst.Recv, stats.netLast.Recv)
+		sent := stats.netLast.Sent - min(stats.netFirst.Sent, stats.netLast.Sent)
+		fmt.Fprintf(&b, "\nNetwork transferred: %s received, %s sent\n", formatBytes(recv), formatBytes(sent))
+	}
+
+	b.WriteString("\nTop processes by CPU time\n")
+	top := stats.topProcs(sessionTopN)
+	if len(top) == 0 {
+		b.WriteString("none yet\n")
+		return b.String()
+	}
+	w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tNAME\tCPU TIME")
+	for _, key := range top {
+		cpu := stats.procs[key]
+		fmt.Fprintf(w, "%d\t%s\t%s\n", key.pid, cpu.name, formatCPUTime(cpu.used()))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// quitSummaryTopN is how many processes the quit summary names.
+const quitSummaryTopN = 3
+
+// quitSummary is the brief session report printed after quitting with quit_summary, a few
+// lines from the same statistics as the session screen.
+func (m model) quitSummary(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s on %s: %s session\n", appName, hostname, formatAge(now.Sub(m.started)))
+	var usage []string
+	for _, s := range []struct {
+		name   string
+		series *series
+	}{{"CPU", m.cpuHistory}, {"memory", m.memHistory}} {
+		if _, ok := s.series.last(); ok {
+			usage = append(usage, fmt.Sprintf("%s avg %s, max %s", s.name, formatPercent(s.series.avg(), 1), formatPercent(s.series.max, 1)))
+		}
+	}
+	if _, ok := m.loadHistory.last(); ok {
+		usage = append(usage, "peak load "+formatFloat(m.loadHistory.max, 2))
+	}
+	if len(usage) > 0 {
+		fmt.Fprintf(&b, "%s\n", strings.Join(usage, " · "))
+	}
+
+	stats := m.sessionStats
+	fullest, peak := "", -1.0
+	for mount, percent := range stats.fsPeak {
+		if percent > peak || (percent == peak && mount < fullest) {
+			fullest, peak = mount, percent
+		}
+	}
+	if fullest != "" {
+		fmt.Fprintf(&b, "fullest disk: %s at %s\n", fullest, formatPercent(peak, 1))
+	}
+	if top := stats.topProcs(quitSummaryTopN); len(top) > 0 {
+		names := make([]string, len(top))
+		for i, key := range top {
+			cpu := stats.procs[key]
+			names[i] = fmt.Sprintf("%s (%d) %s", cpu.name, key.pid, formatCPUTime(cpu.used()))
+		}
+		fmt.Fprintf(&b, "top CPU: %s\n", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+// viewStats renders the session statistics screen.
+func (m model) viewStats() string {
+	return m.viewStyle.Render(strings.TrimRight(m.sessionReport(time.Now()), "\n"))
+}
+
+// exportStatsCmd writes the session statistics to a timestamped text file in the working
+// directory, in the background like every other file write.
+func (m model) exportStatsCmd() tea.Cmd {
+	now := time.Now()
+	path := fmt.Sprintf("%s-session-%s.txt", appName, now.Format("20060102-150405"))
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return m.writeFileCmd(path, []byte(m.sessionReport(now)), "session statistics")
+}
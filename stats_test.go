@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// sessionTick is one pass of the fake session TestQuitSummary runs.
+type sessionTick struct {
+	idle, mem, load1 float64
+	root, data       uint64 // percent used of / and /data
+	procs            map[int32]time.Duration
+}
+
+func TestQuitSummary(t *testing.T) {
+	oldHostname := hostname
+	hostname = "db1"
+	t.Cleanup(func() { hostname = oldHostname })
+
+	start := time.Now()
+	m := newModel(defaultConfig())
+	m.started = start
+	names := map[int32]string{10: "postgres", 20: "nginx", 30: "make", 40: "bash"}
+	// make starts during the session, so all of its CPU time counts; the others only count
+	// from when they were first seen.
+	created := map[int32]time.Time{10: start.Add(-time.Hour), 20: start.Add(-time.Hour), 30: start.Add(90 * time.Second), 40: start.Add(-time.Minute)}
+	ticks := []sessionTick{
+		{idle: 80, mem: 50, load1: 1, root: 50, data: 80, procs: map[int32]time.Duration{10: 100 * time.Second, 20: 10 * time.Second, 40: 5 * time.Second}},
+		{idle: 60, mem: 55, load1: 2.5, root: 60, data: 90, procs: map[int32]time.Duration{10: 120 * time.Second, 20: 20 * time.Second, 30: 5 * time.Second, 40: 6 * time.Second}},
+		{idle: 90, mem: 60, load1: 2, root: 65, data: 88, procs: map[int32]time.Duration{10: 140 * time.Second, 20: 30 * time.Second, 30: 30 * time.Second}},
+		{idle: 70, mem: 45, load1: 0.5, root: 70, data: 85, procs: map[int32]time.Duration{10: 160 * time.Second, 20: 40 * time.Second, 30: 45 * time.Second}},
+	}
+	for i, tk := range ticks {
+		snap := Snapshot{Load: &load.AvgStat{Load1: tk.load1}}
+		snap.CPU.Idle = tk.idle
+		snap.Mem.UsedPercent = tk.mem
+		snap.Filesystems = []Filesystem{{Mountpoint: "/", Used: tk.root, Total: 100}, {Mountpoint: "/data", Used: tk.data, Total: 100}}
+		for pid, cpu := range tk.procs {
+			snap.Procs = append(snap.Procs, ProcessInfo{PID: pid, Name: names[pid], CPUTime: cpu, CreateTime: created[pid].UnixMilli()})
+		}
+		at := start.Add(time.Duration(i+1) * time.Minute)
+		m = send(m, collectedMsg{snap: snap, at: at, ok: []string{collectorCPU, collectorMem, collectorLoad, collectorFS, collectorProcesses}})
+	}
+
+	want := appName + ` on db1: 5m session
+CPU avg 25.0%, max 40.0% · memory avg 52.5%, max 60.0% · peak load 2.50
+fullest disk: /data at 90.0%
+top CPU: postgres (10) 1:00.00, make (30) 0:45.00, nginx (20) 0:30.00
+`
+	if got := m.quitSummary(start.Add(5 * time.Minute)); got != want {
+		t.Errorf("quitSummary =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestQuitSummaryEmpty is the summary of a session quit before the first sample.
+func TestQuitSummaryEmpty(t *testing.T) {
+	m := newModel(defaultConfig())
+	if got, want := m.quitSummary(m.started.Add(3*time.Second)), appName+" on "+hostname+": 3s session\n"; got != want {
+		t.Errorf("quitSummary = %q, want %q", got, want)
+	}
+}
+
+func TestExportStats(t *testing.T) {
+	dir := inTempDir(t)
+	m := newModel(defaultConfig())
+	m, msg := runWrite(t, m, m.exportStatsCmd())
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if filepath.Dir(msg.path) != dir || !strings.HasPrefix(filepath.Base(msg.path), appName+"-session-") {
+		t.Errorf("exported to %s, want a session file in %s", msg.path, dir)
+	}
+	if data, err := os.ReadFile(msg.path); err != nil || !strings.Contains(string(data), "Session statistics") {
+		t.Errorf("export = %q, %v", data, err)
+	}
+	if want := "session statistics saved to " + msg.path; m.banner != want || m.bannerIsErr {
+		t.Errorf("banner = %q, want %q", m.banner, want)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StatsdConfig is the [statsd] section of the config file, used with -statsd.
+type StatsdConfig struct {
+	// Prefix starts the name of every metric, e.g. "smtui.cpu.usage_percent".
+	Prefix string `toml:"prefix"`
+	// Tags are attached to every metric in the DogStatsD format, e.g. env = "prod";
+	// plain StatsD servers don't understand tags, so leave it empty for them.
+	Tags map[string]string `toml:"tags"`
+}
+
+// tagSuffix returns the DogStatsD tag suffix of every line, "" without tags. The tags are
+// sorted so the suffix, and the restart check comparing it, don't depend on map order.
+func (c StatsdConfig) tagSuffix() string {
+	if len(c.Tags) == 0 {
+		return ""
+	}
+	var tags []string
+	for _, k := range slices.Sorted(maps.Keys(c.Tags)) {
+		tags = append(tags, k+":"+c.Tags[k])
+	}
+	return "|#" + strings.Join(tags, ",")
+}
+
+// checkStatsd validates the [statsd] section: the characters StatsD uses as separators
+// may not appear in the prefix or the tags.
+func checkStatsd(c StatsdConfig) error {
+	if strings.ContainsAny(c.Prefix, ":|@#, \n") {
+		return fmt.Errorf("prefix must not contain ':', '|', '@', '#', ',' or spaces, got %q", c.Prefix)
+	}
+	for k, v := range c.Tags {
+		if k == "" || strings.ContainsAny(k, ":|@#, \n") {
+			return fmt.Errorf("tag name must be non-empty without ':', '|', '@', '#', ',' or spaces, got %q", k)
+		}
+		if strings.ContainsAny(v, "|@#,\n") {
+			return fmt.Errorf("tag %s must not contain '|', '@', '#', ',' or newlines, got %q", k, v)
+		}
+	}
+	return nil
+}
+
+// statsdPacketSize keeps each datagram under the usual Ethernet MTU, so no packet is
+// fragmented or dropped on the way to the agent.
+const statsdPacketSize = 1432
+
+// statsdWriteTimeout bounds each send, so a full socket buffer never stalls a collection
+// pass.
+const statsdWriteTimeout = 100 * time.Millisecond
+
+// statsdClient pushes the core metrics of every snapshot as StatsD gauges over UDP. A nil
+// client sends nothing. Send failures are only counted: the agent being down must never
+// affect the monitor.
+type statsdClient struct {
+	conn   net.Conn
+	prefix string
+	tags   string
+	// sent and failed count the packets written and the ones that could not be; they are
+	// served with the self-metrics.
+	sent, failed atomic.Uint64
+}
+
+// newStatsdClient opens the UDP socket to addr, a host:port. UDP has no handshake, so
+// only a malformed or unresolvable address fails here.
+func newStatsdClient(addr string, cfg StatsdConfig) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+	prefix := cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &statsdClient{conn: conn, prefix: prefix, tags: cfg.tagSuffix()}, nil
+}
+
+// statsdLines formats the gauges of one snapshot, one "name:value|g" line each.
+func statsdLines(s Snapshot, prefix, tags string) []string {
+	var lines []string
+	gauge := func(name string, value float64) {
+		lines = append(lines, prefix+name+":"+strconv.FormatFloat(value, 'f', -1, 64)+"|g"+tags)
+	}
+	gauge("cpu.usage_percent", 100-s.CPU.Idle)
+	gauge("memory.used_bytes", float64(s.Mem.Used))
+	gauge("memory.total_bytes", float64(s.Mem.Total))
+	gauge("memory.used_percent", s.Mem.UsedPercent)
+	gauge("swap.used_bytes", float64(s.Mem.SwapTotal-s.Mem.SwapFree))
+	gauge("swap.total_bytes", float64(s.Mem.SwapTotal))
+	if s.Load != nil {
+		gauge("load.1", s.Load.Load1)
+		gauge("load.5", s.Load.Load5)
+		gauge("load.15", s.Load.Load15)
+	}
+	gauge("processes", float64(len(s.Procs)))
+	var read, write float64
+	for _, d := range s.Disks {
+		read += d.ReadRate
+		write += d.WriteRate
+	}
+	gauge("disk.read_bytes_per_sec", read)
+	gauge("disk.write_bytes_per_sec", write)
+	for _, state := range socketStates {
+		gauge("tcp_sockets."+strings.ToLower(state), float64(s.Sockets.States[state]))
+	}
+	return lines
+}
+
+// push sends the gauges of s, packing as many lines into each datagram as fit.
+func (c *statsdClient) push(s Snapshot) {
+	if c == nil {
+		return
+	}
+	var packet strings.Builder
+	flush := func() {
+		if packet.Len() == 0 {
+			return
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(statsdWriteTimeout))
+		if _, err := c.conn.Write([]byte(packet.String())); err != nil {
+			c.failed.Add(1)
+		} else {
+			c.sent.Add(1)
+		}
+		packet.Reset()
+	}
+	for _, line := range statsdLines(s, c.prefix, c.tags) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdPacketSize {
+			flush()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	flush()
+}
+
+// statsdFlag adds the -statsd flag shared by the TUI and the headless subcommands.
+func statsdFlag(fs *flag.FlagSet) *string {
+	return fs.String("statsd", "", "push the core metrics as StatsD gauges over UDP to this host:port on every refresh")
+}
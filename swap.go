@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SwapDevice is one swap partition or file as listed in /proc/swaps.
+type SwapDevice struct {
+	Name     string
+	Type     string // "partition" or "file"
+	Size     uint64
+	Used     uint64
+	Priority int
+}
+
+// readSwaps parses a /proc/swaps style file. Sizes are given in KiB.
+func readSwaps(path string) ([]SwapDevice, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// The first line holds the column names.
+	if !scanner.Scan() {
+		return nil, scanner.Err()
+	}
+	var devices []SwapDevice
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		size, err1 := strconv.ParseUint(fields[2], 10, 64)
+		used, err2 := strconv.ParseUint(fields[3], 10, 64)
+		prio, err3 := strconv.Atoi(fields[4])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		// Spaces in file names are escaped as \040.
+		name := strings.ReplaceAll(fields[0], `\040`, " ")
+		devices = append(devices, SwapDevice{Name: name, Type: fields[1], Size: size * 1024, Used: used * 1024, Priority: prio})
+	}
+	return devices, scanner.Err()
+}
+
+// readSwappiness reads vm.swappiness, or returns -1 where it can't be read.
+func readSwappiness(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// swapPanel breaks the swap usage down by device once there is more than one. The kernel
+// fills higher priorities first, so a zram device and a disk behind it behave very
+// differently, which the aggregate hides. With a single device there is nothing to break
+// down and the panel stays hidden.
+func (m model) swapPanel() panel {
+	p := panel{collector: collectorMem, title: "Swap"}
+	devices := m.data.Mem.SwapDevices
+	if len(devices) < 2 {
+		return p
+	}
+	if s := m.data.Mem.Swappiness; s >= 0 {
+		p.title += fmt.Sprintf(" · swappiness %d", s)
+	}
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	p.lines = append(p.lines, hint(fmt.Sprintf("%-16s %-9s %5s %12s %12s", "device", "type", "prio", "used", "size")))
+	for _, d := range devices {
+		p.lines = append(p.lines, fmt.Sprintf("%s %-9s %5d %12s %12s", fit(d.Name, 16), d.Type, d.Priority, formatBytes(d.Used), formatBytes(d.Size)))
+	}
+	return p
+}
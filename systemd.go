@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// systemdDetected reports whether the host was booted with systemd, the same check as
+// sd_booted(3).
+func systemdDetected() bool {
+	info, err := os.Stat("/run/systemd/system")
+	return err == nil && info.IsDir()
+}
+
+// systemdUnitFromCgroup returns the unit and slice of a process from the contents of
+// /proc/<pid>/cgroup. The unit is the innermost one in the path, so a service started by a
+// user manager, e.g. .../user@1000.service/app.slice/foo.service, belongs to foo.service;
+// the slice is the one directly above it. Both are "" for processes in the root cgroup,
+// such as kernel threads.
+func systemdUnitFromCgroup(cgroup string) (unit, slice string) {
+	var path string
+	scanner := bufio.NewScanner(strings.NewReader(cgroup))
+	for scanner.Scan() {
+		// Lines look like "hierarchy-ID:controllers:path". The unified hierarchy ("0::") and
+		// systemd's own named hierarchy on cgroup v1 both mirror the unit tree.
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "name=systemd" || (parts[0] == "0" && parts[1] == "") {
+			path = parts[2]
+			if parts[1] == "name=systemd" {
+				break
+			}
+		}
+	}
+	for _, part := range strings.Split(path, "/") {
+		switch {
+		case part == "":
+		case strings.HasSuffix(part, ".slice"):
+			slice = part
+		case strings.Contains(part, "."):
+			unit = part
+		}
+	}
+	return unit, slice
+}
+
+// unitEntry is the cached unit of one process.
+type unitEntry struct {
+	createTime  int64
+	unit, slice string
+}
+
+// unitResolver maps processes to their systemd unit. Results are cached per PID, so
+// /proc/<pid>/cgroup is read once per process rather than on every tick; a process that
+// moves to another unit, as with systemd-run --scope, is rare enough to ignore. Like
+// ProcessCollector it is not safe for concurrent use.
+type unitResolver struct {
+	byPID map[int32]unitEntry
+}
+
+func newUnitResolver() *unitResolver {
+	return &unitResolver{byPID: map[int32]unitEntry{}}
+}
+
+// lookup returns the unit and slice of a process, "" when it is in none.
+func (r *unitResolver) lookup(pid int32, createTime int64) (unit, slice string) {
+	if e, ok := r.byPID[pid]; ok && e.createTime == createTime {
+		return e.unit, e.slice
+	}
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup"); err == nil {
+		unit, slice = systemdUnitFromCgroup(string(data))
+	}
+	r.byPID[pid] = unitEntry{createTime: createTime, unit: unit, slice: slice}
+	return unit, slice
+}
+
+// prune forgets processes that no longer exist.
+func (r *unitResolver) prune(alive map[int32]cpuSample) {
+	for pid := range r.byPID {
+		if _, ok := alive[pid]; !ok {
+			delete(r.byPID, pid)
+		}
+	}
+}
+
+// UnitInfo holds the combined resource usage of all processes of one systemd unit.
+type UnitInfo struct {
+	Unit       string
+	Slice      string
+	Processes  int
+	CPUPercent float64
+	Memory     uint64
+	NumFDs     int64
+}
+
+// aggregateByUnit groups processes by unit and sums their resource usage, like
+// systemd-cgtop. Processes in no unit, the kernel threads, are left out.
+func aggregateByUnit(procs []ProcessInfo) []UnitInfo {
+	index := map[string]int{}
+	var units []UnitInfo
+	for _, p := range procs {
+		if p.Unit == "" {
+			continue
+		}
+		i, ok := index[p.Unit]
+		if !ok {
+			i = len(units)
+			index[p.Unit] = i
+			units = append(units, UnitInfo{Unit: p.Unit, Slice: p.Slice})
+		}
+		u := &units[i]
+		u.Processes++
+		u.CPUPercent += p.CPUPercent
+		u.Memory += p.Memory
+		u.NumFDs += int64(p.NumFDs)
+	}
+	return units
+}
+
+// sortUnits orders units by the given metric, highest first, the same metrics the per-user
+// view sorts by. Ties are broken by name so the rows don't jump around between ticks.
+func sortUnits(units []UnitInfo, by userSort) {
+	sort.SliceStable(units, func(i, j int) bool {
+		a, b := units[i], units[j]
+		switch by {
+		case userSortMem:
+			if a.Memory != b.Memory {
+				return a.Memory > b.Memory
+			}
+		case userSortProcs:
+			if a.Processes != b.Processes {
+				return a.Processes > b.Processes
+			}
+		case userSortFDs:
+			if a.NumFDs != b.NumFDs {
+				return a.NumFDs > b.NumFDs
+			}
+		default:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent > b.CPUPercent
+			}
+		}
+		return a.Unit < b.Unit
+	})
+}
+
+func newUnitTable(styles table.Styles) table.Model {
+	return table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Unit", Width: 36},
+			{Title: "Slice", Width: 24},
+			{Title: "Procs", Width: 8},
+			{Title: "CPU", Width: 12},
+			{Title: "MEM", Width: 12},
+			{Title: "FDs", Width: 10},
+		}),
+		table.WithRows([]table.Row{}),
+		table.WithFocused(true),
+		table.WithHeight(20),
+		table.WithStyles(styles),
+	)
+}
+
+// unitRows builds the rows of the per-unit table from the latest process list.
+func unitRows(procs []ProcessInfo, by userSort) []table.Row {
+	units := aggregateByUnit(procs)
+	sortUnits(units, by)
+
+	rows := make([]table.Row, 0, len(units))
+	for _, u := range units {
+		rows = append(rows, table.Row{
+			u.Unit,
+			u.Slice,
+			formatInt(u.Processes),
+			formatPercent(u.CPUPercent, 2),
+			formatBytes(u.Memory),
+			formatInt(u.NumFDs),
+		})
+	}
+	return rows
+}
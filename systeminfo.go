@@ -1,7 +1,12 @@
 package main
 
 import (
-	"fmt"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
 	"sort"
 	"time"
 
@@ -43,42 +48,287 @@ func GetCPUStats() (cpu.TimesStat, error) {
 	return currStats, nil
 }
 
-func GetMEMStats() (mem.VirtualMemoryStat, error) {
+// MemStats is the memory data shown in the header: the portable figures reported by
+// gopsutil plus platform-specific ones filled in by platformMemStats.
+type MemStats struct {
+	mem.VirtualMemoryStat
+
+	// Windows commit charge and kernel pools.
+	CommitTotal  uint64
+	CommitLimit  uint64
+	PagedPool    uint64
+	NonPagedPool uint64
+
+	// macOS compressor usage and memory pressure level.
+	Compressed uint64
+	Pressure   memPressure
+
+	// Linux swap devices and vm.swappiness, -1 when unknown.
+	SwapDevices []SwapDevice
+	Swappiness  int
+
+	// DirtyRate is how fast the dirty pages grew since the previous read, in bytes per
+	// second; negative while they are written back faster than they are dirtied.
+	DirtyRate float64
+}
+
+// memPressure is the kernel's memory pressure level as reported by macOS.
+type memPressure int
+
+const (
+	memPressureUnknown  memPressure = 0
+	memPressureNormal   memPressure = 1
+	memPressureWarn     memPressure = 2
+	memPressureCritical memPressure = 4
+)
+
+func GetMEMStats() (MemStats, error) {
 	v, err := mem.VirtualMemory()
 	if err != nil {
-		return mem.VirtualMemoryStat{}, err
+		return MemStats{}, err
 	}
 
-	return mem.VirtualMemoryStat{
-		Total:       v.Total,
-		Used:        v.Used,
-		Free:        v.Free,
-		UsedPercent: v.UsedPercent,
-		Available:   v.Available,
-	}, nil
+	stats := MemStats{VirtualMemoryStat: *v, Swappiness: -1}
+	if err := platformMemStats(&stats); err != nil {
+		return MemStats{}, err
+	}
+	return stats, nil
 }
 
 type ProcessInfo struct {
 	PID         int32
+	PPID        int32
 	Name        string
+	DisplayName string // the program an interpreter runs, e.g. "python3: celery worker"; see displayName
+	Cmdline     string // full command line, "" when it may not be read
 	Username    string
 	Memory      uint64
-	CPUPercent  float64 // CPU usage percentage
+	CPUPercent  float64       // CPU usage percentage over the last refresh interval
+	CPUTime     time.Duration // cumulative user+system CPU time
+	CreateTime  int64         // milliseconds since the epoch
 	RunningTime string
+	NumFDs      int32  // open file descriptors, 0 unless FDsKnown
+	FDsKnown    bool   // false when the FDs of the process may not be read
+	Threads     int32  // number of threads, 0 when unknown
+	Nice        int32  // nice value, 0 when unknown
+	Realtime    bool   // scheduled under a real-time policy, where nice doesn't apply
+	Zombie      bool   // exited but not yet reaped by its parent
+	Blocked     bool   // in uninterruptible sleep (state D), usually waiting on I/O
+	Container   string // container name or short ID, "" when running on the host
+	// Unit and Slice are the systemd unit and slice of the process, "" on hosts without
+	// systemd and for processes in none.
+	Unit, Slice string
+	// Flags are the anomaly flags set by anomalyWatch, "" for none.
+	Flags string
+	// NetRx and NetTx are the approximate TCP traffic in bytes per second; NetKnown is
+	// false when it was not attributed this interval.
+	NetRx, NetTx float64
+	NetKnown     bool
+	// GPUMem is the GPU memory held by the process and GPUs the indexes of the GPUs it is
+	// on, both empty for processes not using an NVIDIA GPU.
+	GPUMem uint64
+	GPUs   []int
+	// DiskRead and DiskWrite are the storage I/O in bytes per second over the last
+	// interval; DiskKnown is false when it could not be measured for the process.
+	DiskRead, DiskWrite float64
+	DiskKnown           bool
+	// RunDelay and IODelay are the time spent waiting for a CPU and on block I/O, as a
+	// percentage of the last interval; only collected while a delay column is shown.
+	RunDelay, IODelay           float64
+	RunDelayKnown, IODelayKnown bool
+	// MemLimitPercent is the usage of the process's cgroup as a percentage of the memory
+	// limit binding it, MemLimit; without a limit, MemLimit is 0 and the percentage is of
+	// host RAM. Only collected while the MEM/LIM column is shown.
+	MemLimitPercent float64
+	MemLimit        uint64
+	MemLimitKnown   bool
+	// VolCtxRate and InvolCtxRate are the voluntary and involuntary context switches per
+	// second over the last interval; only collected while the CSW/s column is shown.
+	VolCtxRate, InvolCtxRate float64
+	CtxKnown                 bool
+}
+
+// procSched is the scheduling state of a process as read by processSched.
+type procSched struct {
+	nice     int32
+	realtime bool
+	zombie   bool
+	blocked  bool
+	// threads is the number of threads, 0 where processSched doesn't read it.
+	threads int32
+}
+
+// procIOSupported reports whether gopsutil reads per-process I/O counters on this platform.
+const procIOSupported = runtime.GOOS == "linux" || runtime.GOOS == "windows"
+
+// ioSample is the cumulative storage I/O of a process at the moment it was read.
+type ioSample struct {
+	createTime  int64
+	read, write uint64
+	at          time.Time
+}
+
+// cpuSample is the cumulative CPU time of a process at the moment it was read.
+type cpuSample struct {
+	createTime int64
+	total      float64 // seconds
+	at         time.Time
+}
+
+// ProcessCollector lists processes and remembers each one's previous CPU times, so CPU%
+// reflects the last refresh interval rather than the average over the process lifetime.
+// It is not safe for concurrent use; the model runs at most one collection at a time.
+type ProcessCollector struct {
+	prev map[int32]cpuSample
+	// containers resolves each process's container; nil when the column is disabled.
+	containers *containerResolver
+	// net attributes socket traffic to processes; nil when the column is disabled.
+	net *netAttributor
+	// gpu reads the GPU memory of processes; nil when the column is disabled.
+	gpu *gpuAttributor
+	// units resolves each process's systemd unit; nil on hosts without systemd.
+	units *unitResolver
+	// prevIO holds each process's previous I/O counters for the disk rates.
+	prevIO map[int32]ioSample
+	// fdsDenied and ioDenied remember the processes whose FDs and I/O counters may not be
+	// read, by their create time, so a process we lack the privileges for isn't retried
+	// on every tick.
+	fdsDenied map[int32]int64
+	ioDenied  map[int32]int64
+	// prevDelays holds each process's previous scheduler delays; nil while the delay
+	// columns are off.
+	prevDelays map[int32]delayTotals
+	// memLimits resolves each process's cgroup memory limit; nil while the MEM/LIM
+	// column is off.
+	memLimits *memLimitResolver
+	// lite reads only the busiest processes in full and skips their FDs and I/O counters.
+	lite bool
+	// threads reads the thread count of each process; see processOptions.
+	threads bool
+	// prevCtx holds each process's previous context switch counts; nil while the CSW/s
+	// column is off.
+	prevCtx map[int32]ctxSample
+}
+
+// processOptions selects the optional, more expensive per-process data to collect, or
+// with lite the low-overhead collection that reads less than the default.
+type processOptions struct {
+	containers bool
+	net        bool
+	gpu        bool
+	units      bool
+	delays     bool
+	memLimits  bool
+	ctxSwitch  bool
+	// ctxTargets limits the context switches read to the processes on screen, nil to read
+	// those of every process.
+	ctxTargets *ctxTargets
+	lite       bool
+	// audit reads every process's executable for the audit panel.
+	audit bool
+	// netns reads every process's network namespace for the interfaces panel.
+	netns bool
+	// threads reads the thread count where processSched doesn't get it for free, for a
+	// custom column that uses it.
+	threads bool
+	// powerCommand is sensors.power_command, run by the sensors collector.
+	powerCommand []string
+	// firewallCounters is network.firewall_counters, read by the firewall collector.
+	firewallCounters []string
+	// upower is the UPower watcher the battery collector reads, nil to read sysfs.
+	upower *upowerWatcher
 }
 
-func GetProcesses(n int) ([]ProcessInfo, error) {
+func NewProcessCollector(opts processOptions) *ProcessCollector {
+	c := &ProcessCollector{prev: map[int32]cpuSample{}, prevIO: map[int32]ioSample{}, lite: opts.lite, threads: opts.threads}
+	if opts.containers {
+		c.containers = newContainerResolver()
+	}
+	if opts.net {
+		c.net = newNetAttributor()
+	}
+	if opts.gpu {
+		c.gpu = newGPUAttributor()
+	}
+	if opts.units {
+		c.units = newUnitResolver()
+	}
+	if opts.delays {
+		c.prevDelays = map[int32]delayTotals{}
+	}
+	if opts.memLimits {
+		c.memLimits = newMemLimitResolver()
+	}
+	if opts.ctxSwitch {
+		c.prevCtx = map[int32]ctxSample{}
+	}
+	return c
+}
+
+// GetProcesses returns the n most CPU-intensive processes, or all of them when n <= 0.
+// Context switches are only read for the processes in ctxPIDs, or for all of them when it
+// is nil.
+func (c *ProcessCollector) GetProcesses(n int, ctxPIDs map[int32]bool) ([]ProcessInfo, error) {
 	procs, err := process.Processes()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	var ranked map[int32]cpuSample
+	if c.lite {
+		procs, ranked = c.rankByCPU(procs, lowOverheadProcesses, now)
+	}
+	seen := make(map[int32]cpuSample, len(procs))
+	denied := make(map[int32]int64, len(c.fdsDenied))
+	ioSeen := make(map[int32]ioSample, len(c.prevIO))
+	ioDenied := make(map[int32]int64, len(c.ioDenied))
+	names := processNames()
+
+	var delaysSeen map[int32]delayTotals
+	acct := false
+	if c.prevDelays != nil {
+		delaysSeen = make(map[int32]delayTotals, len(c.prevDelays))
+		acct = delayAccountingEnabled()
+	}
+
+	if c.memLimits != nil {
+		c.memLimits.begin()
+	}
+
+	var ctxSeen map[int32]ctxSample
+	if c.prevCtx != nil && !c.lite {
+		ctxSeen = make(map[int32]ctxSample, len(c.prevCtx))
+	}
+
+	var netRates map[int32]netRate
+	if c.net != nil {
+		// A failed attribution leaves the NET column empty rather than failing the whole table.
+		if netRates, err = c.net.rates(); err != nil {
+			slog.Error("Could not attribute network traffic", "error", err)
+		}
+	}
+	var gpuUse map[int32]gpuUsage
+	if c.gpu != nil {
+		if gpuUse, err = c.gpu.usage(); err != nil {
+			slog.Error("Could not read GPU memory", "error", err)
+		}
+	}
+
 	var processInfos []ProcessInfo
 	for _, p := range procs {
 		pid := p.Pid
 		name, err := p.Name()
 		if err != nil {
 			name = "Unknown"
+			if n, ok := names[pid]; ok {
+				name = n
+			}
+		}
+
+		cmdline, err := p.Cmdline()
+		if err != nil {
+			cmdline = ""
 		}
 
 		createTime, err := p.CreateTime()
@@ -86,70 +336,234 @@ func GetProcesses(n int) ([]ProcessInfo, error) {
 			createTime = 0
 		}
 
-		startTime := time.Unix(createTime/1000, 0)
-		runningTime := time.Since(startTime).Truncate(time.Second)
+		startTime := time.UnixMilli(createTime)
+		runningTime := now.Sub(startTime).Truncate(time.Second)
 
-		username, err := p.Username()
+		username, err := processUsername(p)
 		if err != nil {
-			name = "Unknown"
+			username = "Unknown"
 		}
 
-		memoryInfo, err := p.MemoryInfo()
+		ppid, err := p.Ppid()
 		if err != nil {
-			processInfos = append(processInfos, ProcessInfo{
-				PID:         pid,
-				Name:        name,
-				RunningTime: runningTime.String(),
-				Username:    username,
-				Memory:      0,
-				CPUPercent:  0,
-			})
-			continue
+			ppid = 0
 		}
 
-		memory := memoryInfo.RSS
+		var numFDs int32
+		fdsKnown := false
+		if at, ok := c.fdsDenied[pid]; ok && at == createTime {
+			denied[pid] = createTime
+		} else if !c.lite {
+			numFDs, err = p.NumFDs()
+			switch {
+			case err == nil:
+				fdsKnown = true
+			case errors.Is(err, fs.ErrPermission):
+				denied[pid] = createTime
+			default:
+				numFDs = 0
+			}
+		}
+
+		var diskRead, diskWrite float64
+		diskKnown := false
+		if at, ok := c.ioDenied[pid]; ok && at == createTime {
+			ioDenied[pid] = createTime
+		} else if procIOSupported && !c.lite {
+			io, err := p.IOCounters()
+			switch {
+			case err == nil:
+				sample := ioSample{createTime: createTime, read: io.ReadBytes, write: io.WriteBytes, at: now}
+				ioSeen[pid] = sample
+				if prev, ok := c.prevIO[pid]; ok && prev.createTime == createTime && now.After(prev.at) {
+					secs := now.Sub(prev.at).Seconds()
+					diskRead = float64(sample.read-min(prev.read, sample.read)) / secs
+					diskWrite = float64(sample.write-min(prev.write, sample.write)) / secs
+					diskKnown = true
+				}
+			case errors.Is(err, fs.ErrPermission):
+				ioDenied[pid] = createTime
+			}
+		}
 
-		cpuPercent, err := p.CPUPercent()
+		sched, err := processSched(p)
 		if err != nil {
-			cpuPercent = 0
+			sched = procSched{}
+		}
+		if sched.threads == 0 && c.threads {
+			if n, err := p.NumThreads(); err == nil {
+				sched.threads = n
+			}
+		}
+
+		var runDelay, ioDelay float64
+		runDelayKnown, ioDelayKnown := false, false
+		if delaysSeen != nil {
+			if d, err := readDelays(pid, createTime, acct); err == nil {
+				delaysSeen[pid] = d
+				if prev, ok := c.prevDelays[pid]; ok {
+					runDelay, ioDelay, ioDelayKnown, runDelayKnown = delayPercents(prev, d)
+				}
+			}
+		}
+
+		var memory uint64
+		if memoryInfo, err := p.MemoryInfo(); err == nil {
+			memory = memoryInfo.RSS
+		}
+
+		// A single Times() read gives both the cumulative CPU time and, diffed against
+		// the previous sample of the same process, the CPU% over the interval.
+		var cpuTime float64
+		var cpuPercent float64
+		if times, err := p.Times(); err == nil {
+			cpuTime = times.User + times.System
+			sample := cpuSample{createTime: createTime, total: cpuTime, at: now}
+			seen[pid] = sample
+
+			if prev, ok := c.prev[pid]; ok && prev.createTime == createTime && now.After(prev.at) {
+				cpuPercent = (cpuTime - prev.total) / now.Sub(prev.at).Seconds() * 100
+			} else if lifetime := now.Sub(startTime).Seconds(); lifetime > 0 {
+				// First sighting: fall back to the lifetime average until there is a previous sample.
+				cpuPercent = cpuTime / lifetime * 100
+			}
+			cpuPercent = max(cpuPercent, 0)
+		}
+
+		var container string
+		if c.containers != nil {
+			container = c.containers.lookup(pid, createTime)
+		}
+		var unit, slice string
+		if c.units != nil {
+			unit, slice = c.units.lookup(pid, createTime)
 		}
 
 		processInfos = append(processInfos, ProcessInfo{
-			PID:         pid,
-			Name:        name,
-			RunningTime: runningTime.String(),
-			Username:    username,
-			Memory:      memory,
-			CPUPercent:  cpuPercent,
+			PID:           pid,
+			PPID:          ppid,
+			Name:          name,
+			DisplayName:   displayName(name, cmdline),
+			Cmdline:       cmdline,
+			RunningTime:   runningTime.String(),
+			Username:      username,
+			Memory:        memory,
+			CPUPercent:    cpuPercent,
+			CPUTime:       time.Duration(cpuTime * float64(time.Second)),
+			CreateTime:    createTime,
+			NumFDs:        numFDs,
+			FDsKnown:      fdsKnown,
+			Threads:       sched.threads,
+			Nice:          sched.nice,
+			Realtime:      sched.realtime,
+			Zombie:        sched.zombie,
+			Blocked:       sched.blocked,
+			DiskRead:      diskRead,
+			DiskWrite:     diskWrite,
+			DiskKnown:     diskKnown,
+			RunDelay:      runDelay,
+			IODelay:       ioDelay,
+			RunDelayKnown: runDelayKnown,
+			IODelayKnown:  ioDelayKnown && runDelayKnown,
+			Container:     container,
+			Unit:          unit,
+			Slice:         slice,
 		})
+		if rate, ok := netRates[pid]; ok {
+			info := &processInfos[len(processInfos)-1]
+			info.NetRx, info.NetTx = rate.rx, rate.tx
+		}
+		if netRates != nil {
+			processInfos[len(processInfos)-1].NetKnown = true
+		}
+		if u, ok := gpuUse[pid]; ok {
+			info := &processInfos[len(processInfos)-1]
+			info.GPUMem, info.GPUs = u.mem, u.gpus
+		}
+		if c.memLimits != nil {
+			info := &processInfos[len(processInfos)-1]
+			info.MemLimitPercent, info.MemLimit, info.MemLimitKnown = c.memLimits.lookup(pid, createTime, memory)
+		}
+		if ctxSeen != nil && (ctxPIDs == nil || ctxPIDs[pid]) {
+			if cur, err := readCtxSwitches(p, createTime, now); err == nil {
+				ctxSeen[pid] = cur
+				info := &processInfos[len(processInfos)-1]
+				info.VolCtxRate, info.InvolCtxRate, info.CtxKnown = ctxRates(c.prevCtx[pid], cur)
+			}
+		}
+	}
+	// The processes a low-overhead pass didn't read in full keep the samples of the
+	// ranking, so they can be ranked again next time.
+	for pid, sample := range ranked {
+		if _, ok := seen[pid]; !ok {
+			seen[pid] = sample
+		}
+	}
+	// Only keep samples and denials of processes that still exist so the maps don't grow forever.
+	c.prev = seen
+	c.fdsDenied = denied
+	c.prevIO = ioSeen
+	c.ioDenied = ioDenied
+	if delaysSeen != nil {
+		c.prevDelays = delaysSeen
+	}
+	if ctxSeen != nil {
+		c.prevCtx = ctxSeen
+	}
+	if c.containers != nil {
+		c.containers.prune(seen)
+	}
+	if c.units != nil {
+		c.units.prune(seen)
+	}
+	if c.memLimits != nil {
+		c.memLimits.prune(seen)
 	}
 
 	sort.Slice(processInfos, func(i, j int) bool {
 		return processInfos[i].CPUPercent > processInfos[j].CPUPercent
 	})
 
-	if len(processInfos) > n {
+	if n > 0 && len(processInfos) > n {
 		processInfos = processInfos[:n]
 	}
 
 	return processInfos, nil
 }
 
-func convertBytes(bytes uint64) (string, string) {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-	)
-
-	switch {
-	case bytes >= GB:
-		return fmt.Sprintf("%.2f", float64(bytes)/float64(GB)), "GB"
-	case bytes >= MB:
-		return fmt.Sprintf("%.2f", float64(bytes)/float64(MB)), "MB"
-	case bytes >= KB:
-		return fmt.Sprintf("%.2f", float64(bytes)/float64(KB)), "KB"
-	default:
-		return fmt.Sprintf("%d", bytes), "B"
+// selfPID is the PID of this program, which a low-overhead pass always reads so its own
+// usage can be shown.
+var selfPID = int32(os.Getpid())
+
+// rankByCPU is the cheap first pass of a low-overhead collection. It reads only the CPU
+// times of each process, a single stat file on Linux, and returns the n busiest since the
+// previous pass, plus this program, along with the CPU samples of every process.
+func (c *ProcessCollector) rankByCPU(procs []*process.Process, n int, now time.Time) ([]*process.Process, map[int32]cpuSample) {
+	samples := make(map[int32]cpuSample, len(procs))
+	usage := make(map[int32]float64, len(procs))
+	for _, p := range procs {
+		times, err := p.Times()
+		if err != nil {
+			continue
+		}
+		createTime, _ := p.CreateTime()
+		sample := cpuSample{createTime: createTime, total: times.User + times.System, at: now}
+		samples[p.Pid] = sample
+		if prev, ok := c.prev[p.Pid]; ok && prev.createTime == createTime && now.After(prev.at) {
+			usage[p.Pid] = (sample.total - prev.total) / now.Sub(prev.at).Seconds()
+		} else if lifetime := now.Sub(time.UnixMilli(createTime)).Seconds(); lifetime > 0 {
+			usage[p.Pid] = sample.total / lifetime
+		}
+	}
+	sort.SliceStable(procs, func(i, j int) bool { return usage[procs[i].Pid] > usage[procs[j].Pid] })
+	if len(procs) <= n {
+		return procs, samples
+	}
+	top := slices.Clone(procs[:n])
+	if !slices.ContainsFunc(top, func(p *process.Process) bool { return p.Pid == selfPID }) {
+		if i := slices.IndexFunc(procs, func(p *process.Process) bool { return p.Pid == selfPID }); i >= 0 {
+			top = append(top, procs[i])
+		}
 	}
+	return top, samples
 }
@@ -0,0 +1,49 @@
+package main
+
+import "fmt"
+
+// throttleCapRatio is how far below the hardware maximum the frequency policy must cap the
+// CPUs to count as throttled; governors routinely leave a sliver of headroom.
+const throttleCapRatio = 0.95
+
+// ThrottleStats is the frequency and thermal throttling state of the CPUs.
+type ThrottleStats struct {
+	// CurMHz is the average current frequency of the CPUs, MaxMHz the highest the hardware
+	// allows and LimitMHz the highest the frequency policy allows now; 0 when unknown.
+	CurMHz, MaxMHz, LimitMHz float64
+	// Events counts the thermal throttle events of the session; EventsKnown is false where
+	// the kernel exposes no counters.
+	Events      uint64
+	EventsKnown bool
+	// Throttled is set while throttle events keep arriving.
+	Throttled bool
+}
+
+// capped reports whether the frequency policy holds the CPUs well below their maximum,
+// as power-saving profiles and thermal daemons do.
+func (t ThrottleStats) capped() bool {
+	return t.LimitMHz > 0 && t.MaxMHz > 0 && t.LimitMHz < t.MaxMHz*throttleCapRatio
+}
+
+// formatMHz renders a frequency in GHz, e.g. "2.40 GHz".
+func formatMHz(mhz float64) string {
+	return formatFloat(mhz/1000, 2) + " GHz"
+}
+
+// viewThrottleBadge renders the THROTTLED badge next to the CPU title while the CPUs throttle
+// or are capped, with the throttle events of the session; "" otherwise and where nothing
+// is known.
+func (m model) viewThrottleBadge() string {
+	t := m.data.Throttle
+	if t == nil || !(t.Throttled || t.capped()) {
+		return ""
+	}
+	badge := " THROTTLED"
+	switch {
+	case t.EventsKnown && t.Events > 0:
+		badge += fmt.Sprintf(" (%s events)", formatCount(t.Events))
+	case t.capped():
+		badge += " (capped at " + formatMHz(t.LimitMHz) + ")"
+	}
+	return m.baseStyle.Foreground(Color.Red).Bold(true).Render(badge)
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttleSupported reports whether CPU throttling is detected on this platform.
+const throttleSupported = true
+
+// throttleHold is how long the THROTTLED badge stays up after the last throttle event, so
+// a CPU bouncing off its thermal limit doesn't make the badge flicker.
+const throttleHold = 30 * time.Second
+
+// throttleCollector reads the CPU frequencies and thermal throttle counters from sysfs and
+// counts the events since the first read. Like DiskIOCollector it is not safe for
+// concurrent use.
+type throttleCollector struct {
+	base, last  uint64
+	started     bool
+	lastEventAt time.Time
+}
+
+func newThrottleCollector() *throttleCollector {
+	return &throttleCollector{}
+}
+
+// readSysUint reads a sysfs file holding one unsigned number.
+func readSysUint(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return v, err == nil
+}
+
+// GetThrottle reads the throttling state of the CPUs. Virtual machines usually have
+// neither cpufreq nor throttle counters, which fails the collector at the startup probe.
+func (c *throttleCollector) GetThrottle() (*ThrottleStats, error) {
+	cpus, err := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	var t ThrottleStats
+	var cur, freqs float64
+	var events uint64
+	packages := map[uint64]bool{}
+	for _, dir := range cpus {
+		if khz, ok := readSysUint(filepath.Join(dir, "cpufreq/scaling_cur_freq")); ok {
+			cur += float64(khz) / 1000
+			freqs++
+		}
+		if khz, ok := readSysUint(filepath.Join(dir, "cpufreq/cpuinfo_max_freq")); ok {
+			t.MaxMHz = max(t.MaxMHz, float64(khz)/1000)
+		}
+		if khz, ok := readSysUint(filepath.Join(dir, "cpufreq/scaling_max_freq")); ok {
+			t.LimitMHz = max(t.LimitMHz, float64(khz)/1000)
+		}
+		if n, ok := readSysUint(filepath.Join(dir, "thermal_throttle/core_throttle_count")); ok {
+			t.EventsKnown = true
+			events += n
+		}
+		// Every CPU of a package repeats the package counter, so it is only added once.
+		pkg, _ := readSysUint(filepath.Join(dir, "topology/physical_package_id"))
+		if n, ok := readSysUint(filepath.Join(dir, "thermal_throttle/package_throttle_count")); ok && !packages[pkg] {
+			packages[pkg] = true
+			t.EventsKnown = true
+			events += n
+		}
+	}
+	if freqs == 0 && !t.EventsKnown {
+		return nil, fmt.Errorf("no cpufreq or thermal_throttle data in sysfs: %w", fs.ErrNotExist)
+	}
+	if freqs > 0 {
+		t.CurMHz = cur / freqs
+	}
+
+	now := time.Now()
+	if !c.started {
+		c.base, c.last, c.started = events, events, true
+	}
+	if events > c.last {
+		c.lastEventAt = now
+	}
+	c.last = events
+	t.Events = delta(events, c.base)
+	t.Throttled = !c.lastEventAt.IsZero() && now.Sub(c.lastEventAt) < throttleHold
+	return &t, nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// throttleSupported reports whether CPU throttling is detected on this platform; only
+// Linux exposes the frequencies and throttle counters in sysfs.
+const throttleSupported = false
+
+// throttleCollector exists so the collector list builds everywhere; it is never used here.
+type throttleCollector struct{}
+
+func newThrottleCollector() *throttleCollector {
+	return &throttleCollector{}
+}
+
+func (c *throttleCollector) GetThrottle() (*ThrottleStats, error) {
+	return nil, errors.New("CPU throttling is only detected on Linux")
+}
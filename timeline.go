@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// timelineHeight is how many rows each graph of the graphs view takes.
+const timelineHeight = 3
+
+// timelineMetric is one graph of the graphs view.
+type timelineMetric struct {
+	name   string
+	series *series
+	format func(float64) string
+}
+
+// timelineMetrics lists the graphs of the graphs view, top to bottom.
+func (m model) timelineMetrics() []timelineMetric {
+	percent := func(v float64) string { return formatPercent(v, 1) }
+	return []timelineMetric{
+		{"CPU", m.cpuHistory, percent},
+		{"MEM", m.memHistory, percent},
+		{"DISK", m.diskHistory, func(v float64) string { return formatBytes(uint64(v)) + "/s" }},
+		{"NET", m.netHistory, formatRate},
+	}
+}
+
+// recordRates adds the combined disk and network throughput of the latest pass to their
+// series. The network counters are totals since boot, so their rate is the change since
+// the previous pass that read them.
+func (m *model) recordRates(msg collectedMsg, prevNet NetTotals) {
+	for _, name := range msg.ok {
+		switch name {
+		case collectorDisk:
+			var total float64
+			for _, d := range m.data.Disks {
+				total += d.ReadRate + d.WriteRate
+			}
+			m.diskHistory.add(msg.at, total)
+		case collectorNet:
+			if elapsed := msg.at.Sub(m.netAt).Seconds(); !m.netAt.IsZero() && elapsed > 0 {
+				bytes := delta(m.data.Net.Recv, prevNet.Recv) + delta(m.data.Net.Sent, prevNet.Sent)
+				m.netHistory.add(msg.at, float64(bytes)/elapsed)
+			}
+			m.netAt = msg.at
+		}
+	}
+}
+
+// moveTimelineCursor moves the cursor of the graphs view by step cells, negative to the
+// left into the past. The cursor counts cells back from the newest, so it stays on the
+// same age while the graphs scroll.
+func (m *model) moveTimelineCursor(step int) {
+	m.timelineCursor = max(m.timelineCursor-step, 0)
+}
+
+// lastIn returns the newest sample that falls into cell i of a graph drawn with the given
+// bounds.
+func lastIn(samples []sample, from time.Time, span time.Duration, width, i int) (sample, bool) {
+	for j := len(samples) - 1; j >= 0; j-- {
+		if cellIndex(samples[j].at, from, span, width) == i {
+			return samples[j], true
+		}
+	}
+	return sample{}, false
+}
+
+// sparkRows renders cells as a graph height rows tall, scaled so hi fills it. The cell at
+// cursor is drawn in the highlight color, with a line through its empty rows, so the
+// cursor reads as one vertical line across stacked graphs.
+func sparkRows(cells []float64, hi float64, height, cursor int, baseStyle lipgloss.Style) []string {
+	line := baseStyle.Foreground(Color.Green)
+	mark := baseStyle.Foreground(Color.Highlight)
+	levels := height * len(sparkBlocks)
+	rows := make([]strings.Builder, height)
+	for i, v := range cells {
+		level := -1
+		if !math.IsNaN(v) && hi > 0 {
+			level = min(int(v/hi*float64(levels-1)), levels-1)
+		} else if !math.IsNaN(v) {
+			level = 0
+		}
+		for r := range rows {
+			// Row 0 is the top; the row at the bottom fills first.
+			floor := (height - 1 - r) * len(sparkBlocks)
+			ch := " "
+			switch {
+			case level >= floor+len(sparkBlocks):
+				ch = string(sparkBlocks[len(sparkBlocks)-1])
+			case level >= floor:
+				ch = string(sparkBlocks[level-floor])
+			case i == cursor:
+				ch = "│"
+			}
+			if i == cursor {
+				rows[r].WriteString(mark.Render(ch))
+			} else {
+				rows[r].WriteString(line.Render(ch))
+			}
+		}
+	}
+	out := make([]string, height)
+	for r := range rows {
+		out[r] = rows[r].String()
+	}
+	return out
+}
+
+// viewTimeline renders CPU, memory, disk and network throughput as graphs stacked on a
+// shared time axis, so a change in one can be lined up with the others. Each graph scales
+// to its own maximum in the window. The legend shows every metric at the cursor.
+func (m model) viewTimeline() string {
+	now := m.viewAt()
+	from, to := m.windowBounds(now)
+	span := to.Sub(from)
+	title := m.baseStyle.Bold(true).Render
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	label := m.baseStyle.Width(6).Bold(true).Render
+
+	width := max(m.width-8, 10)
+	cursor := width - 1 - min(m.timelineCursor, width-1)
+
+	var lines []string
+	var legend []string
+	var at time.Time
+	for _, metric := range m.timelineMetrics() {
+		samples := metric.series.samples(m.historyWindow, now)
+		cells := bucketize(samples, from, to, width)
+		hi := 0.0
+		for _, v := range cells {
+			if !math.IsNaN(v) {
+				hi = max(hi, v)
+			}
+		}
+		rows := sparkRows(cells, hi, timelineHeight, cursor, m.baseStyle)
+		for r, row := range rows {
+			name := ""
+			if r == 0 {
+				name = metric.name
+			}
+			lines = append(lines, label(name)+row)
+		}
+		lines = append(lines, label("")+hint(fmt.Sprintf("max %s", metric.format(hi))))
+
+		value := "--"
+		if smp, ok := lastIn(samples, from, span, width, cursor); ok {
+			value = metric.format(smp.value)
+			at = smp.at
+		}
+		legend = append(legend, fmt.Sprintf("%s %s", metric.name, value))
+	}
+
+	axisLeft := "-" + m.historyWindow.String()
+	if m.historyWindow == windowSession {
+		axisLeft = formatAge(span) + " ago"
+	}
+	axis := axisLeft + strings.Repeat(" ", max(width-len(axisLeft)-3, 1)) + "now"
+	lines = append(lines, label("")+hint(axis))
+
+	header := title("Graphs") + hint(" · no sample at the cursor")
+	if !at.IsZero() {
+		header = title("Graphs") + hint(fmt.Sprintf(" · %s (%s ago): ", at.Format(time.TimeOnly), formatAge(now.Sub(at)))) + strings.Join(legend, " · ")
+	}
+	lines = append([]string{header, ""}, lines...)
+	return m.viewStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TitleConfig is the [title] section of the config file.
+type TitleConfig struct {
+	// Mode puts a short status such as "cpu 34% mem 72% host1" in the terminal window or
+	// tab title, and in the tmux pane title, so it shows while the monitor is in a
+	// background tab: "off", "auto" to leave out terminals known to mishandle title
+	// escapes, or "on" to set it regardless. The -no-title flag turns it off for one run.
+	Mode string `toml:"mode"`
+	// Interval is the least time between two title updates; some terminals redraw their
+	// whole tab bar for each.
+	Interval time.Duration `toml:"interval"`
+}
+
+// minTitleInterval is the shortest title.interval.
+const minTitleInterval = time.Second
+
+// checkTitle validates the [title] section.
+func checkTitle(c TitleConfig) error {
+	switch c.Mode {
+	case "off", "auto", "on":
+	default:
+		return fmt.Errorf("mode must be \"off\", \"auto\" or \"on\", got %q", c.Mode)
+	}
+	if c.Interval < minTitleInterval {
+		return fmt.Errorf("interval must be at least %s, got %s", minTitleInterval, c.Interval)
+	}
+	return nil
+}
+
+// titleBlockedTerms are the TERM values of terminals that print title escapes instead of
+// taking them, or have no title at all: the Linux and BSD consoles, dumb terminals and
+// Emacs's terminal emulators.
+var titleBlockedTerms = []string{"dumb", "linux", "cons25", "eterm", "eterm-color", "emacs"}
+
+// titleAccepted guesses whether the terminal takes title escapes. Terminals can't be
+// asked, so this only rules out the ones known not to, and output that isn't a terminal.
+func titleAccepted() bool {
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	term := os.Getenv("TERM")
+	return term != "" && !slices.Contains(titleBlockedTerms, term) && os.Getenv("INSIDE_EMACS") == ""
+}
+
+// Title stack escapes of xterm, which most terminals implement: push saves the window
+// title before the first change and pop brings it back on exit.
+const (
+	titlePush = "\x1b[22;2t"
+	titlePop  = "\x1b[23;2t"
+)
+
+// terminalTitle keeps the status in the terminal title. It is nil in the model when the
+// title is left alone.
+type terminalTitle struct {
+	// last is the title set last and at when.
+	last string
+	at   time.Time
+	// tmuxTitle is the pane title found at startup. tmux has no title stack, so it is set
+	// back by hand on exit.
+	tmuxTitle string
+	inTmux    bool
+}
+
+// newTerminalTitle saves the current title and returns the title state, or nil when
+// title.mode, the -no-title flag or the terminal rule the title out.
+func newTerminalTitle(cfg TitleConfig, noTitle bool) *terminalTitle {
+	if noTitle || cfg.Mode == "off" || (cfg.Mode == "auto" && !titleAccepted()) {
+		return nil
+	}
+	t := &terminalTitle{}
+	if os.Getenv("TMUX") != "" {
+		if out, err := exec.Command("tmux", "display-message", "-p", "#{pane_title}").Output(); err == nil {
+			t.tmuxTitle, t.inTmux = strings.TrimRight(string(out), "\n"), true
+		}
+	}
+	os.Stdout.WriteString(titlePush)
+	return t
+}
+
+// statusTitle is the title for the latest data, e.g. "cpu 34% mem 72% host1".
+func (m model) statusTitle() string {
+	host, _, _ := strings.Cut(hostname, ".")
+	return fmt.Sprintf("cpu %s mem %s %s", formatPercent(100-m.data.CPU.Idle, 0), formatPercent(m.data.Mem.UsedPercent, 0), host)
+}
+
+// titleCmd sets the title to the status of the latest data, at most once per
+// title.interval and only when the text changed.
+func (m model) titleCmd(now time.Time) tea.Cmd {
+	t := m.title
+	if t == nil || now.Sub(t.at) < m.cfg.Title.Interval {
+		return nil
+	}
+	title := m.statusTitle()
+	if title == t.last {
+		return nil
+	}
+	t.last, t.at = title, now
+	return tea.SetWindowTitle(title)
+}
+
+// restore puts the title back as it was before the monitor started. It runs after the
+// program has given up the terminal.
+func (t *terminalTitle) restore() {
+	if t == nil {
+		return
+	}
+	os.Stdout.WriteString(titlePop)
+	if t.inTmux {
+		os.Stdout.WriteString(ansi.SetWindowTitle(t.tmuxTitle))
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// TmpfsMount is the usage of one memory-backed filesystem.
+type TmpfsMount struct {
+	Mountpoint string
+	Used       uint64
+	Size       uint64
+}
+
+// SharedMemStats summarises memory that no process RSS accounts for: files in tmpfs mounts
+// and SysV shared memory segments.
+type SharedMemStats struct {
+	Mounts []TmpfsMount
+	// SysVSegments and SysVBytes cover segments from shmget(2), listed in /proc/sysvipc/shm.
+	SysVSegments int
+	SysVBytes    uint64
+}
+
+// TmpfsUsed is the total size of the files stored in all tmpfs mounts.
+func (s SharedMemStats) TmpfsUsed() uint64 {
+	var used uint64
+	for _, m := range s.Mounts {
+		used += m.Used
+	}
+	return used
+}
+
+// GetSharedMemStats lists tmpfs mounts, largest first, and the SysV shared memory in use.
+func GetSharedMemStats() (SharedMemStats, error) {
+	partitions, err := disk.Partitions(true)
+	if err != nil {
+		return SharedMemStats{}, err
+	}
+
+	var stats SharedMemStats
+	seen := map[string]bool{}
+	for _, p := range partitions {
+		if p.Fstype != "tmpfs" || seen[p.Mountpoint] {
+			continue
+		}
+		seen[p.Mountpoint] = true
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil {
+			continue
+		}
+		stats.Mounts = append(stats.Mounts, TmpfsMount{Mountpoint: p.Mountpoint, Used: usage.Used, Size: usage.Total})
+	}
+	sort.SliceStable(stats.Mounts, func(i, j int) bool {
+		return stats.Mounts[i].Used > stats.Mounts[j].Used
+	})
+
+	// SysV segments are optional; the file only exists on Linux.
+	stats.SysVSegments, stats.SysVBytes, _ = readSysVShm("/proc/sysvipc/shm")
+	return stats, nil
+}
+
+// readSysVShm counts the segments in a /proc/sysvipc/shm style file and sums their sizes.
+func readSysVShm(path string) (int, uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// The first line holds the column names; size is the fourth column.
+	if !scanner.Scan() {
+		return 0, 0, scanner.Err()
+	}
+	var segments int
+	var bytes uint64
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+		segments++
+		bytes += size
+	}
+	return segments, bytes, scanner.Err()
+}
+
+// maxTmpfsLines bounds how many tmpfs mounts the panel lists.
+const maxTmpfsLines = 6
+
+// tmpfsPanel lists memory-backed mounts and shared memory segments. A mount is highlighted
+// once its files take more than the configured fraction of total RAM, since that memory
+// shows up in no process's RSS.
+func (m model) tmpfsPanel() panel {
+	p := panel{collector: collectorTmpfs, title: "tmpfs / shm"}
+	shm := m.data.Shm
+	limit := uint64(m.cfg.Tmpfs.WarnFraction * float64(m.data.Mem.Total))
+	for i, mount := range shm.Mounts {
+		if i == maxTmpfsLines {
+			p.lines = append(p.lines, m.baseStyle.Foreground(Color.Secondary).Render(
+				fmt.Sprintf("… %d more mounts", len(shm.Mounts)-maxTmpfsLines)))
+			break
+		}
+		line := fmt.Sprintf("%s %10s / %s", fit(mount.Mountpoint, 16), formatBytes(mount.Used), formatBytes(mount.Size))
+		if limit > 0 && mount.Used > limit {
+			line = m.baseStyle.Foreground(Color.Red).Render(line)
+		}
+		p.lines = append(p.lines, line)
+	}
+	if shm.SysVSegments > 0 {
+		p.lines = append(p.lines, fmt.Sprintf("%-16s %10s (%s segments)", "SysV shm", formatBytes(shm.SysVBytes), formatInt(shm.SysVSegments)))
+	}
+	return p
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// treeNode is one process in the tree view with the totals of the subtree below it.
+type treeNode struct {
+	proc ProcessInfo
+	// prefix draws the branches leading to the process.
+	prefix   string
+	children int
+	// collapsed hides the descendants; hidden counts them, and cpu and mem are the totals
+	// of the process and all of its descendants.
+	collapsed bool
+	hidden    int
+	cpu       float64
+	mem       uint64
+}
+
+// processTree orders procs depth-first under their parents, keeping the order of procs
+// among siblings. Processes whose parent isn't listed, e.g. because a filter left it out,
+// become roots. The descendants of collapsed processes are left out; the subtree totals
+// are computed from procs on every call, so they follow the latest refresh.
+func processTree(procs []ProcessInfo, collapsed map[int32]int64) []treeNode {
+	listed := make(map[int32]bool, len(procs))
+	for _, p := range procs {
+		listed[p.PID] = true
+	}
+	children := map[int32][]ProcessInfo{}
+	var roots []ProcessInfo
+	for _, p := range procs {
+		if p.PPID == p.PID || !listed[p.PPID] {
+			roots = append(roots, p)
+			continue
+		}
+		children[p.PPID] = append(children[p.PPID], p)
+	}
+
+	var nodes []treeNode
+	visited := make(map[int32]bool, len(procs))
+	// walk appends p and, unless it is collapsed, its descendants, and returns the totals
+	// of its subtree.
+	var walk func(p ProcessInfo, prefix, indent string, show bool) (count int, cpu float64, mem uint64)
+	walk = func(p ProcessInfo, prefix, indent string, show bool) (int, float64, uint64) {
+		visited[p.PID] = true
+		at, isCollapsed := collapsed[p.PID]
+		isCollapsed = isCollapsed && at == p.CreateTime
+		i := len(nodes)
+		if show {
+			nodes = append(nodes, treeNode{proc: p, prefix: prefix, children: len(children[p.PID]), collapsed: isCollapsed})
+		}
+		count, cpu, mem := 0, p.CPUPercent, p.Memory
+		kids := children[p.PID]
+		for j, c := range kids {
+			if visited[c.PID] {
+				continue
+			}
+			branch, next := "├─ ", "│  "
+			if j == len(kids)-1 {
+				branch, next = "└─ ", "   "
+			}
+			subCount, subCPU, subMem := walk(c, indent+branch, indent+next, show && !isCollapsed)
+			count, cpu, mem = count+subCount+1, cpu+subCPU, mem+subMem
+		}
+		if show {
+			nodes[i].hidden, nodes[i].cpu, nodes[i].mem = count, cpu, mem
+		}
+		return count, cpu, mem
+	}
+	for _, p := range roots {
+		walk(p, "", "", true)
+	}
+	return nodes
+}
+
+// treeRows formats the process tree for the process table. The name is indented under its
+// parent and marked ▾ or ▸ when the process has children; a collapsed process shows the
+// CPU and memory of its whole subtree, marked Σ, so collapsing doesn't hide the usage.
+func treeRows(procs []ProcessInfo, cols []processColumn, collapsed map[int32]int64) []table.Row {
+	nodes := processTree(procs, collapsed)
+	rows := make([]table.Row, 0, len(nodes))
+	for _, n := range nodes {
+		row := processRow(n.proc, cols)
+		for i, c := range cols {
+			switch c.id {
+			case columnName:
+				name := shownName(n.proc)
+				switch {
+				case n.collapsed && n.children > 0:
+					name = fmt.Sprintf("▸ %s (+%s)", name, formatInt(n.hidden))
+				case n.children > 0:
+					name = "▾ " + name
+				}
+				row[i] = n.prefix + name
+			case columnCPU:
+				if n.collapsed && n.children > 0 {
+					row[i] = "Σ" + formatPercent(n.cpu, 2)
+				}
+			case columnMem:
+				if n.collapsed && n.children > 0 {
+					row[i] = "Σ" + formatBytes(n.mem)
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// setCollapsed collapses or expands the subtree of the selected process in the tree view.
+// The choice is kept by PID and start time, so it survives refreshes but not PID reuse.
+func (m *model) setCollapsed(collapse bool) {
+	pid, ok := m.selectedPID()
+	if !ok {
+		return
+	}
+	p, ok := m.findProcess(pid)
+	if !ok {
+		return
+	}
+	if collapse {
+		m.collapsed[pid] = p.CreateTime
+	} else {
+		delete(m.collapsed, pid)
+	}
+	m.refreshRows()
+}
+
+// pruneCollapsed forgets the collapsed processes that have exited, so the map doesn't grow
+// and a new process reusing the PID starts expanded.
+func (m *model) pruneCollapsed(procs []ProcessInfo) {
+	if len(m.collapsed) == 0 {
+		return
+	}
+	alive := make(map[int32]int64, len(procs))
+	for _, p := range procs {
+		alive[p.PID] = p.CreateTime
+	}
+	for pid, at := range m.collapsed {
+		if created, ok := alive[pid]; !ok || created != at {
+			delete(m.collapsed, pid)
+		}
+	}
+}
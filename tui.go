@@ -1,73 +1,364 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/shirou/gopsutil/v4/cpu"
-	"github.com/shirou/gopsutil/v4/mem"
 )
 
 type model struct {
-	width      int
-	height     int
+	cfg Config
+	// configSrc is the config file and profile re-read by a config reload.
+	configSrc configSource
+	// confirmQuitFlag is set by -confirm-quit, which a reload must not undo.
+	confirmQuitFlag bool
+	// asciiFlag is set by -ascii, which a reload must not undo either.
+	asciiFlag bool
+	// procOpts is the optional per-process data the process collector gathers.
+	procOpts processOptions
+
+	width  int
+	height int
+	// lastUpdate is when a collection pass last collected anything, zero before the first.
 	lastUpdate time.Time
+	// passAt is when the latest collection pass finished, for telling clock jumps.
+	passAt time.Time
+
+	// interval is the refresh interval between collection passes.
+	interval time.Duration
+	// started is when the model was created, used as the age baseline for
+	// collectors that have not succeeded yet.
+	started time.Time
+	// collectors gather the data shown in the panels on every tick.
+	collectors []Collector
+	// lastSuccess records when each collector, by name, last returned data.
+	lastSuccess map[string]time.Time
+	// collecting is set while a collection pass runs in the background.
+	collecting bool
+	// lastPass is how long the latest collection pass took; overruns counts the passes in a
+	// row that took longer than the refresh interval.
+	lastPass time.Duration
+	overruns int
+	// metrics records collection and render timings for the debug endpoint, nil when it is off.
+	metrics *selfMetrics
+	// statsd pushes the core metrics after every collection pass, nil without -statsd.
+	statsd *statsdClient
+	// lowOverhead is set by -low-overhead. gen counts the messages other than ticks, which
+	// could change the frame; render keeps the last frame in low-overhead mode, nil otherwise.
+	lowOverhead bool
+	gen         uint64
+	render      *renderCache
+
+	// confirmQuit asks for confirmation before quitting; quitPrompt is set while the question is shown.
+	confirmQuit bool
+	quitPrompt  bool
+	// inline renders in a region of inlineHeight lines below the prompt instead of the
+	// alternate screen, leaving the last frame in the terminal's history on exit.
+	inline bool
+	// writes tracks background file writes so the program can wait for them before exiting.
+	writes *sync.WaitGroup
 
 	processTable table.Model
+	userTable    table.Model
+	unitTable    table.Model
+	connTable    table.Model
 	tableStyle   table.Styles
 	baseStyle    lipgloss.Style
 	viewStyle    lipgloss.Style
 
-	CpuUsage cpu.TimesStat
-	MemUsage mem.VirtualMemoryStat
+	// state is remembered between runs in the state file at statePath.
+	state     uiState
+	statePath string
+
+	// view selects the table shown below the header.
+	view viewMode
+	// columns are the visible process table columns; sortColumn is the ID of the one the table is ordered by.
+	columns    []processColumn
+	sortColumn string
+	// userSort is the metric the per-user view is ordered by, unitSort the per-unit one.
+	userSort userSort
+	unitSort userSort
+	// userFilter limits the process table to a single user when set, unitFilter to a
+	// single systemd unit.
+	userFilter string
+	unitFilter string
+	// procFilter limits the process table to names and command lines containing it;
+	// procSearching is set while it is typed.
+	procFilter    string
+	procSearching bool
+	// procMatches is the number of processes matching procFilter.
+	procMatches int
+	// command is the text typed at the : prompt while commanding is set; portPicker lists
+	// the owners of a port shared by several processes.
+	command    string
+	commanding bool
+	portPicker *portPicker
+	// treeMode shows the process table as a tree of parents and children; collapsed
+	// holds the processes whose subtree is hidden, by PID with their start time.
+	treeMode  bool
+	collapsed map[int32]int64
+
+	// connState limits the connections view to one TCP state, "" for all; connSearch filters
+	// it by text while connSearching is set as the search is typed.
+	connState     string
+	connSearch    string
+	connSearching bool
+	// caps is the startup probe report shown in the help view; collectors only holds the
+	// collectors that passed the probe.
+	caps capabilities
+	// compactFlag forces the compact view, which is otherwise only shown in tiny terminals.
+	compactFlag bool
+	// webhookStatus holds the outcome of the latest delivery to each webhook URL, and
+	// webhookSeq numbers the deliveries.
+	webhookStatus map[string]webhookStatus
+	webhookSeq    uint64
+	// crash records panics to a crash file; nil outside the interactive program.
+	crash *crashReporter
+	// privilegeHinted is set once the hint about running unprivileged has been shown.
+	privilegeHinted bool
+
+	// resolveDNS shows reverse-resolved names for remote addresses, cached in dns.
+	resolveDNS bool
+	dns        *dnsCache
+
+	// execPanels are the panels of [[exec_panels]] with their latest output.
+	execPanels *execPanels
+
+	// detailPID is the process shown in the detail view.
+	detailPID int32
+	// detailIOPrio is the I/O priority of the detail process, re-read on every refresh.
+	detailIOPrio    ioPriority
+	detailIOPrioErr error
+	// actions is the open actions menu, nil while closed; renice is the open nice value
+	// picker started from it.
+	actions *actionMenu
+	renice  *renicePicker
+	// viewsMenu is the open V menu, nil while closed; namingView is set while the name of
+	// the view to save is typed into viewName.
+	viewsMenu  *menu
+	namingView bool
+	viewName   string
+	// yanking is set after y, while the key naming what to copy is awaited.
+	yanking bool
+	// pinned is the process kept at the top of the process table, nil for none.
+	pinned *procKey
+	// child is the command started by the run subcommand, nil otherwise; childQuitPrompt
+	// is set while asking whether to quit after ctrl+c was forwarded to it.
+	child           *childRun
+	childQuitPrompt bool
+	// ionice is the open I/O priority picker, nil while closed.
+	ionice *ionicePicker
+	// detailAffinity is the CPU affinity mask of the detail process, re-read on every refresh.
+	detailAffinity    []bool
+	detailAffinityErr error
+	// detailDelay measures the scheduler delays of the detail process between refreshes.
+	detailDelay delayReading
+	// detailCtx measures the context switch rates of the detail process between refreshes.
+	detailCtx ctxReading
+	// detailMem is the memory breakdown of the detail process, re-read on every refresh;
+	// detailMemErr says why smaps_rollup couldn't be read.
+	detailMem    memoryMap
+	detailMemErr error
+	// affinity is the open CPU affinity picker, nil while closed.
+	affinity *affinityPicker
+	// cleanupCursor is the selected item of the cleanup assistant; reapTarget is the zombie
+	// parent waiting for confirmation of SIGCHLD, nil otherwise.
+	cleanupCursor int
+	reapTarget    *ProcessInfo
+	// kill is the open kill confirmation dialog, nil while closed.
+	kill *killDialog
+
+	// quiet suppresses the bell and flash of alert rules (--quiet).
+	quiet bool
+	// alertStates tracks each configured alert rule; flash counts the refreshes the header stays inverted.
+	alertStates []alertState
+	flash       int
+
+	// meterStyles selects how each header meter is drawn.
+	meterStyles [meterCount]meterStyle
+	// focus is the area that has the keyboard, cycled with tab; meterFocus is the meter
+	// selected while the header is focused.
+	focus      string
+	meterFocus int
+	// frozen holds the snapshot each frozen area keeps showing, keyed by area.
+	frozen map[string]Snapshot
+	// paused is the snapshot the whole screen shows while paused, nil otherwise.
+	paused   *Snapshot
+	pausedAt time.Time
+
+	// banner is the last error or notice reported to the user, shown until bannerTimeout has passed.
+	banner      string
+	bannerAt    time.Time
+	bannerIsErr bool
+
+	// data is the result of the latest collection pass.
+	data Snapshot
+
+	// cpuHistory and memHistory hold the usage percentages behind the graphs.
+	cpuHistory *series
+	memHistory *series
+	// availHistory holds MemAvailable in bytes for the memory exhaustion estimate.
+	availHistory *series
+	// diskHistory and netHistory hold the combined disk and network throughput behind the
+	// graphs view; netAt is when the network counters were last read, to diff them.
+	diskHistory *series
+	netHistory  *series
+	netAt       time.Time
+	// timelineCursor is how many cells back from the newest the graphs view's cursor is.
+	timelineCursor int
+	// timeWaitHistory holds the TIME_WAIT socket count behind the sockets panel trend.
+	timeWaitHistory *series
+	// loadHistory holds the 1 minute load average; sessionStats the rest of the session statistics.
+	loadHistory  *series
+	sessionStats *sessionStats
+	// fsWatch follows the filesystems' mount state for read-only remounts and errors.
+	fsWatch *fsWatch
+	// fsGrowth keeps the used bytes of each filesystem for the growth shown with it.
+	fsGrowth *fsGrowth
+	// respawns counts the restarts of the watched and pinned processes.
+	respawns *respawnWatch
+	// crashes are the crashes seen this session, oldest first. They come from crashWatch,
+	// or from exits, which follows the watched and pinned processes, where there is no
+	// core dump feed.
+	crashes    []crashEvent
+	crashWatch *crashWatcher
+	exits      *exitWatch
+	// anomalies follows every process for the anomaly flags.
+	anomalies *anomalyWatch
+	// auditHidden holds the audit findings hidden for the session, by auditKey;
+	// auditCursor is the selected finding of the focused audit panel.
+	auditHidden map[string]bool
+	auditCursor int
+	// historyWindow is the time span the graphs show.
+	historyWindow historyWindow
+
+	// history persists tick summaries when started with -history, nil otherwise.
+	history *historyStore
+	// scrub is the stored sample shown in history mode, nil while showing live data.
+	scrub *historyFrame
+	// sortHeldUntil is when the sort hold started by the last navigation key ends;
+	// sortShown is the order of the PIDs the process table last showed, kept while held.
+	sortHeldUntil time.Time
+	sortShown     map[int32]int
+	// diskCursor and ifaceCursor are the devices selected in the focused disk and
+	// interfaces panels; linkFilter is the device picked there with enter, nil for none.
+	diskCursor  int
+	ifaceCursor int
+	linkFilter  *linkFilter
+	// quickFilters are the single-key filters toggled with z, x and M.
+	quickFilters quickFilter
+	// actionLog records the signals, renices, I/O priority and affinity changes made
+	// through the TUI, shown in the L view.
+	actionLog *actionLog
+	// title keeps the status in the terminal title; nil while title.mode is off.
+	title *terminalTitle
 }
 
 type TickMsg time.Time
 
+// viewMode selects which table occupies the area below the header.
+type viewMode int
+
+const (
+	viewProcesses viewMode = iota
+	viewUsers
+	viewDetail
+	viewConnections
+	viewHelp
+	viewStats
+	viewCleanup
+	viewInterrupts
+	viewTimeline
+	viewUnits
+	viewActionLog
+)
+
+// bannerTimeout is how long an error or notice stays in the banner.
+const bannerTimeout = 10 * time.Second
+
 type Theme struct {
 	Primary   lipgloss.AdaptiveColor
 	Secondary lipgloss.AdaptiveColor
 	Highlight lipgloss.AdaptiveColor
 	Border    lipgloss.AdaptiveColor
 	Green     lipgloss.AdaptiveColor
+	Yellow    lipgloss.AdaptiveColor
 	Red       lipgloss.AdaptiveColor
+	// Watched marks the processes listed in [highlight].
+	Watched lipgloss.AdaptiveColor
+	// WarningRow and CriticalRow are the background tints of the [rows] usage bands.
+	WarningRow  lipgloss.AdaptiveColor
+	CriticalRow lipgloss.AdaptiveColor
 }
 
-var Color = Theme{
+// defaultTheme holds the built-in colors; the [theme] config section overrides them.
+var defaultTheme = Theme{
 	Primary:   lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
 	Secondary: lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"},
 	Highlight: lipgloss.AdaptiveColor{Light: "#8b2def", Dark: "#8b2def"},
 	Border:    lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"},
 	Green:     lipgloss.AdaptiveColor{Light: "#00FF00", Dark: "#00FF00"},
+	Yellow:    lipgloss.AdaptiveColor{Light: "#FFD700", Dark: "#FFD700"},
 	Red:       lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"},
+	Watched:   lipgloss.AdaptiveColor{Light: "#0087AF", Dark: "#00D7FF"},
+	// The tints stay close to the terminal background so the text keeps its contrast.
+	WarningRow:  lipgloss.AdaptiveColor{Light: "#FFF5CC", Dark: "#3A3300"},
+	CriticalRow: lipgloss.AdaptiveColor{Light: "#FFE1E1", Dark: "#4A1414"},
 }
 
+// Color is the active theme.
+var Color = defaultTheme
+
 // Calls the tickEvery function to set up a command that sends a TickMsg every second.
 // This command will be executed immediately when the program starts, initiating the periodic updates.
 func (m model) Init() tea.Cmd {
-	return tickEvery()
+	if m.child != nil {
+		return tea.Batch(tickEvery(m.interval), m.execPanels.start(), m.child.waitCmd())
+	}
+	return tea.Batch(tickEvery(m.interval), m.execPanels.start())
 }
 
-func tickEvery() tea.Cmd {
+func tickEvery(interval time.Duration) tea.Cmd {
 	// tea.Every function is a helper function from the Bubble Tea framework
 	// that schedules a command to run at regular intervals.
-	return tea.Every(time.Second,
+	return tea.Every(interval,
 		// Callback function that takes the current time (t time.Time) as a parameter and returns a message (tea.Msg).
-		// This callback is invoked every second.
+		// This callback is invoked once per interval.
 		func(t time.Time) tea.Msg {
 			return TickMsg(t)
 		})
 }
 
 func (m model) View() string {
+	defer m.crash.capture()
+	defer func(start time.Time) { m.metrics.observeRender(time.Since(start)) }(time.Now())
+	if m.render != nil {
+		return m.cachedView()
+	}
+	return m.renderFrame()
+}
+
+// renderFrame renders the frame.
+func (m model) renderFrame() string {
+	// History mode renders the stored sample through the same widgets as live data.
+	if m.scrub != nil {
+		m = m.scrubbed()
+	}
+	if m.compact() {
+		return m.viewCompact()
+	}
 	// Sets the width of the column to the width of the terminal (m.width) and adds padding of 1 unit on the top.
 	// Render is a method from the lipgloss package that applies the defined style and returns a function that can render styled content.
 	column := m.baseStyle.Width(m.width).Padding(1, 0, 0, 0).Render
+	upper, mainHeight, below := m.layout()
 	// Set the content to match the terminal dimensions (m.width and m.height).
 	content := m.baseStyle.
 		Width(m.width).
@@ -75,17 +366,32 @@ func (m model) View() string {
 		Render(
 			// Vertically join multiple elements aligned to the left.
 			lipgloss.JoinVertical(lipgloss.Left,
-				column(m.viewHeader()),
-				column(m.viewProcess()),
+				append([]string{upper, m.region(column(m.viewMain()), mainHeight)}, below...)...,
 			),
 		)
 
 	return content
 }
 
+// Update handles msg, then fits the tables to the main region, whose height changes with
+// the terminal size, the contents of the upper region and the divider position.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.crash.capture()
+	next, cmd := m.update(msg)
+	if nm, ok := next.(model); ok {
+		nm.fitTables()
+		nm.targetCtxSwitches()
+		if _, tick := msg.(TickMsg); !tick {
+			nm.gen++
+		}
+		next = nm
+	}
+	return next, cmd
+}
+
 // Takes a tea.Msg as input and uses a type switch to handle different types of messages.
 // Each case in the switch statement corresponds to a specific message type.
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	// message is sent when the window size changes
@@ -93,13 +399,87 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
 		m.width = msg.Width
+		if m.inline {
+			m.height = min(m.height, inlineHeight)
+		}
 
 	// message is sent when a key is pressed.
 	case tea.KeyMsg:
+		// The quit confirmation and the open dialogs capture all keys while they are shown.
+		if m.quitPrompt {
+			return m.updateQuitPrompt(msg)
+		}
+		if m.childQuitPrompt {
+			return m.updateChildQuitPrompt(msg)
+		}
+		if m.compact() {
+			return m.updateCompact(msg)
+		}
+		if m.yanking {
+			return m.updateYank(msg)
+		}
+		if m.viewsMenu != nil {
+			return m.updateViewsMenu(msg)
+		}
+		if m.namingView {
+			return m.updateViewName(msg)
+		}
+		if m.actions != nil {
+			return m.updateActionMenu(msg)
+		}
+		if m.renice != nil {
+			return m.updateRenicePicker(msg)
+		}
+		if m.ionice != nil {
+			return m.updateIONicePicker(msg)
+		}
+		if m.affinity != nil {
+			return m.updateAffinityPicker(msg)
+		}
+		if m.kill != nil {
+			return m.updateKillDialog(msg)
+		}
+		if m.portPicker != nil {
+			return m.updatePortPicker(msg)
+		}
+		if m.commanding {
+			return m.updateCommand(msg)
+		}
+		if m.scrub != nil {
+			return m.updateScrub(msg)
+		}
+		if m.connSearching {
+			return m.updateConnSearch(msg)
+		}
+		if m.procSearching {
+			return m.updateProcessSearch(msg)
+		}
+		if m.view == viewCleanup {
+			return m.updateCleanup(msg)
+		}
+		if m.focus == areaHeader {
+			return m.updateHeaderFocus(msg)
+		}
+		if m.panelFocused() {
+			return m.updatePanelFocus(msg)
+		}
+
 		switch msg.String() {
-		// Toggles the focus state of the process table
+		// Leaves the per-user, per-unit, detail, connections, help or statistics view, clears an
+		// active user or unit filter, or toggles the focus state of the process table.
 		case "esc":
-			if m.processTable.Focused() {
+			if m.view != viewProcesses {
+				m.view = viewProcesses
+			} else if m.procFilter != "" {
+				m.procFilter = ""
+				m.refreshRows()
+			} else if m.userFilter != "" || m.unitFilter != "" || m.linkFilter != nil {
+				m.userFilter, m.unitFilter, m.linkFilter = "", "", nil
+				m.refreshRows()
+			} else if m.quickFilters != 0 {
+				m.quickFilters = 0
+				m.refreshRows()
+			} else if m.processTable.Focused() {
 				m.tableStyle.Selected = m.baseStyle
 				m.processTable.SetStyles(m.tableStyle)
 				m.processTable.Blur()
@@ -108,60 +488,411 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.processTable.SetStyles(m.tableStyle)
 				m.processTable.Focus()
 			}
-		// Moves the focus up in the process table if the table is focused.
+		// Moves the focus up in the active table if the table is focused.
 		case "up", "k":
-			if m.processTable.Focused() {
+			if m.view == viewUsers {
+				m.userTable.MoveUp(1)
+			} else if m.view == viewUnits {
+				m.unitTable.MoveUp(1)
+			} else if m.view == viewConnections {
+				m.connTable.MoveUp(1)
+			} else if m.processTable.Focused() {
 				m.processTable.MoveUp(1)
+				if m.view == viewProcesses {
+					return m, m.holdSort()
+				}
 			}
-		// Moves the focus down in the process table if the table is focused.
+		// Moves the focus down in the active table if the table is focused.
 		case "down", "j":
-			if m.processTable.Focused() {
+			if m.view == viewUsers {
+				m.userTable.MoveDown(1)
+			} else if m.view == viewUnits {
+				m.unitTable.MoveDown(1)
+			} else if m.view == viewConnections {
+				m.connTable.MoveDown(1)
+			} else if m.processTable.Focused() {
 				m.processTable.MoveDown(1)
+				if m.view == viewProcesses {
+					return m, m.holdSort()
+				}
+			}
+		// Switches between the process table and the per-user view.
+		case "u":
+			if m.view == viewUsers {
+				m.view = viewProcesses
+			} else {
+				m.view = viewUsers
+			}
+		// Switches between the process table and the per-unit view, where systemd runs.
+		case "G":
+			switch {
+			case m.view == viewUnits:
+				m.view = viewProcesses
+			case !m.procOpts.units:
+				m.reportInfo("systemd units are only shown on hosts booted with systemd")
+			default:
+				m.view = viewUnits
+			}
+		// Switches between the process table and the connections view.
+		case "c":
+			if m.view == viewConnections {
+				m.view = viewProcesses
+				return m, nil
+			}
+			m.view = viewConnections
+			m.refreshConnections()
+			return m, m.dnsLookupsCmd()
+		// Shows the key bindings and what the startup probe found on this host.
+		case "?":
+			if m.view == viewHelp {
+				m.view = viewProcesses
+			} else {
+				m.view = viewHelp
+			}
+		// Opens the cleanup assistant for zombies and CLOSE_WAIT pileups.
+		case "Z":
+			m.view = viewCleanup
+			m.cleanupCursor = 0
+		// Shows the interrupt and softirq rates of each CPU.
+		case "I":
+			switch {
+			case m.view == viewInterrupts:
+				m.view = viewProcesses
+			case !m.hasCollector(collectorIRQ):
+				m.reportInfo("interrupt counters are not available on this system")
+			default:
+				m.view = viewInterrupts
+			}
+		// Shows CPU, memory, disk and network graphs on a shared time axis.
+		case "g":
+			if m.view == viewTimeline {
+				m.view = viewProcesses
+			} else {
+				m.view, m.timelineCursor = viewTimeline, 0
+			}
+		// Ends the sort hold and re-sorts the process table now.
+		case "R":
+			if m.sortHeld() {
+				m.sortHeldUntil = time.Time{}
+				m.refreshRows()
+			}
+		// Lists the signals, renices, I/O priority and affinity changes made this session.
+		case "L":
+			if m.view == viewActionLog {
+				m.view = viewProcesses
+			} else {
+				m.view = viewActionLog
+			}
+		// Shows the statistics of the session, exported to a text file with e.
+		case "S":
+			if m.view == viewStats {
+				m.view = viewProcesses
+			} else {
+				m.view = viewStats
+			}
+		case "e":
+			if m.view == viewStats {
+				return m, m.exportStatsCmd()
+			}
+		// Opens the command prompt to jump to a PID or to the process listening on a port.
+		case ":":
+			if m.view == viewProcesses {
+				m.commanding, m.command = true, ""
+			}
+		// Switches the process table between the flat list and the process tree.
+		case "T":
+			if m.view == viewProcesses {
+				m.treeMode = !m.treeMode
+				m.refreshRows()
+			}
+		// Toggles a quick filter: z keeps the zombies and D-state processes, x the ones
+		// above the CPU warning threshold and M the ones above the memory one.
+		case "z", "x", "M":
+			if m.view == viewProcesses {
+				m.quickFilters ^= quickFilterKeys[msg.String()]
+				m.processTable.GotoTop()
+				m.refreshRows()
+			}
+		// Collapses or expands the selected process's subtree in the process tree, or moves
+		// the cursor of the graphs view.
+		case "left", "right":
+			step := 1
+			if msg.String() == "left" {
+				step = -1
+			}
+			switch {
+			case m.view == viewTimeline:
+				m.moveTimelineCursor(step)
+			case m.view == viewProcesses && m.treeMode:
+				m.setCollapsed(step < 0)
+			}
+		// Saves the screen as plain text, layout and all.
+		case "P":
+			return m, m.saveScreenCmd()
+		// Acknowledges the read-only and error reports of the filesystems.
+		case "A":
+			m.fsWatch.acknowledge()
+		// Selects the process with the most disk I/O or network traffic in the process table.
+		case "D":
+			m.selectOffender(diskRate)
+		case "N":
+			m.selectOffender(netRateOf)
+		// Starts typing a search in the connections view or a filter of the process table.
+		case "/":
+			switch m.view {
+			case viewConnections:
+				m.connSearching = true
+			case viewProcesses:
+				m.procSearching = true
+			}
+		// Toggles reverse DNS names for remote addresses in the connections view.
+		case "d":
+			if m.view == viewConnections {
+				m.resolveDNS = !m.resolveDNS
+				m.refreshConnections()
+				return m, m.dnsLookupsCmd()
+			}
+		// Toggles between IEC (KiB, MiB) and SI (kB, MB) byte units everywhere.
+		case "U":
+			Units.SI = !Units.SI
+			m.refreshRows()
+		// Toggles network rates between bits and bytes per second everywhere.
+		case "B":
+			Units.Bits = !Units.Bits
+			m.refreshRows()
+		// Switches the Time column between running and start times and remembers the choice.
+		case "t":
+			if m.view == viewProcesses {
+				Units.StartTimes = !Units.StartTimes
+				m.state.StartTimes = Units.StartTimes
+				m.refreshRows()
+				return m, m.saveStateCmd()
+			}
+		// Switches the Name column between the friendly names of interpreters and the raw
+		// process names.
+		case "n":
+			if m.view == viewProcesses {
+				Names.Friendly = !Names.Friendly
+				m.refreshRows()
+			}
+		// Moves the keyboard focus on to the header and the panels.
+		case "tab":
+			m.focus = m.nextFocus()
+		// Freezes or thaws the process table while the other areas keep updating.
+		case " ":
+			if m.view == viewProcesses {
+				m.toggleFreeze(areaProcesses)
+			}
+		// Pauses or resumes the whole screen.
+		case "p":
+			m.togglePause()
+		// Shrinks or grows the sort column of the focused process table and remembers the width.
+		case "ctrl+left", "ctrl+right":
+			if m.view == viewProcesses && m.processTable.Focused() {
+				step := 1
+				if msg.String() == "ctrl+left" {
+					step = -1
+				}
+				return m, m.resizeColumn(m.sortColumn, step)
+			}
+		// Moves the divider between the upper region and the table up or down.
+		case "ctrl+up", "ctrl+down":
+			step := 1
+			if msg.String() == "ctrl+up" {
+				step = -1
+			}
+			return m, m.moveDivider(step)
+		// Cycles the time window shown by the graphs.
+		case "w":
+			m.historyWindow = m.historyWindow.next()
+		// Cycles the column the active table is sorted by, or the state filter of the connections view.
+		case "s":
+			switch m.view {
+			case viewUsers:
+				m.userSort = m.userSort.next()
+			case viewUnits:
+				m.unitSort = m.unitSort.next()
+			case viewConnections:
+				i := slices.Index(connStateFilters, m.connState)
+				m.connState = connStateFilters[(i+1)%len(connStateFilters)]
+				m.connTable.GotoTop()
+			case viewProcesses:
+				m.sortColumn = nextSortColumn(m.columns, m.sortColumn)
+				m.sortHeldUntil = time.Time{}
+			}
+			m.refreshRows()
+		// Filters the process table to the user or unit selected in the per-user or per-unit
+		// view, or opens the detail view of the selected process.
+		case "enter":
+			if m.view == viewUsers {
+				if row := m.userTable.SelectedRow(); row != nil {
+					m.userFilter = row[0]
+					m.view = viewProcesses
+					m.processTable.GotoTop()
+					m.refreshRows()
+				}
+			} else if m.view == viewUnits {
+				if row := m.unitTable.SelectedRow(); row != nil {
+					m.unitFilter = row[0]
+					m.view = viewProcesses
+					m.processTable.GotoTop()
+					m.refreshRows()
+				}
+			} else if m.view == viewProcesses && m.processTable.Focused() {
+				m.openDetail()
+			}
+		// Asks for confirmation before signalling the selected process (delete, F9) or its whole tree (K).
+		case "delete", "f9", "K":
+			if pid, ok := m.actionPID(); ok {
+				m.openKillDialog(pid, msg.String() == "K")
+			}
+		// Opens the I/O priority picker in the detail view.
+		case "i":
+			if m.view == viewDetail && ioniceSupported {
+				m.openIONicePicker()
+			}
+		// Opens the CPU affinity picker in the detail view, or the actions menu of the
+		// selected process in the process table.
+		case "a":
+			if m.view == viewDetail && affinitySupported {
+				m.openAffinityPicker()
+			} else if m.view == viewProcesses && m.processTable.Focused() {
+				m.openActionMenu()
+			}
+		// Opens the saved views menu to save the current view or recall one.
+		case "V":
+			m.openViewsMenu()
+		// Starts a copy of the selected or detail process's PID, command line or name.
+		case "y":
+			if _, ok := m.actionPID(); ok {
+				m.yanking = true
+			}
+		// Enters history mode at the newest stored sample.
+		case "H":
+			if m.history == nil {
+				m.reportInfo("history is off; start with -history sqlite:/path/db")
+				return m, nil
+			}
+			return m, m.loadFrameCmd(time.Now(), scrubLatest)
+		// Re-reads the config file and applies what can change without a restart.
+		case "r":
+			return m, m.reloadConfigCmd()
+		// Quits the program, asking first when confirm_quit is enabled.
+		case "q":
+			if m.confirmQuit {
+				m.quitPrompt = true
+				return m, nil
 			}
-		// Quits the program by returning the tea.Quit command.
-		case "q", "ctrl+c":
 			return m, tea.Quit
+		// Quits the program immediately by returning the tea.Quit command, or forwards the
+		// interrupt to the command of the run subcommand while it runs.
+		case "ctrl+c":
+			return m.interrupt()
 		}
 	// This custom message is sent periodically by the tickEvery function.
-	// The model's lastUpdate field is updated to the current time.
-	// Fetching CPU Stats, Memory Stats & Processes
+	// Starting a background collection pass unless the previous one is still running,
+	// so a blocked collector never stalls key handling or the shutdown path.
 	// Returning Command: The tickEvery command is returned to ensure that the TickMsg continues to be sent periodically.
 	case TickMsg:
-		m.lastUpdate = time.Time(msg)
-		cpuStats, err := GetCPUStats()
-		if err != nil {
-			slog.Error("Could not get CPU info", "error", err)
-		} else {
-			m.CpuUsage = cpuStats
+		if m.collecting {
+			m.metrics.dropTick()
+			return m, tickEvery(m.interval)
 		}
+		m.collecting = true
+		return m, tea.Batch(tickEvery(m.interval), m.collectCmd())
 
-		memStats, err := GetMEMStats()
-		if err != nil {
-			slog.Error("Could not get memory info", "error", err)
-		} else {
-			m.MemUsage = memStats
+	// Re-sorts the process table once the sort hold ran out; a later navigation key may
+	// have extended it.
+	case sortHoldMsg:
+		if !m.sortHeld() {
+			m.refreshRows()
+		}
+
+	// This message is sent when a background collection pass finished.
+	case collectedMsg:
+		m.collecting = false
+		m.observePass(msg.took)
+		m.applySnapshot(msg)
+		m.recordHistory(msg.at)
+		return m, tea.Batch(m.evaluateAlerts(msg.at), m.dnsLookupsCmd(), m.titleCmd(msg.at))
+
+	// UPower signalled a battery change between refreshes. Plugging or unplugging AC
+	// collects at once, so the alerts and the refresh interval follow without waiting for
+	// a tick that may be battery.interval away.
+	case batteryMsg:
+		wasOnBattery := m.onBattery()
+		m.data.Battery = msg.status
+		if m.applyBattery(wasOnBattery) && !m.collecting {
+			m.collecting = true
+			return m, m.collectCmd()
+		}
+
+	// This message is sent when a copy to the clipboard finished.
+	case clipboardMsg:
+		m.reportCopy(msg)
+
+	// This message is sent when a webhook delivery finished, retries included.
+	case webhookResultMsg:
+		m.storeWebhookResult(msg)
+
+	// This message is sent when a reverse DNS lookup finished; the name replaces the address in place.
+	case dnsResolvedMsg:
+		m.dns.store(msg, time.Now())
+		if m.view == viewConnections {
+			m.refreshConnections()
 		}
 
-		procs, err := GetProcesses(5)
-		if err != nil {
-			slog.Error("Could not get processes", "error", err)
+	// The command of the run subcommand exited; the monitor keeps running so its last
+	// numbers can be read, and its status shows in the banner.
+	case childExitedMsg:
+		m.child.exit(msg)
+		status := fmt.Sprintf("%s finished after %s: %s", m.child.name(), formatAge(msg.at.Sub(m.child.started)), m.child.status())
+		if m.child.exitCode() != 0 {
+			m.reportError(errors.New(status))
 		} else {
-			rows := []table.Row{}
-			for _, p := range procs {
-				memString, memUnit := convertBytes(p.Memory)
-				rows = append(rows, table.Row{
-					fmt.Sprintf("%d", p.PID),
-					p.Name,
-					fmt.Sprintf("%.2f%%", p.CPUPercent),
-					fmt.Sprintf("%s %s", memString, memUnit),
-					p.Username,
-					p.RunningTime,
-				})
-			}
-			m.processTable.SetRows(rows)
+			m.reportInfo(status)
+		}
+		// A command killed by a signal is a crash; its banner already says so.
+		if m.cfg.Crashes.Watch && m.child.signaled() {
+			m.addCrash(m.child.crashEvent())
 		}
 
-		return m, tickEvery()
+	// This message is sent when the crash watcher found a core dump.
+	case crashMsg:
+		m.recordCrash(msg.event)
+
+	// This message is sent on SIGHUP; the config is re-read in the background.
+	case reloadRequestMsg:
+		return m, m.reloadConfigCmd()
+
+	// This message is sent when the config file was re-read.
+	case configLoadedMsg:
+		m.applyReload(msg)
+		return m, m.execPanels.start()
+
+	// This message is sent when the command of an exec panel finished a run.
+	case execResultMsg:
+		return m, m.execPanels.store(msg, m.paused != nil || m.isFrozen(msg.panel.key))
+
+	// This message is sent when a sample was loaded from the history database.
+	case historyFrameMsg:
+		m.applyFrame(msg)
+
+	// This message is sent when the processes of a device picked in a panel were found.
+	case linkFilterMsg:
+		m.applyLinkFilter(msg)
+
+	// This message is sent when the sockets bound to a port looked up with :port were listed.
+	case portListenersMsg:
+		m.showListeners(msg)
+
+	// This message is sent when a background file write finished.
+	case writeDoneMsg:
+		if msg.err != nil {
+			m.reportError(msg.err)
+		} else if msg.what != "" {
+			m.reportInfo(msg.what + " saved to " + msg.path)
+		}
 	}
 	// If the message type does not match any of the handled cases, the model is returned unchanged, and no new command is issued.
 	return m, nil
@@ -170,6 +901,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // Uses lipgloss.JoinVertical and lipgloss.JoinHorizontal to arrange the header content.
 // It displays the last update time and various system statistics (CPU and memory usage) in a structured format.
 func (m model) viewHeader() string {
+	// a flash alert inverts every style derived from the base style for a few refreshes.
+	if m.flash > 0 {
+		m.baseStyle = m.baseStyle.Reverse(true)
+	}
 	// defines the style for list items, including borders, border color, height, and padding.
 	list := m.baseStyle.
 		Border(lipgloss.NormalBorder(), false, true, false, false).
@@ -181,116 +916,608 @@ func (m model) viewHeader() string {
 	listHeader := m.baseStyle.Bold(true).Render
 
 	// helper function that formats a key-value pair with an optional suffix. It aligns the value to the right and renders it with the specified style.
+	// The styles are built once per frame rather than once per item.
+	itemKey := m.baseStyle.Render
+	itemValue := m.baseStyle.Align(lipgloss.Right).Render
 	listItem := func(key string, value string, suffix ...string) string {
-		finalSuffix := ""
 		if len(suffix) > 0 {
-			finalSuffix = suffix[0]
+			value += suffix[0]
+		}
+		return itemKey(key+":") + " " + itemValue(value)
+	}
+
+	// dims the CPU and MEM sections independently when their collector stopped delivering data.
+	cpuList := m.panelStyle(collectorCPU, list)
+	memList := m.panelStyle(collectorMem, list)
+	cpuItem := m.panelStyle(collectorCPU, m.baseStyle).Render
+	memItem := m.panelStyle(collectorMem, m.baseStyle).Render
+
+	// splits items into columns of three below a single title; only the last column of a group
+	// keeps the right border that separates it from the next group.
+	group := func(style lipgloss.Style, title string, items []string) []string {
+		var columns []string
+		for start := 0; start < len(items); start += 3 {
+			end := min(start+3, len(items))
+			colStyle := style.Border(lipgloss.NormalBorder(), false)
+			if end == len(items) {
+				colStyle = style
+			}
+			header := ""
+			if start == 0 {
+				header = title
+			}
+			column := append([]string{listHeader(header)}, items[start:end]...)
+			columns = append(columns, colStyle.Render(lipgloss.JoinVertical(lipgloss.Left, column...)))
 		}
+		return columns
+	}
 
-		listItemValue := m.baseStyle.Align(lipgloss.Right).Render(fmt.Sprintf("%s%s", value, finalSuffix))
+	// CPU and MEM breakdowns only include the fields the platform actually reports.
+	var cpuItems []string
+	for _, f := range cpuFieldsFor(hostOS) {
+		cpuItems = append(cpuItems, listItem(f.label, formatFloat(f.value(m.data.CPU), 1), "%"))
+	}
+	// The average frequency, next to the breakdown, where the platform reports it.
+	if t := m.data.Throttle; t != nil && t.CurMHz > 0 {
+		cpuItems = append(cpuItems, listItem("freq", formatMHz(t.CurMHz)))
+	}
+	var memItems []string
+	for _, f := range memFieldsFor(hostOS) {
+		memItems = append(memItems, listItem(f.label, formatBytes(f.value(m.data.Mem))))
+	}
+	var slabItems []string
+	for _, f := range slabFields(m.data.Mem) {
+		slabItems = append(slabItems, listItem(f.label, formatBytes(f.value(m.data.Mem))))
+	}
+	var hugeItems []string
+	for _, f := range hugePageFields(m.data.Mem) {
+		hugeItems = append(hugeItems, listItem(f.label, formatBytes(f.value(m.data.Mem))))
+	}
 
-		listItemKey := func(key string) string {
-			return m.baseStyle.Render(key + ":")
+	// Dirty pages and their write-back, next to the disk writes that flush them.
+	var writebackItems []string
+	if writebackShown() {
+		dirty := listItem("dirty", formatBytes(m.data.Mem.Dirty))
+		if m.dirtyPressure() {
+			dirty = m.baseStyle.Foreground(Color.Red).Render(dirty)
+		}
+		writebackItems = append(writebackItems,
+			dirty,
+			listItem("rate", formatSignedRate(m.data.Mem.DirtyRate)),
+			listItem("wback", formatBytes(m.data.Mem.WriteBack)))
+		if m.hasCollector(collectorDisk) {
+			writebackItems = append(writebackItems, listItem("disk w", formatBytes(uint64(m.diskWriteRate()))+"/s"))
 		}
+	}
 
-		return fmt.Sprintf("%s %s", listItemKey(key), listItemValue)
+	usage := []string{
+		listHeader("% Usage") + m.frozenBadge(areaHeader),
+		cpuItem(m.viewMeter(meterCPU)),
+		memItem(m.viewMeter(meterMem)),
+	}
+	// The load average is left out where the startup probe found none to read.
+	if m.hasCollector(collectorLoad) {
+		usage = append(usage, m.viewLoad())
+	}
+	sections := []string{
+		// Progress Bars
+		list.Render(lipgloss.JoinVertical(lipgloss.Left, usage...)),
+	}
+	// CPU
+	sections = append(sections, group(cpuList, listHeader("CPU")+m.staleBadge(collectorCPU)+m.viewThrottleBadge()+m.viewUndervoltageBadge(), cpuItems)...)
+	// MEM
+	sections = append(sections, group(memList, listHeader("MEM")+m.staleBadge(collectorMem), memItems)...)
+	// Kernel slab caches, where the platform reports them
+	if len(slabItems) > 0 {
+		sections = append(sections, group(memList, listHeader("SLAB"), slabItems)...)
+	}
+	// Hugepages, where configured
+	if len(hugeItems) > 0 {
+		sections = append(sections, group(memList, listHeader("HUGEPAGES"), hugeItems)...)
+	}
+	// Write-back, where the platform reports it
+	if len(writebackItems) > 0 {
+		sections = append(sections, group(memList, listHeader("WRITEBACK"), writebackItems)...)
 	}
 
+	status := ""
+	if m.cfg.profile != "" {
+		status = "  profile: " + m.cfg.profile
+	}
+	if webhookStatus := m.viewWebhookStatus(); webhookStatus != "" {
+		status += "  " + webhookStatus
+	}
+	if exhaustion := m.viewExhaustion(); exhaustion != "" {
+		status += "  " + exhaustion
+	}
+	if battery := m.viewBattery(); battery != "" {
+		status += "  " + battery
+	}
 	return m.viewStyle.Render(
 		lipgloss.JoinVertical(lipgloss.Top,
-			fmt.Sprintf("Last update: %d milliseconds ago\n", time.Now().Sub(m.lastUpdate).Milliseconds()),
-			lipgloss.JoinHorizontal(lipgloss.Top,
-				// Progress Bars
-				list.Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader("% Usage"),
-						listItem("CPU", fmt.Sprintf("%s %.1f", progressBar(100-m.CpuUsage.Idle, m.baseStyle), 100-m.CpuUsage.Idle), "%"),
-						listItem("MEM", fmt.Sprintf("%s %.1f", progressBar(m.MemUsage.UsedPercent, m.baseStyle), m.MemUsage.UsedPercent), "%"),
-					),
-				),
-
-				// CPU
-				list.Border(lipgloss.NormalBorder(), false).Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader("CPU"),
-						listItem("user", fmt.Sprintf("%.1f", m.CpuUsage.User), "%"),
-						listItem("sys", fmt.Sprintf("%.1f", m.CpuUsage.System), "%"),
-						listItem("idle", fmt.Sprintf("%.1f", m.CpuUsage.Idle), "%"),
-					),
-				),
-				list.Border(lipgloss.NormalBorder(), false).Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader(""),
-						listItem("nice", fmt.Sprintf("%.1f", m.CpuUsage.Nice), "%"),
-						listItem("iowait", fmt.Sprintf("%.1f", m.CpuUsage.Iowait), "%"),
-						listItem("irq", fmt.Sprintf("%.1f", m.CpuUsage.Irq), "%"),
-					),
-				),
-				list.Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader(""),
-						listItem("softirq", fmt.Sprintf("%.1f", m.CpuUsage.Softirq), "%"),
-						listItem("steal", fmt.Sprintf("%.1f", m.CpuUsage.Steal), "%"),
-						listItem("guest", fmt.Sprintf("%.1f", m.CpuUsage.Guest), "%"),
-					),
-				),
-
-				// MEM
-				list.Border(lipgloss.NormalBorder(), false).Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader("MEM"),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Total)
-							return listItem("total", value, unit)
-						}(),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Used)
-							return listItem("used", value, unit)
-						}(),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Available)
-							return listItem("free", value, unit)
-						}(),
-					),
-				),
-				list.Render(
-					lipgloss.JoinVertical(lipgloss.Left,
-						listHeader(""),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Active)
-							return listItem("active", value, unit)
-						}(),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Buffers)
-							return listItem("buffers", value, unit)
-						}(),
-						func() string {
-							value, unit := convertBytes(m.MemUsage.Cached)
-							return listItem("cached", value, unit)
-						}(),
-					),
-				),
-			),
+			m.viewLastUpdate(time.Now())+status+"\n",
+			lipgloss.JoinHorizontal(lipgloss.Top, sections...),
 		),
 	)
 }
 
+// viewLastUpdate renders the age of the data on screen: a placeholder until the first
+// successful collection, then a relative time that turns red once updates have stalled
+// for staleFactor refresh intervals.
+func (m model) viewLastUpdate(now time.Time) string {
+	if m.lastUpdate.IsZero() {
+		return m.baseStyle.Foreground(Color.Secondary).Render("Last update: waiting for first sample…")
+	}
+	age := max(now.Sub(m.lastUpdate), 0)
+	if age < time.Second {
+		// Sub-second ages show in milliseconds, so a fast interval is visibly ticking.
+		return fmt.Sprintf("Last update: %dms ago", age.Milliseconds())
+	}
+	line := "Last update: " + formatAge(age) + " ago"
+	if age > staleFactor*m.interval {
+		return m.baseStyle.Foreground(Color.Red).Render(line + " · updates stalled")
+	}
+	return line
+}
+
+// viewLoad renders the 1, 5 and 15 minute load averages below the header meters.
+func (m model) viewLoad() string {
+	value := "-"
+	if l := m.data.Load; l != nil {
+		value = fmt.Sprintf("%s %s %s", formatFloat(l.Load1, 2), formatFloat(l.Load5, 2), formatFloat(l.Load15, 2))
+	}
+	return m.panelStyle(collectorLoad, m.baseStyle).Render("Load: " + value)
+}
+
+// applySnapshot stores the data of a finished collection pass and records which collectors succeeded.
+func (m *model) applySnapshot(msg collectedMsg) {
+	for _, name := range msg.ok {
+		m.lastSuccess[name] = msg.at
+	}
+	// lastUpdate only moves on a pass where something was collected, so a broken
+	// collection shows as stalled updates rather than fresh, empty ones.
+	if len(msg.ok) > 0 {
+		m.lastUpdate = msg.at
+	}
+
+	prevNet := m.data.Net
+	wasOnBattery := m.onBattery()
+	// The rates of a pass the clock jumped in are left out of the graphs and the session
+	// statistics, or resuming from suspend draws one absurd spike in each.
+	msg.snap.ClockJump = clockJump(m.passAt, msg.at)
+	m.passAt = msg.at
+	if msg.snap.ClockJump != 0 {
+		m.reportInfo(describeClockJump(msg.snap.ClockJump))
+	}
+	m.data = msg.snap
+	m.applyBattery(wasOnBattery)
+	if m.data.ClockJump == 0 {
+		m.recordRates(msg, prevNet)
+	} else {
+		m.netAt = msg.at
+	}
+	if slices.Contains(msg.ok, collectorCPU) && m.data.ClockJump == 0 {
+		m.cpuHistory.add(msg.at, 100-m.data.CPU.Idle)
+	}
+	if slices.Contains(msg.ok, collectorMem) {
+		m.memHistory.add(msg.at, m.data.Mem.UsedPercent)
+		m.availHistory.add(msg.at, float64(m.data.Mem.Available))
+	}
+	if slices.Contains(msg.ok, collectorSockets) {
+		m.timeWaitHistory.add(msg.at, float64(m.data.Sockets.States["TIME_WAIT"]))
+	}
+	if slices.Contains(msg.ok, collectorLoad) && m.data.Load != nil {
+		m.loadHistory.add(msg.at, m.data.Load.Load1)
+	}
+	if m.data.ClockJump == 0 {
+		m.sessionStats.observe(m.data, msg.ok, m.started)
+	}
+	if slices.Contains(msg.ok, collectorProcesses) {
+		m.anomalies.observe(m.data.Procs, m.cfg.Anomalies, msg.at)
+		if m.child != nil {
+			m.child.observe(m.data.Procs)
+		}
+	}
+	if slices.Contains(msg.ok, collectorProcesses) {
+		reports, repin := m.respawns.observe(m.data.Procs, m.cfg.Highlight.Names, m.pinned, msg.at)
+		if len(reports) > 0 {
+			m.reportInfo(strings.Join(reports, "; "))
+		}
+		// The pin follows a restarted process unless something else was pinned meanwhile;
+		// the table drops a pin once its process is gone.
+		if repin != nil && !slices.ContainsFunc(m.data.Procs, m.isPinned) {
+			m.pinned = repin
+		}
+	}
+	if slices.Contains(msg.ok, collectorProcesses) && m.cfg.Crashes.Watch && m.crashWatch == nil {
+		var skip procKey
+		if m.child != nil {
+			skip = m.child.key
+		}
+		// A process killed from the TUI didn't crash.
+		for _, e := range m.exits.observe(m.data.Procs, m.cfg.Highlight.Names, m.pinned, skip, msg.at) {
+			if !m.actionLog.signaled(e.pid) {
+				m.recordCrash(e)
+			}
+		}
+	}
+	if slices.Contains(msg.ok, collectorFS) {
+		m.fsGrowth.observe(m.data.Filesystems, msg.at)
+		if reports := m.fsWatch.observe(m.data.Filesystems); len(reports) > 0 {
+			m.reportError(errors.New(strings.Join(reports, "; ")))
+		}
+	}
+	m.refreshRows()
+	m.refreshDetail()
+}
+
+// reportError logs err and shows it in the error banner.
+func (m *model) reportError(err error) {
+	slog.Error(err.Error())
+	m.banner = err.Error()
+	m.bannerAt = time.Now()
+	m.bannerIsErr = true
+}
+
+// reportInfo shows the outcome of a user action in the banner.
+func (m *model) reportInfo(msg string) {
+	slog.Info(msg)
+	m.banner = msg
+	m.bannerAt = time.Now()
+	m.bannerIsErr = false
+}
+
+// staleBadge returns a "stale 47s" marker for the named collector, or an empty string while its data is fresh.
+func (m model) staleBadge(name string) string {
+	age := m.staleAge(name, time.Now())
+	if age == 0 {
+		return ""
+	}
+	return " " + m.baseStyle.Foreground(Color.Red).Bold(false).Render(fmt.Sprintf("stale %s", age))
+}
+
+// panelStyle dims the given style when the named collector's data is stale.
+func (m model) panelStyle(name string, style lipgloss.Style) lipgloss.Style {
+	if m.staleAge(name, time.Now()) > 0 {
+		return style.Faint(true)
+	}
+	return style
+}
+
+// refreshRows rebuilds the process and per-user tables from the latest process list.
+func (m *model) refreshRows() {
+	data := m.snapshotFor(areaProcesses)
+	procs := make([]ProcessInfo, 0, len(data.Procs))
+	for _, p := range data.Procs {
+		if m.userFilter != "" && p.Username != m.userFilter {
+			continue
+		}
+		if m.unitFilter != "" && p.Unit != m.unitFilter {
+			continue
+		}
+		if m.procFilter != "" && !matchesFilter(p, m.procFilter) {
+			continue
+		}
+		if m.linkFilter != nil && !m.linkFilter.pids[p.PID] {
+			continue
+		}
+		if m.quickFilters != 0 && !m.quickFilters.matches(p, m.cfg.Rows, data.Mem.Total) {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	sortProcesses(procs, m.sortColumn)
+	if m.sortHeld() {
+		keepOrder(procs, m.sortShown)
+	}
+	m.sortShown = shownOrder(procs)
+	m.procMatches = len(procs)
+	if !m.treeMode {
+		procs = m.withChildTree(m.withPinned(procs, data.Procs), data.Procs)
+	}
+
+	var rows []table.Row
+	if m.treeMode {
+		m.pruneCollapsed(data.Procs)
+		rows = treeRows(procs, m.columns, m.collapsed)
+	} else {
+		rows = make([]table.Row, 0, len(procs))
+		for _, p := range procs {
+			rows = append(rows, processRow(p, m.columns))
+		}
+	}
+	m.discardRates(rows, data)
+	// Column widths follow the content, so only touch the columns when a width or the sort marker changed.
+	cols := tableColumns(m.columns, rows, m.sortColumn, m.state.ColumnWidths)
+	if !slices.Equal(cols, m.processTable.Columns()) {
+		m.processTable.SetColumns(cols)
+	}
+	// Cells are cut to the column width by display width here, as the table itself measures
+	// some emoji differently from lipgloss. Matches are highlighted once the widths are
+	// known, so the match can be shifted into view.
+	watched := m.cfg.Highlight.watchedPIDs(procs)
+	bands := m.cfg.Rows.rowBands(procs, data.Mem.Total)
+	for _, row := range rows {
+		for i, c := range m.columns {
+			if m.procFilter != "" && filterColumns[c.id] {
+				row[i] = highlightMatch(row[i], m.procFilter, cols[i].Width)
+			}
+			row[i] = truncate(row[i], cols[i].Width)
+		}
+		if watched[row[0]] {
+			markWatched(row)
+		} else if tag, ok := bands[row[0]]; ok {
+			markBand(row, tag)
+		}
+	}
+	// The table keeps its cursor index across SetRows, so the selection would otherwise
+	// jump to whichever process sorted into that row, or past the end of a shorter list.
+	selected, hadSelection := m.selectedPID()
+	cursor := m.processTable.Cursor()
+	if setRowsIfChanged(&m.processTable, rows) {
+		m.restoreSelection(selected, hadSelection, cursor)
+	}
+	setRowsIfChanged(&m.userTable, userRows(data.Procs, m.userSort))
+	if m.procOpts.units {
+		setRowsIfChanged(&m.unitTable, unitRows(data.Procs, m.unitSort))
+	}
+	if m.view == viewConnections {
+		m.refreshConnections()
+	}
+}
+
+// setRowsIfChanged replaces the rows of t unless they are the same as the ones shown.
+// SetRows renders every row of the table again, the costliest part of a quiet tick, and a
+// frame that didn't change isn't redrawn at all, so a slow link only carries what moved.
+func setRowsIfChanged(t *table.Model, rows []table.Row) bool {
+	if slices.EqualFunc(rows, t.Rows(), slices.Equal) {
+		return false
+	}
+	t.SetRows(rows)
+	return true
+}
+
+// restoreSelection selects pid again after the process table's rows were replaced, or the
+// row at cursor when the process is gone or no PID was selected, clamped to the new length.
+func (m *model) restoreSelection(pid int32, ok bool, cursor int) {
+	rows := m.processTable.Rows()
+	if ok {
+		want := fmt.Sprintf("%d", pid)
+		for i, row := range rows {
+			if stripTags(row[0]) == want {
+				m.setProcessCursor(i)
+				return
+			}
+		}
+	}
+	m.setProcessCursor(min(cursor, len(rows)-1))
+}
+
+// setProcessCursor selects row i of the process table and keeps it on screen. The table
+// renders a window of rows around the cursor and scrolls within it, so SetCursor would
+// shift the list by as many rows as the cursor moved; moving by that many rows instead
+// keeps the top row where it was while the cursor stays in view, like the arrow keys, so
+// a refresh never scrolls the list under the user. Moving by zero rows afterwards pulls
+// the scroll offset back within the rows of a list that shrank.
+func (m *model) setProcessCursor(i int) {
+	switch n := i - m.processTable.Cursor(); {
+	case n < 0:
+		m.processTable.MoveUp(-n)
+	case n > 0:
+		m.processTable.MoveDown(n)
+	default:
+		return
+	}
+	m.processTable.MoveDown(0)
+	m.processTable.MoveUp(0)
+}
+
+// viewMain renders the table selected by the current view mode.
+func (m model) viewMain() string {
+	// Inline, the dialogs are footer prompts below the table instead.
+	if m.actions != nil && !m.inline {
+		return m.viewMenu(m.actions.menu, []string{"↑/↓: select", "enter: run", "esc: close"})
+	}
+	if m.viewsMenu != nil && !m.inline {
+		return m.viewMenu(m.viewsMenu, []string{"↑/↓: select", "enter: save or recall", "1-9: recall", "x: delete", "esc: close"})
+	}
+	if m.kill != nil && !m.inline {
+		return m.viewKillDialog()
+	}
+	if m.portPicker != nil && !m.inline {
+		return m.viewPortPicker()
+	}
+	switch m.view {
+	case viewUsers:
+		return m.viewUsers()
+	case viewUnits:
+		return m.viewTable(m.unitTable)
+	case viewDetail:
+		return m.viewDetail()
+	case viewConnections:
+		return m.viewTable(m.connTable)
+	case viewHelp:
+		return m.viewHelp()
+	case viewStats:
+		return m.viewStats()
+	case viewCleanup:
+		return m.viewCleanup()
+	case viewInterrupts:
+		return m.viewInterrupts()
+	case viewTimeline:
+		return m.viewTimeline()
+	case viewActionLog:
+		return m.viewActionLog()
+	}
+	return m.viewProcess()
+}
+
 func (m model) viewProcess() string {
-	return m.viewStyle.Render(m.processTable.View())
+	return m.viewTable(m.processTable)
+}
+
+func (m model) viewUsers() string {
+	return m.viewTable(m.userTable)
+}
+
+// viewTable renders a table built from process data, dimmed with a badge above it when that data is stale.
+func (m model) viewTable(t table.Model) string {
+	content := m.panelStyle(collectorProcesses, m.viewStyle).Render(colorCells(t.View(), m.selectedPrefix()))
+	if badge := m.staleBadge(collectorProcesses) + m.frozenBadge(areaProcesses); badge != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, badge, content)
+	}
+	return content
+}
+
+// selectedPrefix returns the escape sequence the tables start their selected row with.
+func (m model) selectedPrefix() string {
+	prefix, _, _ := strings.Cut(m.tableStyle.Selected.Render("x"), "x")
+	return prefix
+}
+
+// viewBanner shows the most recent error or notice until it times out.
+func (m model) viewBanner() string {
+	if m.banner == "" || time.Since(m.bannerAt) > bannerTimeout {
+		return ""
+	}
+	if !m.bannerIsErr {
+		return m.baseStyle.Foreground(Color.Highlight).Render(m.banner)
+	}
+	return m.baseStyle.Foreground(Color.Red).Render("error: " + m.banner)
+}
+
+// updateQuitPrompt handles the answer to the quit confirmation.
+func (m model) updateQuitPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "q", "enter", "ctrl+c":
+		return m, tea.Quit
+	}
+	m.quitPrompt = false
+	return m, nil
 }
 
-// creates a visual representation of a percentage as a progress bar.
-func progressBar(percentage float64, baseStyle lipgloss.Style) string {
-	totalBars := 20
-	fillBars := int(percentage / 100 * float64(totalBars))
-	// renders the filled part of the progress bar with a green color.
-	filled := baseStyle.
-		Foreground(Color.Green).
-		Render(strings.Repeat("|", fillBars))
-	// renders the empty part of the progress bar with a secondary color.
-	empty := baseStyle.
-		Foreground(Color.Secondary).
-		Render(strings.Repeat("|", totalBars-fillBars))
-
-	return baseStyle.Render(fmt.Sprintf("%s%s%s%s", "[", filled, empty, "]"))
+// viewFooter shows the keys that apply to the current view and this program's own usage, below a warning when collection
+// keeps overrunning the refresh interval and a notice when the cleanup assistant has found
+// something.
+func (m model) viewFooter() string {
+	lines := []string{m.footerHints()}
+	if self := m.viewSelfUsage(); self != "" {
+		lines[0] += m.baseStyle.Foreground(Color.Secondary).Render("  ·  " + self)
+	}
+	if m.view == viewProcesses {
+		if notice := m.cleanupNotice(); notice != "" {
+			lines = append([]string{m.baseStyle.Foreground(Color.Yellow).Render(notice)}, lines...)
+		}
+	}
+	if warning := m.overrunWarning(); warning != "" {
+		lines = append([]string{m.baseStyle.Foreground(Color.Yellow).Render(warning)}, lines...)
+	}
+	if notice := m.fsAlertNotice(); notice != "" {
+		lines = append([]string{m.baseStyle.Foreground(Color.Red).Render(notice)}, lines...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// footerHints shows the active filter and the keys that apply to the current view.
+func (m model) footerHints() string {
+	hint := m.baseStyle.Foreground(Color.Secondary).Render
+	if m.quitPrompt {
+		return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("Quit? y: yes · any other key: cancel")
+	}
+	if m.childQuitPrompt {
+		return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("Interrupted " + m.child.name() + ". Quit the monitor too? y: yes · any other key: keep watching")
+	}
+	if m.renice != nil {
+		return m.renicePrompt()
+	}
+	if m.yanking {
+		return m.yankPrompt()
+	}
+	if m.namingView {
+		return m.viewNamePrompt()
+	}
+	if m.inline {
+		if prompt := m.inlinePrompt(); prompt != "" {
+			return prompt
+		}
+	}
+	if m.scrub != nil {
+		return m.viewScrubFooter()
+	}
+	if m.paused != nil {
+		return m.baseStyle.Foreground(Color.Highlight).Bold(true).Render("paused") + hint(" · p: resume · q: quit")
+	}
+	if m.focus == areaHeader {
+		return hint(fmt.Sprintf("%s meter: %s · ←/→: select · enter: change style · space: freeze · tab: next · esc: back", meterNames[m.meterFocus], m.meterStyles[m.meterFocus]))
+	}
+	if m.panelFocused() {
+		if m.focus == collectorAudit {
+			return hint("↑/↓: select · x: hide for this session · space: freeze panel · tab: next · esc: back")
+		}
+		if m.focus == collectorDisk || m.focus == collectorIfaces {
+			return hint("↑/↓: select · enter: show its processes · space: freeze panel · tab: next · esc: back")
+		}
+		if m.focusedExecPanel() != nil {
+			return hint("↑/↓: scroll · space: freeze panel · tab: next · esc: back")
+		}
+		return hint("space: freeze panel · tab: next · esc: back")
+	}
+	switch m.view {
+	case viewConnections:
+		return m.viewConnectionsFooter()
+	case viewHelp:
+		return hint("?: close · esc: back")
+	case viewCleanup:
+		return hint("Z: close · esc: back")
+	case viewStats:
+		return hint("e: export to a text file · S: close · esc: back")
+	case viewInterrupts:
+		return hint("I: close · esc: back")
+	case viewActionLog:
+		return hint("L: close · esc: back")
+	case viewTimeline:
+		return hint(fmt.Sprintf("←/→: move the cursor · w: window (%s) · g: close · esc: back", m.historyWindow))
+	case viewUsers:
+		return hint(fmt.Sprintf("sorted by %s · s: sort · enter: show processes · esc: back", m.userSort))
+	case viewUnits:
+		return hint(fmt.Sprintf("sorted by %s · s: sort · enter: show processes · G: close · esc: back", m.unitSort))
+	case viewDetail:
+		var keys []string
+		if ioniceSupported {
+			keys = append(keys, "i: I/O priority")
+		}
+		if affinitySupported {
+			keys = append(keys, "a: CPU affinity")
+		}
+		return hint(strings.Join(append(keys, "y: copy", "del: kill", "K: kill tree", "esc: back"), " · "))
+	}
+	if m.commanding {
+		return m.baseStyle.Foreground(Color.Highlight).Render(":"+m.command+"▏") + hint(" · "+commandUsage)
+	}
+	if m.procSearching {
+		return m.baseStyle.Foreground(Color.Highlight).Render("/"+m.procFilter+"▏") + hint(fmt.Sprintf(" · %s · enter: keep · esc: clear", matchCount(m.procMatches)))
+	}
+	// While the order is held, say so in front of the usual hints, then show the quick
+	// filters that are on.
+	held := ""
+	if m.view == viewProcesses && m.sortHeld() {
+		held = m.baseStyle.Foreground(Color.Highlight).Render("sort held") + hint(" · R: re-sort · ")
+	}
+	held += m.viewQuickChips()
+	if m.procFilter != "" {
+		return held + hint(fmt.Sprintf("filter: %q · %s · /: edit · esc: clear filter", m.procFilter, matchCount(m.procMatches)))
+	}
+	if m.userFilter != "" {
+		return held + hint(fmt.Sprintf("user: %s · esc: clear filter · u: users", m.userFilter))
+	}
+	if m.unitFilter != "" {
+		return held + hint(fmt.Sprintf("unit: %s · esc: clear filter · G: units", m.unitFilter))
+	}
+	if m.linkFilter != nil {
+		return held + hint(fmt.Sprintf("using %s: %s · esc: clear filter", m.linkFilter.label, matchCount(m.procMatches)))
+	}
+	if m.treeMode {
+		return held + hint(fmt.Sprintf("tree · ←/→: collapse/expand · T: flat list · enter: details · a: actions · del: kill · K: kill tree · s: sort (%s) · /: filter · ?: help · q: quit", sortTitle(m.sortColumn)))
+	}
+	return held + hint(fmt.Sprintf("enter: details · a: actions · del: kill · K: kill tree · s: sort (%s) · /: filter · T: tree · u: users · c: connections · U: units (%s) · B: rates in %s · V: views · r: reload config · ?: help · q: quit", sortTitle(m.sortColumn), Units.systemName(), Units.rateName()))
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// send feeds msgs to m in order and returns the resulting model. The commands Update
+// returns are not run.
+func send(m model, msgs ...tea.Msg) model {
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	return m
+}
+
+// keys returns a key press of each of names, as Bubble Tea names them.
+func keys(names ...string) []tea.Msg {
+	var msgs []tea.Msg
+	for _, name := range names {
+		switch name {
+		case "down":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyDown})
+		case "up":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyUp})
+		case "pgdown":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyPgDown})
+		case "esc":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyEsc})
+		case "ctrl+c":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyCtrlC})
+		default:
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)})
+		}
+	}
+	return msgs
+}
+
+// tick is the collectedMsg of a pass that read procs.
+func tick(at time.Time, procs []ProcessInfo) collectedMsg {
+	return collectedMsg{snap: Snapshot{Procs: procs}, at: at, ok: []string{collectorProcesses}}
+}
+
+// busyProcs returns n processes with PIDs 1 to n, in that order by CPU.
+func busyProcs(n int) []ProcessInfo {
+	procs := make([]ProcessInfo, n)
+	for i := range procs {
+		procs[i] = ProcessInfo{PID: int32(i + 1), Name: fmt.Sprintf("worker-%d", i+1), CPUPercent: float64(n - i)}
+	}
+	return procs
+}
+
+// firstVisiblePID returns the PID of the top row the process table shows.
+func firstVisiblePID(t *testing.T, m model) string {
+	t.Helper()
+	for _, line := range strings.Split(ansi.Strip(m.processTable.View()), "\n")[1:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	t.Fatal("the process table shows no rows")
+	return ""
+}
+
+func TestSelectionSurvivesRefresh(t *testing.T) {
+	start := time.Now()
+	m := newModel(defaultConfig())
+	m.sortColumn = columnCPU
+	m = send(m, tea.WindowSizeMsg{Width: 120, Height: 30}, tick(start, busyProcs(200)))
+
+	down := make([]string, 60)
+	for i := range down {
+		down[i] = "down"
+	}
+	m = send(m, keys(down...)...)
+	pid, ok := m.selectedPID()
+	if !ok || pid != 61 {
+		t.Fatalf("selected %d, %v after 60 rows down, want 61", pid, ok)
+	}
+	top := firstVisiblePID(t, m)
+	if top == "1" {
+		t.Fatal("the table didn't scroll")
+	}
+
+	// The next pass changes every row but the order.
+	procs := busyProcs(200)
+	for i := range procs {
+		procs[i].Memory = uint64(i) << 20
+	}
+	m = send(m, tick(start.Add(time.Second), procs))
+	if pid, _ := m.selectedPID(); pid != 61 {
+		t.Errorf("selected %d after a refresh, want 61", pid)
+	}
+	if got := firstVisiblePID(t, m); got != top {
+		t.Errorf("top row is %s after a refresh, want %s", got, top)
+	}
+
+	// The selected process sorts three rows up. Right after the keys the order is held, so
+	// nothing moves under the cursor.
+	procs = busyProcs(200)
+	procs[60].CPUPercent = procs[57].CPUPercent + 0.5
+	m = send(m, tick(start.Add(2*time.Second), procs))
+	if !m.sortHeld() || m.processTable.Cursor() != 60 {
+		t.Fatalf("cursor at row %d during the sort hold, want 60", m.processTable.Cursor())
+	}
+	// Once the hold ran out the cursor follows the process and the list doesn't scroll.
+	m.sortHeldUntil = time.Time{}
+	m = send(m, tick(start.Add(3*time.Second), procs))
+	if pid, _ := m.selectedPID(); pid != 61 {
+		t.Errorf("selected %d after it moved, want 61", pid)
+	}
+	if got := m.processTable.Cursor(); got != 57 {
+		t.Errorf("cursor at row %d, want 57", got)
+	}
+	if got := firstVisiblePID(t, m); got != top {
+		t.Errorf("top row is %s after the selection moved, want %s", got, top)
+	}
+
+	// The selected process exits: the cursor keeps its row, now that of 58.
+	procs = append(busyProcs(200)[:60], busyProcs(200)[61:]...)
+	m = send(m, tick(start.Add(4*time.Second), procs))
+	if pid, _ := m.selectedPID(); pid != 58 {
+		t.Errorf("selected %d after 61 exited, want 58 in its row", pid)
+	}
+	if got := firstVisiblePID(t, m); got != top {
+		t.Errorf("top row is %s after the selection exited, want %s", got, top)
+	}
+
+	// The list shrinks below the cursor: it is clamped to the last row.
+	m = send(m, tick(start.Add(5*time.Second), busyProcs(20)))
+	if pid, ok := m.selectedPID(); !ok || pid != 20 {
+		t.Errorf("selected %d, %v after the list shrank, want the last row", pid, ok)
+	}
+	if !strings.Contains(m.processTable.View(), "worker-20") {
+		t.Errorf("the selected row is out of view after the list shrank:\n%s", m.processTable.View())
+	}
+
+	// The selected process sorts far down, out of view: the list scrolls to it.
+	procs = busyProcs(200)
+	procs[19].CPUPercent = 0.5
+	m = send(m, tick(start.Add(6*time.Second), procs))
+	if cursor := m.processTable.Cursor(); cursor != 199 || !strings.Contains(m.processTable.View(), "worker-20") {
+		t.Errorf("cursor at row %d, want the selection at the bottom in view", cursor)
+	}
+}
+
+func TestBitsKey(t *testing.T) {
+	withUnits(t, iecUnits)
+	m := send(newModel(defaultConfig()), tea.WindowSizeMsg{Width: 300, Height: 30})
+	if !strings.Contains(ansi.Strip(m.footerHints()), "B: rates in bytes") {
+		t.Errorf("footer doesn't show the rate unit: %s", ansi.Strip(m.footerHints()))
+	}
+	m = send(m, keys("B")...)
+	if !Units.Bits || formatRate(125_000) != "976.56 Kib/s" {
+		t.Errorf("B didn't switch to bits: %q", formatRate(125_000))
+	}
+	if !strings.Contains(ansi.Strip(m.footerHints()), "B: rates in bits") {
+		t.Errorf("footer doesn't show the rate unit: %s", ansi.Strip(m.footerHints()))
+	}
+	send(m, keys("B")...)
+	if Units.Bits {
+		t.Error("B didn't switch back to bytes")
+	}
+}
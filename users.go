@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// UserInfo holds the combined resource usage of all processes owned by one user.
+type UserInfo struct {
+	Username   string
+	Processes  int
+	CPUPercent float64
+	Memory     uint64
+	NumFDs     int64
+}
+
+// userSort selects the metric the per-user view is ordered by.
+type userSort int
+
+const (
+	userSortCPU userSort = iota
+	userSortMem
+	userSortProcs
+	userSortFDs
+)
+
+func (s userSort) String() string {
+	switch s {
+	case userSortMem:
+		return "MEM"
+	case userSortProcs:
+		return "Procs"
+	case userSortFDs:
+		return "FDs"
+	default:
+		return "CPU"
+	}
+}
+
+// next returns the metric that follows s when cycling through the sort options.
+func (s userSort) next() userSort {
+	return (s + 1) % (userSortFDs + 1)
+}
+
+// aggregateByUser groups processes by username and sums their resource usage.
+func aggregateByUser(procs []ProcessInfo) []UserInfo {
+	index := map[string]int{}
+	var users []UserInfo
+	for _, p := range procs {
+		i, ok := index[p.Username]
+		if !ok {
+			i = len(users)
+			index[p.Username] = i
+			users = append(users, UserInfo{Username: p.Username})
+		}
+		u := &users[i]
+		u.Processes++
+		u.CPUPercent += p.CPUPercent
+		u.Memory += p.Memory
+		u.NumFDs += int64(p.NumFDs)
+	}
+	return users
+}
+
+// sortUsers orders users by the given metric, highest first. Ties are broken by username
+// so the rows don't jump around between ticks.
+func sortUsers(users []UserInfo, by userSort) {
+	sort.SliceStable(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		switch by {
+		case userSortMem:
+			if a.Memory != b.Memory {
+				return a.Memory > b.Memory
+			}
+		case userSortProcs:
+			if a.Processes != b.Processes {
+				return a.Processes > b.Processes
+			}
+		case userSortFDs:
+			if a.NumFDs != b.NumFDs {
+				return a.NumFDs > b.NumFDs
+			}
+		default:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent > b.CPUPercent
+			}
+		}
+		return a.Username < b.Username
+	})
+}
+
+func newUserTable(styles table.Styles) table.Model {
+	return table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Username", Width: 16},
+			{Title: "Procs", Width: 8},
+			{Title: "CPU", Width: 12},
+			{Title: "MEM", Width: 12},
+			{Title: "FDs", Width: 10},
+		}),
+		table.WithRows([]table.Row{}),
+		table.WithFocused(true),
+		table.WithHeight(20),
+		table.WithStyles(styles),
+	)
+}
+
+// userRows builds the rows of the per-user table from the latest process list.
+func userRows(procs []ProcessInfo, by userSort) []table.Row {
+	users := aggregateByUser(procs)
+	sortUsers(users, by)
+
+	rows := make([]table.Row, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, table.Row{
+			u.Username,
+			formatInt(u.Processes),
+			formatPercent(u.CPUPercent, 2),
+			formatBytes(u.Memory),
+			formatInt(u.NumFDs),
+		})
+	}
+	return rows
+}
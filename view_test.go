@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// withHostOS renders the header with the field tables of goos for the rest of the test.
+func withHostOS(t *testing.T, goos string) {
+	old := hostOS
+	hostOS = goos
+	t.Cleanup(func() { hostOS = old })
+}
+
+// viewModel returns a model sized to an 160x32 terminal, rendering without colors.
+func viewModel(t *testing.T) model {
+	withPlainProfile(t)
+	withHostOS(t, "linux")
+	m := newModel(defaultConfig())
+	m.interval = time.Second
+	return send(m, tea.WindowSizeMsg{Width: 160, Height: 32})
+}
+
+// TestViewLastUpdate renders the whole frame before the first sample, right after one,
+// and once updates stalled.
+func TestViewLastUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		// age is how long ago the last pass was collected; 0 for none yet.
+		age time.Duration
+	}{
+		{"waiting", 0},
+		{"fresh", 2 * time.Second},
+		{"stalled", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		m := viewModel(t)
+		if tt.age > 0 {
+			m = send(m, tick(time.Now().Add(-tt.age), busyProcs(5)))
+		}
+		view := m.View()
+		if w, h := lipgloss.Size(view); w != 160 || h != 32 {
+			t.Errorf("%s: frame is %dx%d, want 160x32", tt.name, w, h)
+		}
+		checkGolden(t, "view_"+tt.name+".golden", view)
+	}
+}
+
+func TestViewLastUpdateLine(t *testing.T) {
+	withPlainProfile(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newModel(defaultConfig())
+	m.interval = 2 * time.Second
+	tests := []struct {
+		last time.Time
+		want string
+	}{
+		{time.Time{}, "Last update: waiting for first sample…"},
+		{now.Add(-250 * time.Millisecond), "Last update: 250ms ago"},
+		// A clock that went back never shows a negative age.
+		{now.Add(time.Second), "Last update: 0ms ago"},
+		{now.Add(-6 * time.Second), "Last update: 6s ago"},
+		{now.Add(-7 * time.Second), "Last update: 7s ago · updates stalled"},
+		{now.Add(-90 * time.Minute), "Last update: 1h30m ago · updates stalled"},
+	}
+	for _, tt := range tests {
+		m.lastUpdate = tt.last
+		if got := m.viewLastUpdate(now); got != tt.want {
+			t.Errorf("last update %s before: %q, want %q", now.Sub(tt.last), got, tt.want)
+		}
+	}
+}
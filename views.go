@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SavedView is a named combination of the process table's columns, sort and filters and
+// the view it is shown in. V saves the current one to the state file; teams can share
+// theirs as [[views]] entries of the config file.
+type SavedView struct {
+	Name string `toml:"name" json:"name"`
+	// Tab is the view shown: "processes", "users", "units" or "connections".
+	Tab string `toml:"tab" json:"tab,omitempty"`
+	// Columns are the IDs of the visible columns, see columns.visible; empty keeps the
+	// current ones.
+	Columns []string `toml:"columns" json:"columns,omitempty"`
+	Sort    string   `toml:"sort" json:"sort,omitempty"`
+	// Filter, User and Unit are the process filter and the user and unit filters.
+	Filter string `toml:"filter" json:"filter,omitempty"`
+	User   string `toml:"user" json:"user,omitempty"`
+	Unit   string `toml:"unit" json:"unit,omitempty"`
+	// Tree shows the process tree instead of the flat list.
+	Tree bool `toml:"tree" json:"tree,omitempty"`
+}
+
+// savedTabs maps the tab names of saved views to the views they show.
+var savedTabs = map[string]viewMode{
+	"processes":   viewProcesses,
+	"users":       viewUsers,
+	"units":       viewUnits,
+	"connections": viewConnections,
+}
+
+// checkViews validates the [[views]] entries. Unknown columns are not an error here: a
+// view written for another version applies what it can when recalled.
+func checkViews(views []SavedView) error {
+	seen := map[string]bool{}
+	for i, v := range views {
+		if strings.TrimSpace(v.Name) == "" {
+			return fmt.Errorf("view %d: name must not be empty", i+1)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("view %d: duplicate name %q", i+1, v.Name)
+		}
+		seen[v.Name] = true
+		if _, ok := savedTabs[v.Tab]; v.Tab != "" && !ok {
+			return fmt.Errorf("view %q: unknown tab %q, want processes, users, units or connections", v.Name, v.Tab)
+		}
+	}
+	return nil
+}
+
+// savedView is a view listed in the V menu, with where it is kept.
+type savedView struct {
+	SavedView
+	fromConfig bool
+}
+
+// maxRecallViews is how many views the V menu recalls by digit.
+const maxRecallViews = 9
+
+// savedViews lists the views of the state file, then those of the config file whose name a
+// view of the state file doesn't shadow.
+func (m model) savedViews() []savedView {
+	var views []savedView
+	for _, v := range m.state.Views {
+		views = append(views, savedView{SavedView: v})
+	}
+	for _, v := range m.cfg.Views {
+		if !slices.ContainsFunc(m.state.Views, func(s SavedView) bool { return s.Name == v.Name }) {
+			views = append(views, savedView{SavedView: v, fromConfig: true})
+		}
+	}
+	return views
+}
+
+// openViewsMenu opens the V menu: save the current view, or recall a saved one.
+func (m *model) openViewsMenu() {
+	items := []menuItem{{id: "save", label: "Save the current view…"}}
+	for i, v := range m.savedViews() {
+		label := v.Name
+		if i < maxRecallViews {
+			label = strconv.Itoa(i+1) + "  " + label
+		}
+		if v.fromConfig {
+			label += "  (config)"
+		}
+		items = append(items, menuItem{id: v.Name, label: label})
+	}
+	m.viewsMenu = newMenu("Saved views", items)
+}
+
+// updateViewsMenu handles key presses while the V menu is open. A digit recalls a view
+// right away, so V 1 brings back the first one.
+func (m model) updateViewsMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "up", "k":
+		m.viewsMenu.move(-1)
+	case "down", "j":
+		m.viewsMenu.move(1)
+	// Saves, or recalls the view under the cursor.
+	case "enter":
+		item, _ := m.viewsMenu.selected()
+		m.viewsMenu = nil
+		if item.id == "save" {
+			m.namingView, m.viewName = true, ""
+			return m, nil
+		}
+		m.recallView(item.id)
+	// Deletes the view under the cursor from the state file; views of the config file stay.
+	case "x":
+		item, _ := m.viewsMenu.selected()
+		i := slices.IndexFunc(m.state.Views, func(v SavedView) bool { return v.Name == item.id })
+		if item.id == "save" || i < 0 {
+			return m, nil
+		}
+		m.state.Views = slices.Delete(m.state.Views, i, i+1)
+		m.openViewsMenu()
+		return m, m.saveStateCmd()
+	case "esc", "q", "V":
+		m.viewsMenu = nil
+	case "ctrl+c":
+		return m.interrupt()
+	default:
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= maxRecallViews {
+			if views := m.savedViews(); n <= len(views) {
+				m.viewsMenu = nil
+				m.recallView(views[n-1].Name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateViewName handles typing the name of the view to save: enter saves it, replacing a
+// saved view of the same name, esc cancels.
+func (m model) updateViewName(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m.interrupt()
+	}
+	m.viewName, m.namingView = editSearch(m.viewName, msg)
+	if msg.Type != tea.KeyEnter {
+		return m, nil
+	}
+	name := strings.TrimSpace(m.viewName)
+	m.viewName = ""
+	if name == "" {
+		return m, nil
+	}
+	v := m.currentView(name)
+	if i := slices.IndexFunc(m.state.Views, func(s SavedView) bool { return s.Name == name }); i >= 0 {
+		m.state.Views[i] = v
+	} else {
+		m.state.Views = append(m.state.Views, v)
+	}
+	m.reportInfo(fmt.Sprintf("saved view %q", name))
+	return m, m.saveStateCmd()
+}
+
+// currentView captures the columns, sort, filters and tab shown now. Views that aren't
+// tables, such as the details, are saved as the process table.
+func (m model) currentView(name string) SavedView {
+	v := SavedView{
+		Name:   name,
+		Tab:    "processes",
+		Sort:   m.sortColumn,
+		Filter: m.procFilter,
+		User:   m.userFilter,
+		Unit:   m.unitFilter,
+		Tree:   m.treeMode,
+	}
+	for tab, mode := range savedTabs {
+		if mode == m.view {
+			v.Tab = tab
+		}
+	}
+	for _, c := range m.columns {
+		v.Columns = append(v.Columns, c.id)
+	}
+	return v
+}
+
+// recallView applies the saved view called name. What can't be applied, such as a column
+// a later version renamed or one whose data isn't collected, is skipped and reported.
+func (m *model) recallView(name string) {
+	i := slices.IndexFunc(m.savedViews(), func(v savedView) bool { return v.Name == name })
+	if i < 0 {
+		return
+	}
+	v := m.savedViews()[i]
+	var skipped []string
+
+	if len(v.Columns) > 0 {
+		var known, unknown, uncollected []string
+		for _, id := range v.Columns {
+			if _, ok := columnByID(id); !ok {
+				unknown = append(unknown, id)
+			} else if !m.procOpts.collects(id) {
+				uncollected = append(uncollected, id)
+			} else {
+				known = append(known, id)
+			}
+		}
+		if len(unknown) > 0 {
+			skipped = append(skipped, "unknown columns "+strings.Join(unknown, ", "))
+		}
+		if len(uncollected) > 0 {
+			skipped = append(skipped, "uncollected columns "+strings.Join(uncollected, ", "))
+		}
+		// Rows are found by the PID in their first cell, so it can't be hidden.
+		if len(known) > 0 && !slices.Contains(known, columnPID) {
+			known = append([]string{columnPID}, known...)
+			skipped = append(skipped, "hiding pid")
+		}
+		if len(known) > 0 {
+			m.setColumns(selectColumns(known))
+		}
+	}
+	if v.Sort != "" {
+		if slices.ContainsFunc(m.columns, func(c processColumn) bool { return c.id == v.Sort }) {
+			m.sortColumn = v.Sort
+		} else {
+			skipped = append(skipped, "sort column "+v.Sort)
+		}
+	}
+	m.procFilter, m.userFilter, m.unitFilter = v.Filter, v.User, v.Unit
+	if v.Unit != "" && !m.procOpts.units {
+		m.unitFilter = ""
+		skipped = append(skipped, "unit filter (no systemd)")
+	}
+	m.treeMode = v.Tree
+	m.view = viewProcesses
+	if mode, ok := savedTabs[v.Tab]; ok {
+		switch {
+		case mode == viewUnits && !m.procOpts.units:
+			skipped = append(skipped, "units tab (no systemd)")
+		case mode == viewConnections:
+			m.view = mode
+			m.refreshConnections()
+		default:
+			m.view = mode
+		}
+	}
+	m.processTable.GotoTop()
+	m.refreshRows()
+
+	if len(skipped) > 0 {
+		m.reportError(fmt.Errorf("view %q applied without %s", name, strings.Join(skipped, "; ")))
+		return
+	}
+	m.reportInfo(fmt.Sprintf("view %q", name))
+}
+
+// viewNamePrompt renders the name being typed for a view to save.
+func (m model) viewNamePrompt() string {
+	return m.baseStyle.Foreground(Color.Highlight).Render("save view as: "+m.viewName+"▏") +
+		m.baseStyle.Foreground(Color.Secondary).Render(" · enter: save · esc: cancel")
+}
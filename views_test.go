@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// columnIDsOf returns the IDs of the columns m shows.
+func columnIDsOf(m model) []string {
+	var ids []string
+	for _, c := range m.columns {
+		ids = append(ids, c.id)
+	}
+	return ids
+}
+
+func TestRecallViewKeepsPID(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Views = []SavedView{{Name: "slim", Columns: []string{columnName, columnCPU}}}
+	m := newModel(cfg)
+	m.recallView("slim")
+	if ids := columnIDsOf(m); len(ids) != 3 || ids[0] != columnPID {
+		t.Errorf("columns = %v, want pid, name and cpu", ids)
+	}
+	if !m.bannerIsErr || !strings.Contains(m.banner, "hiding pid") {
+		t.Errorf("banner = %q, want the hidden pid reported", m.banner)
+	}
+}
+
+func TestRecallViewUncollectedColumns(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Views = []SavedView{{Name: "deep", Columns: []string{columnPID, columnName, columnFDs, columnCtxSwitch, columnNet, columnMemLimit}}}
+	m := newModel(cfg)
+	m.enableLowOverhead()
+	m.recallView("deep")
+	if ids := strings.Join(columnIDsOf(m), ","); ids != "pid,name" {
+		t.Errorf("columns = %s, want pid,name", ids)
+	}
+	if want := "uncollected columns fds, ctxsw, net, memlimit"; !strings.Contains(m.banner, want) {
+		t.Errorf("banner = %q, want it to contain %q", m.banner, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// watches reports whether p is one of the watched processes.
+func (c HighlightConfig) watches(p ProcessInfo) bool {
+	_, ok := watchedName(p, c.Names)
+	return ok
+}
+
+// watchedPIDs returns the PID cells of the watched processes among procs, for marking
+// their rows once the table rows are built.
+func (c HighlightConfig) watchedPIDs(procs []ProcessInfo) map[string]bool {
+	if len(c.Names) == 0 {
+		return nil
+	}
+	pids := map[string]bool{}
+	for _, p := range procs {
+		if c.watches(p) {
+			pids[fmt.Sprintf("%d", p.PID)] = true
+		}
+	}
+	return pids
+}
+
+// markWatched draws every cell of row in the watched color. Cells that carry a color of
+// their own, such as a negative nice value, keep it.
+func markWatched(row table.Row) {
+	for i, cell := range row {
+		if !strings.ContainsAny(cell, tagRed+tagDim+tagHighlight) {
+			row[i] = tagCell(cell, tagWatched)
+		}
+	}
+}
+
+// watchedPanel sums the CPU and memory of the processes of each watched name, however
+// far down the table they sort, with the restarts seen this session. A name with no
+// process running is shown in red: it is often the one thing to notice.
+func (m model) watchedPanel() panel {
+	p := panel{collector: collectorProcesses, title: "Watched"}
+	if !m.cfg.Highlight.Panel || len(m.cfg.Highlight.Names) == 0 || m.data.Procs == nil {
+		return p
+	}
+	type usage struct {
+		count int
+		cpu   float64
+		mem   uint64
+	}
+	byName := map[string]*usage{}
+	for _, name := range m.cfg.Highlight.Names {
+		byName[name] = &usage{}
+	}
+	for _, proc := range m.data.Procs {
+		if name, ok := watchedName(proc, m.cfg.Highlight.Names); ok {
+			u := byName[name]
+			u.count++
+			u.cpu += proc.CPUPercent
+			u.mem += proc.Memory
+		}
+	}
+	for _, name := range m.cfg.Highlight.Names {
+		u := byName[name]
+		restarts := ""
+		if n := m.respawns.restarts[name]; n > 0 {
+			restarts = m.baseStyle.Foreground(Color.Yellow).Render(fmt.Sprintf(" restarted %s×", formatInt(n)))
+		}
+		if u.count == 0 {
+			p.lines = append(p.lines, m.baseStyle.Foreground(Color.Red).Render(fmt.Sprintf("%s not running", fit(name, 16)))+restarts)
+			continue
+		}
+		p.lines = append(p.lines, fmt.Sprintf("%s %3s× %8s %11s", fit(name, 16), formatInt(u.count), formatPercent(u.cpu, 1), formatBytes(u.mem))+restarts)
+	}
+	return p
+}
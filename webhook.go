@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Webhook is one [[webhooks]] entry of the config file. Every alert that fires or
+// resolves is POSTed to it as a JSON alertEvent.
+type Webhook struct {
+	URL string `toml:"url"`
+	// Secret, when set, signs each payload with HMAC-SHA256 in the X-Signature-256
+	// header as "sha256=<hex>", so the receiver can check where it came from.
+	Secret string `toml:"secret"`
+	// Timeout bounds each delivery attempt; zero means defaultWebhookTimeout.
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// defaultWebhookTimeout is used by webhooks that don't set a timeout.
+const defaultWebhookTimeout = 5 * time.Second
+
+// webhookRetries are the pauses before each retry of a failed delivery.
+var webhookRetries = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+// Alert states in webhook payloads.
+const (
+	alertFiring   = "firing"
+	alertResolved = "resolved"
+)
+
+// alertEvent is the JSON payload of a webhook delivery.
+type alertEvent struct {
+	Rule      string    `json:"rule"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Hostname  string    `json:"hostname"`
+	Time      time.Time `json:"timestamp"`
+	State     string    `json:"state"`
+}
+
+// hostname names this host in webhook payloads.
+var hostname, _ = os.Hostname()
+
+// checkWebhook validates one webhook entry of the config.
+func checkWebhook(w Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL, got %q", w.URL)
+	}
+	if w.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", w.Timeout)
+	}
+	return nil
+}
+
+// webhookResultMsg reports the outcome of delivering one event to one webhook. seq numbers
+// the deliveries, as their retries make them finish out of order.
+type webhookResultMsg struct {
+	url string
+	seq uint64
+	err error
+}
+
+// webhookStatus is the outcome of the latest delivery to a webhook that finished.
+type webhookStatus struct {
+	seq uint64
+	err error
+}
+
+// webhookCmd delivers the event for rule to every configured webhook in the background.
+func (m *model) webhookCmd(rule AlertRule, value float64, now time.Time, state string) tea.Cmd {
+	if len(m.cfg.Webhooks) == 0 {
+		return nil
+	}
+	event := alertEvent{
+		Rule:      rule.Name,
+		Metric:    rule.Metric,
+		Value:     value,
+		Threshold: rule.threshold(),
+		Hostname:  hostname,
+		Time:      now,
+		State:     state,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil
+	}
+	m.webhookSeq++
+	seq := m.webhookSeq
+	cmds := make([]tea.Cmd, 0, len(m.cfg.Webhooks))
+	for _, w := range m.cfg.Webhooks {
+		cmds = append(cmds, func() tea.Msg {
+			return webhookResultMsg{url: w.URL, seq: seq, err: deliverWebhook(w, body)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// deliverWebhook POSTs body to w, retrying with backoff on network errors, 429 and 5xx
+// responses. Other client errors are not retried, as the same request would fail again.
+func deliverWebhook(w Webhook, body []byte) error {
+	timeout := w.Timeout
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = postWebhook(client, w, body)
+		if err == nil || !retry || attempt == len(webhookRetries) {
+			return err
+		}
+		time.Sleep(webhookRetries[attempt])
+	}
+}
+
+// postWebhook makes one delivery attempt and reports whether a failure is worth retrying.
+func postWebhook(client *http.Client, w Webhook, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", appName)
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, fmt.Errorf("%s", resp.Status)
+	}
+	return false, nil
+}
+
+// storeWebhookResult keeps the outcome of a delivery unless a later one already finished
+// or a reload removed the webhook while it was delivered.
+func (m *model) storeWebhookResult(msg webhookResultMsg) {
+	if last, ok := m.webhookStatus[msg.url]; ok && last.seq > msg.seq {
+		return
+	}
+	if !slices.ContainsFunc(m.cfg.Webhooks, func(w Webhook) bool { return w.URL == msg.url }) {
+		return
+	}
+	m.webhookStatus[msg.url] = webhookStatus{seq: msg.seq, err: msg.err}
+}
+
+// pruneWebhookStatus forgets the webhooks cfg no longer lists.
+func (m *model) pruneWebhookStatus(cfg Config) {
+	for u := range m.webhookStatus {
+		if !slices.ContainsFunc(cfg.Webhooks, func(w Webhook) bool { return w.URL == u }) {
+			delete(m.webhookStatus, u)
+		}
+	}
+}
+
+// viewWebhookStatus flags webhooks whose last delivery failed, or returns "". Only the
+// host is shown: the URL of many receivers, such as Slack's, is their secret.
+func (m model) viewWebhookStatus() string {
+	var urls []string
+	for u, st := range m.webhookStatus {
+		if st.err != nil {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return ""
+	}
+	sort.Strings(urls)
+	err := m.webhookStatus[urls[0]].err
+	// A transport error names the whole URL; show only its cause.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	host := urls[0]
+	if u, perr := url.Parse(urls[0]); perr == nil {
+		host = u.Host
+	}
+	status := fmt.Sprintf("webhook failing: %s: %v", host, err)
+	if len(urls) > 1 {
+		status += fmt.Sprintf(" (+%d more)", len(urls)-1)
+	}
+	return m.baseStyle.Foreground(Color.Red).Render(status)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const slackURL = "https://hooks.slack.com/services/T000/B000/secret-token"
+
+func TestWebhookStatus(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Webhooks = []Webhook{{URL: slackURL}, {URL: "https://alerts.example.com/hook"}}
+	m := newModel(cfg)
+
+	failed := errors.New("503 Service Unavailable")
+	// The retries of the first delivery finish after the second delivery succeeded.
+	m = send(m, webhookResultMsg{url: slackURL, seq: 2}, webhookResultMsg{url: slackURL, seq: 1, err: failed})
+	if status := m.viewWebhookStatus(); status != "" {
+		t.Errorf("a late failure overrides a newer success: %q", ansi.Strip(status))
+	}
+
+	m = send(m, webhookResultMsg{url: slackURL, seq: 3, err: failed})
+	status := ansi.Strip(m.viewWebhookStatus())
+	if status != "webhook failing: hooks.slack.com: 503 Service Unavailable" {
+		t.Errorf("status = %q", status)
+	}
+	if strings.Contains(status, "secret-token") {
+		t.Error("the status shows the secret part of the URL")
+	}
+
+	// Removing the failing webhook clears its status, including a delivery still running.
+	cfg.Webhooks = cfg.Webhooks[1:]
+	m.applyConfig(cfg)
+	m = send(m, webhookResultMsg{url: slackURL, seq: 4, err: failed})
+	if status := m.viewWebhookStatus(); status != "" {
+		t.Errorf("a removed webhook still shows: %q", ansi.Strip(status))
+	}
+}
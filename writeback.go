@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// dirtyTracker turns the Dirty figure of successive memory reads into a rate of change.
+// Dirty pages piling up faster than the disks write them back end in a flush of gigabytes
+// at once, which stalls every writer for seconds.
+type dirtyTracker struct {
+	prev uint64
+	at   time.Time
+}
+
+// observe sets s.DirtyRate from the change since the previous read; the first read has
+// nothing to compare against and leaves it 0.
+func (t *dirtyTracker) observe(s *MemStats, now time.Time) {
+	if !t.at.IsZero() && now.After(t.at) {
+		s.DirtyRate = (float64(s.Dirty) - float64(t.prev)) / now.Sub(t.at).Seconds()
+	}
+	t.prev, t.at = s.Dirty, now
+}
+
+// writebackShown reports whether the header shows the WRITEBACK section; only Linux
+// reports dirty and writeback pages.
+func writebackShown() bool {
+	return hostOS == "linux"
+}
+
+// dirtyPressure reports whether the dirty pages exceed the configured share of RAM.
+func (m model) dirtyPressure() bool {
+	warn := m.cfg.Memory.DirtyWarnPercent
+	return warn > 0 && m.data.Mem.Total > 0 && float64(m.data.Mem.Dirty)/float64(m.data.Mem.Total)*100 >= warn
+}
+
+// diskWriteRate is the combined write throughput of the local disks, which is where the
+// dirty pages go when they are flushed.
+func (m model) diskWriteRate() float64 {
+	var total float64
+	for _, d := range m.data.Disks {
+		total += d.WriteRate
+	}
+	return total
+}
+
+// formatSignedRate renders a rate of change in bytes per second with its sign.
+func formatSignedRate(rate float64) string {
+	sign := "+"
+	if rate < 0 {
+		sign = "-"
+	}
+	return sign + formatBytes(uint64(math.Abs(rate))) + "/s"
+}